@@ -18,9 +18,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -28,118 +25,50 @@ import (
 
 	"github.com/caarlos0/env/v6"
 	"github.com/spf13/cobra"
-	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
-	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
 	"github.com/thestormforge/optimize-go/pkg/command"
 	"github.com/thestormforge/optimize-go/pkg/config"
 	"golang.org/x/oauth2"
+	"sigs.k8s.io/yaml"
 )
 
 func main() {
-	cfg := &config.Config{}
+	cfg := &config.Config{Credentials: config.DefaultCredentialStore()}
+	baseTransport := http.DefaultTransport
 
-	cmd := &cobra.Command{
-		Use:          "optimize",
-		SilenceUsage: true,
+	loadConfig := func(ctx context.Context, filename string) (command.Config, http.RoundTripper, error) {
+		dstCfg := &config.Config{Credentials: cfg.Credentials}
+		if err := env.Parse(dstCfg); err != nil {
+			return nil, nil, err
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := yaml.Unmarshal(data, dstCfg); err != nil {
+			return nil, nil, err
+		}
+		return dstCfg, dstCfg.Transport(dstCfg.TokenSource(ctx), baseTransport), nil
+	}
+
+	cmd := command.NewRootCommand(cfg, command.RootOptions{
+		LoadConfig: loadConfig,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			if err := env.Parse(cfg); err != nil {
 				return err
 			}
 
-			http.DefaultTransport = cfg.Transport(cfg.TokenSource(cmd.Context()), http.DefaultTransport)
+			if cfg.Organization == "" {
+				org, err := command.SelectedOrganization(cfg)
+				if err != nil {
+					return err
+				}
+				cfg.Organization = org
+			}
+
+			http.DefaultTransport = cfg.Transport(cfg.TokenSource(cmd.Context()), baseTransport)
 			return nil
 		},
-	}
-
-	// Aggregate the CREATE commands
-	createCmd := &cobra.Command{
-		Use: "create",
-	}
-
-	createCmd.AddCommand(
-		command.NewCreateApplicationCommand(cfg, &printer{format: `created application %q.`}),
-		command.NewCreateScenarioCommand(cfg, &printer{format: `created scenario %q.`}),
-		command.NewCreateTrialCommand(cfg, &printer{format: `created trial %q.`}),
-	)
-
-	// Aggregate the EDIT commands
-	editCmd := &cobra.Command{
-		Use: "edit",
-	}
-
-	editCmd.AddCommand(
-		command.NewEditApplicationCommand(cfg, &printer{format: `updated application %q.`}),
-		command.NewEditScenarioCommand(cfg, &printer{format: `updated scenario %q.`}),
-		command.NewEditExperimentCommand(cfg, &printer{format: `updated experiment %q.`}),
-		command.NewEditTrialCommand(cfg, &printer{format: `updated trial %q.`}),
-		command.NewEditClusterCommand(cfg, &printer{format: `updated cluster %q.`}),
-	)
-
-	// Aggregate the GET commands
-	getCmd := &cobra.Command{
-		Use: "get",
-	}
-
-	getCmd.AddCommand(
-		command.NewGetApplicationsCommand(cfg, &printer{}),
-		command.NewGetScenariosCommand(cfg, &printer{}),
-		command.NewGetRecommendationsCommand(cfg, &printer{}),
-		command.NewGetExperimentsCommand(cfg, &printer{}),
-		command.NewGetTrialsCommand(cfg, &printer{}),
-		command.NewGetClustersCommand(cfg, &printer{}),
-		command.NewGetActivityCommand(cfg, &printer{}),
-	)
-
-	// Aggregate the DELETE commands
-	deleteCmd := &cobra.Command{
-		Use: "delete",
-	}
-
-	deleteCmd.AddCommand(
-		command.NewDeleteApplicationsCommand(cfg, &printer{format: `deleted application %q.`}),
-		command.NewDeleteScenariosCommand(cfg, &printer{format: `deleted scenario %q.`}),
-		command.NewDeleteExperimentsCommand(cfg, &printer{format: `deleted experiment %q.`}),
-		command.NewDeleteTrialsCommand(cfg, &printer{format: `deleted trial %q.`}),
-		command.NewDeleteClustersCommand(cfg, &printer{format: `deleted cluster %q.`}),
-	)
-
-	// Aggregate the ENABLE commands
-	enableCmd := &cobra.Command{
-		Use: "enable",
-	}
-
-	enableCmd.AddCommand(
-		command.NewEnableApplicationRecommendationsCommand(cfg, &printer{format: `enabled application recommendations.`}),
-	)
-
-	// Aggregate the DISABLE commands
-	disableCmd := &cobra.Command{
-		Use: "disable",
-	}
-
-	disableCmd.AddCommand(
-		command.NewDisableApplicationRecommendationsCommand(cfg, &printer{format: `disabled application recommendations.`}),
-	)
-
-	// Aggregate the WATCH commands
-	watchCmd := &cobra.Command{
-		Use: "watch",
-	}
-
-	watchCmd.AddCommand(
-		command.NewWatchActivityCommand(cfg),
-	)
-
-	// Add the aggregate commends to the root
-	cmd.AddCommand(
-		createCmd,
-		editCmd,
-		getCmd,
-		deleteCmd,
-		enableCmd,
-		watchCmd,
-		command.NewWhoAmICommand(cfg),
-	)
+	})
 
 	// Create a context for the command
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -152,37 +81,6 @@ func main() {
 	err := cmd.ExecuteContext(ctx)
 	cancel()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(command.HandleError(cmd, err))
 	}
 }
-
-type printer struct {
-	format string
-}
-
-func (p *printer) Fprint(w io.Writer, obj interface{}) error {
-	if p.format != "" {
-		format := p.format + "\n"
-		var err error
-		switch obj := obj.(type) {
-		case *applications.ApplicationItem:
-			_, err = fmt.Fprintf(w, format, obj.Name)
-		case *applications.ScenarioItem:
-			_, err = fmt.Fprintf(w, format, obj.Name)
-		case *applications.RecommendationList:
-			_, err = fmt.Fprint(w, format)
-		case *applications.RecommendationItem:
-			_, err = fmt.Fprintf(w, format, obj.Name)
-		case *experiments.ExperimentItem:
-			_, err = fmt.Fprintf(w, format, obj.Name)
-		case *experiments.TrialItem:
-			_, err = fmt.Fprintf(w, format, experiments.JoinTrialName(obj.Experiment, obj.Number))
-		}
-		return err
-	}
-
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(false)
-	enc.SetIndent("", "  ")
-	return enc.Encode(obj)
-}
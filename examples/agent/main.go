@@ -0,0 +1,101 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The agent example subscribes to the application activity feed and handles
+// each activity item, acknowledging it once it has been processed. Interrupting
+// the program drains the subscription so in-flight items are not lost.
+//
+// Configuration is read from the environment (e.g. `STORMFORGE_CLIENT_ID` and
+// `STORMFORGE_CLIENT_SECRET`).
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	"github.com/thestormforge/optimize-go/pkg/config"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg := &config.Config{}
+	if err := env.Parse(cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := api.NewClient(cfg.Address(), cfg.Transport(cfg.TokenSource(ctx), http.DefaultTransport))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	appAPI := applications.NewAPI(client)
+
+	q := applications.ActivityFeedQuery{}
+	q.SetType(applications.TagScan, applications.TagRun)
+	sub, err := appAPI.SubscribeActivity(ctx, q)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Split the feed so each kind of activity is handled independently
+	scans, runs := make(chan applications.ActivityItem), make(chan applications.ActivityItem)
+	f := &applications.FanOut{Subscriber: sub}
+	f.Add(scans, applications.TagScan)
+	f.Add(runs, applications.TagRun)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handle(scans, sub, "scan")
+	}()
+	go handle(runs, sub, "run")
+
+	// Drain the subscription when interrupted
+	go func() {
+		<-ctx.Done()
+		drainCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := f.Drain(drainCtx); err != nil {
+			log.Printf("drain: %v", err)
+		}
+	}()
+
+	if err := f.Subscribe(context.Background(), nil); err != nil {
+		log.Fatal(err)
+	}
+	<-done
+}
+
+// handle processes the activity items received on the supplied channel.
+func handle(ch <-chan applications.ActivityItem, sub applications.Subscriber, kind string) {
+	for item := range ch {
+		log.Printf("%s activity %s: %s", kind, item.ID, item.Title)
+
+		// Only the polling subscriber tracks acknowledgements
+		if ps, ok := sub.(*applications.PollingSubscriber); ok {
+			ps.Ack(item.ID)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples_test
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// TestBuild ensures the examples continue to compile against the current API.
+func TestBuild(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool is not available")
+	}
+
+	out, err := exec.Command(goTool, "build", "./...").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to build examples: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The recommendations example is a bot which finds the recommendations that
+// have not been deployed yet and writes the corresponding `kubectl patch`
+// commands to stdout. Resource changes are limited to a maximum step from the
+// current values (when they are known) using the recommendation guardrails.
+//
+// Usage:
+//
+//	recommendations -max-step cpu=30% -max-step memory=20% | sh
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	"github.com/thestormforge/optimize-go/pkg/command/recommendation"
+	"github.com/thestormforge/optimize-go/pkg/config"
+)
+
+// stepValues collects `resource=limit` pairs.
+type stepValues map[string]string
+
+func (s stepValues) String() string { return fmt.Sprint(map[string]string(s)) }
+
+func (s stepValues) Set(v string) error {
+	k, l, ok := strings.Cut(v, "=")
+	if !ok {
+		return fmt.Errorf("expected RESOURCE=LIMIT, got %q", v)
+	}
+	s[k] = l
+	return nil
+}
+
+func main() {
+	steps := stepValues{}
+	flag.Var(steps, "max-step", "a RESOURCE=LIMIT pair restricting the change of a resource")
+	flag.Parse()
+
+	limits, err := recommendation.ParseStepLimits(steps)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg := &config.Config{}
+	if err := env.Parse(cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := api.NewClient(cfg.Address(), cfg.Transport(cfg.TokenSource(ctx), http.DefaultTransport))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	l := applications.Lister{
+		API: applications.NewAPI(client),
+	}
+
+	err = l.ForEachApplication(ctx, applications.ApplicationListQuery{}, func(app *applications.ApplicationItem) error {
+		// The discovered workloads include the current resources of each container
+		var workloads []applications.WorkloadItem
+		if err := l.ForEachWorkload(ctx, &app.Application, func(item *applications.WorkloadItem) error {
+			workloads = append(workloads, *item)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return l.ForEachRecommendation(ctx, &app.Application, func(item *applications.RecommendationItem) error {
+			if item.DeployedAt != nil {
+				return nil
+			}
+
+			rec, err := l.API.GetRecommendation(ctx, item.Link(api.RelationSelf))
+			if err != nil {
+				return err
+			}
+
+			for _, p := range rec.Parameters {
+				current, err := recommendation.CurrentResources(workloads, p.Target)
+				if err != nil {
+					return err
+				}
+
+				patch, clamped := limits.Clamp(current, p.ContainerResources)
+				for _, c := range clamped {
+					log.Print(c.String())
+				}
+
+				if err := printPatch(p.Target, patch); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printPatch writes a `kubectl patch` command for the supplied container resources.
+func printPatch(target applications.TargetRef, containerResources []interface{}) error {
+	var containers []interface{}
+	for _, cr := range containerResources {
+		m, ok := cr.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := m["containerName"].(string)
+		resources := map[string]interface{}{}
+		for _, k := range []string{"requests", "limits"} {
+			if rl, ok := m[k].(map[string]interface{}); ok {
+				resources[k] = quantities(rl)
+			}
+		}
+		containers = append(containers, map[string]interface{}{
+			"name":      name,
+			"resources": resources,
+		})
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": containers},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Printf("kubectl patch --namespace %q %s %q --patch %q\n",
+		target.Namespace, strings.ToLower(target.Kind), target.Workload, patch)
+	return err
+}
+
+// quantities converts a resource list to Kubernetes quantities, numeric CPU values are in millicores.
+func quantities(rl map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(rl))
+	for k, v := range rl {
+		switch f, ok := v.(float64); {
+		case ok && k == "cpu":
+			result[k] = fmt.Sprintf("%.0fm", f)
+		case ok:
+			result[k] = fmt.Sprintf("%.0f", f)
+		default:
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The trialloop example runs the trials of an experiment, applying the
+// assignments using a user supplied command and collecting the metric values
+// from Prometheus once the command completes.
+//
+// Usage:
+//
+//	trialloop -experiment NAME -prometheus http://localhost:9090 -metric 'latency=histogram_quantile(0.95, ...)' -- ./deploy.sh
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/config"
+	"github.com/thestormforge/optimize-go/pkg/metrics"
+)
+
+// metricQueries maps metric names to PromQL queries.
+type metricQueries map[string]string
+
+func (m metricQueries) String() string { return fmt.Sprint(map[string]string(m)) }
+
+func (m metricQueries) Set(s string) error {
+	name, query, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expected NAME=QUERY, got %q", s)
+	}
+	m[name] = query
+	return nil
+}
+
+// Queries returns the metric queries, evaluated as instant queries.
+func (m metricQueries) Queries() []metrics.Query {
+	result := make([]metrics.Query, 0, len(m))
+	for name, query := range m {
+		result = append(result, metrics.Query{MetricName: name, Query: query})
+	}
+	return result
+}
+
+func main() {
+	var (
+		name       string
+		prometheus string
+		queries    = metricQueries{}
+	)
+	flag.StringVar(&name, "experiment", "", "the name of the experiment to run")
+	flag.StringVar(&prometheus, "prometheus", "http://localhost:9090", "the Prometheus server address")
+	flag.Var(queries, "metric", "a NAME=QUERY pair used to collect a metric value")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("missing command used to apply trial assignments")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg := &config.Config{}
+	if err := env.Parse(cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := api.NewClient(cfg.Address(), cfg.Transport(cfg.TokenSource(ctx), http.DefaultTransport))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	prom := &metrics.Prometheus{URL: prometheus}
+	expAPI := experiments.NewAPI(client)
+	exp, err := expAPI.GetExperimentByName(ctx, experiments.ExperimentName(name))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	l := &experiments.TrialLoop{
+		API:          expAPI,
+		TrialTimeout: 30 * time.Minute,
+		OnTrialFinish: func(ta *experiments.TrialAssignments, vls *experiments.TrialValues) {
+			log.Printf("trial finished: %+v", vls.Values)
+		},
+	}
+
+	err = l.Run(ctx, exp.Link(api.RelationNextTrial), func(ctx context.Context, ta *experiments.TrialAssignments) (experiments.TrialValues, error) {
+		vls := experiments.TrialValues{}
+
+		// Apply the assignments using the command line arguments
+		cmd := exec.CommandContext(ctx, flag.Arg(0), flag.Args()[1:]...)
+		cmd.Env = os.Environ()
+		for _, a := range ta.Assignments {
+			cmd.Env = append(cmd.Env, a.ParameterName+"="+a.Value.String())
+		}
+		cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+		start := time.Now()
+		if err := cmd.Run(); err != nil {
+			return vls, err
+		}
+		end := time.Now()
+
+		// Collect the metric values
+		values, err := prom.Query(ctx, queries.Queries(), metrics.Window{Start: start, End: end})
+		if err != nil {
+			return vls, err
+		}
+		vls.Values = values
+
+		vls.StartTime = &start
+		vls.CompletionTime = &end
+		return vls, nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
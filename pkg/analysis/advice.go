@@ -0,0 +1,262 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package analysis provides statistical helpers for inspecting the trials of an experiment.
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// AdviceOptions control the thresholds used to produce advice.
+type AdviceOptions struct {
+	// The confidence level of the measurement confidence intervals, defaults to 0.95.
+	Confidence float64
+	// The acceptable confidence interval half-width relative to the mean metric
+	// value, defaults to 0.05 (i.e. ±5%).
+	Precision float64
+	// The number of completed trials needed for each parameter, defaults to 10.
+	TrialsPerParameter int
+}
+
+// Advice describes whether the completed trials of an experiment are sufficient
+// to trust the results.
+type Advice struct {
+	// The number of completed trials.
+	CompletedTrials int
+	// The suggested number of completed trials.
+	SuggestedTrials int
+	// The measurement precision of each metric.
+	Metrics []MetricPrecision
+}
+
+// MetricPrecision describes the measurement precision of a single metric.
+type MetricPrecision struct {
+	// The name of the metric.
+	MetricName string
+	// The number of completed trials with a value for the metric.
+	Count int
+	// The mean value of the metric across trials.
+	Mean float64
+	// The standard deviation of the metric across trials.
+	StdDev float64
+	// The estimated standard error of a single measurement, zero if it could
+	// not be estimated.
+	MeasurementError float64
+	// The number of trials which repeated the assignments of another trial, the
+	// measurement error is estimated from the replicates when there are any.
+	Replicates int
+	// The half-width of the confidence interval of a single measurement.
+	ConfidenceInterval float64
+	// The confidence interval relative to the magnitude of the mean.
+	RelativePrecision float64
+	// The factor by which the measurement window should grow to reach the
+	// requested precision, 1 if the precision is sufficient.
+	WindowFactor float64
+}
+
+// Advise inspects the variance of the completed trials and suggests whether
+// more trials or longer measurement windows are needed. The measurement error
+// is the standard error of a measured value, which shrinks with the square
+// root of the window length. It is estimated from trials which were repeated
+// with the same assignments or, without replicates, from the errors reported
+// with the values; reported errors must be standard errors (i.e. the standard
+// deviation of the samples in the window divided by the square root of their
+// number) and not the standard deviation of the samples, which does not shrink.
+func Advise(exp *experiments.Experiment, trials []experiments.TrialItem, opts AdviceOptions) *Advice {
+	if opts.Confidence <= 0 || opts.Confidence >= 1 {
+		opts.Confidence = 0.95
+	}
+	if opts.Precision <= 0 {
+		opts.Precision = 0.05
+	}
+	if opts.TrialsPerParameter <= 0 {
+		opts.TrialsPerParameter = 10
+	}
+	z := math.Sqrt2 * math.Erfinv(opts.Confidence)
+
+	completed := completedTrials(trials)
+	a := &Advice{CompletedTrials: len(completed)}
+
+	// Noise that is large compared to the differences between trials makes the optimization harder
+	noise := 0.0
+	for _, m := range exp.Metrics {
+		values, errs, keys := metricValues(completed, m.Name)
+		mp := MetricPrecision{MetricName: m.Name, Count: len(values), WindowFactor: 1}
+		mp.Mean, mp.StdDev = meanStdDev(values)
+		mp.MeasurementError, mp.Replicates = replicateError(values, keys)
+		if mp.Replicates == 0 {
+			mp.MeasurementError = rms(errs)
+		}
+		mp.ConfidenceInterval = z * mp.MeasurementError
+		if mp.Mean != 0 {
+			mp.RelativePrecision = mp.ConfidenceInterval / math.Abs(mp.Mean)
+		}
+		if mp.RelativePrecision > opts.Precision {
+			mp.WindowFactor = math.Pow(mp.RelativePrecision/opts.Precision, 2)
+		}
+		if mp.StdDev > 0 {
+			noise = math.Max(noise, mp.MeasurementError/mp.StdDev)
+		}
+		a.Metrics = append(a.Metrics, mp)
+	}
+
+	a.SuggestedTrials = int(math.Round(float64(opts.TrialsPerParameter*len(exp.Parameters)) * (1 + noise*noise)))
+	return a
+}
+
+// MoreTrials returns true if more completed trials are needed.
+func (a *Advice) MoreTrials() bool {
+	return a.CompletedTrials < a.SuggestedTrials
+}
+
+// LongerWindows returns true if any metric needs a longer measurement window.
+func (a *Advice) LongerWindows() bool {
+	for _, m := range a.Metrics {
+		if m.WindowFactor > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Messages returns human readable suggestions, empty if the trials are sufficient.
+func (a *Advice) Messages() []string {
+	var msgs []string
+	if a.MoreTrials() {
+		msgs = append(msgs, fmt.Sprintf("run at least %d more trials (%d of %d suggested trials completed)",
+			a.SuggestedTrials-a.CompletedTrials, a.CompletedTrials, a.SuggestedTrials))
+	}
+	for _, m := range a.Metrics {
+		switch {
+		case m.Count == 0:
+			msgs = append(msgs, fmt.Sprintf("no completed trials reported a value for %q", m.MetricName))
+		case m.MeasurementError == 0:
+			msgs = append(msgs, fmt.Sprintf("the measurement precision of %q is unknown, report errors with the values or repeat trials with the same assignments", m.MetricName))
+		case m.WindowFactor > 1:
+			msgs = append(msgs, fmt.Sprintf("increase the measurement window by %.1fx to measure %q within ±%.1f%%",
+				m.WindowFactor, m.MetricName, 100*m.RelativePrecision/math.Sqrt(m.WindowFactor)))
+		}
+	}
+	return msgs
+}
+
+// completedTrials returns the completed trials which did not fail.
+func completedTrials(trials []experiments.TrialItem) []*experiments.TrialItem {
+	var result []*experiments.TrialItem
+	for i := range trials {
+		if trials[i].Status == experiments.TrialCompleted && !trials[i].Failed {
+			result = append(result, &trials[i])
+		}
+	}
+	return result
+}
+
+// metricValues returns the values and errors of the named metric along with
+// a key identifying the assignments of the trial each value came from. Only
+// reported errors are returned, values without an error are not included.
+func metricValues(trials []*experiments.TrialItem, name string) ([]float64, []float64, []string) {
+	var values, errs []float64
+	var keys []string
+	for _, t := range trials {
+		for _, v := range t.Values {
+			if v.MetricName == name {
+				values = append(values, v.Value)
+				keys = append(keys, assignmentsKey(t.Assignments))
+				if v.Error > 0 {
+					errs = append(errs, v.Error)
+				}
+				break
+			}
+		}
+	}
+	return values, errs, keys
+}
+
+// assignmentsKey returns a string which is the same for equal assignments.
+func assignmentsKey(assignments []experiments.Assignment) string {
+	parts := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		parts = append(parts, a.ParameterName+"="+a.Value.String())
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// replicateError returns the pooled standard deviation of the values measured
+// for the same assignments and the number of replicates it is based on (i.e.
+// the degrees of freedom); both are zero if no assignments were repeated.
+func replicateError(values []float64, keys []string) (float64, int) {
+	groups := make(map[string][]float64)
+	for i, k := range keys {
+		groups[k] = append(groups[k], values[i])
+	}
+
+	var ss float64
+	var df int
+	for _, g := range groups {
+		if len(g) < 2 {
+			continue
+		}
+		_, sd := meanStdDev(g)
+		ss += sd * sd * float64(len(g)-1)
+		df += len(g) - 1
+	}
+	if df == 0 {
+		return 0, 0
+	}
+	return math.Sqrt(ss / float64(df)), df
+}
+
+// meanStdDev returns the mean and the sample standard deviation.
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var ss float64
+	for _, v := range values {
+		ss += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(ss / float64(len(values)-1))
+}
+
+// rms returns the root mean square of the values.
+func rms(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var ss float64
+	for _, v := range values {
+		ss += v * v
+	}
+	return math.Sqrt(ss / float64(len(values)))
+}
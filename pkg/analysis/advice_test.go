@@ -0,0 +1,129 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestAdvise(t *testing.T) {
+	exp := &experiments.Experiment{
+		Metrics:    []experiments.Metric{{Name: "cost"}, {Name: "latency"}},
+		Parameters: []experiments.Parameter{{Name: "cpu"}, {Name: "memory"}},
+	}
+
+	cases := []struct {
+		desc          string
+		trials        []experiments.TrialItem
+		moreTrials    bool
+		longerWindows bool
+		messages      int
+	}{
+		{
+			desc:       "no trials",
+			moreTrials: true,
+			messages:   3,
+		},
+		{
+			desc:   "precise",
+			trials: trialItems(20, func(i int) (float64, float64) { return float64(100 + i), 0.5 }),
+		},
+		{
+			desc:          "noisy",
+			trials:        trialItems(20, func(i int) (float64, float64) { return float64(100 + i), 10 }),
+			moreTrials:    true,
+			longerWindows: true,
+			messages:      3,
+		},
+		{
+			desc:       "no errors",
+			trials:     trialItems(20, func(i int) (float64, float64) { return float64(100 + i), 0 }),
+			moreTrials: false,
+			messages:   2,
+		},
+		{
+			desc: "ignores failed trials",
+			trials: append(trialItems(5, func(i int) (float64, float64) { return 100, 0.5 }),
+				experiments.TrialItem{Status: experiments.TrialCompleted, TrialValues: experiments.TrialValues{Failed: true}}),
+			moreTrials: true,
+			messages:   1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			a := Advise(exp, c.trials, AdviceOptions{})
+			assert.Equal(t, c.moreTrials, a.MoreTrials())
+			assert.Equal(t, c.longerWindows, a.LongerWindows())
+			assert.Len(t, a.Messages(), c.messages)
+		})
+	}
+}
+
+func TestAdvise_Replicates(t *testing.T) {
+	exp := &experiments.Experiment{Metrics: []experiments.Metric{{Name: "cost"}}}
+
+	// Pairs of trials differ by ±1, the reported errors are ignored
+	trials := replicatedTrialItems(10, 2, func(i int) (float64, float64) { return float64(100 + 10*(i/2) + i%2*2), 50 })
+	a := Advise(exp, trials, AdviceOptions{})
+	if assert.Len(t, a.Metrics, 1) {
+		assert.Equal(t, 5, a.Metrics[0].Replicates)
+		assert.InDelta(t, math.Sqrt2, a.Metrics[0].MeasurementError, 1e-9)
+		assert.False(t, a.LongerWindows())
+	}
+}
+
+func TestAdvise_WindowFactor(t *testing.T) {
+	exp := &experiments.Experiment{Metrics: []experiments.Metric{{Name: "cost"}}}
+	trials := trialItems(3, func(int) (float64, float64) { return 100, 5 })
+
+	// The 95% interval is ±9.8%, it takes a ~3.8x longer window to get to ±5%
+	a := Advise(exp, trials, AdviceOptions{})
+	if assert.Len(t, a.Metrics, 1) {
+		assert.InDelta(t, 9.8, a.Metrics[0].ConfidenceInterval, 0.01)
+		assert.InDelta(t, 3.84, a.Metrics[0].WindowFactor, 0.01)
+	}
+}
+
+// trialItems returns completed trials with distinct assignments and the same cost and latency values.
+func trialItems(n int, value func(int) (float64, float64)) []experiments.TrialItem {
+	return replicatedTrialItems(n, 1, value)
+}
+
+// replicatedTrialItems returns completed trials where every group of r trials have the same assignments.
+func replicatedTrialItems(n, r int, value func(int) (float64, float64)) []experiments.TrialItem {
+	items := make([]experiments.TrialItem, 0, n)
+	for i := 0; i < n; i++ {
+		v, e := value(i)
+		items = append(items, experiments.TrialItem{
+			Status: experiments.TrialCompleted,
+			Number: int64(i + 1),
+			TrialAssignments: experiments.TrialAssignments{Assignments: []experiments.Assignment{
+				{ParameterName: "cpu", Value: api.FromInt64(int64(i / r))},
+			}},
+			TrialValues: experiments.TrialValues{Values: []experiments.Value{
+				{MetricName: "cost", Value: v, Error: e},
+				{MetricName: "latency", Value: v, Error: e},
+			}},
+		})
+	}
+	return items
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"math"
+	"sort"
+
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// ImportanceOptions control the estimation of parameter importance.
+type ImportanceOptions struct {
+	// The number of equally sized bins numeric assignments are grouped into
+	// when estimating the explained variance, defaults to 4.
+	Bins int
+}
+
+// ParameterImportance estimates how much a parameter influences a metric.
+type ParameterImportance struct {
+	// The name of the metric.
+	MetricName string
+	// The name of the parameter.
+	ParameterName string
+	// The Spearman rank correlation between the parameter and the metric, nil
+	// for categorical parameters.
+	Correlation *float64
+	// The fraction of the metric variance explained by grouping the trials by
+	// the parameter alone (eta squared), between 0 and 1.
+	VarianceExplained float64
+	// The explained variance relative to the other parameters, the importance
+	// of all the parameters of a metric sums to 1.
+	Importance float64
+}
+
+// Importance estimates the importance of each parameter for each metric from
+// the completed trials. This is a simplified functional ANOVA: the variance of
+// the metric is decomposed by the main effect of each parameter, interactions
+// between parameters are ignored. Metrics with fewer than 3 values are
+// omitted. The results are grouped by metric and sorted by descending importance.
+func Importance(exp *experiments.Experiment, trials []experiments.TrialItem, opts ImportanceOptions) []ParameterImportance {
+	if opts.Bins < 2 {
+		opts.Bins = 4
+	}
+
+	completed := completedTrials(trials)
+
+	var result []ParameterImportance
+	for _, m := range exp.Metrics {
+		var metric []ParameterImportance
+		total := 0.0
+		for _, p := range exp.Parameters {
+			// Collect the trials which have both a value and an assignment
+			var xs []interface{}
+			var ys []float64
+			for _, t := range completed {
+				y, ok := metricValue(t, m.Name)
+				a := assignment(t, p.Name)
+				if !ok || a == nil {
+					continue
+				}
+				if p.Type == experiments.ParameterTypeCategorical {
+					xs = append(xs, a.String())
+				} else if x, err := a.Float64(); err == nil {
+					xs = append(xs, x)
+				} else {
+					continue
+				}
+				ys = append(ys, y)
+			}
+			if len(ys) < 3 {
+				continue
+			}
+
+			pi := ParameterImportance{MetricName: m.Name, ParameterName: p.Name}
+			if p.Type == experiments.ParameterTypeCategorical {
+				pi.VarianceExplained = etaSquared(ys, categoryGroups(xs))
+			} else {
+				nums := make([]float64, len(xs))
+				for i := range xs {
+					nums[i] = xs[i].(float64)
+				}
+				c := pearson(ranks(nums), ranks(ys))
+				pi.Correlation = &c
+				pi.VarianceExplained = etaSquared(ys, quantileGroups(nums, opts.Bins))
+			}
+			total += pi.VarianceExplained
+			metric = append(metric, pi)
+		}
+
+		for i := range metric {
+			if total > 0 {
+				metric[i].Importance = metric[i].VarianceExplained / total
+			}
+		}
+		sort.SliceStable(metric, func(i, j int) bool { return metric[i].Importance > metric[j].Importance })
+		result = append(result, metric...)
+	}
+	return result
+}
+
+// categoryGroups returns the group index of each value, one group per distinct value.
+func categoryGroups(values []interface{}) []int {
+	index := make(map[interface{}]int)
+	groups := make([]int, len(values))
+	for i, v := range values {
+		g, ok := index[v]
+		if !ok {
+			g = len(index)
+			index[v] = g
+		}
+		groups[i] = g
+	}
+	return groups
+}
+
+// quantileGroups returns the group index of each value, splitting the sorted
+// values into bins of (nearly) equal size. Equal values share a bin.
+func quantileGroups(values []float64, bins int) []int {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	groups := make([]int, len(values))
+	for rank, i := range order {
+		g := rank * bins / len(values)
+		if rank > 0 && values[i] == values[order[rank-1]] {
+			g = groups[order[rank-1]]
+		}
+		groups[i] = g
+	}
+	return groups
+}
+
+// etaSquared returns the fraction of the variance of the values explained by the groups.
+func etaSquared(values []float64, groups []int) float64 {
+	mean, _ := meanStdDev(values)
+
+	sums := make(map[int]float64)
+	counts := make(map[int]float64)
+	var ssTotal float64
+	for i, v := range values {
+		sums[groups[i]] += v
+		counts[groups[i]]++
+		ssTotal += (v - mean) * (v - mean)
+	}
+	if ssTotal == 0 {
+		return 0
+	}
+
+	var ssBetween float64
+	for g, sum := range sums {
+		gm := sum / counts[g]
+		ssBetween += counts[g] * (gm - mean) * (gm - mean)
+	}
+	return ssBetween / ssTotal
+}
+
+// ranks returns the rank of each value, tied values receive their average rank.
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	result := make([]float64, len(values))
+	for i := 0; i < len(order); {
+		j := i
+		for j+1 < len(order) && values[order[j+1]] == values[order[i]] {
+			j++
+		}
+		r := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			result[order[k]] = r
+		}
+		i = j + 1
+	}
+	return result
+}
+
+// pearson returns the Pearson correlation coefficient, zero if either series is constant.
+func pearson(xs, ys []float64) float64 {
+	mx, _ := meanStdDev(xs)
+	my, _ := meanStdDev(ys)
+
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
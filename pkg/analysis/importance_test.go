@@ -0,0 +1,103 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestImportance(t *testing.T) {
+	exp := &experiments.Experiment{
+		Metrics: []experiments.Metric{{Name: "cost"}, {Name: "latency"}},
+		Parameters: []experiments.Parameter{
+			{Name: "cpu", Type: experiments.ParameterTypeInteger},
+			{Name: "replicas", Type: experiments.ParameterTypeInteger},
+			{Name: "gc", Type: experiments.ParameterTypeCategorical},
+		},
+	}
+
+	// The cost only depends on the CPU, the latency only depends on the GC
+	var trials []experiments.TrialItem
+	for i := 0; i < 16; i++ {
+		cpu, replicas, gc := int64(i), int64((i*7)%5), []string{"a", "b"}[i%2]
+		latency := 10.0
+		if gc == "b" {
+			latency = 50
+		}
+		trials = append(trials, experiments.TrialItem{
+			Number: int64(i + 1),
+			Status: experiments.TrialCompleted,
+			TrialAssignments: experiments.TrialAssignments{Assignments: []experiments.Assignment{
+				{ParameterName: "cpu", Value: api.FromInt64(cpu)},
+				{ParameterName: "replicas", Value: api.FromInt64(replicas)},
+				{ParameterName: "gc", Value: api.FromString(gc)},
+			}},
+			TrialValues: experiments.TrialValues{Values: []experiments.Value{
+				{MetricName: "cost", Value: float64(100 - 5*cpu)},
+				{MetricName: "latency", Value: latency},
+			}},
+		})
+	}
+
+	result := Importance(exp, trials, ImportanceOptions{})
+	if !assert.Len(t, result, 6) {
+		return
+	}
+
+	// The most important parameter is sorted first
+	cost, latency := result[:3], result[3:]
+	assert.Equal(t, "cost", cost[0].MetricName)
+	assert.Equal(t, "cpu", cost[0].ParameterName)
+	if assert.NotNil(t, cost[0].Correlation) {
+		assert.InDelta(t, -1, *cost[0].Correlation, 1e-9)
+	}
+	assert.Greater(t, cost[0].Importance, 0.5)
+
+	assert.Equal(t, "latency", latency[0].MetricName)
+	assert.Equal(t, "gc", latency[0].ParameterName)
+	assert.Nil(t, latency[0].Correlation)
+	assert.InDelta(t, 1, latency[0].VarianceExplained, 1e-9)
+
+	for _, r := range [][]ParameterImportance{cost, latency} {
+		sum := 0.0
+		for _, pi := range r {
+			sum += pi.Importance
+		}
+		assert.InDelta(t, 1, sum, 1e-9)
+	}
+}
+
+func TestImportance_TooFewTrials(t *testing.T) {
+	exp := &experiments.Experiment{
+		Metrics:    []experiments.Metric{{Name: "cost"}},
+		Parameters: []experiments.Parameter{{Name: "cpu", Type: experiments.ParameterTypeInteger}},
+	}
+	assert.Empty(t, Importance(exp, nil, ImportanceOptions{}))
+}
+
+func TestRanks(t *testing.T) {
+	assert.Equal(t, []float64{3, 1, 3, 3, 5}, ranks([]float64{2, 1, 2, 2, 7}))
+}
+
+func TestQuantileGroups(t *testing.T) {
+	assert.Equal(t, []int{0, 0, 1, 1}, quantileGroups([]float64{1, 2, 3, 4}, 2))
+	assert.Equal(t, []int{0, 0, 0, 1}, quantileGroups([]float64{1, 1, 1, 4}, 2))
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// OutlierOptions control the detection of outliers.
+type OutlierOptions struct {
+	// The number of nearest trials in parameter space each trial is compared
+	// to, defaults to 5.
+	Neighbors int
+	// The robust z-score above which a value is an outlier, defaults to 3.5.
+	Threshold float64
+}
+
+// Outlier is a metric value that is inconsistent with the values of the
+// neighboring trials.
+type Outlier struct {
+	// The number of the trial.
+	Number int64
+	// The name of the metric.
+	MetricName string
+	// The observed value.
+	Value float64
+	// The median value of the neighboring trials.
+	Expected float64
+	// The robust z-score of the value relative to the neighbors.
+	Score float64
+}
+
+// String returns a short description of the outlier.
+func (o *Outlier) String() string {
+	return fmt.Sprintf("%s=%g (expected ~%g)", o.MetricName, o.Value, o.Expected)
+}
+
+// FindOutliers returns the metric values of the completed trials which are
+// statistical outliers relative to their nearest neighbors in parameter space.
+// Each value is compared to the median of its neighbors, scaled by the median
+// absolute deviation (MAD) of the neighbors.
+func FindOutliers(exp *experiments.Experiment, trials []experiments.TrialItem, opts OutlierOptions) []Outlier {
+	if opts.Neighbors <= 0 {
+		opts.Neighbors = 5
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = 3.5
+	}
+
+	completed := completedTrials(trials)
+	k := opts.Neighbors
+	if k > len(completed)-1 {
+		k = len(completed) - 1
+	}
+	if k < 3 {
+		return nil
+	}
+
+	space := newParameterSpace(exp, completed)
+
+	var outliers []Outlier
+	for i, t := range completed {
+		neighbors := space.nearest(i, k)
+		for _, m := range exp.Metrics {
+			v, ok := metricValue(t, m.Name)
+			if !ok {
+				continue
+			}
+
+			var nv []float64
+			for _, n := range neighbors {
+				if x, ok := metricValue(completed[n], m.Name); ok {
+					nv = append(nv, x)
+				}
+			}
+			if len(nv) < 3 {
+				continue
+			}
+
+			med := median(nv)
+			scale := 1.4826 * mad(nv, med)
+			if scale == 0 {
+				// Identical neighbors, fall back to a fraction of their magnitude
+				scale = 0.01 * math.Abs(med)
+			}
+			if scale == 0 {
+				continue
+			}
+
+			if score := math.Abs(v-med) / scale; score > opts.Threshold {
+				outliers = append(outliers, Outlier{Number: t.Number, MetricName: m.Name, Value: v, Expected: med, Score: score})
+			}
+		}
+	}
+	return outliers
+}
+
+// parameterSpace holds the normalized assignments of each trial.
+type parameterSpace struct {
+	categorical []bool
+	points      [][]interface{}
+}
+
+// newParameterSpace normalizes the numeric assignments of the trials to the
+// unit interval using the parameter bounds (or the observed range).
+func newParameterSpace(exp *experiments.Experiment, trials []*experiments.TrialItem) *parameterSpace {
+	s := &parameterSpace{
+		categorical: make([]bool, len(exp.Parameters)),
+		points:      make([][]interface{}, len(trials)),
+	}
+
+	for j, p := range exp.Parameters {
+		s.categorical[j] = p.Type == experiments.ParameterTypeCategorical
+
+		lo, hi := math.Inf(1), math.Inf(-1)
+		if p.Bounds != nil {
+			lo, _ = p.Bounds.Min.Float64()
+			hi, _ = p.Bounds.Max.Float64()
+		}
+
+		for i, t := range trials {
+			if s.points[i] == nil {
+				s.points[i] = make([]interface{}, len(exp.Parameters))
+			}
+			a := assignment(t, p.Name)
+			if a == nil {
+				continue
+			}
+			if s.categorical[j] {
+				s.points[i][j] = a.String()
+				continue
+			}
+			if v, err := a.Float64(); err == nil {
+				s.points[i][j] = v
+				if p.Bounds == nil {
+					lo, hi = math.Min(lo, v), math.Max(hi, v)
+				}
+			}
+		}
+
+		if s.categorical[j] {
+			continue
+		}
+		for i := range trials {
+			if v, ok := s.points[i][j].(float64); ok {
+				if hi > lo {
+					s.points[i][j] = (v - lo) / (hi - lo)
+				} else {
+					s.points[i][j] = 0.0
+				}
+			}
+		}
+	}
+	return s
+}
+
+// nearest returns the indexes of the k nearest points to point i.
+func (s *parameterSpace) nearest(i, k int) []int {
+	type candidate struct {
+		index    int
+		distance float64
+	}
+
+	candidates := make([]candidate, 0, len(s.points)-1)
+	for j := range s.points {
+		if j != i {
+			candidates = append(candidates, candidate{index: j, distance: s.distance(i, j)})
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].distance < candidates[b].distance })
+
+	result := make([]int, 0, k)
+	for _, c := range candidates[:k] {
+		result = append(result, c.index)
+	}
+	return result
+}
+
+// distance returns the Euclidean distance between two points, categorical
+// values contribute 1 if they differ; missing values are maximally distant.
+func (s *parameterSpace) distance(i, j int) float64 {
+	var d float64
+	for p := range s.categorical {
+		a, b := s.points[i][p], s.points[j][p]
+		switch {
+		case a == nil || b == nil:
+			d++
+		case s.categorical[p]:
+			if a != b {
+				d++
+			}
+		default:
+			x := a.(float64) - b.(float64)
+			d += x * x
+		}
+	}
+	return math.Sqrt(d)
+}
+
+// assignment returns the value assigned to the named parameter.
+func assignment(t *experiments.TrialItem, name string) *api.NumberOrString {
+	for i := range t.Assignments {
+		if t.Assignments[i].ParameterName == name {
+			return &t.Assignments[i].Value
+		}
+	}
+	return nil
+}
+
+// metricValue returns the value of the named metric.
+func metricValue(t *experiments.TrialItem, name string) (float64, bool) {
+	for _, v := range t.Values {
+		if v.MetricName == name {
+			return v.Value, true
+		}
+	}
+	return 0, false
+}
+
+// median returns the median of the values.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// mad returns the median absolute deviation of the values from the center.
+func mad(values []float64, center float64) float64 {
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - center)
+	}
+	return median(deviations)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestFindOutliers(t *testing.T) {
+	exp := &experiments.Experiment{
+		Metrics: []experiments.Metric{{Name: "cost"}},
+		Parameters: []experiments.Parameter{
+			{Name: "cpu", Type: experiments.ParameterTypeInteger, Bounds: &experiments.Bounds{Min: "0", Max: "100"}},
+			{Name: "gc", Type: experiments.ParameterTypeCategorical, Values: []string{"a", "b"}},
+		},
+	}
+
+	// The cost is roughly proportional to the CPU
+	trial := func(number int64, cpu int64, gc string, cost float64) experiments.TrialItem {
+		return experiments.TrialItem{
+			Number: number,
+			Status: experiments.TrialCompleted,
+			TrialAssignments: experiments.TrialAssignments{Assignments: []experiments.Assignment{
+				{ParameterName: "cpu", Value: api.FromInt64(cpu)},
+				{ParameterName: "gc", Value: api.FromString(gc)},
+			}},
+			TrialValues: experiments.TrialValues{Values: []experiments.Value{{MetricName: "cost", Value: cost}}},
+		}
+	}
+
+	cases := []struct {
+		desc     string
+		trials   []experiments.TrialItem
+		expected []int64
+	}{
+		{
+			desc:   "too few trials",
+			trials: []experiments.TrialItem{trial(1, 10, "a", 10), trial(2, 20, "a", 200), trial(3, 30, "a", 30)},
+		},
+		{
+			desc: "consistent",
+			trials: []experiments.TrialItem{
+				trial(1, 10, "a", 10), trial(2, 20, "a", 21), trial(3, 30, "a", 29),
+				trial(4, 40, "a", 41), trial(5, 50, "a", 50), trial(6, 60, "a", 59),
+			},
+		},
+		{
+			desc: "outlier",
+			trials: []experiments.TrialItem{
+				trial(1, 10, "a", 10), trial(2, 20, "a", 21), trial(3, 30, "a", 29),
+				trial(4, 40, "a", 400), trial(5, 50, "a", 50), trial(6, 60, "a", 59),
+			},
+			expected: []int64{4},
+		},
+		{
+			desc: "ignores failed trials",
+			trials: []experiments.TrialItem{
+				trial(1, 10, "a", 10), trial(2, 20, "a", 21), trial(3, 30, "a", 29),
+				trial(4, 40, "a", 41), trial(5, 50, "a", 50),
+				{Number: 6, Status: experiments.TrialFailed},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var actual []int64
+			for _, o := range FindOutliers(exp, c.trials, OutlierOptions{}) {
+				actual = append(actual, o.Number)
+			}
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestOutlier_String(t *testing.T) {
+	o := &Outlier{MetricName: "cost", Value: 400, Expected: 41}
+	assert.Equal(t, "cost=400 (expected ~41)", o.String())
+}
@@ -57,9 +57,21 @@ func (ai *ActivityItem) HasTag(tag string) bool {
 	return false
 }
 
+// RefersTo checks if the external URL of the item references the supplied
+// URL, or a resource nested under it (e.g. a scenario of an application).
+func (ai *ActivityItem) RefersTo(u string) bool {
+	prefix := strings.TrimSuffix(u, "/")
+	if prefix == "" {
+		return false
+	}
+	return ai.ExternalURL == prefix || strings.HasPrefix(ai.ExternalURL, prefix+"/")
+}
+
 const (
 	TagApprove string = "approve"
 	TagRefresh string = "refresh"
+	TagRun     string = "run"
+	TagScan    string = "scan"
 )
 
 type ActivityExtension struct {
@@ -77,6 +89,31 @@ func (q *ActivityFeedQuery) SetType(t ...string) {
 	url.Values(q.Query).Set("type", strings.Join(t, ","))
 }
 
+// SetLastEventID limits the activity to items after the supplied identifier, ignored if empty.
+func (q *ActivityFeedQuery) SetLastEventID(id string) {
+	if id == "" {
+		return
+	}
+	if q.Query == nil {
+		q.Query = make(map[string][]string)
+	}
+	url.Values(q.Query).Set("last_event_id", id)
+}
+
+// SetRange limits the activity to items published in the specified time range,
+// either end of the range is ignored if it is zero.
+func (q *ActivityFeedQuery) SetRange(since, until time.Time) {
+	if q.Query == nil {
+		q.Query = make(map[string][]string)
+	}
+	if !since.IsZero() {
+		url.Values(q.Query).Set("since", since.UTC().Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		url.Values(q.Query).Set("until", until.UTC().Format(time.RFC3339))
+	}
+}
+
 type Activity struct {
 	api.Metadata `json:"-"`
 	Run          *RunActivity     `json:"run,omitempty"`
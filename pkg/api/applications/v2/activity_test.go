@@ -18,6 +18,7 @@ package v2
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -109,3 +110,82 @@ func TestActivityFeed_SetBaseURL(t *testing.T) {
 		})
 	}
 }
+
+func TestActivityItem_RefersTo(t *testing.T) {
+	cases := []struct {
+		desc        string
+		externalURL string
+		u           string
+		expected    bool
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:        "exact",
+			externalURL: "https://test.example.com/applications/app1",
+			u:           "https://test.example.com/applications/app1",
+			expected:    true,
+		},
+		{
+			desc:        "trailing slash",
+			externalURL: "https://test.example.com/applications/app1",
+			u:           "https://test.example.com/applications/app1/",
+			expected:    true,
+		},
+		{
+			desc:        "nested",
+			externalURL: "https://test.example.com/applications/app1/scenarios/scn1",
+			u:           "https://test.example.com/applications/app1",
+			expected:    true,
+		},
+		{
+			desc:        "common prefix",
+			externalURL: "https://test.example.com/applications/app10",
+			u:           "https://test.example.com/applications/app1",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			ai := ActivityItem{ExternalURL: c.externalURL}
+			assert.Equal(t, c.expected, ai.RefersTo(c.u))
+		})
+	}
+}
+
+func TestActivityFeedQuery_SetRange(t *testing.T) {
+	cases := []struct {
+		desc     string
+		since    time.Time
+		until    time.Time
+		expected map[string][]string
+	}{
+		{
+			desc:     "empty",
+			expected: map[string][]string{},
+		},
+		{
+			desc:  "since",
+			since: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			expected: map[string][]string{
+				"since": {"2023-01-02T03:04:05Z"},
+			},
+		},
+		{
+			desc:  "both",
+			since: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			until: time.Date(2023, 1, 3, 0, 0, 0, 0, time.FixedZone("EST", -5*60*60)),
+			expected: map[string][]string{
+				"since": {"2023-01-02T03:04:05Z"},
+				"until": {"2023-01-03T05:00:00Z"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			q := ActivityFeedQuery{}
+			q.SetRange(c.since, c.until)
+			assert.Equal(t, c.expected, q.Query)
+		})
+	}
+}
@@ -35,6 +35,9 @@ const (
 	ErrRecommendationInvalid  api.ErrorType = "recommendation-invalid"
 	ErrRecommendationNotFound api.ErrorType = "recommendation-not-found"
 	ErrClusterNotFound        api.ErrorType = "cluster-not-found"
+	ErrCredentialInvalid      api.ErrorType = "credential-invalid"
+	ErrCredentialNotFound     api.ErrorType = "credential-not-found"
+	ErrCredentialExists       api.ErrorType = "credential-exists"
 )
 
 // Subscriber describes a strategy for subscribing to feed notifications.
@@ -43,6 +46,13 @@ type Subscriber interface {
 	Subscribe(ctx context.Context, ch chan<- ActivityItem) error
 }
 
+// Drainer is implemented by subscribers which can be stopped gracefully.
+type Drainer interface {
+	// Drain stops the subscription from accepting new items, waits for in-flight
+	// items to be handled (or for the context to end) and closes the channel.
+	Drain(ctx context.Context) error
+}
+
 type API interface {
 	// CheckEndpoint verifies we can talk to the backend.
 	CheckEndpoint(ctx context.Context) (api.Metadata, error)
@@ -63,6 +73,8 @@ type API interface {
 	UpdateApplication(ctx context.Context, u string, app Application) (api.Metadata, error)
 	// UpdateApplicationByName updates or creates an application.
 	UpdateApplicationByName(ctx context.Context, n ApplicationName, app Application) (api.Metadata, error)
+	// PatchApplication updates only the fields of the application that are set on the supplied patch.
+	PatchApplication(ctx context.Context, u string, app Application) error
 	// DeleteApplication deletes an application.
 	DeleteApplication(ctx context.Context, u string) error
 
@@ -122,6 +134,9 @@ type API interface {
 	// PatchRecommendations updates recommendation configuration.
 	PatchRecommendations(ctx context.Context, u string, details RecommendationList) error
 
+	// ListWorkloads lists the workloads discovered for an application.
+	ListWorkloads(ctx context.Context, u string) (WorkloadList, error)
+
 	// GetCluster retrieves a cluster.
 	GetCluster(ctx context.Context, u string) (Cluster, error)
 	// GetClusterByName retrieves a cluster.
@@ -132,4 +147,14 @@ type API interface {
 	PatchCluster(ctx context.Context, u string, c ClusterTitle) error
 	// DeleteCluster deletes a cluster.
 	DeleteCluster(ctx context.Context, u string) error
+
+	// CreateCredentialByName creates a new machine credential; the returned
+	// credential is the only time the client secret is available.
+	CreateCredentialByName(ctx context.Context, n CredentialName, c Credential) (Credential, error)
+	// GetCredentialByName retrieves a machine credential.
+	GetCredentialByName(ctx context.Context, n CredentialName) (Credential, error)
+	// ListCredentials lists machine credentials.
+	ListCredentials(ctx context.Context) (CredentialList, error)
+	// RevokeCredential revokes a machine credential.
+	RevokeCredential(ctx context.Context, u string) error
 }
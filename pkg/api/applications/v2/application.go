@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"strings"
 	"time"
 
 	"github.com/thestormforge/optimize-go/pkg/api"
@@ -28,6 +29,20 @@ type Application struct {
 	DisplayName  string          `json:"title,omitempty"` // TODO This doesn't seem to get set
 	Resources    []Resource      `json:"resources,omitempty"`
 	CreatedAt    *time.Time      `json:"createdAt,omitempty"`
+	// Fields not known to this client, preserved when the application is updated.
+	Extensions api.Extensions `json:"-"`
+}
+
+func (app *Application) UnmarshalJSON(b []byte) error {
+	type t Application
+	ext, err := api.UnmarshalJSONWithExtensions(b, (*t)(app))
+	app.Extensions = ext
+	return err
+}
+
+func (app Application) MarshalJSON() ([]byte, error) {
+	type t Application
+	return api.MarshalJSONWithExtensions(t(app), app.Extensions)
 }
 
 // NOTE: Use `DisplayName` as the field since `Title()` is a function on the embedded `Metadata`.
@@ -56,8 +71,28 @@ func (m RecommendationsMode) Enabled() bool {
 }
 
 func (ai *ApplicationItem) UnmarshalJSON(b []byte) error {
+	// The fields shadow the JSON methods promoted from the embedded application
+	type t ApplicationItem
+	v := struct {
+		*t
+		MarshalJSON, UnmarshalJSON struct{} `json:"-"`
+	}{t: (*t)(ai)}
+	if err := api.UnmarshalJSON(b, &v); err != nil {
+		return err
+	}
+
+	ext, err := api.ExtractExtensions(b, &v)
+	ai.Extensions = ext
+	return err
+}
+
+func (ai ApplicationItem) MarshalJSON() ([]byte, error) {
 	type t ApplicationItem
-	return api.UnmarshalJSON(b, (*t)(ai))
+	v := struct {
+		*t
+		MarshalJSON, UnmarshalJSON struct{} `json:"-"`
+	}{t: (*t)(&ai)}
+	return api.MarshalJSONWithExtensions(&v, ai.Extensions)
 }
 
 type ApplicationList struct {
@@ -80,3 +115,76 @@ type Resource struct {
 		Selector          string   `json:"selector,omitempty" yaml:"selector,omitempty"`
 	} `json:"kubernetes" yaml:"kubernetes"`
 }
+
+// NormalizeResource returns the canonical form of the resource: a single
+// namespace is stored in `Namespace`, multiple namespaces are stored (without
+// duplicates) in `Namespaces`. The boolean result is false if the resource
+// does not select any namespaces.
+func NormalizeResource(r Resource) (Resource, bool) {
+	k := &r.Kubernetes
+	if k.Namespace == "" && len(k.Namespaces) == 0 && k.NamespaceSelector == "" {
+		return r, false
+	}
+
+	// Fold everything into the list of namespaces
+	var namespaces []string
+	if k.Namespace != "" {
+		namespaces = append(namespaces, k.Namespace)
+	}
+	namespaces = appendUnique(namespaces, k.Namespaces...)
+
+	k.Namespace, k.Namespaces = "", nil
+	if len(namespaces) == 1 {
+		k.Namespace = namespaces[0]
+	} else if len(namespaces) > 1 {
+		k.Namespaces = namespaces
+	}
+
+	return r, true
+}
+
+// MergeResources combines the supplied resources into a single normalized
+// resource: namespaces and types are combined, and label selectors are
+// joined so that only resources matching all the selectors are included.
+// Namespaces and namespace selectors are both retained, validation rejects
+// a resource that mixes them.
+func MergeResources(resources ...Resource) (Resource, bool) {
+	result := Resource{}
+	k := &result.Kubernetes
+
+	var selectors, namespaceSelectors []string
+	for _, r := range resources {
+		if r.Kubernetes.Namespace != "" {
+			k.Namespaces = appendUnique(k.Namespaces, r.Kubernetes.Namespace)
+		}
+		k.Namespaces = appendUnique(k.Namespaces, r.Kubernetes.Namespaces...)
+		k.Types = appendUnique(k.Types, r.Kubernetes.Types...)
+		if r.Kubernetes.Selector != "" {
+			selectors = appendUnique(selectors, r.Kubernetes.Selector)
+		}
+		if r.Kubernetes.NamespaceSelector != "" {
+			namespaceSelectors = appendUnique(namespaceSelectors, r.Kubernetes.NamespaceSelector)
+		}
+	}
+	k.Selector = strings.Join(selectors, ",")
+	k.NamespaceSelector = strings.Join(namespaceSelectors, ",")
+
+	return NormalizeResource(result)
+}
+
+// appendUnique appends the values which are not already present in the slice.
+func appendUnique(s []string, values ...string) []string {
+	for _, v := range values {
+		found := v == ""
+		for _, vv := range s {
+			if vv == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s = append(s, v)
+		}
+	}
+	return s
+}
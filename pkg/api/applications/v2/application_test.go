@@ -80,3 +80,160 @@ func TestApplicationList_UnmarshalJSON(t *testing.T) {
 		assert.Equal(t, "Test2", l.Applications[1].Title())
 	}
 }
+
+func TestApplication_Extensions(t *testing.T) {
+	cases := []struct {
+		desc     string
+		value    interface{}
+		data     string
+		expected api.Extensions
+	}{
+		{
+			desc:  "application",
+			value: &Application{},
+			data:  `{"name":"a","title":"A","owner":{"team":"x"}}`,
+			expected: api.Extensions{
+				"owner": json.RawMessage(`{"team":"x"}`),
+			},
+		},
+		{
+			desc:  "application item",
+			value: &ApplicationItem{},
+			data:  `{"_metadata":{"Link":"</a>; rel=self"},"name":"a","scenarioCount":2,"owner":{"team":"x"}}`,
+			expected: api.Extensions{
+				"owner": json.RawMessage(`{"team":"x"}`),
+			},
+		},
+		{
+			desc:  "no extensions",
+			value: &ApplicationItem{},
+			data:  `{"name":"a","scenarioCount":2,"recommendations":"auto"}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(c.data), c.value); !assert.NoError(t, err) {
+				return
+			}
+
+			var app *Application
+			switch v := c.value.(type) {
+			case *Application:
+				app = v
+			case *ApplicationItem:
+				app = &v.Application
+				assert.Equal(t, 2, v.ScenarioCount)
+			}
+			assert.Equal(t, "a", app.Name.String())
+			assert.Equal(t, c.expected, app.Extensions)
+
+			data, err := json.Marshal(c.value)
+			if assert.NoError(t, err) {
+				assert.JSONEq(t, withoutMetadata(t, c.data), string(data))
+			}
+		})
+	}
+}
+
+// withoutMetadata removes the "_metadata" field, which is never encoded, from a JSON object.
+func withoutMetadata(t *testing.T, data string) string {
+	fields := map[string]json.RawMessage{}
+	assert.NoError(t, json.Unmarshal([]byte(data), &fields))
+	delete(fields, "_metadata")
+	result, err := json.Marshal(fields)
+	assert.NoError(t, err)
+	return string(result)
+}
+
+func TestNormalizeResource(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource Resource
+		expected Resource
+		ok       bool
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:     "single namespace list",
+			resource: resource("", []string{"a"}, ""),
+			expected: resource("a", nil, ""),
+			ok:       true,
+		},
+		{
+			desc:     "namespace and list",
+			resource: resource("a", []string{"b", "a"}, ""),
+			expected: resource("", []string{"a", "b"}, ""),
+			ok:       true,
+		},
+		{
+			desc:     "selector only",
+			resource: resource("", nil, "env=prod"),
+			expected: resource("", nil, "env=prod"),
+			ok:       true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, ok := NormalizeResource(c.resource)
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestMergeResources(t *testing.T) {
+	cases := []struct {
+		desc      string
+		resources []Resource
+		expected  Resource
+		invalid   bool
+	}{
+		{
+			desc: "namespaces",
+			resources: []Resource{
+				withSelector(resource("a", nil, ""), "app=foo"),
+				withSelector(resource("", []string{"a", "b"}, ""), "tier=web"),
+			},
+			expected: withSelector(resource("", []string{"a", "b"}, ""), "app=foo,tier=web"),
+		},
+		{
+			desc: "namespace selectors",
+			resources: []Resource{
+				resource("", nil, "env=prod"),
+				resource("", nil, "team=a"),
+			},
+			expected: resource("", nil, "env=prod,team=a"),
+		},
+		{
+			desc: "namespaces and selectors",
+			resources: []Resource{
+				resource("a", nil, "env=prod"),
+				resource("", []string{"b"}, ""),
+			},
+			expected: resource("", []string{"a", "b"}, "env=prod"),
+			invalid:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, ok := MergeResources(c.resources...)
+			assert.True(t, ok)
+			assert.Equal(t, c.expected, actual)
+
+			errs := &api.FieldErrorList{}
+			validateResource(errs, "resource", &actual)
+			if c.invalid {
+				assert.Error(t, errs.Err())
+			} else {
+				assert.NoError(t, errs.Err())
+			}
+		})
+	}
+}
+
+func withSelector(r Resource, selector string) Resource {
+	r.Kubernetes.Selector = selector
+	return r
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// CopyOptions controls how an application is copied between API instances.
+type CopyOptions struct {
+	// The name of the copy, defaults to the name of the source application.
+	Name ApplicationName
+	// The behavior when the name is already in use by the destination.
+	OnConflict api.ConflictPolicy
+}
+
+// CopyApplication copies the named application and its scenarios from the
+// source API to the destination API (which are typically configured with
+// different servers or credentials). The name of the copy is returned, the
+// name is empty if the copy was skipped.
+func CopyApplication(ctx context.Context, src, dst API, name ApplicationName, opts CopyOptions) (ApplicationName, error) {
+	app, err := src.GetApplicationByName(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	// Collect the scenarios before we start writing anything
+	var scenarios []Scenario
+	l := Lister{API: src}
+	if err := l.ForEachScenario(ctx, &app, ScenarioListQuery{}, func(item *ScenarioItem) error {
+		scenarios = append(scenarios, item.Scenario)
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	target := opts.Name
+	if target == "" {
+		target = name
+	}
+
+	// Server assigned values do not get copied
+	app.Metadata = nil
+	app.Name = ""
+	app.CreatedAt = nil
+
+	var md api.Metadata
+	for attempt := 1; ; attempt++ {
+		if opts.OnConflict == api.ConflictSuffix {
+			n, err := api.UniqueName(name.String())
+			if err != nil {
+				return "", err
+			}
+			target = ApplicationName(n)
+		}
+
+		md, err = createApplication(ctx, dst, target, app, opts.OnConflict)
+		var apiErr *api.Error
+		if !errors.As(err, &apiErr) || apiErr.Type != ErrApplicationExists {
+			break
+		}
+		if opts.OnConflict == api.ConflictRename {
+			target = ApplicationName(api.NextName(name.String(), attempt))
+		} else if opts.OnConflict != api.ConflictSuffix {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if md == nil {
+		// The existing application is only reported back when adopting it
+		if opts.OnConflict == api.ConflictAdopt {
+			return target, nil
+		}
+		return "", nil
+	}
+
+	// Fetch the copy back to find the scenarios link
+	copied, err := dst.GetApplication(ctx, md.Link(api.RelationSelf))
+	if err != nil {
+		return "", err
+	}
+	scenariosURL := copied.Link(api.RelationScenarios)
+	if len(scenarios) > 0 && scenariosURL == "" {
+		return "", fmt.Errorf("malformed response, missing scenarios link")
+	}
+
+	for _, scn := range scenarios {
+		scnName := scn.Name
+		scn.Metadata = nil
+		scn.Name = ""
+		if _, err := dst.UpdateScenarioByName(ctx, scenariosURL, scnName, scn); err != nil {
+			return "", err
+		}
+	}
+
+	return target, nil
+}
+
+// createApplication creates the named application, applying the conflict policy.
+// A nil result indicates the application was skipped.
+func createApplication(ctx context.Context, dst API, n ApplicationName, app Application, onConflict api.ConflictPolicy) (api.Metadata, error) {
+	if onConflict == api.ConflictOverwrite {
+		return dst.UpdateApplicationByName(ctx, n, app)
+	}
+
+	md, err := dst.CreateApplicationByName(ctx, n, app)
+	var apiErr *api.Error
+	if (onConflict == api.ConflictSkip || onConflict == api.ConflictAdopt) && errors.As(err, &apiErr) && apiErr.Type == ErrApplicationExists {
+		return nil, nil
+	}
+	return md, err
+}
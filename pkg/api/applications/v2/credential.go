@@ -0,0 +1,57 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"time"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// Credential is a machine credential (a client ID and secret pair) used for
+// non-interactive access to the API, for example from a CI pipeline.
+type Credential struct {
+	api.Metadata `json:"-"`
+	// The name of the credential.
+	Name CredentialName `json:"name,omitempty"`
+	// The client identifier of the credential.
+	ClientID string `json:"clientId,omitempty"`
+	// The client secret of the credential, only returned when the credential is created.
+	ClientSecret string `json:"clientSecret,omitempty"`
+	// The time at which the credential was created.
+	CreatedAt *time.Time `json:"created,omitempty"`
+	// The time at which the credential was last used.
+	LastUsed *time.Time `json:"lastUsed,omitempty"`
+}
+
+type CredentialItem struct {
+	Credential
+}
+
+func (ci *CredentialItem) UnmarshalJSON(b []byte) error {
+	type t CredentialItem
+	return api.UnmarshalJSON(b, (*t)(ci))
+}
+
+type CredentialList struct {
+	// The credential list metadata.
+	api.Metadata `json:"-"`
+	// The total number of items in the collection.
+	TotalCount int `json:"totalCount,omitempty"`
+	// The list of credentials.
+	Items []CredentialItem `json:"items"`
+}
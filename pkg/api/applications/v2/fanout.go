@@ -0,0 +1,107 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+)
+
+// FanOut is a subscriber that shares a single subscription between multiple
+// consumers, each consumer only receives the items matching its tags.
+type FanOut struct {
+	// The subscriber used to obtain activity items.
+	Subscriber Subscriber
+
+	consumers []fanOutConsumer
+}
+
+type fanOutConsumer struct {
+	ch   chan<- ActivityItem
+	tags []string
+}
+
+// Add registers a consumer channel which will receive the items having any of
+// the supplied tags (or all items if no tags are specified). Consumers must be
+// added before subscribing; their channels are closed when the subscription ends.
+func (f *FanOut) Add(ch chan<- ActivityItem, tags ...string) {
+	f.consumers = append(f.consumers, fanOutConsumer{ch: ch, tags: tags})
+}
+
+// Subscribe initiates the shared subscription and delivers items to each
+// consumer. The supplied channel is optional, if not nil it receives all items.
+// Items are acknowledged once they have been sent to every interested consumer
+// if the subscriber supports it (see `PollingSubscriber.Ack`).
+func (f *FanOut) Subscribe(ctx context.Context, ch chan<- ActivityItem) error {
+	acker, _ := f.Subscriber.(interface{ Ack(id string) })
+
+	consumers := f.consumers
+	if ch != nil {
+		consumers = append(consumers[:len(consumers):len(consumers)], fanOutConsumer{ch: ch})
+	}
+
+	// Close all the consumers when we are done sending things
+	defer func() {
+		for _, c := range consumers {
+			close(c.ch)
+		}
+	}()
+
+	items := make(chan ActivityItem)
+	done := make(chan error, 1)
+	go func() { done <- f.Subscriber.Subscribe(ctx, items) }()
+
+	for item := range items {
+		for _, c := range consumers {
+			if !c.accepts(&item) {
+				continue
+			}
+
+			// A slow consumer blocks the others, but it must not block cancellation
+			select {
+			case c.ch <- item:
+			case <-ctx.Done():
+			}
+		}
+
+		if acker != nil {
+			acker.Ack(item.ID)
+		}
+	}
+
+	return <-done
+}
+
+// accepts checks if the consumer wants the supplied item.
+func (c *fanOutConsumer) accepts(item *ActivityItem) bool {
+	if len(c.tags) == 0 {
+		return true
+	}
+	for _, t := range c.tags {
+		if item.HasTag(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Drain stops the shared subscription if the subscriber supports it, see `Drainer`.
+func (f *FanOut) Drain(ctx context.Context) error {
+	if d, ok := f.Subscriber.(Drainer); ok {
+		return d.Drain(ctx)
+	}
+	return nil
+}
@@ -223,11 +223,38 @@ func (h *httpAPI) UpdateApplication(ctx context.Context, u string, app Applicati
 		return nil, api.NewError(ErrApplicationInvalid, resp, body)
 	case http.StatusUnprocessableEntity:
 		return nil, api.NewError(ErrApplicationInvalid, resp, body)
+	case http.StatusPreconditionFailed:
+		return nil, api.NewError(api.ErrConflict, resp, body)
 	default:
 		return nil, api.NewUnexpectedError(resp, body)
 	}
 }
 
+func (h *httpAPI) PatchApplication(ctx context.Context, u string, app Application) error {
+	req, err := httpNewMergePatchRequest(u, app, app.Metadata)
+	if err != nil {
+		return err
+	}
+
+	resp, body, err := h.client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return api.NewError(ErrApplicationNotFound, resp, body)
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return api.NewError(ErrApplicationInvalid, resp, body)
+	case http.StatusPreconditionFailed:
+		return api.NewError(api.ErrConflict, resp, body)
+	default:
+		return api.NewUnexpectedError(resp, body)
+	}
+}
+
 func (h *httpAPI) UpdateApplicationByName(ctx context.Context, n ApplicationName, app Application) (api.Metadata, error) {
 	u := h.client.URL(h.endpoint)
 	u.Path = path.Join(u.Path, n.String())
@@ -436,7 +463,7 @@ func (h *httpAPI) DeleteScenario(ctx context.Context, u string) error {
 }
 
 func (h *httpAPI) PatchScenario(ctx context.Context, u string, scn Scenario) error {
-	req, err := httpNewJSONRequest(http.MethodPatch, u, scn)
+	req, err := httpNewMergePatchRequest(u, scn, scn.Metadata)
 	if err != nil {
 		return err
 	}
@@ -453,6 +480,8 @@ func (h *httpAPI) PatchScenario(ctx context.Context, u string, scn Scenario) err
 		return api.NewError(ErrScenarioInvalid, resp, body)
 	case http.StatusUnprocessableEntity:
 		return api.NewError(ErrScenarioInvalid, resp, body)
+	case http.StatusPreconditionFailed:
+		return api.NewError(api.ErrConflict, resp, body)
 	default:
 		return api.NewUnexpectedError(resp, body)
 	}
@@ -706,6 +735,29 @@ func (h *httpAPI) ListRecommendations(ctx context.Context, u string) (Recommenda
 	}
 }
 
+func (h *httpAPI) ListWorkloads(ctx context.Context, u string) (WorkloadList, error) {
+	result := WorkloadList{}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return result, err
+	}
+
+	resp, body, err := h.client.Do(ctx, req)
+	if err != nil {
+		return result, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		api.UnmarshalMetadata(resp, &result.Metadata)
+		err = json.Unmarshal(body, &result)
+		return result, err
+	default:
+		return result, api.NewUnexpectedError(resp, body)
+	}
+}
+
 func (h *httpAPI) PatchRecommendations(ctx context.Context, u string, details RecommendationList) error {
 	req, err := httpNewJSONRequest(http.MethodPatch, u, details)
 	if err != nil {
@@ -836,6 +888,114 @@ func (h *httpAPI) DeleteCluster(ctx context.Context, u string) error {
 	}
 }
 
+func (h *httpAPI) CreateCredentialByName(ctx context.Context, n CredentialName, c Credential) (Credential, error) {
+	u := h.client.URL(h.endpoint)
+	// TODO This is less then ideal
+	u.Path = path.Join(u.Path, "..", "credentials", n.String())
+	result := Credential{}
+
+	req, err := httpNewJSONRequest(http.MethodPut, u.String(), c)
+	if err != nil {
+		return result, err
+	}
+
+	resp, body, err := h.client.Do(ctx, req)
+	if err != nil {
+		return result, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		api.UnmarshalMetadata(resp, &result.Metadata)
+		err = json.Unmarshal(body, &result)
+		return result, err
+	case http.StatusConflict:
+		return result, api.NewError(ErrCredentialExists, resp, body)
+	case http.StatusBadRequest:
+		return result, api.NewError(ErrCredentialInvalid, resp, body)
+	case http.StatusUnprocessableEntity:
+		return result, api.NewError(ErrCredentialInvalid, resp, body)
+	default:
+		return result, api.NewUnexpectedError(resp, body)
+	}
+}
+
+func (h *httpAPI) GetCredentialByName(ctx context.Context, n CredentialName) (Credential, error) {
+	u := h.client.URL(h.endpoint)
+	// TODO This is less then ideal
+	u.Path = path.Join(u.Path, "..", "credentials", n.String())
+	result := Credential{}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return result, err
+	}
+
+	resp, body, err := h.client.Do(ctx, req)
+	if err != nil {
+		return result, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		api.UnmarshalMetadata(resp, &result.Metadata)
+		err = json.Unmarshal(body, &result)
+		return result, err
+	case http.StatusNotFound:
+		err := api.NewError(ErrCredentialNotFound, resp, body)
+		err.Message = fmt.Sprintf(`credential "%s" not found`, n)
+		return result, err
+	default:
+		return result, api.NewUnexpectedError(resp, body)
+	}
+}
+
+func (h *httpAPI) ListCredentials(ctx context.Context) (CredentialList, error) {
+	// TODO This is less then ideal
+	u := h.client.URL(h.endpoint + "../credentials")
+	result := CredentialList{}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return result, err
+	}
+
+	resp, body, err := h.client.Do(ctx, req)
+	if err != nil {
+		return result, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		api.UnmarshalMetadata(resp, &result.Metadata)
+		err = json.Unmarshal(body, &result)
+		return result, err
+	default:
+		return result, api.NewUnexpectedError(resp, body)
+	}
+}
+
+func (h *httpAPI) RevokeCredential(ctx context.Context, u string) error {
+	req, err := http.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, body, err := h.client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return api.NewError(ErrCredentialNotFound, resp, body)
+	default:
+		return api.NewUnexpectedError(resp, body)
+	}
+}
+
 // httpNewJSONRequest returns a new HTTP request with a JSON payload.
 func httpNewJSONRequest(method, u string, body interface{}) (*http.Request, error) {
 	b, err := json.Marshal(body)
@@ -852,6 +1012,23 @@ func httpNewJSONRequest(method, u string, body interface{}) (*http.Request, erro
 	return req, err
 }
 
+// httpNewMergePatchRequest returns a JSON Merge Patch (RFC 7396) request. If
+// the supplied metadata includes an entity tag, it is sent as an `If-Match`
+// precondition so the patch is rejected if the resource was modified.
+func httpNewMergePatchRequest(u string, patch interface{}, md api.Metadata) (*http.Request, error) {
+	req, err := httpNewJSONRequest(http.MethodPatch, u, patch)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	if etag := md.ETag(); etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+
+	return req, nil
+}
+
 // applyQuery adds the query values to the supplied URL.
 func applyQuery(u string, q url.Values) string {
 	if len(q) == 0 {
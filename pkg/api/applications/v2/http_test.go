@@ -0,0 +1,52 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+func TestHTTPAPI_PatchApplication_Conflict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") != `"2"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client, err := api.NewClient(srv.URL, nil)
+	require.NoError(t, err)
+	appAPI := NewAPI(client)
+	ctx := context.Background()
+	u := srv.URL + "/v2/applications/my-app"
+
+	current := Application{Metadata: api.Metadata{"Etag": {`"2"`}}, DisplayName: "current"}
+	assert.NoError(t, appAPI.PatchApplication(ctx, u, current))
+
+	stale := Application{Metadata: api.Metadata{"Etag": {`"1"`}}, DisplayName: "stale"}
+	err = appAPI.PatchApplication(ctx, u, stale)
+	assert.True(t, api.IsConflict(err), "expected a conflict, got %v", err)
+}
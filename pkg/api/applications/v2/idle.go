@@ -0,0 +1,107 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// WaitForIdleOptions controls how an application is checked for pending work.
+type WaitForIdleOptions struct {
+	// Time between checks. Defaults to 10 seconds.
+	PollInterval time.Duration
+	// Optional check for work outside the activity feed (e.g. active experiments),
+	// returns the number of pending items for the application.
+	Pending func(ctx context.Context, app *Application) (int, error)
+}
+
+// WaitForIdle blocks until the application has no pending scans or runs in the
+// activity feed (and no other pending work reported by the options) or until the
+// context is done.
+func WaitForIdle(ctx context.Context, appAPI API, app *Application, opts WaitForIdleOptions) error {
+	selfURL := app.Link(api.RelationSelf)
+	if selfURL == "" {
+		return fmt.Errorf("malformed application, missing self link")
+	}
+
+	md, err := appAPI.CheckEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	feedURL := md.Link(api.RelationAlternate)
+	if feedURL == "" {
+		return fmt.Errorf("missing activity feed URL")
+	}
+
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	q := ActivityFeedQuery{}
+	q.SetType(TagScan, TagRun)
+
+	for {
+		pending, err := pendingActivity(ctx, appAPI, feedURL, q, selfURL)
+		if err != nil {
+			return err
+		}
+
+		if pending == 0 && opts.Pending != nil {
+			if pending, err = opts.Pending(ctx, app); err != nil {
+				return err
+			}
+		}
+
+		if pending == 0 {
+			return nil
+		}
+
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// pendingActivity returns the number of un-failed activity items that refer to the application.
+func pendingActivity(ctx context.Context, appAPI API, feedURL string, q ActivityFeedQuery, selfURL string) (int, error) {
+	feed, err := appAPI.ListActivity(ctx, feedURL, q)
+	if err != nil {
+		return 0, err
+	}
+	feed.SetBaseURL(feedURL)
+
+	pending := 0
+	for i := range feed.Items {
+		if feed.Items[i].StormForge != nil && feed.Items[i].StormForge.FailureReason != "" {
+			continue
+		}
+		if feed.Items[i].RefersTo(selfURL) {
+			pending++
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// idleAPI returns a canned sequence of activity feeds, repeating the last one.
+type idleAPI struct {
+	API
+	feeds [][]ActivityItem
+	polls int
+}
+
+func (a *idleAPI) CheckEndpoint(context.Context) (api.Metadata, error) {
+	return api.Metadata{"Link": {`<http://example.com/feed>; rel="alternate"`}}, nil
+}
+
+func (a *idleAPI) ListActivity(context.Context, string, ActivityFeedQuery) (ActivityFeed, error) {
+	feed := ActivityFeed{}
+	if len(a.feeds) > 0 {
+		feed.Items = a.feeds[0]
+		if len(a.feeds) > 1 {
+			a.feeds = a.feeds[1:]
+		}
+	}
+	a.polls++
+	return feed, nil
+}
+
+func TestWaitForIdle(t *testing.T) {
+	scan := ActivityItem{ID: "1", ExternalURL: "http://example.com/applications/my-app/scenarios/s1", Tags: []string{TagScan}}
+	failed := ActivityItem{ID: "2", ExternalURL: "http://example.com/applications/my-app", StormForge: &ActivityExtension{ActivityFailure{FailureReason: "oops"}}}
+	other := ActivityItem{ID: "3", ExternalURL: "http://example.com/applications/my-other-app"}
+
+	cases := []struct {
+		desc      string
+		feeds     [][]ActivityItem
+		pending   []int
+		polls     int
+		expectErr error
+	}{
+		{
+			desc:  "idle",
+			feeds: [][]ActivityItem{{failed, other}},
+			polls: 1,
+		},
+		{
+			desc:  "becomes idle",
+			feeds: [][]ActivityItem{{scan}, {scan}, {}},
+			polls: 3,
+		},
+		{
+			desc:    "pending work",
+			pending: []int{2, 1, 0},
+			polls:   3,
+		},
+		{
+			desc:      "timeout",
+			feeds:     [][]ActivityItem{{scan}},
+			expectErr: context.DeadlineExceeded,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			a := &idleAPI{feeds: c.feeds}
+			app := &Application{Metadata: api.Metadata{"Link": {`<http://example.com/applications/my-app>; rel="self"`}}}
+
+			opts := WaitForIdleOptions{PollInterval: time.Millisecond}
+			if c.pending != nil {
+				pending := c.pending
+				opts.Pending = func(context.Context, *Application) (int, error) {
+					n := pending[0]
+					pending = pending[1:]
+					return n, nil
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			err := WaitForIdle(ctx, a, app, opts)
+			if c.expectErr != nil {
+				assert.ErrorIs(t, err, c.expectErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.polls, a.polls)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"path"
 	"sort"
 	"strings"
 
@@ -33,6 +34,17 @@ type Lister struct {
 	API API
 	// BatchSize overrides the default batch size for fetching lists.
 	BatchSize int
+	// OnPage is an optional hook invoked after each page of a list is visited,
+	// return `api.ErrStopPaging` to stop iterating early without an error.
+	OnPage api.PageFunc
+	// ExactNames disables glob pattern matching of names, e.g. to prevent
+	// destructive operations from accidentally matching too many applications.
+	ExactNames bool
+	// Parallelism is the maximum number of names resolved concurrently.
+	Parallelism int
+	// ContinueOnError visits all the names that can be resolved, failures are
+	// reported together as an `api.NameErrorList`.
+	ContinueOnError bool
 }
 
 // ForEachApplication iterates over all the applications matching the supplied query.
@@ -40,6 +52,8 @@ func (l *Lister) ForEachApplication(ctx context.Context, q ApplicationListQuery,
 	// Only fetch a single page if an offset was supplied
 	onePage := url.Values(q.IndexQuery).Get(api.ParamOffset) != ""
 
+	page := 0
+
 	// Define a helper to iteratively (NOT recursively) visit applications
 	forEach := func(lst ApplicationList, err error) (string, error) {
 		if err != nil {
@@ -55,7 +69,9 @@ func (l *Lister) ForEachApplication(ctx context.Context, q ApplicationListQuery,
 			}
 		}
 
-		return lst.Link(api.RelationNext), nil
+		next, err := l.OnPage.Next(page, len(lst.Applications), lst.Next())
+		page++
+		return next, err
 	}
 
 	// Overwrite the limit
@@ -72,27 +88,139 @@ func (l *Lister) ForEachApplication(ctx context.Context, q ApplicationListQuery,
 }
 
 // ForEachNamedApplication iterates over all the named applications, optionally ignoring those that do not exist.
+// Unless exact names are required, names may also be glob patterns (e.g. `payments-*`) which are matched
+// against the full list of applications; a pattern which does not match any applications is not found.
 func (l *Lister) ForEachNamedApplication(ctx context.Context, names []string, ignoreNotFound bool, f func(item *ApplicationItem) error) error {
-	for _, name := range names {
-		app, err := l.API.GetApplicationByName(ctx, ApplicationName(name))
-		if err != nil {
-			var notFoundErr *api.Error
-			if errors.As(err, &notFoundErr) && notFoundErr.Type == ErrApplicationNotFound && ignoreNotFound {
-				continue
+	batch := l.batchApplications(ctx, names)
+	resolved := make([][]ApplicationItem, len(names))
+	visited := make(map[ApplicationName]bool, len(names))
+	return api.ForEachName(ctx, names, l.nameOptions(),
+		func(ctx context.Context, i int) (err error) {
+			if !l.ExactNames && isNamePattern(names[i]) {
+				resolved[i], err = l.matchingApplications(ctx, names[i], ignoreNotFound)
+				return err
 			}
-			return err
+
+			if item, ok := batch[ApplicationName(names[i])]; ok {
+				resolved[i] = []ApplicationItem{*item}
+				return nil
+			}
+
+			app, err := l.API.GetApplicationByName(ctx, ApplicationName(names[i]))
+			if err != nil {
+				var notFoundErr *api.Error
+				if errors.As(err, &notFoundErr) && notFoundErr.Type == ErrApplicationNotFound && ignoreNotFound {
+					return nil
+				}
+				return err
+			}
+
+			if app.Name == "" {
+				app.Name = ApplicationName(names[i])
+			}
+			resolved[i] = []ApplicationItem{{Application: app}}
+			return nil
+		},
+		func(i int) error {
+			for j := range resolved[i] {
+				if visited[resolved[i][j].Name] {
+					continue
+				}
+				visited[resolved[i][j].Name] = true
+
+				if err := f(&resolved[i][j]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+}
+
+// ListByNames returns the named applications which exist, in the order of the
+// names. If the server supports it, the applications are fetched using a single
+// request, otherwise each application is fetched individually.
+func (l *Lister) ListByNames(ctx context.Context, names []string) ([]ApplicationItem, error) {
+	var result []ApplicationItem
+	err := l.ForEachNamedApplication(ctx, names, true, func(item *ApplicationItem) error {
+		result = append(result, *item)
+		return nil
+	})
+	return result, err
+}
+
+// batchApplications fetches multiple applications using a single list request,
+// nothing is returned if the server does not support filtering by name.
+func (l *Lister) batchApplications(ctx context.Context, names []string) map[ApplicationName]*ApplicationItem {
+	wanted := make(map[ApplicationName]bool, len(names))
+	exact := make([]string, 0, len(names))
+	for _, n := range names {
+		if !l.ExactNames && isNamePattern(n) {
+			continue
+		}
+		wanted[ApplicationName(n)] = true
+		exact = append(exact, n)
+	}
+	if len(exact) < 2 {
+		return nil
+	}
+
+	q := ApplicationListQuery{}
+	q.SetNames(exact...)
+	q.SetLimit(len(exact))
+	lst, err := l.API.ListApplications(ctx, q)
+	if err != nil {
+		return nil
+	}
+
+	batch := make(map[ApplicationName]*ApplicationItem, len(lst.Applications))
+	for i := range lst.Applications {
+		// An unexpected name means the server ignored the filter
+		if !wanted[lst.Applications[i].Name] {
+			return nil
 		}
+		batch[lst.Applications[i].Name] = &lst.Applications[i]
+	}
+	return batch
+}
 
-		if err := f(&ApplicationItem{Application: app}); err != nil {
-			return err
+// matchingApplications returns the applications whose names match the supplied glob pattern.
+func (l *Lister) matchingApplications(ctx context.Context, pattern string, ignoreNotFound bool) ([]ApplicationItem, error) {
+	// Validate the pattern before fetching anything
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid application name pattern %q: %w", pattern, err)
+	}
+
+	var matches []ApplicationItem
+	if err := l.ForEachApplication(ctx, ApplicationListQuery{}, func(item *ApplicationItem) error {
+		if ok, _ := path.Match(pattern, item.Name.String()); ok {
+			matches = append(matches, *item)
 		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
-	return nil
+
+	if len(matches) == 0 && !ignoreNotFound {
+		return nil, &api.Error{Type: ErrApplicationNotFound, Message: fmt.Sprintf("no applications match %q", pattern)}
+	}
+	return matches, nil
+}
+
+// nameOptions returns the options used to resolve multiple names.
+func (l *Lister) nameOptions() api.NameOptions {
+	return api.NameOptions{Parallelism: l.Parallelism, ContinueOnError: l.ContinueOnError}
+}
+
+// isNamePattern checks if the supplied name contains glob pattern characters.
+func isNamePattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
 }
 
 // ForEachScenario iterates over all scenarios for an application matching the supplied query.
 // Deprecated: scenarios should no longer be used.
 func (l *Lister) ForEachScenario(ctx context.Context, app *Application, q ScenarioListQuery, f func(*ScenarioItem) error) (err error) {
+	page := 0
+
 	// Define a helper to iteratively (NOT recursively) list and visit scenarios
 	forEach := func(u string) (string, error) {
 		lst, err := l.API.ListScenarios(ctx, u, q)
@@ -109,7 +237,9 @@ func (l *Lister) ForEachScenario(ctx context.Context, app *Application, q Scenar
 			}
 		}
 
-		return lst.Link(api.RelationNext), nil
+		next, err := l.OnPage.Next(page, len(lst.Scenarios), lst.Next())
+		page++
+		return next, err
 	}
 
 	// Overwrite the limit
@@ -171,6 +301,8 @@ func (l *Lister) ForEachNamedScenario(ctx context.Context, names []string, ignor
 
 // ForEachRecommendation iterates over all the recommendations for an application.
 func (l *Lister) ForEachRecommendation(ctx context.Context, app *Application, f func(item *RecommendationItem) error) (err error) {
+	page := 0
+
 	// Define a helper to iteratively (NOT recursively) list and visit recommendations
 	forEach := func(u string) (string, error) {
 		lst, err := l.API.ListRecommendations(ctx, u)
@@ -187,7 +319,9 @@ func (l *Lister) ForEachRecommendation(ctx context.Context, app *Application, f
 			}
 		}
 
-		return lst.Link(api.RelationNext), nil
+		next, err := l.OnPage.Next(page, len(lst.Recommendations), lst.Next())
+		page++
+		return next, err
 	}
 
 	// Iterate over all scenario pages, starting with the application's "rel=scenarios"
@@ -198,6 +332,74 @@ func (l *Lister) ForEachRecommendation(ctx context.Context, app *Application, f
 	return
 }
 
+// ForEachWorkload iterates over all the workloads discovered for an application.
+func (l *Lister) ForEachWorkload(ctx context.Context, app *Application, f func(item *WorkloadItem) error) (err error) {
+	page := 0
+
+	// Define a helper to iteratively (NOT recursively) list and visit workloads
+	forEach := func(u string) (string, error) {
+		lst, err := l.API.ListWorkloads(ctx, u)
+		if err != nil {
+			return "", err
+		}
+
+		for i := range lst.Workloads {
+			if err := f(&lst.Workloads[i]); err != nil {
+				return "", err
+			}
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+		}
+
+		next, err := l.OnPage.Next(page, len(lst.Workloads), lst.Next())
+		page++
+		return next, err
+	}
+
+	// Iterate over all workload pages, starting with the application's "rel=workloads"
+	u := app.Link(api.RelationWorkloads)
+	for u != "" && err == nil {
+		u, err = forEach(u)
+	}
+	return
+}
+
+// ForEachActivity iterates over all the activity items in the feed matching the supplied query.
+// Unlike a subscription, this visits historical activity by following the feed's "next" URLs.
+func (l *Lister) ForEachActivity(ctx context.Context, u string, q ActivityFeedQuery, f func(item *ActivityItem) error) (err error) {
+	page := 0
+
+	// Define a helper to iteratively (NOT recursively) list and visit activity
+	forEach := func(u string) (string, error) {
+		feed, err := l.API.ListActivity(ctx, u, q)
+		if err != nil {
+			return "", err
+		}
+
+		for i := range feed.Items {
+			if err := f(&feed.Items[i]); err != nil {
+				return "", err
+			}
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+		}
+
+		next, err := l.OnPage.Next(page, len(feed.Items), feed.NextURL)
+		page++
+		return next, err
+	}
+
+	for u != "" && err == nil {
+		u, err = forEach(u)
+
+		// Reset the query so it is only used once, the "next" URL already includes it
+		q = ActivityFeedQuery{}
+	}
+	return
+}
+
 // ForEachNamedRecommendation iterates over all the named recommendations, optionally ignoring those that do not exist.
 func (l *Lister) ForEachNamedRecommendation(ctx context.Context, names []string, ignoreNotFound bool, f func(item *RecommendationItem) error) error {
 	cache := make(map[ApplicationName]map[string]string)
@@ -351,6 +553,8 @@ func (l *Lister) GetScenarioByNameOrTitle(ctx context.Context, app *Application,
 
 // ForEachCluster iterates over all the clusters.
 func (l *Lister) ForEachCluster(ctx context.Context, q ClusterListQuery, f func(item *ClusterItem) error) error {
+	page := 0
+
 	// Define a helper to iteratively (NOT recursively) visit clusters
 	forEach := func(lst ClusterList, err error) (string, error) {
 		if err != nil {
@@ -366,7 +570,9 @@ func (l *Lister) ForEachCluster(ctx context.Context, q ClusterListQuery, f func(
 			}
 		}
 
-		return lst.Link(api.RelationNext), nil
+		next, err := l.OnPage.Next(page, len(lst.Items), lst.Next())
+		page++
+		return next, err
 	}
 
 	// Iterate over all clusters, starting with first page
@@ -380,19 +586,68 @@ func (l *Lister) ForEachCluster(ctx context.Context, q ClusterListQuery, f func(
 
 // ForEachNamedCluster iterates over all the named clusters, optionally ignoring those that do not exist.
 func (l *Lister) ForEachNamedCluster(ctx context.Context, names []string, ignoreNotFound bool, f func(item *ClusterItem) error) error {
-	for _, name := range names {
-		c, err := l.API.GetClusterByName(ctx, ClusterName(name))
-		if err != nil {
-			var notFoundErr *api.Error
-			if errors.As(err, &notFoundErr) && notFoundErr.Type == ErrClusterNotFound && ignoreNotFound {
-				continue
+	resolved := make([]*ClusterItem, len(names))
+	return api.ForEachName(ctx, names, l.nameOptions(),
+		func(ctx context.Context, i int) error {
+			c, err := l.API.GetClusterByName(ctx, ClusterName(names[i]))
+			if err != nil {
+				var notFoundErr *api.Error
+				if errors.As(err, &notFoundErr) && notFoundErr.Type == ErrClusterNotFound && ignoreNotFound {
+					return nil
+				}
+				return err
+			}
+
+			resolved[i] = &ClusterItem{Cluster: c}
+			return nil
+		},
+		func(i int) error {
+			if resolved[i] == nil {
+				return nil
 			}
+			return f(resolved[i])
+		})
+}
+
+// ForEachCredential iterates over all the machine credentials.
+func (l *Lister) ForEachCredential(ctx context.Context, f func(item *CredentialItem) error) error {
+	lst, err := l.API.ListCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range lst.Items {
+		if err := f(&lst.Items[i]); err != nil {
 			return err
 		}
-
-		if err := f(&ClusterItem{Cluster: c}); err != nil {
+		if err := ctx.Err(); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// ForEachNamedCredential iterates over all the named machine credentials, optionally ignoring those that do not exist.
+func (l *Lister) ForEachNamedCredential(ctx context.Context, names []string, ignoreNotFound bool, f func(item *CredentialItem) error) error {
+	resolved := make([]*CredentialItem, len(names))
+	return api.ForEachName(ctx, names, l.nameOptions(),
+		func(ctx context.Context, i int) error {
+			c, err := l.API.GetCredentialByName(ctx, CredentialName(names[i]))
+			if err != nil {
+				var notFoundErr *api.Error
+				if errors.As(err, &notFoundErr) && notFoundErr.Type == ErrCredentialNotFound && ignoreNotFound {
+					return nil
+				}
+				return err
+			}
+
+			resolved[i] = &CredentialItem{Credential: c}
+			return nil
+		},
+		func(i int) error {
+			if resolved[i] == nil {
+				return nil
+			}
+			return f(resolved[i])
+		})
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// listerAPI returns a fixed list of applications.
+type listerAPI struct {
+	API
+	names []string
+	// Honor the names query parameter when listing applications
+	filter bool
+	gets   int32
+}
+
+func (a *listerAPI) ListApplications(_ context.Context, q ApplicationListQuery) (ApplicationList, error) {
+	lst := ApplicationList{}
+	filter := url.Values(q.IndexQuery).Get(api.ParamNames)
+	for _, n := range a.names {
+		if a.filter && filter != "" && !strings.Contains(","+filter+",", ","+n+",") {
+			continue
+		}
+		lst.Applications = append(lst.Applications, ApplicationItem{Application: Application{Name: ApplicationName(n)}})
+	}
+	return lst, nil
+}
+
+func (a *listerAPI) GetApplicationByName(_ context.Context, n ApplicationName) (Application, error) {
+	atomic.AddInt32(&a.gets, 1)
+	for _, name := range a.names {
+		if name == n.String() {
+			return Application{Name: n}, nil
+		}
+	}
+	return Application{}, &api.Error{Type: ErrApplicationNotFound}
+}
+
+func TestLister_ForEachNamedApplication(t *testing.T) {
+	cases := []struct {
+		desc            string
+		names           []string
+		exact           bool
+		ignoreNotFound  bool
+		continueOnError bool
+		expected        []string
+		notFound        bool
+	}{
+		{
+			desc:     "exact names",
+			names:    []string{"payments-api", "checkout"},
+			expected: []string{"payments-api", "checkout"},
+		},
+		{
+			desc:     "glob pattern",
+			names:    []string{"payments-*"},
+			expected: []string{"payments-api", "payments-db"},
+		},
+		{
+			desc:     "duplicates",
+			names:    []string{"payments-db", "payments-?b", "*"},
+			expected: []string{"payments-db", "checkout", "payments-api"},
+		},
+		{
+			desc:     "no match",
+			names:    []string{"orders-*"},
+			notFound: true,
+		},
+		{
+			desc:           "no match ignored",
+			names:          []string{"orders-*", "checkout"},
+			ignoreNotFound: true,
+			expected:       []string{"checkout"},
+		},
+		{
+			desc:            "continue on error",
+			names:           []string{"orders", "checkout", "orders-*", "payments-db"},
+			continueOnError: true,
+			expected:        []string{"checkout", "payments-db"},
+			notFound:        true,
+		},
+		{
+			desc:     "require exact",
+			names:    []string{"payments-*"},
+			exact:    true,
+			notFound: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			l := &Lister{
+				API:             &listerAPI{names: []string{"checkout", "payments-api", "payments-db"}},
+				ExactNames:      c.exact,
+				Parallelism:     2,
+				ContinueOnError: c.continueOnError,
+			}
+
+			var actual []string
+			err := l.ForEachNamedApplication(context.Background(), c.names, c.ignoreNotFound, func(item *ApplicationItem) error {
+				actual = append(actual, item.Name.String())
+				return nil
+			})
+			if c.notFound {
+				var nameErrs api.NameErrorList
+				if errors.As(err, &nameErrs) {
+					assert.Len(t, nameErrs, 2)
+					assert.True(t, api.IsNotFound(nameErrs[0]))
+					assert.Equal(t, c.expected, actual)
+					return
+				}
+				assert.True(t, api.IsNotFound(err))
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLister_ListByNames(t *testing.T) {
+	cases := []struct {
+		desc     string
+		names    []string
+		filter   bool
+		expected []string
+		gets     int32
+	}{
+		{
+			desc:     "batch",
+			names:    []string{"payments-db", "checkout"},
+			filter:   true,
+			expected: []string{"payments-db", "checkout"},
+		},
+		{
+			desc:     "batch missing",
+			names:    []string{"payments-db", "orders", "checkout"},
+			filter:   true,
+			expected: []string{"payments-db", "checkout"},
+			gets:     1,
+		},
+		{
+			desc:     "batch not supported",
+			names:    []string{"payments-db", "checkout"},
+			expected: []string{"payments-db", "checkout"},
+			gets:     2,
+		},
+		{
+			desc:     "single name",
+			names:    []string{"checkout"},
+			filter:   true,
+			expected: []string{"checkout"},
+			gets:     1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			fake := &listerAPI{names: []string{"checkout", "payments-api", "payments-db"}, filter: c.filter}
+			l := &Lister{API: fake}
+
+			items, err := l.ListByNames(context.Background(), c.names)
+			if assert.NoError(t, err) {
+				var actual []string
+				for _, item := range items {
+					actual = append(actual, item.Name.String())
+				}
+				assert.Equal(t, c.expected, actual)
+				assert.Equal(t, c.gets, fake.gets)
+			}
+		})
+	}
+}
@@ -33,6 +33,11 @@ type ClusterName string
 
 func (n ClusterName) String() string { return string(n) }
 
+// CredentialName represents a name token used to identify a machine credential.
+type CredentialName string
+
+func (n CredentialName) String() string { return string(n) }
+
 func SplitScenarioName(name string) (ApplicationName, ScenarioName) {
 	parts := strings.SplitN(name, "/", 2)
 	var scenarioName string
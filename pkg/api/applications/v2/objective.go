@@ -0,0 +1,79 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Goal is a single goal of a scenario objective, e.g. minimizing cost or
+// keeping the 95th percentile latency below a target.
+type Goal struct {
+	// The name of the goal, e.g. "cost" or "p95-latency".
+	Name string `json:"name"`
+	// The optional target value of the goal. Latency goals use a duration
+	// (e.g. "200ms"), all other goals use a number.
+	Target string `json:"target,omitempty"`
+}
+
+// ParseGoal parses a goal from a `name` or `name=target` string.
+func ParseGoal(s string) (Goal, error) {
+	name, target, _ := strings.Cut(s, "=")
+	g := Goal{Name: strings.TrimSpace(name), Target: strings.TrimSpace(target)}
+	if g.Name == "" {
+		return g, fmt.Errorf("invalid objective %q, goal name is required", s)
+	}
+	if err := checkGoalTarget(g.Name, g.Target); err != nil {
+		return g, fmt.Errorf("invalid objective %q: %w", s, err)
+	}
+	return g, nil
+}
+
+// NewObjective returns the scenario objective configuration for the supplied goals.
+func NewObjective(goals ...Goal) interface{} {
+	gs := make([]interface{}, 0, len(goals))
+	for _, g := range goals {
+		gm := map[string]interface{}{"name": g.Name}
+		if g.Target != "" {
+			gm["target"] = g.Target
+		}
+		gs = append(gs, gm)
+	}
+	return map[string]interface{}{"goals": gs}
+}
+
+// checkGoalTarget verifies the goal target can be interpreted for the named goal.
+func checkGoalTarget(name, target string) error {
+	if target == "" {
+		return nil
+	}
+
+	if strings.HasSuffix(name, "-latency") {
+		if d, err := time.ParseDuration(target); err != nil || d <= 0 {
+			return fmt.Errorf("latency target must be a positive duration: %s", target)
+		}
+		return nil
+	}
+
+	if f, err := strconv.ParseFloat(target, 64); err != nil || f < 0 {
+		return fmt.Errorf("target must be a non-negative number: %s", target)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGoal(t *testing.T) {
+	cases := []struct {
+		desc      string
+		goal      string
+		expected  Goal
+		expectErr bool
+	}{
+		{
+			desc:     "name only",
+			goal:     "cost",
+			expected: Goal{Name: "cost"},
+		},
+		{
+			desc:     "latency target",
+			goal:     "p95-latency=200ms",
+			expected: Goal{Name: "p95-latency", Target: "200ms"},
+		},
+		{
+			desc:     "numeric target",
+			goal:     "cost=100",
+			expected: Goal{Name: "cost", Target: "100"},
+		},
+		{
+			desc:      "invalid latency target",
+			goal:      "p95-latency=fast",
+			expectErr: true,
+		},
+		{
+			desc:      "invalid numeric target",
+			goal:      "cost=cheap",
+			expectErr: true,
+		},
+		{
+			desc:      "missing name",
+			goal:      "=1",
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := ParseGoal(c.goal)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, actual)
+			}
+		})
+	}
+}
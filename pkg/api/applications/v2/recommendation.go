@@ -43,6 +43,17 @@ type TargetRef struct {
 	Workload  string `json:"workload,omitempty"`
 }
 
+// ContainerName returns the name of the container a set of recommended
+// container resources (an element of `ContainerResources`) applies to.
+func ContainerName(containerResources map[string]interface{}) string {
+	for _, k := range []string{"containerName", "container", "name"} {
+		if s, ok := containerResources[k].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
 type RecommendationItem struct {
 	Recommendation
 }
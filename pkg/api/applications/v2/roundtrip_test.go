@@ -0,0 +1,59 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2_test
+
+import (
+	"testing"
+
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	"github.com/thestormforge/optimize-go/pkg/api/internal/apitest"
+)
+
+// roundTripTypes are the application API types which must round trip through JSON.
+var roundTripTypes = []struct {
+	name            string
+	newValue        func() interface{}
+	preserveUnknown bool
+}{
+	{name: "Application", newValue: func() interface{} { return &applications.Application{} }, preserveUnknown: true},
+	{name: "ApplicationItem", newValue: func() interface{} { return &applications.ApplicationItem{} }, preserveUnknown: true},
+	{name: "ApplicationList", newValue: func() interface{} { return &applications.ApplicationList{} }},
+	{name: "Scenario", newValue: func() interface{} { return &applications.Scenario{} }, preserveUnknown: true},
+	{name: "ScenarioItem", newValue: func() interface{} { return &applications.ScenarioItem{} }, preserveUnknown: true},
+	{name: "Template", newValue: func() interface{} { return &applications.Template{} }, preserveUnknown: true},
+	{name: "Recommendation", newValue: func() interface{} { return &applications.Recommendation{} }},
+	{name: "RecommendationList", newValue: func() interface{} { return &applications.RecommendationList{} }},
+	{name: "Cluster", newValue: func() interface{} { return &applications.Cluster{} }},
+	{name: "Credential", newValue: func() interface{} { return &applications.Credential{} }},
+	{name: "Workload", newValue: func() interface{} { return &applications.Workload{} }},
+	{name: "ActivityFeed", newValue: func() interface{} { return &applications.ActivityFeed{} }},
+}
+
+func FuzzJSONRoundTrip(f *testing.F) {
+	f.Add([]byte(`{"name":"my-app","title":"My App","resources":[{"kubernetes":{"namespace":"default","selector":"app=a"}}],"createdAt":"2023-01-02T03:04:05Z","scenarioCount":1,"recommendations":"auto"}`))
+	f.Add([]byte(`{"name":"s1","title":"Scenario","configuration":[{"name":"cpu","min":1,"max":10}],"objective":[{"name":"cost"}],"stormforge":{"testCase":"test"}}`))
+	f.Add([]byte(`{"parameters":[{"name":"cpu","type":"int","bounds":{"min":100,"max":4000}}],"metrics":[{"name":"cost","minimize":true}]}`))
+	f.Add([]byte(`{"items":[{"id":"1","url":"/a","title":"scan","date_published":"2023-01-02T03:04:05Z","tags":["scan"]}]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, rt := range roundTripTypes {
+			if err := apitest.CheckJSONRoundTrip(data, rt.newValue, rt.preserveUnknown); err != nil {
+				t.Errorf("%s: %v", rt.name, err)
+			}
+		}
+	})
+}
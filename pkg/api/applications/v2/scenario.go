@@ -18,6 +18,8 @@ package v2
 
 import (
 	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/thestormforge/optimize-go/pkg/api"
 )
@@ -30,14 +32,116 @@ type Scenario struct {
 	Objective     []interface{} `json:"objective,omitempty"`
 	Clusters      []string      `json:"clusters,omitempty"`
 
-	StormForgePerformance interface{} `json:"stormforgePerf,omitempty"`
-	Locust                interface{} `json:"locust,omitempty"`
-	Custom                interface{} `json:"custom,omitempty"`
+	StormForgePerformance *StormForgePerformanceScenario `json:"stormforgePerf,omitempty"`
+	Locust                *LocustScenario                `json:"locust,omitempty"`
+	JMeter                *JMeterScenario                `json:"jmeter,omitempty"`
+	Custom                interface{}                    `json:"custom,omitempty"`
+
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+
+	// Fields not known to this client, preserved when the scenario is updated.
+	Extensions api.Extensions `json:"-"`
+}
+
+func (scn *Scenario) UnmarshalJSON(b []byte) error {
+	type t Scenario
+	ext, err := api.UnmarshalJSONWithExtensions(b, (*t)(scn))
+	scn.Extensions = ext
+	return err
+}
+
+func (scn Scenario) MarshalJSON() ([]byte, error) {
+	type t Scenario
+	return api.MarshalJSONWithExtensions(t(scn), scn.Extensions)
+}
+
+// RetentionPolicy controls how many of the experiments created for a scenario are kept.
+type RetentionPolicy struct {
+	// The number of most recent experiments to keep.
+	KeepLast int `json:"keepLast,omitempty"`
+	// The number of days after which an experiment is removed.
+	MaxAgeDays int `json:"maxAgeDays,omitempty"`
+}
+
+// MaxAge returns the maximum age of an experiment as a duration.
+func (rp *RetentionPolicy) MaxAge() time.Duration {
+	return time.Duration(rp.MaxAgeDays) * 24 * time.Hour
 }
 
 // NOTE: Use `DisplayName` as the field since `Title()` is a function on the embedded `Metadata`
 var _ = Scenario{}.Title()
 
+// ScenarioKind identifies the type of load test configured for a scenario.
+type ScenarioKind string
+
+const (
+	ScenarioKindStormForgePerformance ScenarioKind = "stormforgePerf"
+	ScenarioKindLocust                ScenarioKind = "locust"
+	ScenarioKindJMeter                ScenarioKind = "jmeter"
+	ScenarioKindCustom                ScenarioKind = "custom"
+)
+
+// Kinds returns the kinds of load test configured for the scenario, a valid
+// scenario has at most one kind.
+func (s *Scenario) Kinds() []ScenarioKind {
+	var kinds []ScenarioKind
+	if s.StormForgePerformance != nil {
+		kinds = append(kinds, ScenarioKindStormForgePerformance)
+	}
+	if s.Locust != nil {
+		kinds = append(kinds, ScenarioKindLocust)
+	}
+	if s.JMeter != nil {
+		kinds = append(kinds, ScenarioKindJMeter)
+	}
+	if s.Custom != nil {
+		kinds = append(kinds, ScenarioKindCustom)
+	}
+	return kinds
+}
+
+// StormForgePerformanceScenario configures a load test using a StormForge Performance test case.
+type StormForgePerformanceScenario struct {
+	// The test case reference, either "CASE" or "ORG/CASE".
+	TestCase string `json:"testCase,omitempty"`
+	// The percentage of the test case's target load used during trials.
+	TargetUtilization int `json:"targetUtilization,omitempty"`
+}
+
+// LocustScenario configures a load test using Locust.
+type LocustScenario struct {
+	// The Python module to run, either a URL or the inlined module source.
+	Locustfile string `json:"locustfile,omitempty"`
+	// The number of concurrent users.
+	Users int `json:"users,omitempty"`
+	// The rate per second at which users are spawned.
+	SpawnRate int `json:"spawnRate,omitempty"`
+	// The amount of time to run the test for.
+	RunTime api.Duration `json:"runTime,omitempty"`
+}
+
+// JMeterScenario configures a load test using Apache JMeter.
+type JMeterScenario struct {
+	// The test plan to run, either a URL or the inlined JMX document.
+	TestPlan string `json:"testPlan,omitempty"`
+	// The number of threads used by the thread groups of the test plan.
+	Threads int `json:"threads,omitempty"`
+	// The amount of time taken to start all the threads.
+	RampUp api.Duration `json:"rampUp,omitempty"`
+	// The amount of time to run the test for.
+	Duration api.Duration `json:"duration,omitempty"`
+	// Additional properties passed to the test plan.
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// SplitTestCase splits a test case reference into the organization and test case names.
+func SplitTestCase(ref string) (org string, testCase string) {
+	if i := strings.Index(ref, "/"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return "", ref
+}
+
 type ScenarioListQuery struct{ api.IndexQuery }
 
 type ScenarioItem struct {
@@ -101,4 +205,18 @@ type Template struct {
 	Parameters []TemplateParameter `json:"parameters,omitempty"`
 	// The list of metrics for this template.
 	Metrics []TemplateMetric `json:"metrics,omitempty"`
+	// Fields not known to this client, preserved when the template is updated.
+	Extensions api.Extensions `json:"-"`
+}
+
+func (tmpl *Template) UnmarshalJSON(b []byte) error {
+	type t Template
+	ext, err := api.UnmarshalJSONWithExtensions(b, (*t)(tmpl))
+	tmpl.Extensions = ext
+	return err
+}
+
+func (tmpl Template) MarshalJSON() ([]byte, error) {
+	type t Template
+	return api.MarshalJSONWithExtensions(t(tmpl), tmpl.Extensions)
 }
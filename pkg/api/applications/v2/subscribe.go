@@ -22,6 +22,7 @@ import (
 	"math/rand"
 	"os"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/thestormforge/optimize-go/pkg/api"
@@ -55,11 +56,130 @@ type PollingSubscriber struct {
 	JitterFactor float64
 	// Flag indicating that failed activities should still be reported.
 	ReportFailedActivities bool // TODO Should this be part of the ActivityFeedQuery?
+	// The identifier of the last item seen by a previous subscription, only
+	// items after this identifier will be reported.
+	ResumeID string
+	// The initial delay before reconnecting after a network or server error,
+	// the delay doubles on each consecutive failure. Defaults to 1 second.
+	RetryInterval time.Duration
+	// The maximum delay between reconnect attempts. Defaults to 5 minutes.
+	MaxRetryInterval time.Duration
+	// Optional callback invoked with errors that do not end the subscription.
+	OnError func(error)
+
+	// Flag indicating that the consumer acknowledges each item (see `Ack`),
+	// draining waits for the acknowledgements of the items already sent.
+	TrackAcks bool
 
 	// The server may periodically request a longer delay.
 	rateLimit time.Duration
+	// The current delay due to consecutive failures.
+	backoff time.Duration
 	// The last feed item identifier acknowledged by this subscriber.
 	lastID string
+
+	// State used to gracefully drain the current subscription.
+	mu       sync.Mutex
+	inFlight map[string]struct{}
+	acked    chan struct{}
+	draining chan struct{}
+	drained  chan struct{}
+	drainCtx context.Context
+	drainErr error
+}
+
+// start resets the drain state for a new subscription.
+func (s *PollingSubscriber) start() (draining, drained chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight = make(map[string]struct{})
+	s.acked = make(chan struct{}, 1)
+	s.draining = make(chan struct{})
+	s.drained = make(chan struct{})
+	s.drainCtx = nil
+	s.drainErr = nil
+	return s.draining, s.drained
+}
+
+// Ack acknowledges that the consumer has finished handling the identified item.
+// Acknowledgements are only tracked when `TrackAcks` is set.
+func (s *PollingSubscriber) Ack(id string) {
+	s.mu.Lock()
+	delete(s.inFlight, id)
+	acked := s.acked
+	s.mu.Unlock()
+
+	select {
+	case acked <- struct{}{}:
+	default:
+	}
+}
+
+// Drain stops the current subscription from accepting new items and waits for
+// the items already sent to be acknowledged (when tracking acknowledgements)
+// before the channel is closed. If the supplied context ends before all items
+// are acknowledged, the channel is closed anyway and the context error is
+// returned. Draining without an active subscription does nothing.
+func (s *PollingSubscriber) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	draining, drained := s.draining, s.drained
+	if draining != nil && s.drainCtx == nil {
+		s.drainCtx = ctx
+		close(draining)
+	}
+	s.mu.Unlock()
+
+	if drained == nil {
+		return nil
+	}
+
+	select {
+	case <-drained:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.drainErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitForAcks blocks until all the in-flight items are acknowledged or the drain context is done.
+func (s *PollingSubscriber) waitForAcks() error {
+	if !s.TrackAcks {
+		return nil
+	}
+
+	s.mu.Lock()
+	ctx, acked := s.drainCtx, s.acked
+	s.mu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for {
+		s.mu.Lock()
+		n := len(s.inFlight)
+		s.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+
+		select {
+		case <-acked:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// LastID returns the identifier of the last item reported by this subscriber.
+// The value can be used as the `ResumeID` of a new subscriber to avoid missing
+// items between subscriptions.
+func (s *PollingSubscriber) LastID() string {
+	if s.lastID == "" {
+		return s.ResumeID
+	}
+	return s.lastID
 }
 
 // PollTimer returns a new timer for the next polling operation.
@@ -86,26 +206,48 @@ func (s *PollingSubscriber) PollTimer() *time.Timer {
 	interval += s.rateLimit
 	s.rateLimit = 0
 
+	// Include the backoff from consecutive failures
+	interval += s.backoff
+
 	return time.NewTimer(interval + time.Duration(jitter))
 }
 
 // Subscribe polls for activity, blocking until the supplied context is finished
 // or a fatal error occurs talking to the activity endpoint.
-func (s *PollingSubscriber) Subscribe(ctx context.Context, ch chan<- ActivityItem) error {
+func (s *PollingSubscriber) Subscribe(ctx context.Context, ch chan<- ActivityItem) (err error) {
+	draining, drained := s.start()
+
 	// Close the channel when we are done sending things
-	defer close(ch)
+	defer func() {
+		close(ch)
+		s.mu.Lock()
+		s.drainErr = err
+		s.mu.Unlock()
+		close(drained)
+	}()
+
+	// Pick up where a previous subscription left off
+	if s.lastID == "" {
+		s.lastID = s.ResumeID
+	}
 
 	for {
 		// Wait for the timer
 		t := s.PollTimer()
 		select {
 		case <-ctx.Done():
+			t.Stop()
 			return ctx.Err()
+		case <-draining:
+			t.Stop()
+			return s.waitForAcks()
 		case <-t.C:
 		}
 
 		// Fetch the feed and send new items to the channel
-		f, err := s.API.ListActivity(ctx, s.FeedURL, ActivityFeedQuery{})
+		q := ActivityFeedQuery{}
+		q.SetLastEventID(s.lastID)
+		f, err := s.API.ListActivity(ctx, s.FeedURL, q)
 		if err != nil {
 			var apiErr *api.Error
 			if errors.As(err, &apiErr) {
@@ -116,16 +258,71 @@ func (s *PollingSubscriber) Subscribe(ctx context.Context, ch chan<- ActivityIte
 				}
 			}
 
-			return err
+			if !s.retry(ctx, err) {
+				return err
+			}
+			continue
 		}
 
-		s.notify(f.Items, ch)
+		s.backoff = 0
+		if !s.notify(f.Items, ch, draining) {
+			return s.waitForAcks()
+		}
 	}
 }
 
-// notify sends all the items from the supplied feed to the channel.
+// retry reports a recoverable error and increases the backoff, returning false
+// if the error should end the subscription.
+func (s *PollingSubscriber) retry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || !isTemporary(err) {
+		return false
+	}
+
+	if s.OnError != nil {
+		s.OnError(err)
+	}
+
+	maxBackoff := s.MaxRetryInterval
+	if maxBackoff <= 0 {
+		maxBackoff = 5 * time.Minute
+	}
+
+	switch {
+	case s.backoff == 0 && s.RetryInterval > 0:
+		s.backoff = s.RetryInterval
+	case s.backoff == 0:
+		s.backoff = 1 * time.Second
+	default:
+		s.backoff *= 2
+	}
+	if s.backoff > maxBackoff {
+		s.backoff = maxBackoff
+	}
+
+	// Honor the server's request to wait (e.g. "service unavailable")
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > s.backoff {
+		s.rateLimit = apiErr.RetryAfter - s.backoff
+	}
+	return true
+}
+
+// isTemporary checks if an error is likely to resolve itself by reconnecting.
+func isTemporary(err error) bool {
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		// Only retry if the server asked us to come back later
+		return apiErr.RetryAfter > 0
+	}
+
+	// Everything else (e.g. network errors, malformed responses) is worth another try
+	return true
+}
+
+// notify sends all the items from the supplied feed to the channel, returning
+// false if the subscription started draining before all the items were sent.
 // IMPORTANT: this function assumes item identifiers can be compared lexicographically.
-func (s *PollingSubscriber) notify(items []ActivityItem, ch chan<- ActivityItem) {
+func (s *PollingSubscriber) notify(items []ActivityItem, ch chan<- ActivityItem, draining <-chan struct{}) bool {
 	// Make sure the items are sorted by their identifier
 	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
 	for i := range items {
@@ -140,7 +337,20 @@ func (s *PollingSubscriber) notify(items []ActivityItem, ch chan<- ActivityItem)
 		}
 
 		// Send the item to the channel and update the last ID
-		ch <- items[i]
-		s.lastID = items[i].ID
+		if s.TrackAcks {
+			s.mu.Lock()
+			s.inFlight[items[i].ID] = struct{}{}
+			s.mu.Unlock()
+		}
+		select {
+		case ch <- items[i]:
+			s.lastID = items[i].ID
+		case <-draining:
+			s.mu.Lock()
+			delete(s.inFlight, items[i].ID)
+			s.mu.Unlock()
+			return false
+		}
 	}
+	return true
 }
@@ -0,0 +1,274 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// subscribeAPI returns a canned sequence of activity feed results.
+type subscribeAPI struct {
+	API
+	results []error
+	items   []ActivityItem
+	queries []ActivityFeedQuery
+}
+
+func (a *subscribeAPI) ListActivity(_ context.Context, _ string, q ActivityFeedQuery) (ActivityFeed, error) {
+	a.queries = append(a.queries, q)
+	if len(a.results) > 0 {
+		err := a.results[0]
+		a.results = a.results[1:]
+		if err != nil {
+			return ActivityFeed{}, err
+		}
+	}
+	return ActivityFeed{Items: a.items}, nil
+}
+
+func TestPollingSubscriber_Subscribe(t *testing.T) {
+	cases := []struct {
+		desc      string
+		resumeID  string
+		results   []error
+		items     []ActivityItem
+		expected  []string
+		errors    int
+		expectErr bool
+	}{
+		{
+			desc:     "reconnect",
+			results:  []error{errors.New("connection reset"), errors.New("connection reset"), nil},
+			items:    []ActivityItem{{ID: "1"}, {ID: "2"}},
+			expected: []string{"1", "2"},
+			errors:   2,
+		},
+		{
+			desc:     "resume",
+			resumeID: "1",
+			items:    []ActivityItem{{ID: "1"}, {ID: "2"}},
+			expected: []string{"2"},
+		},
+		{
+			desc:      "fatal",
+			results:   []error{&api.Error{Type: api.ErrUnauthorized}},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			a := &subscribeAPI{results: c.results, items: c.items}
+			errCount := 0
+			s := &PollingSubscriber{
+				API:           a,
+				PollInterval:  time.Millisecond,
+				RetryInterval: time.Millisecond,
+				ResumeID:      c.resumeID,
+				OnError:       func(error) { errCount++ },
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			ch := make(chan ActivityItem)
+			done := make(chan error, 1)
+			go func() { done <- s.Subscribe(ctx, ch) }()
+
+			var actual []string
+			for item := range ch {
+				actual = append(actual, item.ID)
+				if len(actual) == len(c.expected) {
+					cancel()
+				}
+			}
+
+			err := <-done
+			if c.expectErr {
+				assert.False(t, errors.Is(err, context.Canceled))
+				return
+			}
+			assert.Equal(t, c.expected, actual)
+			assert.Equal(t, c.errors, errCount)
+			assert.Equal(t, c.expected[len(c.expected)-1], s.LastID())
+			if c.resumeID != "" {
+				assert.Equal(t, c.resumeID, url.Values(a.queries[0].Query).Get("last_event_id"))
+			}
+		})
+	}
+}
+
+func TestPollingSubscriber_Drain(t *testing.T) {
+	cases := []struct {
+		desc      string
+		trackAcks bool
+		ack       bool
+		expectErr bool
+	}{
+		{
+			desc:      "acknowledged",
+			trackAcks: true,
+			ack:       true,
+		},
+		{
+			desc:      "deadline",
+			trackAcks: true,
+			expectErr: true,
+		},
+		{
+			desc: "untracked",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			s := &PollingSubscriber{
+				API:          &subscribeAPI{items: []ActivityItem{{ID: "1"}, {ID: "2"}}},
+				PollInterval: time.Millisecond,
+				TrackAcks:    c.trackAcks,
+			}
+
+			ch := make(chan ActivityItem)
+			done := make(chan error, 1)
+			go func() { done <- s.Subscribe(context.Background(), ch) }()
+
+			// Receive the first item, the second item must never be delivered
+			item := <-ch
+			assert.Equal(t, "1", item.ID)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			drained := make(chan error, 1)
+			go func() { drained <- s.Drain(ctx) }()
+			if c.ack {
+				s.Ack(item.ID)
+			}
+
+			err := <-drained
+			if c.expectErr {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			_, ok := <-ch
+			assert.False(t, ok, "channel should be closed")
+			assert.Equal(t, err, <-done)
+			if !c.trackAcks {
+				assert.Empty(t, s.inFlight)
+			}
+		})
+	}
+}
+
+func TestPollingSubscriber_Resubscribe(t *testing.T) {
+	s := &PollingSubscriber{
+		API:          &subscribeAPI{items: []ActivityItem{{ID: "1"}, {ID: "2"}}},
+		PollInterval: time.Millisecond,
+	}
+
+	// Draining before subscribing does nothing
+	assert.NoError(t, s.Drain(context.Background()))
+
+	for _, expected := range []string{"1", "2"} {
+		ch := make(chan ActivityItem)
+		done := make(chan error, 1)
+		go func() { done <- s.Subscribe(context.Background(), ch) }()
+
+		item := <-ch
+		assert.Equal(t, expected, item.ID)
+		assert.NoError(t, s.Drain(context.Background()))
+		assert.NoError(t, <-done)
+	}
+}
+
+// staticSubscriber sends a fixed list of items.
+type staticSubscriber []ActivityItem
+
+func (s staticSubscriber) Subscribe(_ context.Context, ch chan<- ActivityItem) error {
+	defer close(ch)
+	for _, item := range s {
+		ch <- item
+	}
+	return nil
+}
+
+func TestFanOut_Subscribe(t *testing.T) {
+	f := &FanOut{
+		Subscriber: staticSubscriber{
+			{ID: "1", Tags: []string{TagScan}},
+			{ID: "2", Tags: []string{TagRun}},
+			{ID: "3", Tags: []string{TagApprove}},
+		},
+	}
+
+	scans, runs := make(chan ActivityItem), make(chan ActivityItem)
+	f.Add(scans, TagScan)
+	f.Add(runs, TagRun, TagApprove)
+	all := make(chan ActivityItem)
+
+	ids := func(ch <-chan ActivityItem) <-chan []string {
+		result := make(chan []string, 1)
+		go func() {
+			var ids []string
+			for item := range ch {
+				ids = append(ids, item.ID)
+			}
+			result <- ids
+		}()
+		return result
+	}
+	scanIDs, runIDs, allIDs := ids(scans), ids(runs), ids(all)
+
+	assert.NoError(t, f.Subscribe(context.Background(), all))
+	assert.Equal(t, []string{"1"}, <-scanIDs)
+	assert.Equal(t, []string{"2", "3"}, <-runIDs)
+	assert.Equal(t, []string{"1", "2", "3"}, <-allIDs)
+}
+
+func TestFanOut_Drain(t *testing.T) {
+	s := &PollingSubscriber{
+		API:          &subscribeAPI{items: []ActivityItem{{ID: "1"}, {ID: "2"}}},
+		PollInterval: time.Millisecond,
+		TrackAcks:    true,
+	}
+	f := &FanOut{Subscriber: s}
+
+	ch := make(chan ActivityItem)
+	done := make(chan error, 1)
+	go func() { done <- f.Subscribe(context.Background(), ch) }()
+	assert.Equal(t, "1", (<-ch).ID)
+
+	// The fan out acknowledges delivered items so draining does not wait for the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() {
+		for range ch {
+		}
+	}()
+	assert.NoError(t, f.Drain(ctx))
+	assert.NoError(t, ctx.Err())
+	assert.NoError(t, <-done)
+
+	assert.NoError(t, (&FanOut{Subscriber: staticSubscriber{}}).Drain(ctx))
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// Validate checks the application for problems that would cause the server to
+// reject it. The result is an `api.FieldErrorList` describing each problem, or
+// nil if the application is valid.
+func Validate(app *Application) error {
+	var errs api.FieldErrorList
+	for i := range app.Resources {
+		validateResource(&errs, fmt.Sprintf("resources[%d].kubernetes", i), &app.Resources[i])
+	}
+	return errs.Err()
+}
+
+// ValidateScenario checks the scenario for problems that would cause the server
+// to reject it. The result is an `api.FieldErrorList` describing each problem,
+// or nil if the scenario is valid.
+func ValidateScenario(scn *Scenario) error {
+	var errs api.FieldErrorList
+
+	for i, c := range scn.Clusters {
+		if strings.TrimSpace(c) == "" {
+			errs.Add(fmt.Sprintf("clusters[%d]", i), "cluster name is required")
+		}
+	}
+
+	for i, obj := range scn.Objective {
+		validateObjective(&errs, fmt.Sprintf("objective[%d]", i), obj)
+	}
+
+	// At most one type of load test may be configured
+	if kinds := scn.Kinds(); len(kinds) > 1 {
+		errs.Add(string(kinds[1]), "conflicts with %s", kinds[0])
+	}
+
+	if scn.StormForgePerformance != nil {
+		validateStormForgePerformance(&errs, string(ScenarioKindStormForgePerformance), scn.StormForgePerformance)
+	}
+	if scn.Locust != nil {
+		validateLocust(&errs, string(ScenarioKindLocust), scn.Locust)
+	}
+	if scn.JMeter != nil {
+		validateJMeter(&errs, string(ScenarioKindJMeter), scn.JMeter)
+	}
+
+	return errs.Err()
+}
+
+// ValidateTemplate checks the template against the experiment that will be
+// generated from it: the parameters and metrics must make a valid experiment
+// and each baseline must be within the parameter's bounds. The result is an
+// `api.FieldErrorList` describing each problem, or nil if the template is valid.
+func ValidateTemplate(t *Template) error {
+	var errs api.FieldErrorList
+
+	exp := TemplateExperiment(t)
+	if err := experiments.Validate(&exp); err != nil && !errors.As(err, &errs) {
+		return err
+	}
+
+	for i := range t.Parameters {
+		field := fmt.Sprintf("parameters[%d]", i)
+		if t.Parameters[i].Baseline == nil || hasFieldErrors(errs, field) {
+			continue
+		}
+		if err := experiments.CheckParameterValue(&exp.Parameters[i], t.Parameters[i].Baseline); err != nil {
+			errs.Add(field+".baseline", "%s", err.Error())
+		}
+	}
+
+	for i, m := range t.Metrics {
+		if b := m.Bounds; b != nil && b.Max != 0 && b.Min > b.Max {
+			errs.Add(fmt.Sprintf("metrics[%d].bounds", i), "minimum (%g) must not be greater than maximum (%g)", b.Min, b.Max)
+		}
+	}
+
+	return errs.Err()
+}
+
+// TemplateExperiment returns the experiment parameters and metrics which will
+// be generated from the template.
+func TemplateExperiment(t *Template) experiments.Experiment {
+	exp := experiments.Experiment{
+		Parameters: make([]experiments.Parameter, 0, len(t.Parameters)),
+		Metrics:    make([]experiments.Metric, 0, len(t.Metrics)),
+	}
+
+	for _, p := range t.Parameters {
+		ep := experiments.Parameter{
+			Name:   p.Name,
+			Type:   experiments.ParameterType(p.Type),
+			Values: p.Values,
+		}
+		if p.Bounds != nil {
+			ep.Bounds = &experiments.Bounds{Min: p.Bounds.Min, Max: p.Bounds.Max}
+		}
+		exp.Parameters = append(exp.Parameters, ep)
+	}
+
+	for _, m := range t.Metrics {
+		exp.Metrics = append(exp.Metrics, experiments.Metric{
+			Name:     m.Name,
+			Minimize: m.Minimize,
+			Optimize: m.Optimize,
+		})
+	}
+
+	return exp
+}
+
+// hasFieldErrors checks if any of the errors are for the supplied field or one of its children.
+func hasFieldErrors(errs api.FieldErrorList, field string) bool {
+	for _, e := range errs {
+		if e.Field == field || strings.HasPrefix(e.Field, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateStormForgePerformance checks the test case reference and target utilization.
+func validateStormForgePerformance(errs *api.FieldErrorList, field string, perf *StormForgePerformanceScenario) {
+	_, testCase := SplitTestCase(perf.TestCase)
+	switch {
+	case perf.TestCase == "":
+		errs.Add(field+".testCase", "test case is required")
+	case testCase == "" || strings.HasPrefix(perf.TestCase, "/") || strings.Count(perf.TestCase, "/") > 1:
+		errs.Add(field+".testCase", "invalid test case %q, expected CASE or ORG/CASE", perf.TestCase)
+	}
+
+	if perf.TargetUtilization < 0 || perf.TargetUtilization > 100 {
+		errs.Add(field+".targetUtilization", "must be between 0 and 100")
+	}
+}
+
+// validateResource checks that the resource selects something and that it
+// does not mix the different ways of selecting namespaces.
+func validateResource(errs *api.FieldErrorList, field string, r *Resource) {
+	k := &r.Kubernetes
+	if k.Namespace == "" && len(k.Namespaces) == 0 && k.NamespaceSelector == "" {
+		errs.Add(field, "one of namespace, namespaces or namespaceSelector is required")
+		return
+	}
+
+	if k.Namespace != "" && len(k.Namespaces) > 0 {
+		errs.Add(field+".namespaces", "conflicts with namespace")
+	}
+	if k.NamespaceSelector != "" && (k.Namespace != "" || len(k.Namespaces) > 0) {
+		errs.Add(field+".namespaceSelector", "conflicts with namespace")
+	}
+
+	seen := make(map[string]struct{}, len(k.Namespaces))
+	for i, ns := range k.Namespaces {
+		nsField := fmt.Sprintf("%s.namespaces[%d]", field, i)
+		if ns == "" {
+			errs.Add(nsField, "namespace is required")
+		} else if _, ok := seen[ns]; ok {
+			errs.Add(nsField, "duplicate namespace %q", ns)
+		} else {
+			seen[ns] = struct{}{}
+		}
+	}
+
+	for i, t := range k.Types {
+		if strings.TrimSpace(t) == "" {
+			errs.Add(fmt.Sprintf("%s.types[%d]", field, i), "type is required")
+		}
+	}
+}
+
+// validateObjective checks the loosely typed objective configuration.
+func validateObjective(errs *api.FieldErrorList, field string, obj interface{}) {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		errs.Add(field, "objective must be an object")
+		return
+	}
+
+	goals, ok := m["goals"]
+	if !ok {
+		return
+	}
+
+	gs, ok := goals.([]interface{})
+	if !ok {
+		errs.Add(field+".goals", "goals must be a list")
+		return
+	}
+
+	names := make(map[string]struct{}, len(gs))
+	for i, g := range gs {
+		goalField := fmt.Sprintf("%s.goals[%d]", field, i)
+		gm, ok := g.(map[string]interface{})
+		if !ok {
+			errs.Add(goalField, "goal must be an object")
+			continue
+		}
+
+		name, _ := gm["name"].(string)
+		if name == "" {
+			errs.Add(goalField+".name", "name is required")
+		} else if _, ok := names[name]; ok {
+			errs.Add(goalField+".name", "duplicate goal name %q", name)
+		} else {
+			names[name] = struct{}{}
+		}
+
+		if target, ok := gm["target"]; ok {
+			ts, ok := target.(string)
+			if !ok {
+				errs.Add(goalField+".target", "target must be a string")
+			} else if err := checkGoalTarget(name, ts); err != nil {
+				errs.Add(goalField+".target", "%s", err.Error())
+			}
+		}
+	}
+}
+
+// validateLocust checks the Locust file and load settings.
+func validateLocust(errs *api.FieldErrorList, field string, locust *LocustScenario) {
+	if strings.TrimSpace(locust.Locustfile) == "" {
+		errs.Add(field+".locustfile", "locustfile is required")
+	}
+	if locust.Users < 0 {
+		errs.Add(field+".users", "must not be negative")
+	}
+	if locust.SpawnRate < 0 {
+		errs.Add(field+".spawnRate", "must not be negative")
+	}
+	if locust.RunTime < 0 {
+		errs.Add(field+".runTime", "must not be negative")
+	}
+}
+
+// validateJMeter checks the JMeter test plan and load settings.
+func validateJMeter(errs *api.FieldErrorList, field string, jmeter *JMeterScenario) {
+	if strings.TrimSpace(jmeter.TestPlan) == "" {
+		errs.Add(field+".testPlan", "test plan is required")
+	}
+	if jmeter.Threads < 0 {
+		errs.Add(field+".threads", "must not be negative")
+	}
+	if jmeter.RampUp < 0 {
+		errs.Add(field+".rampUp", "must not be negative")
+	}
+	if jmeter.Duration < 0 {
+		errs.Add(field+".duration", "must not be negative")
+	}
+	for k := range jmeter.Properties {
+		if strings.TrimSpace(k) == "" {
+			errs.Add(field+".properties", "property name is required")
+		}
+	}
+}
+
+// StrictTemplateAPI is an application API which validates templates before
+// they are updated, catching problems which the server would otherwise only
+// report when it generates an experiment from the template.
+type StrictTemplateAPI struct {
+	API
+}
+
+// UpdateTemplate validates the template before updating it.
+func (s *StrictTemplateAPI) UpdateTemplate(ctx context.Context, u string, t Template) error {
+	if err := ValidateTemplate(&t); err != nil {
+		return &api.Error{
+			Type:    ErrScanInvalid,
+			Message: fmt.Sprintf("template is invalid:\n%s", err),
+		}
+	}
+	return s.API.UpdateTemplate(ctx, u, t)
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		desc      string
+		resources []Resource
+		fields    []string
+	}{
+		{
+			desc: "no resources",
+		},
+		{
+			desc:      "namespace",
+			resources: []Resource{resource("default", nil, "")},
+		},
+		{
+			desc:      "empty",
+			resources: []Resource{resource("", nil, "")},
+			fields:    []string{"resources[0].kubernetes"},
+		},
+		{
+			desc:      "conflicting namespaces",
+			resources: []Resource{resource("default", []string{"a", "a"}, "env=prod")},
+			fields: []string{
+				"resources[0].kubernetes.namespaces",
+				"resources[0].kubernetes.namespaceSelector",
+				"resources[0].kubernetes.namespaces[1]",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assertFields(t, c.fields, Validate(&Application{Resources: c.resources}))
+		})
+	}
+}
+
+func TestValidateScenario(t *testing.T) {
+	cases := []struct {
+		desc   string
+		scn    Scenario
+		fields []string
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc: "goals",
+			scn: Scenario{
+				Objective: []interface{}{
+					map[string]interface{}{"goals": []interface{}{
+						map[string]interface{}{"name": "cost"},
+						map[string]interface{}{"name": "cost"},
+						map[string]interface{}{},
+						map[string]interface{}{"name": "p95-latency", "target": "200ms"},
+						map[string]interface{}{"name": "p99-latency", "target": "200"},
+						map[string]interface{}{"name": "error-rate", "target": 1},
+					}},
+					"foo",
+				},
+			},
+			fields: []string{
+				"objective[0].goals[1].name",
+				"objective[0].goals[2].name",
+				"objective[0].goals[4].target",
+				"objective[0].goals[5].target",
+				"objective[1]",
+			},
+		},
+		{
+			desc: "conflicting tests",
+			scn: Scenario{
+				Clusters:              []string{""},
+				StormForgePerformance: &StormForgePerformanceScenario{TestCase: "test"},
+				Custom:                map[string]interface{}{},
+			},
+			fields: []string{"clusters[0]", "custom"},
+		},
+		{
+			desc: "stormforge performance",
+			scn: Scenario{
+				StormForgePerformance: &StormForgePerformanceScenario{TestCase: "/test", TargetUtilization: 120},
+			},
+			fields: []string{"stormforgePerf.testCase", "stormforgePerf.targetUtilization"},
+		},
+		{
+			desc: "locust",
+			scn: Scenario{
+				Locust: &LocustScenario{Users: -1},
+			},
+			fields: []string{"locust.locustfile", "locust.users"},
+		},
+		{
+			desc: "jmeter",
+			scn: Scenario{
+				JMeter: &JMeterScenario{TestPlan: "https://example.com/plan.jmx", Threads: 10, Duration: -1},
+			},
+			fields: []string{"jmeter.duration"},
+		},
+		{
+			desc: "stormforge performance organization",
+			scn: Scenario{
+				StormForgePerformance: &StormForgePerformanceScenario{TestCase: "org/test", TargetUtilization: 80},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assertFields(t, c.fields, ValidateScenario(&c.scn))
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	baseline := func(v api.NumberOrString) *api.NumberOrString { return &v }
+	metrics := []TemplateMetric{{Name: "cost", Minimize: true}}
+	cases := []struct {
+		desc     string
+		template Template
+		fields   []string
+	}{
+		{
+			desc: "valid",
+			template: Template{
+				Parameters: []TemplateParameter{
+					{Name: "cpu", Type: "int", Baseline: baseline(api.FromInt64(500)), Bounds: &TemplateParameterBounds{Min: "100", Max: "1000"}},
+					{Name: "gc", Type: "categorical", Baseline: baseline(api.FromString("g1")), Values: []string{"g1", "zgc"}},
+				},
+				Metrics: metrics,
+			},
+		},
+		{
+			desc:   "empty",
+			fields: []string{"parameters", "metrics"},
+		},
+		{
+			desc: "parameters",
+			template: Template{
+				Parameters: []TemplateParameter{
+					{Name: "cpu", Type: "int", Bounds: &TemplateParameterBounds{Min: "0.5", Max: "2"}},
+					{Name: "cpu", Type: "double", Bounds: &TemplateParameterBounds{Min: "2", Max: "1"}},
+					{Name: "memory", Type: "int"},
+				},
+				Metrics: metrics,
+			},
+			fields: []string{
+				"parameters[0].bounds.min",
+				"parameters[1].name",
+				"parameters[1].bounds",
+				"parameters[2].bounds",
+			},
+		},
+		{
+			desc: "baseline",
+			template: Template{
+				Parameters: []TemplateParameter{
+					{Name: "cpu", Type: "int", Baseline: baseline(api.FromInt64(5000)), Bounds: &TemplateParameterBounds{Min: "100", Max: "1000"}},
+					{Name: "gc", Type: "categorical", Baseline: baseline(api.FromString("cms")), Values: []string{"g1", "zgc"}},
+					{Name: "memory", Type: "int", Baseline: baseline(api.FromInt64(5000))},
+				},
+				Metrics: metrics,
+			},
+			fields: []string{
+				"parameters[2].bounds",
+				"parameters[0].baseline",
+				"parameters[1].baseline",
+			},
+		},
+		{
+			desc: "metrics",
+			template: Template{
+				Parameters: []TemplateParameter{{Name: "gc", Type: "categorical", Values: []string{"g1"}}},
+				Metrics: []TemplateMetric{
+					{Name: "cost", Bounds: &TemplateMetricBounds{Min: 10, Max: 1}},
+					{Name: "cost"},
+				},
+			},
+			fields: []string{"metrics[1].name", "metrics[0].bounds"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assertFields(t, c.fields, ValidateTemplate(&c.template))
+		})
+	}
+}
+
+func resource(namespace string, namespaces []string, namespaceSelector string) Resource {
+	r := Resource{}
+	r.Kubernetes.Namespace = namespace
+	r.Kubernetes.Namespaces = namespaces
+	r.Kubernetes.NamespaceSelector = namespaceSelector
+	return r
+}
+
+func assertFields(t *testing.T, expected []string, err error) {
+	if len(expected) == 0 {
+		assert.NoError(t, err)
+		return
+	}
+
+	var errs api.FieldErrorList
+	if assert.True(t, errors.As(err, &errs), "expected field errors, got %v", err) {
+		var fields []string
+		for _, e := range errs {
+			fields = append(fields, e.Field)
+		}
+		assert.Equal(t, expected, fields)
+	}
+}
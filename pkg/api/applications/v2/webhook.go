@@ -0,0 +1,198 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+const (
+	// WebhookSignatureHeader is the request header containing the HMAC-SHA256
+	// signature of the request body, formatted as "sha256=<hex digest>".
+	WebhookSignatureHeader = "X-StormForge-Signature-256"
+	// WebhookDeliveryHeader is the request header containing the activity item
+	// identifier, receivers can use it to discard duplicate deliveries.
+	WebhookDeliveryHeader = "X-StormForge-Delivery"
+)
+
+// ActivityWebhookForwarder subscribes to the activity feed and POSTs each item
+// as JSON to a webhook URL, allowing systems that cannot hold a long-lived
+// subscription to receive activity.
+type ActivityWebhookForwarder struct {
+	// The subscriber used to obtain activity items.
+	Subscriber Subscriber
+	// The URL each item is posted to.
+	URL string
+	// The optional secret used to sign the request body.
+	Secret []byte
+	// Only forward items having any of these tags, forward all items if empty.
+	Tags []string
+	// The HTTP client used to deliver items. Defaults to `http.DefaultClient`.
+	Client *http.Client
+	// The number of times a failed delivery is retried. Defaults to 3, use a
+	// negative number to disable retries.
+	MaxRetries int
+	// The initial delay before retrying a failed delivery, the delay doubles
+	// on each consecutive failure. Defaults to 1 second.
+	RetryInterval time.Duration
+	// Optional callback invoked after an item is delivered.
+	OnDelivery func(item *ActivityItem)
+	// Optional callback invoked with errors that do not end the subscription,
+	// e.g. when an item could not be delivered after all the retries.
+	OnError func(item *ActivityItem, err error)
+}
+
+// Forward subscribes to activity and delivers each item to the webhook,
+// blocking until the subscription ends. Items are acknowledged once they have
+// been delivered (or have failed all delivery attempts) if the subscriber
+// supports it (see `PollingSubscriber.Ack`).
+func (f *ActivityWebhookForwarder) Forward(ctx context.Context) error {
+	acker, _ := f.Subscriber.(interface{ Ack(id string) })
+
+	items := make(chan ActivityItem)
+	done := make(chan error, 1)
+	go func() { done <- f.Subscriber.Subscribe(ctx, items) }()
+
+	for item := range items {
+		// Items without a matching tag are acknowledged without being delivered
+		if f.accepts(&item) {
+			f.forward(ctx, &item)
+		}
+
+		if acker != nil {
+			acker.Ack(item.ID)
+		}
+	}
+
+	return <-done
+}
+
+// forward delivers a single item and reports the outcome.
+func (f *ActivityWebhookForwarder) forward(ctx context.Context, item *ActivityItem) {
+	if err := f.Deliver(ctx, item); err != nil {
+		if f.OnError != nil {
+			f.OnError(item, err)
+		}
+	} else if f.OnDelivery != nil {
+		f.OnDelivery(item)
+	}
+}
+
+// accepts checks if the item has any of the configured tags.
+func (f *ActivityWebhookForwarder) accepts(item *ActivityItem) bool {
+	c := fanOutConsumer{tags: f.Tags}
+	return c.accepts(item)
+}
+
+// Deliver posts a single item to the webhook, retrying failed attempts.
+func (f *ActivityWebhookForwarder) Deliver(ctx context.Context, item *ActivityItem) error {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	retries := f.MaxRetries
+	if retries == 0 {
+		retries = 3
+	}
+
+	delay := f.RetryInterval
+	if delay <= 0 {
+		delay = 1 * time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		retry, err := f.post(ctx, item.ID, body)
+		if err == nil || !retry || attempt >= retries {
+			return err
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		delay *= 2
+	}
+}
+
+// post makes a single delivery attempt, returning a flag indicating if a failed attempt should be retried.
+func (f *ActivityWebhookForwarder) post(ctx context.Context, id string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookDeliveryHeader, id)
+	if len(f.Secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(f.Secret, body))
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Network errors are retried unless we were canceled
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	// Limit how much of the response we hold on to
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, api.NewUnexpectedError(resp, respBody)
+	default:
+		return false, api.NewUnexpectedError(resp, respBody)
+	}
+}
+
+// SignWebhook returns the signature header value for the supplied request body.
+func SignWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks the signature header value of a request body, it can be
+// used by receivers to authenticate deliveries.
+func VerifyWebhook(secret, body []byte, signature string) error {
+	if !hmac.Equal([]byte(signature), []byte(SignWebhook(secret, body))) {
+		return fmt.Errorf("invalid webhook signature")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityWebhookForwarder_Forward(t *testing.T) {
+	cases := []struct {
+		desc       string
+		statuses   []int
+		maxRetries int
+		delivered  []string
+		failed     []string
+		attempts   int
+	}{
+		{
+			desc:      "delivered",
+			delivered: []string{"1", "2"},
+			attempts:  2,
+		},
+		{
+			desc:      "retry server error",
+			statuses:  []int{http.StatusBadGateway, http.StatusTooManyRequests},
+			delivered: []string{"1", "2"},
+			attempts:  4,
+		},
+		{
+			desc:     "client error",
+			statuses: []int{http.StatusBadRequest, http.StatusBadRequest},
+			failed:   []string{"1", "2"},
+			attempts: 2,
+		},
+		{
+			desc:       "retries exhausted",
+			statuses:   []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			maxRetries: 1,
+			failed:     []string{"1"},
+			delivered:  []string{"2"},
+			attempts:   3,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				attempts int
+				statuses = c.statuses
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.NoError(t, VerifyWebhook([]byte("secret"), body, r.Header.Get(WebhookSignatureHeader)))
+				assert.NotEmpty(t, r.Header.Get(WebhookDeliveryHeader))
+
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+				if len(statuses) > 0 {
+					w.WriteHeader(statuses[0])
+					statuses = statuses[1:]
+				}
+			}))
+			defer srv.Close()
+
+			var delivered, failed []string
+			f := &ActivityWebhookForwarder{
+				Subscriber:    staticSubscriber{{ID: "1"}, {ID: "2"}},
+				URL:           srv.URL,
+				Secret:        []byte("secret"),
+				MaxRetries:    c.maxRetries,
+				RetryInterval: time.Millisecond,
+				OnDelivery:    func(item *ActivityItem) { delivered = append(delivered, item.ID) },
+				OnError:       func(item *ActivityItem, _ error) { failed = append(failed, item.ID) },
+			}
+
+			assert.NoError(t, f.Forward(context.Background()))
+			assert.Equal(t, c.delivered, delivered)
+			assert.Equal(t, c.failed, failed)
+			assert.Equal(t, c.attempts, attempts)
+		})
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	sig := SignWebhook([]byte("secret"), body)
+	assert.NoError(t, VerifyWebhook([]byte("secret"), body, sig))
+	assert.Error(t, VerifyWebhook([]byte("other"), body, sig))
+	assert.Error(t, VerifyWebhook([]byte("secret"), []byte(`{"id":"2"}`), sig))
+}
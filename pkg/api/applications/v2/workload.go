@@ -0,0 +1,58 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// Workload is a Kubernetes workload discovered for an application.
+type Workload struct {
+	api.Metadata `json:"-"`
+	// The reference to the workload.
+	Target TargetRef `json:"target"`
+	// The containers of the workload and their current resources.
+	Containers []WorkloadContainer `json:"containers,omitempty"`
+}
+
+// WorkloadContainer is the current resource configuration of a workload container.
+type WorkloadContainer struct {
+	// The name of the container.
+	Name string `json:"name"`
+	// The current resource requests of the container.
+	Requests *ResourceList `json:"requests,omitempty"`
+	// The current resource limits of the container.
+	Limits *ResourceList `json:"limits,omitempty"`
+}
+
+type WorkloadItem struct {
+	Workload
+}
+
+func (l *WorkloadItem) UnmarshalJSON(b []byte) error {
+	type t WorkloadItem
+	return api.UnmarshalJSON(b, (*t)(l))
+}
+
+type WorkloadList struct {
+	// The workload list metadata.
+	api.Metadata `json:"-"`
+	// The total number of items in the collection.
+	TotalCount int `json:"totalCount,omitempty"`
+	// The list of workloads.
+	Workloads []WorkloadItem `json:"workloads,omitempty"`
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerOptions control when a circuit breaker trips and how it probes for recovery.
+type CircuitBreakerOptions struct {
+	// The number of consecutive failures that trips the breaker, defaults to 5.
+	FailureThreshold int
+	// The amount of time the breaker stays open before a probe request is
+	// allowed through, defaults to 10 seconds. Each failed probe doubles the
+	// amount of time the breaker stays open.
+	OpenTimeout time.Duration
+	// The maximum amount of time the breaker stays open, defaults to 5 minutes.
+	MaxOpenTimeout time.Duration
+}
+
+// NewCircuitBreaker returns a client which stops sending requests to a degraded
+// server. After consecutive failures (transport errors or server error
+// responses) the breaker opens and requests fail immediately with an
+// `ErrUnavailable` error; once the open timeout elapses a single probe request
+// is sent, if it succeeds the breaker closes otherwise it re-opens for longer.
+func NewCircuitBreaker(client Client, opts CircuitBreakerOptions) Client {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 10 * time.Second
+	}
+	if opts.MaxOpenTimeout <= 0 {
+		opts.MaxOpenTimeout = 5 * time.Minute
+	}
+	if opts.MaxOpenTimeout < opts.OpenTimeout {
+		opts.MaxOpenTimeout = opts.OpenTimeout
+	}
+
+	cb := &circuitBreaker{Client: client, opts: opts, now: time.Now}
+	if sc, ok := client.(StreamingClient); ok {
+		return &streamingCircuitBreaker{circuitBreaker: cb, streaming: sc}
+	}
+	return cb
+}
+
+// circuitBreaker is a client wrapper that fails fast while the server is unavailable.
+type circuitBreaker struct {
+	Client
+	opts CircuitBreakerOptions
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	timeout   time.Duration
+	probing   bool
+
+	// Used for testing
+	now func() time.Time
+}
+
+// Do sends the request if the breaker allows it.
+func (cb *circuitBreaker) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	if err := cb.allow(req); err != nil {
+		return nil, nil, err
+	}
+
+	resp, body, err := cb.Client.Do(ctx, req)
+	cb.record(ctx, resp, err)
+	return resp, body, err
+}
+
+// allow returns an error if the request must not be sent.
+func (cb *circuitBreaker) allow(req *http.Request) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.openUntil.IsZero() {
+		return nil
+	}
+
+	// Only a single probe is allowed once the open timeout elapses
+	now := cb.now()
+	if now.Before(cb.openUntil) || cb.probing {
+		retryAfter := cb.openUntil.Sub(now)
+		if retryAfter < 0 {
+			retryAfter = 0
+		}
+		return &Error{
+			Type:       ErrUnavailable,
+			Message:    fmt.Sprintf("server unavailable after %d consecutive failures", cb.failures),
+			RetryAfter: retryAfter,
+			Location:   req.URL.String(),
+		}
+	}
+
+	cb.probing = true
+	return nil
+}
+
+// record updates the state of the breaker using the outcome of a request.
+func (cb *circuitBreaker) record(ctx context.Context, resp *http.Response, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	probe := cb.probing
+	cb.probing = false
+
+	// Our own cancellation says nothing about the health of the server
+	if err != nil && ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return
+	}
+
+	if err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+		cb.failures = 0
+		cb.openUntil = time.Time{}
+		cb.timeout = 0
+		return
+	}
+
+	cb.failures++
+	switch {
+	case probe:
+		cb.timeout *= 2
+		if cb.timeout > cb.opts.MaxOpenTimeout {
+			cb.timeout = cb.opts.MaxOpenTimeout
+		}
+	case cb.failures >= cb.opts.FailureThreshold && cb.openUntil.IsZero():
+		cb.timeout = cb.opts.OpenTimeout
+	default:
+		return
+	}
+	cb.openUntil = cb.now().Add(cb.timeout)
+}
+
+// streamingCircuitBreaker is a circuit breaker that also supports streaming responses.
+type streamingCircuitBreaker struct {
+	*circuitBreaker
+	streaming StreamingClient
+}
+
+// Stream sends the request using the wrapped streaming client if the breaker allows it.
+func (cb *streamingCircuitBreaker) Stream(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if err := cb.allow(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := cb.streaming.Stream(ctx, req)
+	cb.record(ctx, resp, err)
+	return resp, err
+}
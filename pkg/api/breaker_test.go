@@ -0,0 +1,114 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// statusClient is a fake client which responds with a sequence of status codes,
+// a zero status code is a transport error.
+type statusClient struct {
+	statuses []int
+	requests int
+}
+
+func (c *statusClient) URL(endpoint string) *url.URL {
+	u, _ := url.Parse(endpoint)
+	return u
+}
+
+func (c *statusClient) Do(_ context.Context, req *http.Request) (*http.Response, []byte, error) {
+	status := c.statuses[c.requests]
+	c.requests++
+	if status == 0 {
+		return nil, nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: status, Request: req}, nil, nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	type step struct {
+		advance     time.Duration
+		unavailable bool
+	}
+	cases := []struct {
+		desc     string
+		statuses []int
+		steps    []step
+	}{
+		{
+			desc:     "successes",
+			statuses: []int{http.StatusOK, http.StatusNotFound, http.StatusOK},
+			steps:    []step{{}, {}, {}},
+		},
+		{
+			desc:     "intermittent failures",
+			statuses: []int{http.StatusBadGateway, 0, http.StatusOK, http.StatusBadGateway, 0, http.StatusOK},
+			steps:    []step{{}, {}, {}, {}, {}, {}},
+		},
+		{
+			desc:     "trips",
+			statuses: []int{http.StatusBadGateway, 0, http.StatusServiceUnavailable},
+			steps:    []step{{}, {}, {}, {unavailable: true}, {advance: 5 * time.Second, unavailable: true}},
+		},
+		{
+			desc:     "recovers",
+			statuses: []int{0, 0, 0, http.StatusOK, http.StatusBadGateway},
+			steps:    []step{{}, {}, {}, {unavailable: true}, {advance: 10 * time.Second}, {}},
+		},
+		{
+			desc:     "failed probe",
+			statuses: []int{0, 0, 0, http.StatusTooManyRequests, http.StatusOK},
+			steps: []step{
+				{}, {}, {}, {unavailable: true},
+				{advance: 10 * time.Second},
+				{advance: 10 * time.Second, unavailable: true},
+				{advance: 10 * time.Second},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+			sc := &statusClient{statuses: c.statuses}
+			cb := NewCircuitBreaker(sc, CircuitBreakerOptions{FailureThreshold: 3}).(*circuitBreaker)
+			cb.now = func() time.Time { return now }
+
+			for i, s := range c.steps {
+				now = now.Add(s.advance)
+				req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+				before := sc.requests
+				_, _, err := cb.Do(context.Background(), req)
+				if s.unavailable {
+					assert.True(t, IsUnavailable(err), "step %d", i)
+					assert.Equal(t, before, sc.requests, "step %d", i)
+				} else {
+					assert.False(t, IsUnavailable(err), "step %d", i)
+					assert.Equal(t, before+1, sc.requests, "step %d", i)
+				}
+			}
+		})
+	}
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package api
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -32,25 +35,107 @@ type Client interface {
 	Do(context.Context, *http.Request) (*http.Response, []byte, error)
 }
 
+// ClientOption configures the client returned by `NewClient`.
+type ClientOption func(*httpClient) error
+
+// WithTimeout sets the default amount of time allowed for a request, including
+// reading the response body. Streamed responses only apply the timeout while
+// waiting on the server. A value of zero means requests do not time out.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *httpClient) error {
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithLongPollTimeout sets the default amount of time allowed for long polling
+// requests (e.g. obtaining the next trial). A value of zero means long polling
+// requests do not time out.
+func WithLongPollTimeout(timeout time.Duration) ClientOption {
+	return func(c *httpClient) error {
+		c.longPollTimeout = timeout
+		return nil
+	}
+}
+
+// WithCABundle trusts the certificate authorities in the supplied bundle when
+// verifying the API server, in addition to the system roots. The bundle may be
+// either PEM encoded certificates or the path to a file containing them.
+func WithCABundle(bundle string) ClientOption {
+	return func(c *httpClient) (err error) {
+		c.client.Transport, err = TLSTransport(c.client.Transport, TLSOptions{CABundle: bundle})
+		return
+	}
+}
+
+// WithClientCertificate presents the supplied certificate when connecting to
+// an API server that requires mutual TLS. The certificate and key may be
+// either PEM encoded or the paths to files containing them.
+func WithClientCertificate(cert, key string) ClientOption {
+	return func(c *httpClient) (err error) {
+		c.client.Transport, err = TLSTransport(c.client.Transport, TLSOptions{ClientCertificate: cert, ClientKey: key})
+		return
+	}
+}
+
+// WithMaxResponseSize limits the number of bytes read from a response body,
+// larger responses fail with an `ErrTooLarge` error. A value of zero means the
+// response size is not limited.
+func WithMaxResponseSize(size int64) ClientOption {
+	return func(c *httpClient) error {
+		c.maxResponseSize = size
+		return nil
+	}
+}
+
+// WithRequestCompression compresses request bodies of at least the supplied
+// number of bytes (e.g. large template updates or bulk trial reports) using
+// gzip. The server must accept a "Content-Encoding: gzip" request body. Note
+// that compressed responses are always negotiated and decoded by the transport.
+func WithRequestCompression(minSize int) ClientOption {
+	return func(c *httpClient) error {
+		c.compressMinSize = minSize
+		return nil
+	}
+}
+
+const (
+	// DefaultTimeout is the default amount of time allowed for a request.
+	DefaultTimeout = 10 * time.Second
+	// DefaultLongPollTimeout is the default amount of time allowed for a long polling request.
+	DefaultLongPollTimeout = 2 * time.Minute
+)
+
 // NewClient returns a new client for accessing API server.
-func NewClient(address string, transport http.RoundTripper) (Client, error) {
+func NewClient(address string, transport http.RoundTripper, opts ...ClientOption) (Client, error) {
 	u, err := url.Parse(address)
 	if err != nil {
 		return nil, err
 	}
 
-	return &httpClient{
+	c := &httpClient{
 		client: http.Client{
 			Transport: transport,
-			Timeout:   10 * time.Second, // TODO This should be configurable, e.g. for debugging
 		},
-		base: *u,
-	}, nil
+		base:            *u,
+		timeout:         DefaultTimeout,
+		longPollTimeout: DefaultLongPollTimeout,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 type httpClient struct {
-	client http.Client
-	base   url.URL
+	client          http.Client
+	base            url.URL
+	timeout         time.Duration
+	longPollTimeout time.Duration
+	maxResponseSize int64
+	compressMinSize int
 }
 
 // URL resolves an endpoint to a fully qualified URL.
@@ -66,9 +151,21 @@ func (c *httpClient) URL(ep string) *url.URL {
 
 // Do executes an HTTP request using this client and the supplied context.
 func (c *httpClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
-	if ctx != nil {
-		req = req.WithContext(ctx)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if timeout := c.requestTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
+
+	req, err := c.newRequest(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, nil, err
@@ -78,7 +175,7 @@ func (c *httpClient) Do(ctx context.Context, req *http.Request) (*http.Response,
 	var body []byte
 	done := make(chan struct{})
 	go func() {
-		body, err = io.ReadAll(resp.Body)
+		body, err = io.ReadAll(c.limitBody(resp))
 		close(done)
 	}()
 
@@ -94,3 +191,146 @@ func (c *httpClient) Do(ctx context.Context, req *http.Request) (*http.Response,
 
 	return resp, body, err
 }
+
+// Stream executes an HTTP request using this client and the supplied context,
+// the response body is returned unread so it can be decoded incrementally. The
+// caller is responsible for closing the response body. The request timeout
+// applies to receiving the response headers and to each individual read of
+// the body, time the caller spends between reads does not count against it.
+func (c *httpClient) Stream(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timeout := c.requestTimeout(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+
+	req, err := c.newRequest(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	var timer *time.Timer
+	if timeout > 0 {
+		timer = time.AfterFunc(timeout, cancel)
+	}
+	resp, err := c.client.Do(req)
+	if timer != nil && !timer.Stop() && err != nil {
+		err = context.DeadlineExceeded
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &streamBody{Reader: c.limitBody(resp), body: resp.Body, cancel: cancel, timeout: timeout}
+	return resp, nil
+}
+
+// newRequest returns a copy of the request to send using the supplied context,
+// the context headers are added and the request body is compressed if it is
+// large enough.
+func (c *httpClient) newRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	if h, ok := ctx.Value(headerKey{}).(http.Header); ok {
+		req = req.Clone(ctx)
+		for k, v := range h {
+			// Headers explicitly set on the request take precedence
+			if _, ok := req.Header[k]; !ok {
+				req.Header[k] = v
+			}
+		}
+	}
+
+	if c.compressMinSize <= 0 || req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return req.WithContext(ctx), nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(ctx)
+	if len(data) >= c.compressMinSize {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	req.ContentLength = int64(len(data))
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+	return req, nil
+}
+
+// limitBody returns a reader for the response body which fails once the maximum response size is exceeded.
+func (c *httpClient) limitBody(resp *http.Response) io.Reader {
+	if c.maxResponseSize <= 0 {
+		return resp.Body
+	}
+
+	var location string
+	if resp.Request != nil && resp.Request.URL != nil {
+		location = resp.Request.URL.String()
+	}
+	return &limitedReader{
+		r:         resp.Body,
+		remaining: c.maxResponseSize,
+		err: &Error{
+			Type:     ErrTooLarge,
+			Message:  fmt.Sprintf("response exceeds the maximum size of %d bytes", c.maxResponseSize),
+			Location: location,
+		},
+	}
+}
+
+// requestTimeout returns the timeout for a request made using the supplied context.
+func (c *httpClient) requestTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	if longPoll, _ := ctx.Value(longPollKey{}).(bool); longPoll {
+		return c.longPollTimeout
+	}
+	return c.timeout
+}
+
+type timeoutKey struct{}
+type longPollKey struct{}
+type headerKey struct{}
+
+// ContextWithTimeout returns a context which overrides the client's timeout for
+// the requests made using it. A value of zero means the requests do not time out;
+// deadlines on the context itself are always honored.
+func ContextWithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// ContextWithLongPoll returns a context indicating the requests made using it
+// are expected to wait on the server, the client's long polling timeout is used
+// instead of the default timeout.
+func ContextWithLongPoll(ctx context.Context) context.Context {
+	return context.WithValue(ctx, longPollKey{}, true)
+}
+
+// WithHeader returns a context which adds the supplied header to the requests
+// made using it, for example to select an organization or to correlate requests.
+// The innermost value for a header wins; headers explicitly set on a request are
+// not overwritten.
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	h := http.Header{}
+	if parent, ok := ctx.Value(headerKey{}).(http.Header); ok {
+		h = parent.Clone()
+	}
+	h.Set(key, value)
+	return context.WithValue(ctx, headerKey{}, h)
+}
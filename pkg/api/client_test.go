@@ -17,7 +17,14 @@ limitations under the License.
 package api
 
 import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -68,3 +75,187 @@ func TestHttpClient_URL(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpClient_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		desc    string
+		opts    []ClientOption
+		ctx     func(context.Context) context.Context
+		timeout bool
+	}{
+		{
+			desc:    "default timeout",
+			opts:    []ClientOption{WithTimeout(10 * time.Millisecond)},
+			timeout: true,
+		},
+		{
+			desc: "no timeout",
+			opts: []ClientOption{WithTimeout(0)},
+		},
+		{
+			desc: "long poll",
+			opts: []ClientOption{WithTimeout(10 * time.Millisecond), WithLongPollTimeout(time.Second)},
+			ctx:  ContextWithLongPoll,
+		},
+		{
+			desc:    "long poll timeout",
+			opts:    []ClientOption{WithTimeout(time.Second), WithLongPollTimeout(10 * time.Millisecond)},
+			ctx:     ContextWithLongPoll,
+			timeout: true,
+		},
+		{
+			desc: "context override",
+			opts: []ClientOption{WithTimeout(10 * time.Millisecond)},
+			ctx: func(ctx context.Context) context.Context {
+				return ContextWithTimeout(ctx, time.Second)
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			client, err := NewClient(srv.URL, nil, c.opts...)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			ctx := context.Background()
+			if c.ctx != nil {
+				ctx = c.ctx(ctx)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			_, _, err = client.Do(ctx, req)
+			if c.timeout {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHttpClient_RequestCompression(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+
+		data, err := io.ReadAll(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Request-Encoding", r.Header.Get("Content-Encoding"))
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		desc       string
+		minSize    int
+		body       string
+		compressed bool
+	}{
+		{
+			desc: "disabled",
+			body: strings.Repeat("x", 1024),
+		},
+		{
+			desc:    "small body",
+			minSize: 1024,
+			body:    "x",
+		},
+		{
+			desc:       "large body",
+			minSize:    1024,
+			body:       strings.Repeat("x", 1024),
+			compressed: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			client, err := NewClient(srv.URL, nil, WithRequestCompression(c.minSize))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(c.body))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			resp, body, err := client.Do(context.Background(), req)
+			if assert.NoError(t, err) && assert.Equal(t, http.StatusOK, resp.StatusCode) {
+				assert.Equal(t, c.body, string(body))
+				assert.Equal(t, c.compressed, resp.Header.Get("Request-Encoding") == "gzip")
+			}
+		})
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Echo"] = r.Header.Values("Test")
+		w.Header()["Org"] = r.Header.Values(HeaderOrganization)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	parent := WithHeader(context.Background(), HeaderOrganization, "org1")
+	ctx := WithHeader(parent, "Test", "a")
+	ctx = WithHeader(ctx, "Test", "b")
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	resp, _, err := client.Do(ctx, req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"b"}, resp.Header.Values("Echo"))
+		assert.Equal(t, []string{"org1"}, resp.Header.Values("Org"))
+		assert.Empty(t, req.Header, "original request was modified")
+	}
+
+	// Nested values replace the outer value
+	resp, _, err = client.Do(WithHeader(ctx, HeaderOrganization, "org3"), req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"org3"}, resp.Header.Values("Org"))
+	}
+
+	// The parent context is unaffected by the child headers
+	resp, _, err = client.Do(parent, req)
+	if assert.NoError(t, err) {
+		assert.Empty(t, resp.Header.Values("Echo"))
+	}
+
+	// Explicit request headers win
+	req.Header.Set(HeaderOrganization, "org2")
+	resp, _, err = client.Do(ctx, req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"org2"}, resp.Header.Values("Org"))
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConflictPolicy describes how to handle a name that is already in use when
+// creating a named resource.
+type ConflictPolicy string
+
+const (
+	// ConflictFail returns an error if the name is already in use.
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictSkip leaves the existing resource unchanged.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing resource.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictRename creates the resource using the next available numbered name.
+	ConflictRename ConflictPolicy = "rename"
+	// ConflictSuffix creates the resource using a name with a unique (ULID) suffix.
+	ConflictSuffix ConflictPolicy = "suffix"
+	// ConflictAdopt uses the existing resource in place of the new one.
+	ConflictAdopt ConflictPolicy = "adopt"
+)
+
+// ParseConflictPolicy returns the conflict policy for the supplied string, an
+// empty string is the same as ConflictFail.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(strings.ToLower(s)); p {
+	case "":
+		return ConflictFail, nil
+	case ConflictFail, ConflictSkip, ConflictOverwrite, ConflictRename, ConflictSuffix, ConflictAdopt:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q", s)
+	}
+}
+
+// NextName returns the name to try after the supplied name collides with an
+// existing resource when using the ConflictRename policy.
+func NextName(base string, attempt int) string {
+	return fmt.Sprintf("%s-%d", base, attempt+1)
+}
+
+// UniqueName returns the supplied name with a unique (lower case ULID) suffix
+// for use with the ConflictSuffix policy.
+func UniqueName(base string) (string, error) {
+	id, err := NewULID(time.Now())
+	if err != nil {
+		return "", err
+	}
+	return base + "-" + strings.ToLower(id), nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// Do sends a request to an arbitrary endpoint, allowing access to new or
+// experimental endpoints that are not yet covered by the typed APIs. The
+// request goes through the supplied client, so authorization, retries and any
+// other client behavior still apply.
+//
+// The URL may be relative to the client's address. The body may be nil, a
+// byte slice, an `io.Reader` or any value to encode as JSON. A successful
+// response body is decoded into `out` (if it is not nil); when `out` is a
+// struct with an `api.Metadata` field, the field is populated from the response
+// headers. The response metadata (e.g. links) is also returned directly.
+// Unsuccessful responses are returned as an `*api.Error`.
+func Do(ctx context.Context, client Client, method, u string, body, out interface{}) (Metadata, error) {
+	var r io.Reader
+	var contentType string
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		r = bytes.NewReader(b)
+	case io.Reader:
+		r = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		r, contentType = bytes.NewReader(data), "application/json"
+	}
+
+	req, err := http.NewRequest(method, client.URL(u).String(), r)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, data, err := client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, NewUnexpectedError(resp, data)
+	}
+
+	md := Metadata{}
+	UnmarshalMetadata(resp, &md)
+
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return md, nil
+	}
+
+	if rv := reflect.ValueOf(out); rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct {
+		if err := UnmarshalJSON(data, out); err != nil {
+			return md, err
+		}
+		if f := findMetadataField(rv); f.IsValid() && len(md) > 0 {
+			f.Set(reflect.ValueOf(md))
+		}
+		return md, nil
+	}
+
+	return md, json.Unmarshal(data, out)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/things/":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Link", `</v1/things/?offset=1>;rel="next"`)
+			if r.Method == http.MethodPost {
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				_, _ = w.Write(body)
+				return
+			}
+			_, _ = w.Write([]byte(`{"name":"a"}`))
+		case "/v1/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, nil)
+	require.NoError(t, err)
+
+	type thing struct {
+		Metadata `json:"-"`
+		Name     string `json:"name"`
+	}
+
+	cases := []struct {
+		desc     string
+		method   string
+		url      string
+		body     interface{}
+		expected *thing
+		next     string
+		notFound bool
+	}{
+		{
+			desc:     "get",
+			method:   http.MethodGet,
+			url:      "v1/things/",
+			expected: &thing{Name: "a"},
+			next:     srv.URL + "/v1/things/?offset=1",
+		},
+		{
+			desc:     "post json",
+			method:   http.MethodPost,
+			url:      srv.URL + "/v1/things/",
+			body:     map[string]string{"name": "b"},
+			expected: &thing{Name: "b"},
+			next:     srv.URL + "/v1/things/?offset=1",
+		},
+		{
+			desc:   "no content",
+			method: http.MethodDelete,
+			url:    "v1/empty",
+		},
+		{
+			desc:     "not found",
+			method:   http.MethodGet,
+			url:      "v1/missing",
+			notFound: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			out := &thing{}
+			md, err := Do(context.Background(), client, c.method, c.url, c.body, out)
+			if c.notFound {
+				assert.True(t, IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.next, md.Next())
+			if c.expected != nil {
+				assert.Equal(t, c.expected.Name, out.Name)
+				assert.Equal(t, c.next, out.Next())
+			}
+		})
+	}
+}
@@ -34,6 +34,10 @@ type ErrorType string
 const (
 	ErrUnauthorized ErrorType = "unauthorized"
 	ErrUnexpected   ErrorType = "unexpected"
+	ErrReadOnly     ErrorType = "read-only"
+	ErrConflict     ErrorType = "conflict"
+	ErrTooLarge     ErrorType = "too-large"
+	ErrUnavailable  ErrorType = "unavailable"
 )
 
 // Error represents the API specific error messages and may be used in response to HTTP status codes
@@ -42,6 +46,8 @@ type Error struct {
 	Message    string        `json:"error"`
 	RetryAfter time.Duration `json:"-"`
 	Location   string        `json:"-"`
+	StatusCode int           `json:"-"`
+	RequestID  string        `json:"-"`
 }
 
 // Error returns the message associated with this API error.
@@ -56,13 +62,15 @@ func NewUnexpectedError(resp *http.Response, body []byte) *Error {
 	switch resp.StatusCode {
 	case http.StatusUnauthorized, http.StatusPaymentRequired:
 		t = ErrUnauthorized
+	case http.StatusPreconditionFailed:
+		t = ErrConflict
 	}
 	return NewError(t, resp, body)
 }
 
 // NewError returns a new error with an API specific error condition, it also captures the details of the response
 func NewError(t ErrorType, resp *http.Response, body []byte) *Error {
-	err := &Error{Type: t}
+	err := &Error{Type: t, StatusCode: resp.StatusCode, RequestID: Metadata(resp.Header).RequestID()}
 
 	// Unmarshal the response body into the error to get the server supplied error message
 	// TODO We should be comparing compatible media types here (e.g. charset)
@@ -91,6 +99,8 @@ func NewError(t ErrorType, resp *http.Response, body []byte) *Error {
 			err.Message = "unauthorized"
 		case http.StatusPaymentRequired:
 			err.Message = "account is not activated"
+		case http.StatusPreconditionFailed:
+			err.Message = fmt.Sprintf("resource was modified by another request: %s", err.Location)
 		default:
 			switch err.Type {
 			case ErrUnexpected:
@@ -125,3 +135,30 @@ func IsUnauthorized(err error) bool {
 
 	return false
 }
+
+// IsReadOnly checks to see if the error was caused by a mutating request
+// attempted using a read-only client.
+func IsReadOnly(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Type == ErrReadOnly
+}
+
+// IsConflict checks to see if the error was caused by a conditional request
+// failing because the resource was modified by another request.
+func IsConflict(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Type == ErrConflict
+}
+
+// IsNotFound checks to see if the error was caused by a missing resource.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || strings.HasSuffix(string(apiErr.Type), "-not-found"))
+}
+
+// IsUnavailable checks to see if the error was caused by a circuit breaker
+// refusing to send requests to a degraded server.
+func IsUnavailable(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Type == ErrUnavailable
+}
@@ -69,10 +69,90 @@ func TestNewError(t *testing.T) {
 				Message: "not found: https://invalid.example.com/testing",
 			},
 		},
+		{
+			desc:      "request ID",
+			errorType: ErrorType("test-error"),
+			response: http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Header: http.Header{
+					"X-Request-Id": []string{"abc123"},
+				},
+			},
+			expected: Error{
+				Type:      "test-error",
+				Message:   "test error",
+				RequestID: "abc123",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := NewError(c.errorType, &c.response, c.body)
+			assert.Error(t, &c.expected, err)
+			assert.Equal(t, c.expected.RequestID, err.RequestID)
+		})
+	}
+}
+
+func TestNewUnexpectedError(t *testing.T) {
+	cases := []struct {
+		desc       string
+		statusCode int
+		expected   ErrorType
+	}{
+		{
+			desc:       "unexpected",
+			statusCode: http.StatusInternalServerError,
+			expected:   ErrUnexpected,
+		},
+		{
+			desc:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			expected:   ErrUnauthorized,
+		},
+		{
+			desc:       "precondition failed",
+			statusCode: http.StatusPreconditionFailed,
+			expected:   ErrConflict,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := NewUnexpectedError(&http.Response{StatusCode: c.statusCode}, nil)
+			assert.Equal(t, c.expected, err.Type)
+			assert.Equal(t, c.statusCode, err.StatusCode)
+			assert.Equal(t, c.expected == ErrConflict, IsConflict(fmt.Errorf("test: %w", err)))
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	cases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:     "status code",
+			err:      NewUnexpectedError(&http.Response{StatusCode: http.StatusNotFound}, nil),
+			expected: true,
+		},
+		{
+			desc:     "error type",
+			err:      fmt.Errorf("wrapped: %w", &Error{Type: "experiment-not-found"}),
+			expected: true,
+		},
+		{
+			desc: "other error",
+			err:  &Error{Type: ErrUnexpected, StatusCode: http.StatusInternalServerError},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
-			assert.Error(t, &c.expected, NewError(c.errorType, &c.response, c.body))
+			assert.Equal(t, c.expected, IsNotFound(c.err))
 		})
 	}
 }
@@ -50,9 +50,12 @@ type API interface {
 	CreateExperimentByName(context.Context, ExperimentName, Experiment) (Experiment, error)
 	CreateExperiment(context.Context, string, Experiment) (Experiment, error)
 	DeleteExperiment(context.Context, string) error
+	PatchExperiment(context.Context, string, ExperimentPatch) error
 	LabelExperiment(context.Context, string, ExperimentLabels) error
 
 	GetAllTrials(context.Context, string, TrialListQuery) (TrialList, error)
+	StreamAllTrials(context.Context, string, TrialListQuery, func(*TrialItem) error) (api.Metadata, error)
+	GetTrial(context.Context, string) (TrialItem, error)
 	CreateTrial(context.Context, string, TrialAssignments) (TrialAssignments, error)
 	NextTrial(context.Context, string) (TrialAssignments, error)
 	ReportTrial(context.Context, string, TrialValues) error
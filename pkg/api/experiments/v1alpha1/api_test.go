@@ -18,7 +18,6 @@ package v1alpha1_test
 
 import (
 	"context"
-	"errors"
 	"flag"
 	"log"
 	"os"
@@ -115,18 +114,12 @@ func runTest(t *testing.T, td *apitest.ExperimentTestDefinition, expAPI experime
 			t.Skip("skipping trial loop.")
 		}
 
-		for {
-			ta, err := expAPI.NextTrial(ctx, exp.Link(api.RelationNextTrial))
-			var aerr *api.Error
-			if errors.As(err, &aerr) && aerr.Type == experiments.ErrExperimentStopped {
-				break
-			}
-			require.NoError(t, err, "failed to fetch trial assignments")
+		l := &experiments.TrialLoop{API: expAPI}
+		err := l.Run(ctx, exp.Link(api.RelationNextTrial), func(_ context.Context, ta *experiments.TrialAssignments) (experiments.TrialValues, error) {
 			assert.NotEmpty(t, ta.Location(), "missing location")
-
-			err = expAPI.ReportTrial(ctx, ta.Location(), td.TrialResults(&ta))
-			require.NoError(t, err, "failed to report trial")
-		}
+			return td.TrialResults(ta), nil
+		})
+		require.NoError(t, err, "failed to run trial loop")
 	})
 
 	t.Run("Delete Experiment", func(t *testing.T) {
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// LabelArchived is the experiment label marking an experiment as archived.
+// Archived experiments (and their trials) are retained, listings can exclude
+// them using `Lister.ExcludeArchived`.
+const LabelArchived = "archived"
+
+// IsArchived checks if the experiment has been archived.
+func (e *Experiment) IsArchived() bool {
+	archived, _ := strconv.ParseBool(e.Labels[LabelArchived])
+	return archived
+}
+
+// ArchiveExperiment archives (or restores) the experiment by changing its
+// archived label. Restoring an experiment removes the label.
+func ArchiveExperiment(ctx context.Context, expAPI API, exp *Experiment, archived bool) error {
+	labelsURL := exp.Link(api.RelationLabels)
+	if labelsURL == "" {
+		return fmt.Errorf("malformed response, missing labels link")
+	}
+
+	// An empty label value removes the label
+	value := ""
+	if archived {
+		value = "true"
+	}
+
+	if err := expAPI.LabelExperiment(ctx, labelsURL, ExperimentLabels{Labels: map[string]string{LabelArchived: value}}); err != nil {
+		return err
+	}
+
+	if exp.Labels == nil {
+		exp.Labels = make(map[string]string)
+	}
+	if archived {
+		exp.Labels[LabelArchived] = value
+	} else {
+		delete(exp.Labels, LabelArchived)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// archiveAPI records label changes and lists a fixed set of experiments.
+type archiveAPI struct {
+	API
+	experiments []ExperimentItem
+	labels      []map[string]string
+}
+
+func (a *archiveAPI) GetAllExperiments(context.Context, ExperimentListQuery) (ExperimentList, error) {
+	return ExperimentList{Experiments: a.experiments}, nil
+}
+
+func (a *archiveAPI) LabelExperiment(_ context.Context, _ string, lbl ExperimentLabels) error {
+	a.labels = append(a.labels, lbl.Labels)
+	return nil
+}
+
+func TestArchiveExperiment(t *testing.T) {
+	a := &archiveAPI{}
+	exp := &Experiment{Metadata: api.Metadata{"Link": {`<labels>; rel="https://stormforge.io/rel/labels"`}}}
+
+	assert.NoError(t, ArchiveExperiment(context.Background(), a, exp, true))
+	assert.True(t, exp.IsArchived())
+
+	assert.NoError(t, ArchiveExperiment(context.Background(), a, exp, false))
+	assert.False(t, exp.IsArchived())
+
+	assert.Equal(t, []map[string]string{{LabelArchived: "true"}, {LabelArchived: ""}}, a.labels)
+
+	assert.Error(t, ArchiveExperiment(context.Background(), a, &Experiment{}, true))
+}
+
+func TestLister_ExcludeArchived(t *testing.T) {
+	a := &archiveAPI{experiments: []ExperimentItem{
+		{Experiment: Experiment{Name: "a"}},
+		{Experiment: Experiment{Name: "b", Labels: map[string]string{LabelArchived: "true"}}},
+		{Experiment: Experiment{Name: "c", Labels: map[string]string{LabelArchived: "false"}}},
+	}}
+
+	names := func(l *Lister) []string {
+		var result []string
+		assert.NoError(t, l.ForEachExperiment(context.Background(), ExperimentListQuery{}, func(item *ExperimentItem) error {
+			result = append(result, item.Name.String())
+			return nil
+		}))
+		return result
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, names(&Lister{API: a}))
+	assert.Equal(t, []string{"a", "c"}, names(&Lister{API: a, ExcludeArchived: true}))
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// ConstraintBuilder is used to construct experiment constraints, for example:
+//
+//	c, err := NewSumConstraint(4000).
+//		Named("total-cpu").
+//		UpperBound().
+//		Parameter("cpu_a", 1).
+//		Parameter("cpu_b", 1).
+//		Build(exp.Parameters)
+type ConstraintBuilder struct {
+	c Constraint
+}
+
+// NewOrderConstraint starts building a constraint which requires the value of
+// the lower parameter to not exceed the value of the upper parameter.
+func NewOrderConstraint(lower, upper string) *ConstraintBuilder {
+	return &ConstraintBuilder{c: Constraint{
+		ConstraintType: ConstraintOrder,
+		OrderConstraint: &OrderConstraint{
+			LowerParameter: lower,
+			UpperParameter: upper,
+		},
+	}}
+}
+
+// NewSumConstraint starts building a constraint on the weighted sum of
+// parameter values. By default, the bound is a lower bound.
+func NewSumConstraint(bound float64) *ConstraintBuilder {
+	return &ConstraintBuilder{c: Constraint{
+		ConstraintType: ConstraintSum,
+		SumConstraint: &SumConstraint{
+			Bound: bound,
+		},
+	}}
+}
+
+// Named sets the optional name of the constraint.
+func (b *ConstraintBuilder) Named(name string) *ConstraintBuilder {
+	b.c.Name = name
+	return b
+}
+
+// UpperBound makes the bound of a sum constraint an upper bound.
+func (b *ConstraintBuilder) UpperBound() *ConstraintBuilder {
+	if b.c.SumConstraint != nil {
+		b.c.IsUpperBound = true
+	}
+	return b
+}
+
+// LowerBound makes the bound of a sum constraint a lower bound.
+func (b *ConstraintBuilder) LowerBound() *ConstraintBuilder {
+	if b.c.SumConstraint != nil {
+		b.c.IsUpperBound = false
+	}
+	return b
+}
+
+// Parameter adds a weighted parameter to a sum constraint.
+func (b *ConstraintBuilder) Parameter(name string, weight float64) *ConstraintBuilder {
+	if b.c.SumConstraint != nil {
+		b.c.SumConstraint.Parameters = append(b.c.SumConstraint.Parameters, SumConstraintParameter{
+			ParameterName: name,
+			Weight:        weight,
+		})
+	}
+	return b
+}
+
+// Constraint returns the constraint without validating it.
+func (b *ConstraintBuilder) Constraint() Constraint {
+	c := b.c
+	if b.c.SumConstraint != nil {
+		sc := *b.c.SumConstraint
+		sc.Parameters = append([]SumConstraintParameter(nil), sc.Parameters...)
+		c.SumConstraint = &sc
+	}
+	if b.c.OrderConstraint != nil {
+		oc := *b.c.OrderConstraint
+		c.OrderConstraint = &oc
+	}
+	return c
+}
+
+// Build returns the constraint after validating it against the supplied
+// experiment parameters. The result is an `api.FieldErrorList` describing each
+// problem with the constraint, for example a reference to an unknown parameter.
+func (b *ConstraintBuilder) Build(params []Parameter) (Constraint, error) {
+	c := b.Constraint()
+
+	index := make(map[string]*Parameter, len(params))
+	for i := range params {
+		index[params[i].Name] = &params[i]
+	}
+
+	var errs api.FieldErrorList
+	validateConstraint(&errs, "constraint", &c, index)
+	if err := errs.Err(); err != nil {
+		return Constraint{}, err
+	}
+	return c, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+func TestConstraintBuilder_Build(t *testing.T) {
+	params := []Parameter{
+		{Name: "min", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+		{Name: "max", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+		{Name: "tier", Type: ParameterTypeCategorical, Values: []string{"x", "y"}},
+	}
+
+	cases := []struct {
+		desc     string
+		builder  *ConstraintBuilder
+		expected Constraint
+		fields   []string
+	}{
+		{
+			desc:    "order",
+			builder: NewOrderConstraint("min", "max").Named("min-max"),
+			expected: Constraint{
+				Name:            "min-max",
+				ConstraintType:  ConstraintOrder,
+				OrderConstraint: &OrderConstraint{LowerParameter: "min", UpperParameter: "max"},
+			},
+		},
+		{
+			desc:    "order same parameter",
+			builder: NewOrderConstraint("min", "min"),
+			fields:  []string{"constraint"},
+		},
+		{
+			desc:    "order unknown parameter",
+			builder: NewOrderConstraint("min", "other"),
+			fields:  []string{"constraint.upperParameter"},
+		},
+		{
+			desc:    "sum",
+			builder: NewSumConstraint(12).UpperBound().Parameter("min", 1).Parameter("max", 2),
+			expected: Constraint{
+				ConstraintType: ConstraintSum,
+				SumConstraint: &SumConstraint{
+					IsUpperBound: true,
+					Bound:        12,
+					Parameters: []SumConstraintParameter{
+						{ParameterName: "min", Weight: 1},
+						{ParameterName: "max", Weight: 2},
+					},
+				},
+			},
+		},
+		{
+			desc:    "sum without parameters",
+			builder: NewSumConstraint(12),
+			fields:  []string{"constraint.parameters"},
+		},
+		{
+			desc:    "sum categorical parameter",
+			builder: NewSumConstraint(12).Parameter("min", 1).Parameter("tier", 1),
+			fields:  []string{"constraint.parameters[1].parameterName"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := c.builder.Build(params)
+			if len(c.fields) > 0 {
+				var errs api.FieldErrorList
+				if assert.ErrorAs(t, err, &errs) {
+					var fields []string
+					for _, e := range errs {
+						fields = append(fields, e.Field)
+					}
+					assert.Equal(t, c.fields, fields)
+				}
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConstraintBuilder_Constraint(t *testing.T) {
+	b := NewSumConstraint(1).Parameter("a", 1)
+	c := b.Constraint()
+	b.Parameter("b", 1).UpperBound()
+
+	assert.Len(t, c.SumConstraint.Parameters, 1)
+	assert.False(t, c.IsUpperBound)
+}
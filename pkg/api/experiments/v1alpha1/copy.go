@@ -0,0 +1,65 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// CopyOptions controls how an experiment is copied between API instances.
+type CopyOptions struct {
+	// The name of the copy, defaults to the name of the source experiment.
+	Name ExperimentName
+	// The behavior when the name is already in use by the destination.
+	OnConflict api.ConflictPolicy
+}
+
+// CopyExperiment copies the definition of the named experiment from the source
+// API to the destination API (which are typically configured with different
+// servers or credentials). Trials are not copied. The name of the copy is
+// returned, the name is empty if the copy was skipped.
+func CopyExperiment(ctx context.Context, src, dst API, name ExperimentName, opts CopyOptions) (ExperimentName, error) {
+	exp, err := src.GetExperimentByName(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	// Server assigned values do not get copied
+	exp.Metadata = nil
+	exp.Name = ""
+	exp.Observations = 0
+
+	target := opts.Name
+	if target == "" {
+		target = name
+	}
+
+	target, exists, err := ReserveExperimentName(ctx, dst, target, opts.OnConflict)
+	if err != nil || target == "" {
+		return "", err
+	}
+	if exists && opts.OnConflict == api.ConflictAdopt {
+		return target, nil
+	}
+
+	if _, err := dst.CreateExperimentByName(ctx, target, exp); err != nil {
+		return "", err
+	}
+	return target, nil
+}
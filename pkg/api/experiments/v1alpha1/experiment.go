@@ -36,6 +36,8 @@ type Metric struct {
 	Minimize bool `json:"minimize,omitempty"`
 	// The flag indicating this metric is optimized (nil defaults to true).
 	Optimize *bool `json:"optimize,omitempty"`
+	// The unit reported values are normalized to.
+	Unit api.Unit `json:"unit,omitempty"`
 }
 
 type ConstraintType string
@@ -126,6 +128,8 @@ type Experiment struct {
 	Parameters []Parameter `json:"parameters"`
 	// Labels for this experiment.
 	Labels map[string]string `json:"labels,omitempty"`
+	// Fields not known to this client, preserved when the experiment is sent back.
+	Extensions api.Extensions `json:"-"`
 }
 
 func (e *Experiment) UnmarshalJSON(data []byte) error {
@@ -134,11 +138,29 @@ func (e *Experiment) UnmarshalJSON(data []byte) error {
 	}
 
 	type t Experiment
-	return json.Unmarshal(data, (*t)(e))
+	ext, err := api.UnmarshalJSONWithExtensions(data, (*t)(e))
+	e.Extensions = ext
+	return err
+}
+
+func (e Experiment) MarshalJSON() ([]byte, error) {
+	type t Experiment
+	return api.MarshalJSONWithExtensions(t(e), e.Extensions)
 }
 
 type ExperimentListQuery struct{ api.IndexQuery }
 
+// ExperimentPatch is a partial update of the mutable experiment fields, only
+// the fields which are set are changed. Labels are changed using `LabelExperiment`.
+type ExperimentPatch struct {
+	// The experiment metadata, the entity tag is used as a precondition if present.
+	api.Metadata `json:"-"`
+	// The display name of the experiment.
+	DisplayName string `json:"displayName,omitempty"`
+	// The target number of observations for this experiment.
+	Budget int64 `json:"budget,omitempty"`
+}
+
 type ExperimentItem struct {
 	Experiment
 }
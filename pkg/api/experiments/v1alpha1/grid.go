@@ -0,0 +1,108 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+)
+
+// TrialGrid is the list of values for each parameter of a parameter sweep,
+// keyed by parameter name.
+type TrialGrid map[string][]string
+
+// Combinations returns every combination of the grid values (the cartesian
+// product) in a deterministic order: parameters are enumerated in the order
+// they appear in the experiment with the last parameter varying fastest.
+func (g TrialGrid) Combinations(e *Experiment) ([]map[string]string, error) {
+	known := make(map[string]bool, len(e.Parameters))
+	for _, p := range e.Parameters {
+		known[p.Name] = true
+	}
+	for name, values := range g {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("missing values for parameter %q", name)
+		}
+	}
+
+	result := []map[string]string{{}}
+	for _, p := range e.Parameters {
+		values, ok := g[p.Name]
+		if !ok {
+			continue
+		}
+
+		next := make([]map[string]string, 0, len(result)*len(values))
+		for _, c := range result {
+			for _, v := range values {
+				m := make(map[string]string, len(c)+1)
+				for k := range c {
+					m[k] = c[k]
+				}
+				m[p.Name] = v
+				next = append(next, m)
+			}
+		}
+		result = next
+	}
+	return result, nil
+}
+
+// Assignments returns the trial assignments for every combination of the grid
+// values. Parameters which are not part of the grid are assigned using the
+// default behavior, see `NewTrialAssignments`.
+func (g TrialGrid) Assignments(e *Experiment, defaultBehavior string) ([]TrialAssignments, error) {
+	combinations, err := g.Combinations(e)
+	if err != nil {
+		return nil, err
+	}
+	return NewTrialAssignmentsList(e, combinations, defaultBehavior)
+}
+
+// NewTrialAssignmentsList constructs the trial assignments for a list of explicit assignment sets.
+func NewTrialAssignmentsList(e *Experiment, sets []map[string]string, defaultBehavior string) ([]TrialAssignments, error) {
+	result := make([]TrialAssignments, 0, len(sets))
+	for i, assignments := range sets {
+		ta, err := NewTrialAssignments(e, assignments, nil, defaultBehavior)
+		if err != nil {
+			return nil, fmt.Errorf("invalid assignments at index %d: %w", i, err)
+		}
+		result = append(result, *ta)
+	}
+	return result, nil
+}
+
+// Sweep submits the supplied trial assignments in order using the experiment's
+// trials URL, bypassing the optimizer's trial suggestions. The submitted trials
+// are subsequently returned by `NextTrial`, so `Run` can be used to execute them.
+// If `MaxTrials` is set, at most that many trials are submitted. The number of
+// submitted trials is returned.
+func (l *TrialLoop) Sweep(ctx context.Context, trialsURL string, tas []TrialAssignments) (int, error) {
+	if l.MaxTrials > 0 && len(tas) > l.MaxTrials {
+		tas = tas[:l.MaxTrials]
+	}
+
+	for i := range tas {
+		if _, err := l.API.CreateTrial(ctx, trialsURL, tas[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(tas), nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrialGrid_Combinations(t *testing.T) {
+	exp := &Experiment{
+		Parameters: []Parameter{
+			{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+			{Name: "b", Type: ParameterTypeCategorical, Values: []string{"x", "y", "z"}},
+			{Name: "c", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		grid      TrialGrid
+		expected  []map[string]string
+		expectErr bool
+	}{
+		{
+			desc:     "empty",
+			grid:     TrialGrid{},
+			expected: []map[string]string{{}},
+		},
+		{
+			desc: "single parameter",
+			grid: TrialGrid{"b": {"x", "z"}},
+			expected: []map[string]string{
+				{"b": "x"},
+				{"b": "z"},
+			},
+		},
+		{
+			desc: "experiment order",
+			grid: TrialGrid{"c": {"1", "2"}, "a": {"3", "4"}},
+			expected: []map[string]string{
+				{"a": "3", "c": "1"},
+				{"a": "3", "c": "2"},
+				{"a": "4", "c": "1"},
+				{"a": "4", "c": "2"},
+			},
+		},
+		{
+			desc:      "unknown parameter",
+			grid:      TrialGrid{"d": {"1"}},
+			expectErr: true,
+		},
+		{
+			desc:      "missing values",
+			grid:      TrialGrid{"a": {}},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := c.grid.Combinations(exp)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+// sweepAPI records the created trials.
+type sweepAPI struct {
+	API
+	created []TrialAssignments
+}
+
+func (a *sweepAPI) CreateTrial(_ context.Context, _ string, ta TrialAssignments) (TrialAssignments, error) {
+	a.created = append(a.created, ta)
+	return ta, nil
+}
+
+func TestTrialLoop_Sweep(t *testing.T) {
+	exp := &Experiment{
+		Parameters: []Parameter{
+			{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+			{Name: "b", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+		},
+	}
+
+	tas, err := TrialGrid{"a": {"1", "2", "3"}}.Assignments(exp, "min")
+	require.NoError(t, err)
+	require.Len(t, tas, 3)
+
+	fake := &sweepAPI{}
+	l := &TrialLoop{API: fake, MaxTrials: 2}
+	n, err := l.Sweep(context.Background(), "trials", tas)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, fake.created, 2)
+	assert.Equal(t, "2", fake.created[1].Assignments[0].Value.String())
+	assert.Equal(t, "1", fake.created[1].Assignments[1].Value.String())
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -151,6 +152,14 @@ func (h *httpAPI) GetExperiment(ctx context.Context, u string) (Experiment, erro
 }
 
 func (h *httpAPI) CreateExperimentByName(ctx context.Context, n ExperimentName, exp Experiment) (Experiment, error) {
+	// Catch invalid experiments before they are sent to the server
+	if err := Validate(&exp); err != nil {
+		return Experiment{}, &api.Error{
+			Type:    ErrExperimentInvalid,
+			Message: fmt.Sprintf("experiment %q is invalid:\n%s", n, err),
+		}
+	}
+
 	u := h.client.URL(h.endpoint)
 	u.Path = path.Join(u.Path, n.String())
 	return h.CreateExperiment(ctx, u.String(), exp)
@@ -176,7 +185,7 @@ func (h *httpAPI) CreateExperiment(ctx context.Context, u string, exp Experiment
 		return e, err
 	case http.StatusBadRequest:
 		return e, api.NewError(ErrExperimentNameInvalid, resp, body)
-	case http.StatusConflict:
+	case http.StatusConflict, http.StatusPreconditionFailed:
 		return e, api.NewError(ErrExperimentNameConflict, resp, body)
 	case http.StatusUnprocessableEntity:
 		return e, api.NewError(ErrExperimentInvalid, resp, body)
@@ -226,6 +235,7 @@ func (h *httpAPI) GetAllTrials(ctx context.Context, u string, q TrialListQuery)
 
 	switch resp.StatusCode {
 	case http.StatusOK:
+		api.UnmarshalMetadata(resp, &lst.Metadata)
 		err = json.Unmarshal(body, &lst)
 		return lst, err
 	default:
@@ -233,6 +243,85 @@ func (h *httpAPI) GetAllTrials(ctx context.Context, u string, q TrialListQuery)
 	}
 }
 
+// StreamAllTrials decodes the trials as they are received instead of buffering
+// the entire list, the supplied function is invoked for each trial.
+func (h *httpAPI) StreamAllTrials(ctx context.Context, u string, q TrialListQuery, f func(*TrialItem) error) (api.Metadata, error) {
+	md := api.Metadata{}
+
+	sc, ok := h.client.(api.StreamingClient)
+	if !ok {
+		lst, err := h.GetAllTrials(ctx, u, q)
+		if err != nil {
+			return md, err
+		}
+		for i := range lst.Trials {
+			if err := f(&lst.Trials[i]); err != nil {
+				return lst.Metadata, err
+			}
+		}
+		return lst.Metadata, nil
+	}
+
+	u, err := q.IndexQuery.AppendToURL(u)
+	if err != nil {
+		return md, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return md, err
+	}
+
+	resp, err := sc.Stream(ctx, req)
+	if err != nil {
+		return md, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		api.UnmarshalMetadata(resp, &md)
+		return md, api.DecodeList(resp.Body, "trials", func(data json.RawMessage) error {
+			t := TrialItem{}
+			if err := json.Unmarshal(data, &t); err != nil {
+				return err
+			}
+			return f(&t)
+		})
+	default:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return md, err
+		}
+		return md, api.NewUnexpectedError(resp, body)
+	}
+}
+
+func (h *httpAPI) GetTrial(ctx context.Context, u string) (TrialItem, error) {
+	t := TrialItem{}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return t, err
+	}
+
+	resp, body, err := h.client.Do(ctx, req)
+	if err != nil {
+		return t, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		api.UnmarshalMetadata(resp, &t.Metadata)
+		err = json.Unmarshal(body, &t)
+		return t, err
+	case http.StatusNotFound:
+		return t, api.NewError(ErrTrialNotFound, resp, body)
+	default:
+		return t, api.NewUnexpectedError(resp, body)
+	}
+}
+
 func (h *httpAPI) CreateTrial(ctx context.Context, u string, asm TrialAssignments) (TrialAssignments, error) {
 	ta := TrialAssignments{}
 
@@ -268,7 +357,8 @@ func (h *httpAPI) NextTrial(ctx context.Context, u string) (TrialAssignments, er
 		return asm, err
 	}
 
-	resp, body, err := h.client.Do(ctx, req)
+	// The server may hold the request until a trial is available
+	resp, body, err := h.client.Do(api.ContextWithLongPoll(ctx), req)
 	if err != nil {
 		return asm, err
 	}
@@ -292,6 +382,11 @@ func (h *httpAPI) ReportTrial(ctx context.Context, u string, vls TrialValues) er
 		vls.Values = nil
 	}
 
+	// Catch values the server will never accept before sending them
+	if err := CheckTrialValues(&vls); err != nil {
+		return err
+	}
+
 	if vls.StartTime != nil && vls.CompletionTime != nil {
 		*vls.StartTime = vls.StartTime.Round(time.Millisecond).UTC()
 		*vls.CompletionTime = vls.CompletionTime.Round(time.Millisecond).UTC()
@@ -345,6 +440,31 @@ func (h *httpAPI) AbandonRunningTrial(ctx context.Context, u string) error {
 	}
 }
 
+func (h *httpAPI) PatchExperiment(ctx context.Context, u string, patch ExperimentPatch) error {
+	req, err := httpNewMergePatchRequest(u, patch, patch.Metadata)
+	if err != nil {
+		return err
+	}
+
+	resp, body, err := h.client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return api.NewError(ErrExperimentNotFound, resp, body)
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return api.NewError(ErrExperimentInvalid, resp, body)
+	case http.StatusPreconditionFailed:
+		return api.NewError(api.ErrConflict, resp, body)
+	default:
+		return api.NewUnexpectedError(resp, body)
+	}
+}
+
 func (h *httpAPI) LabelExperiment(ctx context.Context, u string, lbl ExperimentLabels) error {
 	req, err := httpNewJSONRequest(http.MethodPost, u, lbl)
 	if err != nil {
@@ -406,3 +526,20 @@ func httpNewJSONRequest(method, u string, body interface{}) (*http.Request, erro
 
 	return req, err
 }
+
+// httpNewMergePatchRequest returns a JSON Merge Patch (RFC 7396) request. If
+// the supplied metadata includes an entity tag, it is sent as an `If-Match`
+// precondition so the patch is rejected if the resource was modified.
+func httpNewMergePatchRequest(u string, patch interface{}, md api.Metadata) (*http.Request, error) {
+	req, err := httpNewJSONRequest(http.MethodPatch, u, patch)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	if etag := md.ETag(); etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// LabelImported is the trial label used to mark imported observations.
+const LabelImported = "imported"
+
+// Observation is a single historical measurement, it contains the values of
+// the experiment parameters and metrics keyed by name. Other values are ignored.
+type Observation map[string]api.NumberOrString
+
+// ReadObservations reads observations from either a JSON list of objects or
+// from CSV data with a header row containing the parameter and metric names.
+func ReadObservations(r io.Reader) ([]Observation, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var obs []Observation
+	if data = bytes.TrimSpace(data); bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &obs); err != nil {
+			return nil, fmt.Errorf("failed to read observations: %w", err)
+		}
+		return obs, nil
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read observations: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for _, record := range records[1:] {
+		o := make(Observation, len(header))
+		for i, name := range header {
+			if record[i] != "" {
+				o[name] = api.FromValue(record[i])
+			}
+		}
+		obs = append(obs, o)
+	}
+	return obs, nil
+}
+
+// ImportObservations records the supplied observations as completed trials of
+// the experiment, each trial is created with the assignments of an observation
+// and immediately reported with its metric values. All observations are
+// validated before any trials are created. The number of imported trials is returned.
+func ImportObservations(ctx context.Context, expAPI API, exp *Experiment, obs []Observation) (int, error) {
+	trialsURL := exp.Link(api.RelationTrials)
+	if trialsURL == "" {
+		return 0, fmt.Errorf("malformed response, missing trials link")
+	}
+
+	tas := make([]TrialAssignments, 0, len(obs))
+	vls := make([]TrialValues, 0, len(obs))
+	for i, o := range obs {
+		ta, vl, err := o.trial(exp)
+		if err != nil {
+			return 0, fmt.Errorf("invalid observation at index %d: %w", i, err)
+		}
+		tas = append(tas, *ta)
+		vls = append(vls, *vl)
+	}
+
+	for i := range tas {
+		ta, err := expAPI.CreateTrial(ctx, trialsURL, tas[i])
+		if err != nil {
+			return i, err
+		}
+
+		u := ta.Location()
+		if u == "" {
+			return i, fmt.Errorf("malformed response, missing trial location")
+		}
+
+		if err := expAPI.ReportTrial(ctx, u, vls[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(tas), nil
+}
+
+// trial returns the trial assignments and values for the observation.
+func (o Observation) trial(exp *Experiment) (*TrialAssignments, *TrialValues, error) {
+	assignments := make(map[string]string, len(exp.Parameters))
+	for _, p := range exp.Parameters {
+		if v, ok := o[p.Name]; ok {
+			assignments[p.Name] = v.String()
+		}
+	}
+
+	ta, err := NewTrialAssignments(exp, assignments, nil, "none")
+	if err != nil {
+		return nil, nil, err
+	}
+	ta.Labels = map[string]string{LabelImported: "true"}
+
+	vl := &TrialValues{}
+	for _, m := range exp.Metrics {
+		v, ok := o[m.Name]
+		if !ok {
+			return nil, nil, fmt.Errorf("no value for metric %q", m.Name)
+		}
+		value, err := NewValue(m.Name, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		vl.Values = append(vl.Values, value)
+	}
+	if err := NormalizeTrialValues(vl, exp.Metrics); err != nil {
+		return nil, nil, err
+	}
+	return ta, vl, nil
+}
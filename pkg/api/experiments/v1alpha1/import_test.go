@@ -0,0 +1,149 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+func TestReadObservations(t *testing.T) {
+	cases := []struct {
+		desc     string
+		data     string
+		expected []Observation
+	}{
+		{
+			desc: "csv",
+			data: "a,b,latency\n1,x,120.5\n2,,99\n",
+			expected: []Observation{
+				{"a": api.FromInt64(1), "b": api.FromString("x"), "latency": api.FromFloat64(120.5)},
+				{"a": api.FromInt64(2), "latency": api.FromInt64(99)},
+			},
+		},
+		{
+			desc: "json",
+			data: `[{"a": 1, "b": "x", "latency": 120.5}]`,
+			expected: []Observation{
+				{"a": api.FromNumber("1"), "b": api.FromString("x"), "latency": api.FromNumber("120.5")},
+			},
+		},
+		{
+			desc: "empty",
+			data: "",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := ReadObservations(strings.NewReader(c.data))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+// importAPI records the created and reported trials.
+type importAPI struct {
+	API
+	created  []TrialAssignments
+	reported []TrialValues
+}
+
+func (a *importAPI) CreateTrial(_ context.Context, _ string, ta TrialAssignments) (TrialAssignments, error) {
+	a.created = append(a.created, ta)
+	ta.Metadata = api.Metadata{"Location": {"trial"}}
+	return ta, nil
+}
+
+func (a *importAPI) ReportTrial(_ context.Context, u string, vls TrialValues) error {
+	if u != "trial" {
+		return &api.Error{Type: ErrTrialNotFound}
+	}
+	a.reported = append(a.reported, vls)
+	return nil
+}
+
+func TestImportObservations(t *testing.T) {
+	exp := &Experiment{
+		Metadata: api.Metadata{"Link": {"<trials>; rel=" + api.RelationTrials}},
+		Parameters: []Parameter{
+			{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+			{Name: "b", Type: ParameterTypeCategorical, Values: []string{"x", "y"}},
+		},
+		Metrics: []Metric{{Name: "latency"}},
+	}
+
+	cases := []struct {
+		desc      string
+		obs       []Observation
+		expected  int
+		expectErr bool
+	}{
+		{
+			desc: "valid",
+			obs: []Observation{
+				{"a": api.FromInt64(1), "b": api.FromString("x"), "latency": api.FromFloat64(1.5), "extra": api.FromString("ignored")},
+				{"a": api.FromInt64(2), "b": api.FromString("y"), "latency": api.FromInt64(2)},
+			},
+			expected: 2,
+		},
+		{
+			desc: "missing parameter",
+			obs: []Observation{
+				{"a": api.FromInt64(1), "b": api.FromString("x"), "latency": api.FromFloat64(1.5)},
+				{"a": api.FromInt64(2), "latency": api.FromInt64(2)},
+			},
+			expectErr: true,
+		},
+		{
+			desc: "missing metric",
+			obs: []Observation{
+				{"a": api.FromInt64(1), "b": api.FromString("x")},
+			},
+			expectErr: true,
+		},
+		{
+			desc: "out of bounds",
+			obs: []Observation{
+				{"a": api.FromInt64(11), "b": api.FromString("x"), "latency": api.FromFloat64(1.5)},
+			},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			fake := &importAPI{}
+			n, err := ImportObservations(context.Background(), fake, exp, c.obs)
+			if c.expectErr {
+				assert.Error(t, err)
+				assert.Empty(t, fake.created, "no trials should be created for invalid observations")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, n)
+			require.Len(t, fake.created, c.expected)
+			require.Len(t, fake.reported, c.expected)
+			assert.Equal(t, "true", fake.created[0].Labels[LabelImported])
+			assert.Equal(t, 1.5, fake.reported[0].Values[0].Value)
+		})
+	}
+}
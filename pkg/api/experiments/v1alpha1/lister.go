@@ -31,10 +31,23 @@ type Lister struct {
 	API API
 	// BatchSize overrides the default batch size for fetching lists.
 	BatchSize int
+	// OnPage is an optional hook invoked after each page of a list is visited,
+	// return `api.ErrStopPaging` to stop iterating early without an error.
+	OnPage api.PageFunc
+	// Parallelism is the maximum number of names resolved concurrently.
+	Parallelism int
+	// ContinueOnError visits all the names that can be resolved, failures are
+	// reported together as an `api.NameErrorList`.
+	ContinueOnError bool
+	// ExcludeArchived skips archived experiments when listing experiments,
+	// named experiments are always visited.
+	ExcludeArchived bool
 }
 
 // ForEachExperiment iterates over all the experiments matching the supplied query.
 func (l *Lister) ForEachExperiment(ctx context.Context, q ExperimentListQuery, f func(*ExperimentItem) error) error {
+	page := 0
+
 	// Define a helper to iteratively (NOT recursively) visit experiments
 	forEach := func(lst ExperimentList, err error) (string, error) {
 		if err != nil {
@@ -42,6 +55,9 @@ func (l *Lister) ForEachExperiment(ctx context.Context, q ExperimentListQuery, f
 		}
 
 		for i := range lst.Experiments {
+			if lst.Experiments[i].IsArchived() && l.ExcludeArchived {
+				continue
+			}
 			if err := f(&lst.Experiments[i]); err != nil {
 				return "", err
 			}
@@ -50,7 +66,9 @@ func (l *Lister) ForEachExperiment(ctx context.Context, q ExperimentListQuery, f
 			}
 		}
 
-		return lst.Link(api.RelationNext), nil
+		next, err := l.OnPage.Next(page, len(lst.Experiments), lst.Next())
+		page++
+		return next, err
 	}
 
 	// Overwrite the limit
@@ -68,43 +86,101 @@ func (l *Lister) ForEachExperiment(ctx context.Context, q ExperimentListQuery, f
 
 // ForEachNamedExperiment iterates over all the named experiments, optionally ignoring those that do not exist.
 func (l *Lister) ForEachNamedExperiment(ctx context.Context, names []string, ignoreNotFound bool, f func(*ExperimentItem) error) error {
-	for _, name := range names {
-		exp, err := l.API.GetExperimentByName(ctx, ExperimentName(name))
-		if err != nil {
-			var notFoundErr *api.Error
-			if errors.As(err, &notFoundErr) && notFoundErr.Type == ErrExperimentNotFound && ignoreNotFound {
-				continue
+	batch := l.batchExperiments(ctx, names)
+	resolved := make([]*ExperimentItem, len(names))
+	opts := api.NameOptions{Parallelism: l.Parallelism, ContinueOnError: l.ContinueOnError}
+	return api.ForEachName(ctx, names, opts,
+		func(ctx context.Context, i int) error {
+			if item, ok := batch[ExperimentName(names[i])]; ok {
+				resolved[i] = item
+				return nil
 			}
-			return err
-		}
 
-		if err := f(&ExperimentItem{Experiment: exp}); err != nil {
-			return err
+			exp, err := l.API.GetExperimentByName(ctx, ExperimentName(names[i]))
+			if err != nil {
+				var notFoundErr *api.Error
+				if errors.As(err, &notFoundErr) && notFoundErr.Type == ErrExperimentNotFound && ignoreNotFound {
+					return nil
+				}
+				return err
+			}
+
+			resolved[i] = &ExperimentItem{Experiment: exp}
+			return nil
+		},
+		func(i int) error {
+			if resolved[i] == nil {
+				return nil
+			}
+			return f(resolved[i])
+		})
+}
+
+// ListByNames returns the named experiments which exist, in the order of the
+// names. If the server supports it, the experiments are fetched using a single
+// request, otherwise each experiment is fetched individually.
+func (l *Lister) ListByNames(ctx context.Context, names []string) ([]ExperimentItem, error) {
+	var result []ExperimentItem
+	err := l.ForEachNamedExperiment(ctx, names, true, func(item *ExperimentItem) error {
+		result = append(result, *item)
+		return nil
+	})
+	return result, err
+}
+
+// batchExperiments fetches multiple experiments using a single list request,
+// nothing is returned if the server does not support filtering by name.
+func (l *Lister) batchExperiments(ctx context.Context, names []string) map[ExperimentName]*ExperimentItem {
+	if len(names) < 2 {
+		return nil
+	}
+
+	wanted := make(map[ExperimentName]bool, len(names))
+	for _, n := range names {
+		wanted[ExperimentName(n)] = true
+	}
+
+	q := ExperimentListQuery{}
+	q.SetNames(names...)
+	q.SetLimit(len(names))
+	lst, err := l.API.GetAllExperiments(ctx, q)
+	if err != nil {
+		return nil
+	}
+
+	batch := make(map[ExperimentName]*ExperimentItem, len(lst.Experiments))
+	for i := range lst.Experiments {
+		// An unexpected name means the server ignored the filter
+		if !wanted[lst.Experiments[i].Name] {
+			return nil
 		}
+		batch[lst.Experiments[i].Name] = &lst.Experiments[i]
 	}
-	return nil
+	return batch
 }
 
 // ForEachTrial iterates over all trials for an experiment matching the supplied query.
 func (l *Lister) ForEachTrial(ctx context.Context, exp *Experiment, q TrialListQuery, f func(*TrialItem) error) (err error) {
-	// Define a helper to iteratively (NOT recursively) list and visit scenarios
+	page := 0
+
+	// Define a helper to iteratively (NOT recursively) list and visit trials as they are decoded
 	forEach := func(u string) (string, error) {
-		lst, err := l.API.GetAllTrials(ctx, u, q)
+		n := 0
+		md, err := l.API.StreamAllTrials(ctx, u, q, func(item *TrialItem) error {
+			n++
+			item.Experiment = exp
+			if err := f(item); err != nil {
+				return err
+			}
+			return ctx.Err()
+		})
 		if err != nil {
 			return "", err
 		}
 
-		for i := range lst.Trials {
-			lst.Trials[i].Experiment = exp
-			if err := f(&lst.Trials[i]); err != nil {
-				return "", err
-			}
-			if err := ctx.Err(); err != nil {
-				return "", err
-			}
-		}
-
-		return lst.Link(api.RelationNext), nil
+		next, err := l.OnPage.Next(page, n, md.Next())
+		page++
+		return next, err
 	}
 
 	// Overwrite the limit
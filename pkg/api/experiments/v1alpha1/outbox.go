@@ -0,0 +1,261 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// Outbox is a file backed queue of trial reports which could not be delivered,
+// allowing runners on unreliable networks to keep going and deliver the results
+// once connectivity returns. Each report is stored with an idempotency key that
+// is sent with every delivery attempt so the server only records it once.
+type Outbox struct {
+	// The directory used to store the pending reports.
+	Dir string
+
+	mu sync.Mutex
+}
+
+// OutboxEntry is a pending trial report.
+type OutboxEntry struct {
+	// The idempotency key of the report.
+	Key string `json:"key"`
+	// The URL of the trial being reported.
+	Location string `json:"location"`
+	// The values being reported.
+	Values TrialValues `json:"values"`
+	// The time the report was queued.
+	Queued time.Time `json:"queued"`
+}
+
+// Enqueue stores a trial report for later delivery. Queuing another report for
+// the same trial replaces the pending report, keeping the original key.
+func (o *Outbox) Enqueue(key, u string, vls TrialValues) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries, err := o.entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Location == u {
+			key = e.Key
+		}
+	}
+
+	if key == "" {
+		if key, err = api.NewULID(time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(o.Dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(&OutboxEntry{Key: key, Location: u, Values: vls, Queued: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial entry
+	tmp, err := os.CreateTemp(o.Dir, ".pending-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), o.path(key))
+}
+
+// Pending returns the queued reports in the order they were queued.
+func (o *Outbox) Pending() ([]OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.entries()
+}
+
+// Flush attempts to deliver all the queued reports, returning the number of
+// reports delivered. Reports the server will never accept (e.g. because the
+// trial no longer exists) are discarded and reported as a `api.NameErrorList`
+// keyed by the trial URL; delivery stops at the first transient
+// failure (or cancellation), leaving the remaining reports queued.
+func (o *Outbox) Flush(ctx context.Context, expAPI API) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries, err := o.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	var errs api.NameErrorList
+	for _, e := range entries {
+		err := reportTrial(ctx, expAPI, e.Key, e.Location, e.Values)
+		if isTransientReportError(err) || errors.Is(err, context.Canceled) {
+			return n, err
+		}
+
+		if rerr := os.Remove(o.path(e.Key)); rerr != nil && !os.IsNotExist(rerr) {
+			return n, rerr
+		}
+
+		if err != nil {
+			errs = append(errs, &api.NameError{Name: e.Location, Err: err})
+			continue
+		}
+		n++
+	}
+	return n, errs.Err()
+}
+
+// entries reads the queued reports.
+func (o *Outbox) entries() ([]OutboxEntry, error) {
+	files, err := os.ReadDir(o.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entries []OutboxEntry
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(o.Dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		e := OutboxEntry{}
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Queued.Before(entries[j].Queued) })
+	return entries, nil
+}
+
+// path returns the file name of an entry.
+func (o *Outbox) path(key string) string {
+	return filepath.Join(o.Dir, key+".json")
+}
+
+// OutboxAPI is an experiments API that queues trial reports in an outbox when
+// they cannot be delivered. Every report first attempts to deliver any queued
+// reports, so the outbox drains as soon as the server is reachable again.
+type OutboxAPI struct {
+	API
+	// The outbox used to store undelivered reports.
+	Outbox *Outbox
+	// Optional callback invoked with the queued reports that were discarded
+	// because the server will never accept them, if not set the failures are
+	// returned once the current report has been handled.
+	OnDiscard func(err error)
+}
+
+// ReportTrial reports the trial values, queuing them in the outbox if they
+// cannot be delivered because of a transient failure.
+func (o *OutboxAPI) ReportTrial(ctx context.Context, u string, vls TrialValues) error {
+	_, err := o.Outbox.Flush(ctx, o.API)
+	var discarded api.NameErrorList
+	switch {
+	case errors.As(err, &discarded):
+		// Report the current trial before dealing with the discarded reports
+	case isTransientReportError(err):
+		return o.Outbox.Enqueue("", u, vls)
+	case err != nil:
+		return err
+	}
+
+	key, err := api.NewULID(time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = reportTrial(ctx, o.API, key, u, vls)
+	if isTransientReportError(err) {
+		err = o.Outbox.Enqueue(key, u, vls)
+	}
+	if err != nil || len(discarded) == 0 {
+		return err
+	}
+
+	if o.OnDiscard == nil {
+		return discarded
+	}
+	o.OnDiscard(discarded)
+	return nil
+}
+
+// reportTrial sends a single trial report using the supplied idempotency key.
+// A report for a trial that was already reported is assumed to be a retry of a
+// delivery whose response was lost.
+func reportTrial(ctx context.Context, expAPI API, key, u string, vls TrialValues) error {
+	err := expAPI.ReportTrial(api.WithHeader(ctx, api.HeaderIdempotencyKey, key), u, vls)
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.Type == ErrTrialAlreadyReported {
+		return nil
+	}
+	return err
+}
+
+// isTransientReportError checks if a failed report should be retried later.
+func isTransientReportError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		// Transport failures (e.g. no network) are always worth retrying
+		return true
+	}
+
+	switch {
+	case apiErr.Type == api.ErrUnavailable,
+		apiErr.StatusCode == http.StatusTooManyRequests,
+		apiErr.StatusCode >= http.StatusInternalServerError:
+		return true
+	default:
+		return false
+	}
+}
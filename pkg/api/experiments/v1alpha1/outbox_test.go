@@ -0,0 +1,164 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// outboxAPI records delivered trial reports, failing with the configured error for each trial URL.
+type outboxAPI struct {
+	API
+	errs     map[string]error
+	reported []string
+}
+
+func (a *outboxAPI) ReportTrial(_ context.Context, u string, _ TrialValues) error {
+	if err := a.errs[u]; err != nil {
+		return err
+	}
+	a.reported = append(a.reported, u)
+	return nil
+}
+
+func TestOutboxAPI_ReportTrial(t *testing.T) {
+	ctx := context.Background()
+	offline := errors.New("network is unreachable")
+	fake := &outboxAPI{errs: map[string]error{"t1": offline, "t2": offline}}
+	o := &OutboxAPI{API: fake, Outbox: &Outbox{Dir: t.TempDir()}}
+
+	// Undeliverable reports are queued, reporting the same trial again replaces the report
+	require.NoError(t, o.ReportTrial(ctx, "t1", TrialValues{Failed: true}))
+	pending, err := o.Outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	key := pending[0].Key
+
+	require.NoError(t, o.ReportTrial(ctx, "t1", TrialValues{Values: []Value{{MetricName: "m", Value: 1}}}))
+	require.NoError(t, o.ReportTrial(ctx, "t2", TrialValues{}))
+	pending, err = o.Outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "t1", pending[0].Location)
+	assert.Equal(t, key, pending[0].Key)
+	assert.False(t, pending[0].Values.Failed)
+	assert.Equal(t, "t2", pending[1].Location)
+
+	// Once the server is back, queued reports are delivered first
+	fake.errs = map[string]error{"t2": &api.Error{Type: ErrTrialAlreadyReported, StatusCode: http.StatusConflict}}
+	require.NoError(t, o.ReportTrial(ctx, "t3", TrialValues{}))
+	assert.Equal(t, []string{"t1", "t3"}, fake.reported)
+	pending, err = o.Outbox.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestOutboxAPI_ReportTrial_Discard(t *testing.T) {
+	ctx := context.Background()
+	rejected := &api.Error{Type: ErrTrialInvalid, StatusCode: http.StatusUnprocessableEntity}
+
+	queue := func(t *testing.T) *OutboxAPI {
+		o := &OutboxAPI{API: &outboxAPI{errs: map[string]error{"t1": rejected}}, Outbox: &Outbox{Dir: t.TempDir()}}
+		require.NoError(t, o.Outbox.Enqueue("", "t1", TrialValues{}))
+		return o
+	}
+
+	t.Run("returned", func(t *testing.T) {
+		o := queue(t)
+		err := o.ReportTrial(ctx, "t2", TrialValues{})
+		var nameErrs api.NameErrorList
+		if assert.ErrorAs(t, err, &nameErrs) && assert.Len(t, nameErrs, 1) {
+			assert.Equal(t, "t1", nameErrs[0].Name)
+		}
+		assert.Equal(t, []string{"t2"}, o.API.(*outboxAPI).reported)
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		o := queue(t)
+		var discarded error
+		o.OnDiscard = func(err error) { discarded = err }
+		assert.NoError(t, o.ReportTrial(ctx, "t2", TrialValues{}))
+		var nameErrs api.NameErrorList
+		if assert.ErrorAs(t, discarded, &nameErrs) && assert.Len(t, nameErrs, 1) {
+			assert.Equal(t, rejected, nameErrs[0].Err)
+		}
+		assert.Equal(t, []string{"t2"}, o.API.(*outboxAPI).reported)
+	})
+}
+
+func TestOutbox_Flush(t *testing.T) {
+	cases := []struct {
+		desc      string
+		errs      map[string]error
+		delivered int
+		pending   int
+		err       bool
+	}{
+		{
+			desc:      "delivered",
+			delivered: 3,
+		},
+		{
+			desc:      "server unavailable",
+			errs:      map[string]error{"t2": &api.Error{Type: api.ErrUnexpected, StatusCode: http.StatusServiceUnavailable}},
+			delivered: 1,
+			pending:   2,
+			err:       true,
+		},
+		{
+			desc:      "canceled",
+			errs:      map[string]error{"t2": context.Canceled},
+			delivered: 1,
+			pending:   2,
+			err:       true,
+		},
+		{
+			desc:      "rejected",
+			errs:      map[string]error{"t2": &api.Error{Type: ErrTrialInvalid, StatusCode: http.StatusUnprocessableEntity}},
+			delivered: 2,
+			err:       true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			ctx := context.Background()
+			o := &Outbox{Dir: t.TempDir()}
+			for _, u := range []string{"t1", "t2", "t3"} {
+				require.NoError(t, o.Enqueue("", u, TrialValues{}))
+			}
+
+			n, err := o.Flush(ctx, &outboxAPI{errs: c.errs})
+			if c.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.delivered, n)
+
+			pending, err := o.Pending()
+			require.NoError(t, err)
+			assert.Len(t, pending, c.pending)
+		})
+	}
+}
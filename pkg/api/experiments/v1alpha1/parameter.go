@@ -183,12 +183,18 @@ func CheckParameterValue(p *Parameter, v *api.NumberOrString) error {
 
 	switch p.Type {
 	case ParameterTypeInteger:
-		val := v.Int64Value()
+		val, err := v.Int64()
+		if err != nil {
+			return fmt.Errorf("invalid value for integer parameter %q: %w", p.Name, err)
+		}
 		min, max := lower.Int64Value(), upper.Int64Value()
 		if val < min || val > max {
 			return fmt.Errorf("integer value is out of range [%d-%d]: %d", min, max, val)
 		}
 	case ParameterTypeDouble:
+		if !v.IsFinite() {
+			return fmt.Errorf("invalid value for double parameter %q: %s", p.Name, v.String())
+		}
 		val := v.Float64Value()
 		min, max := lower.Float64Value(), upper.Float64Value()
 		if val < min || val > max {
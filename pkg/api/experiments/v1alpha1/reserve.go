@@ -0,0 +1,108 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// CreateOptions controls how an experiment is created by name.
+type CreateOptions struct {
+	// The behavior when the name is already in use.
+	OnConflict api.ConflictPolicy
+}
+
+// ReserveExperimentName returns the name to use for a new experiment based on
+// the conflict policy. The returned flag is true if the name refers to an
+// existing experiment (e.g. when adopting or overwriting). The name is empty if
+// the experiment should be skipped.
+//
+// The ConflictSuffix policy always generates a unique name without checking
+// the server, it is the only policy that is safe for concurrent use.
+func ReserveExperimentName(ctx context.Context, expAPI API, name ExperimentName, policy api.ConflictPolicy) (ExperimentName, bool, error) {
+	if policy == api.ConflictSuffix {
+		n, err := api.UniqueName(name.String())
+		return ExperimentName(n), false, err
+	}
+
+	target := name
+	for attempt := 1; ; attempt++ {
+		_, err := expAPI.GetExperimentByName(ctx, target)
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) && apiErr.Type == ErrExperimentNotFound {
+			return target, false, nil
+		} else if err != nil {
+			return "", false, err
+		}
+
+		switch policy {
+		case api.ConflictSkip:
+			return "", true, nil
+		case api.ConflictOverwrite, api.ConflictAdopt:
+			return target, true, nil
+		case api.ConflictRename:
+			target = ExperimentName(api.NextName(name.String(), attempt))
+		default:
+			return "", true, &api.Error{
+				Type:    ErrExperimentNameConflict,
+				Message: fmt.Sprintf("experiment %q already exists", target),
+			}
+		}
+	}
+}
+
+// CreateExperimentByNameWithOptions creates an experiment, handling collisions
+// with existing experiments according to the options. When adopting (or
+// skipping) an existing experiment, the existing experiment is returned.
+func CreateExperimentByNameWithOptions(ctx context.Context, expAPI API, name ExperimentName, exp Experiment, opts CreateOptions) (Experiment, error) {
+	for {
+		target, exists, err := ReserveExperimentName(ctx, expAPI, name, opts.OnConflict)
+		if err != nil {
+			return Experiment{}, err
+		}
+
+		if exists && opts.OnConflict != api.ConflictOverwrite {
+			if target == "" {
+				target = name
+			}
+			return expAPI.GetExperimentByName(ctx, target)
+		}
+
+		// Only create a new experiment if the name was not claimed since it was reserved
+		createCtx := ctx
+		if !exists {
+			createCtx = api.WithHeader(ctx, "If-None-Match", "*")
+		}
+
+		result, err := expAPI.CreateExperimentByName(createCtx, target, exp)
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) && apiErr.Type == ErrExperimentNameConflict {
+			switch opts.OnConflict {
+			case api.ConflictSuffix, api.ConflictRename:
+				// We lost a race for the name, try again
+				continue
+			case api.ConflictAdopt, api.ConflictSkip:
+				return expAPI.GetExperimentByName(ctx, target)
+			}
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// reserveAPI is an in-memory set of experiments.
+type reserveAPI struct {
+	API
+	experiments map[ExperimentName]Experiment
+}
+
+func (a *reserveAPI) GetExperimentByName(_ context.Context, n ExperimentName) (Experiment, error) {
+	exp, ok := a.experiments[n]
+	if !ok {
+		return exp, &api.Error{Type: ErrExperimentNotFound}
+	}
+	return exp, nil
+}
+
+func (a *reserveAPI) CreateExperimentByName(_ context.Context, n ExperimentName, exp Experiment) (Experiment, error) {
+	exp.Name = n
+	a.experiments[n] = exp
+	return exp, nil
+}
+
+func TestCreateExperimentByNameWithOptions(t *testing.T) {
+	cases := []struct {
+		desc         string
+		onConflict   api.ConflictPolicy
+		expectedName string
+		expectedObs  int64
+		expectErr    bool
+	}{
+		{
+			desc:       "fail",
+			onConflict: api.ConflictFail,
+			expectErr:  true,
+		},
+		{
+			desc:         "adopt",
+			onConflict:   api.ConflictAdopt,
+			expectedName: "test",
+			expectedObs:  10,
+		},
+		{
+			desc:         "overwrite",
+			onConflict:   api.ConflictOverwrite,
+			expectedName: "test",
+		},
+		{
+			desc:         "rename",
+			onConflict:   api.ConflictRename,
+			expectedName: "test-3",
+		},
+		{
+			desc:         "suffix",
+			onConflict:   api.ConflictSuffix,
+			expectedName: "test-",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			a := &reserveAPI{experiments: map[ExperimentName]Experiment{
+				"test":   {Name: "test", Observations: 10},
+				"test-2": {Name: "test-2"},
+			}}
+
+			exp, err := CreateExperimentByNameWithOptions(context.Background(), a, "test", Experiment{}, CreateOptions{OnConflict: c.onConflict})
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.True(t, strings.HasPrefix(exp.Name.String(), c.expectedName), "expected %q to start with %q", exp.Name, c.expectedName)
+				assert.Equal(t, c.expectedObs, exp.Observations)
+			}
+		})
+	}
+}
+
+func TestCreateExperimentByNameWithOptions_Race(t *testing.T) {
+	// The server claims "test" for another client between the check and the create
+	var mu sync.Mutex
+	existing := map[string]bool{}
+	var creates []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		name := strings.TrimPrefix(r.URL.Path, "/v1/experiments/")
+		switch r.Method {
+		case http.MethodGet:
+			if !existing[name] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		case http.MethodPut:
+			creates = append(creates, name+" "+r.Header.Get("If-None-Match"))
+			if name == "test" {
+				existing[name] = true
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			existing[name] = true
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := api.NewClient(srv.URL, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	expAPI := NewAPI(client)
+
+	exp := Experiment{
+		Parameters: []Parameter{{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "2"}}},
+		Metrics:    []Metric{{Name: "m"}},
+	}
+
+	_, err = CreateExperimentByNameWithOptions(context.Background(), expAPI, "test", exp, CreateOptions{OnConflict: api.ConflictFail})
+	var apiErr *api.Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, ErrExperimentNameConflict, apiErr.Type)
+	}
+
+	mu.Lock()
+	delete(existing, "test")
+	mu.Unlock()
+	_, err = CreateExperimentByNameWithOptions(context.Background(), expAPI, "test", exp, CreateOptions{OnConflict: api.ConflictRename})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test *", "test *", "test-2 *"}, creates)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"sort"
+	"time"
+)
+
+// ExpiredExperiments returns the experiments which are not retained: an
+// experiment is expired if it is not one of the `keepLast` most recently
+// modified experiments or if it was last modified more than `maxAge` before
+// `now`. A zero value disables the corresponding limit. The result is ordered
+// from the most to the least recently modified experiment.
+func ExpiredExperiments(items []ExperimentItem, keepLast int, maxAge time.Duration, now time.Time) []ExperimentItem {
+	sorted := make([]ExperimentItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].LastModified().After(sorted[j].LastModified())
+	})
+
+	var expired []ExperimentItem
+	for i := range sorted {
+		lm := sorted[i].LastModified()
+		switch {
+		case keepLast > 0 && i >= keepLast:
+			expired = append(expired, sorted[i])
+		case maxAge > 0 && !lm.IsZero() && now.Sub(lm) > maxAge:
+			expired = append(expired, sorted[i])
+		}
+	}
+	return expired
+}
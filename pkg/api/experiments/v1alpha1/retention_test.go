@@ -0,0 +1,82 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+func TestExpiredExperiments(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	item := func(name string, age time.Duration) ExperimentItem {
+		return ExperimentItem{Experiment: Experiment{
+			Metadata: api.Metadata{"Last-Modified": {now.Add(-age).Format(http.TimeFormat)}},
+			Name:     ExperimentName(name),
+		}}
+	}
+	items := []ExperimentItem{
+		item("b", 48*time.Hour),
+		item("a", time.Hour),
+		item("d", 30*24*time.Hour),
+		item("c", 72*time.Hour),
+	}
+
+	cases := []struct {
+		desc     string
+		keepLast int
+		maxAge   time.Duration
+		expected []string
+	}{
+		{
+			desc: "no limits",
+		},
+		{
+			desc:     "keep last",
+			keepLast: 2,
+			expected: []string{"c", "d"},
+		},
+		{
+			desc:     "max age",
+			maxAge:   7 * 24 * time.Hour,
+			expected: []string{"d"},
+		},
+		{
+			desc:     "both",
+			keepLast: 3,
+			maxAge:   60 * time.Hour,
+			expected: []string{"c", "d"},
+		},
+		{
+			desc:     "keep more than available",
+			keepLast: 10,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var actual []string
+			for _, e := range ExpiredExperiments(items, c.keepLast, c.maxAge, now) {
+				actual = append(actual, e.Name.String())
+			}
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"os"
+	"testing"
+
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/api/internal/apitest"
+)
+
+// roundTripTypes are the experiment API types which must round trip through JSON.
+var roundTripTypes = []struct {
+	name            string
+	newValue        func() interface{}
+	preserveUnknown bool
+}{
+	{name: "Experiment", newValue: func() interface{} { return &experiments.Experiment{} }, preserveUnknown: true},
+	{name: "ExperimentItem", newValue: func() interface{} { return &experiments.ExperimentItem{} }, preserveUnknown: true},
+	{name: "ExperimentList", newValue: func() interface{} { return &experiments.ExperimentList{} }},
+	{name: "TrialAssignments", newValue: func() interface{} { return &experiments.TrialAssignments{} }},
+	{name: "TrialValues", newValue: func() interface{} { return &experiments.TrialValues{} }},
+	{name: "TrialItem", newValue: func() interface{} { return &experiments.TrialItem{} }},
+	{name: "TrialList", newValue: func() interface{} { return &experiments.TrialList{} }},
+}
+
+func FuzzJSONRoundTrip(f *testing.F) {
+	f.Add([]byte(`{"displayName":"test","budget":10,"metrics":[{"name":"cost","minimize":true,"unit":"cores"}],"parameters":[{"name":"cpu","type":"int","bounds":{"min":100,"max":4000}},{"name":"tier","type":"categorical","values":["a","b"]}],"constraints":[{"constraintType":"order","lowerParameter":"a","upperParameter":"b"}],"labels":{"a":"b"}}`))
+	f.Add([]byte(`{"assignments":[{"parameterName":"cpu","value":100},{"parameterName":"tier","value":"a"}],"labels":{"baseline":"true"}}`))
+	f.Add([]byte(`{"values":[{"metricName":"cost","value":1.5,"error":0.1}],"failed":false,"startTime":"2023-01-02T03:04:05Z","completionTime":"2023-01-02T03:05:05.5Z","annotations":{"commit":"abc"}}`))
+	f.Add([]byte(`{"trials":[{"number":1,"status":"completed","assignments":[],"values":[],"_metadata":{"Link":["</t/1>; rel=self"]}}]}`))
+	if data, err := os.ReadFile("testdata/postgres-integration-test.json"); err == nil {
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, rt := range roundTripTypes {
+			if err := apitest.CheckJSONRoundTrip(data, rt.newValue, rt.preserveUnknown); err != nil {
+				t.Errorf("%s: %v", rt.name, err)
+			}
+		}
+	})
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"math"
 	"net/url"
 	"strings"
 	"time"
@@ -47,6 +49,26 @@ type Value struct {
 	Value float64 `json:"value"`
 	// The observed error of the metric.
 	Error float64 `json:"error,omitempty"`
+	// The unit of the observed value and error.
+	Unit api.Unit `json:"unit,omitempty"`
+}
+
+// NewValue returns a metric value from the supplied number or string. Numeric
+// strings are accepted, optionally including a unit (e.g. "250ms"), however the
+// resulting value must be finite.
+func NewValue(metricName string, value api.NumberOrString) (Value, error) {
+	var unit api.Unit
+	v, err := value.Float64()
+	if err != nil && value.IsString {
+		v, unit, err = api.ParseValueWithUnit(value.StrVal)
+	}
+	if err != nil {
+		return Value{}, fmt.Errorf("invalid value for metric %q: %w", metricName, err)
+	}
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return Value{}, fmt.Errorf("invalid value for metric %q: %s", metricName, value.String())
+	}
+	return Value{MetricName: metricName, Value: v, Unit: unit}, nil
 }
 
 type TrialValues struct {
@@ -62,6 +84,88 @@ type TrialValues struct {
 	StartTime *time.Time `json:"startTime,omitempty"`
 	// CompletionTime is the time at which the trial was completed.
 	CompletionTime *time.Time `json:"completionTime,omitempty"`
+	// Annotations describe the run that produced the values (e.g. a build URL or commit SHA).
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Annotate adds the supplied annotations to the trial values. Annotations
+// already present on the values take precedence.
+func (vls *TrialValues) Annotate(annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	if vls.Annotations == nil {
+		vls.Annotations = make(map[string]string, len(annotations))
+	}
+	for k, v := range annotations {
+		if _, ok := vls.Annotations[k]; !ok {
+			vls.Annotations[k] = v
+		}
+	}
+}
+
+// CheckTrialValues verifies that the supplied values can be reported. Values
+// which cannot be represented in JSON (i.e. NaN and infinity) are rejected with
+// an ErrTrialInvalid API error.
+func CheckTrialValues(vls *TrialValues) error {
+	if vls.Failed {
+		return nil
+	}
+
+	for _, v := range vls.Values {
+		if math.IsInf(v.Value, 0) || math.IsNaN(v.Value) {
+			return &api.Error{Type: ErrTrialInvalid, Message: fmt.Sprintf("invalid value for metric %q: %v", v.MetricName, v.Value)}
+		}
+		if math.IsInf(v.Error, 0) || math.IsNaN(v.Error) {
+			return &api.Error{Type: ErrTrialInvalid, Message: fmt.Sprintf("invalid error for metric %q: %v", v.MetricName, v.Error)}
+		}
+	}
+
+	return nil
+}
+
+// NormalizeTrialValues converts the supplied values to the units of the
+// corresponding experiment metrics. Values without a unit are assumed to
+// already use the unit of the metric; values which cannot be converted (e.g. a
+// duration reported for a memory metric, or any unit reported for a metric
+// without one) are rejected with an ErrTrialInvalid API error.
+func NormalizeTrialValues(vls *TrialValues, metrics []Metric) error {
+	if vls.Failed {
+		return nil
+	}
+
+	for i := range vls.Values {
+		v := &vls.Values[i]
+
+		var unit api.Unit
+		for _, m := range metrics {
+			if m.Name == v.MetricName {
+				unit = m.Unit
+				break
+			}
+		}
+
+		if v.Unit == "" || v.Unit == unit {
+			v.Unit = unit
+			continue
+		}
+
+		value, err := v.Unit.Convert(v.Value, unit)
+		if err != nil {
+			return &api.Error{Type: ErrTrialInvalid, Message: fmt.Sprintf("invalid value for metric %q: %s", v.MetricName, err.Error())}
+		}
+		v.Error, _ = v.Unit.Convert(v.Error, unit)
+		v.Value, v.Unit = value, unit
+	}
+
+	return nil
+}
+
+// RoundTrialValues applies the rounding policy (keyed by metric name) to the supplied values.
+func RoundTrialValues(vls *TrialValues, p api.RoundingPolicy) {
+	for i := range vls.Values {
+		vls.Values[i].Value = p.Round(vls.Values[i].MetricName, vls.Values[i].Value)
+	}
 }
 
 type TrialStatus string
@@ -114,6 +218,16 @@ type TrialItem struct {
 	Experiment *Experiment `json:"-"`
 }
 
+// Finished returns true if the trial is in a terminal state.
+func (ti *TrialItem) Finished() bool {
+	switch ti.Status {
+	case TrialCompleted, TrialFailed, TrialAbandoned:
+		return true
+	default:
+		return false
+	}
+}
+
 func (ti *TrialItem) UnmarshalJSON(b []byte) error {
 	type t TrialItem
 	return api.UnmarshalJSON(b, (*t)(ti))
@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -56,3 +57,162 @@ func TestTrialList_UnmarshalJSON(t *testing.T) {
 		assert.Equal(t, "true", l.Trials[1].Labels["manually_created"])
 	}
 }
+
+func TestCheckTrialValues(t *testing.T) {
+	cases := []struct {
+		desc   string
+		values TrialValues
+		valid  bool
+	}{
+		{
+			desc:  "empty",
+			valid: true,
+		},
+		{
+			desc: "finite",
+			values: TrialValues{
+				Values: []Value{{MetricName: "m", Value: 1.5, Error: 0.1}},
+			},
+			valid: true,
+		},
+		{
+			desc: "nan",
+			values: TrialValues{
+				Values: []Value{{MetricName: "m", Value: math.NaN()}},
+			},
+		},
+		{
+			desc: "infinite error",
+			values: TrialValues{
+				Values: []Value{{MetricName: "m", Value: 1, Error: math.Inf(1)}},
+			},
+		},
+		{
+			desc: "failed",
+			values: TrialValues{
+				Values: []Value{{MetricName: "m", Value: math.NaN()}},
+				Failed: true,
+			},
+			valid: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := CheckTrialValues(&c.values)
+			if c.valid {
+				assert.NoError(t, err)
+				return
+			}
+
+			var apiErr *api.Error
+			if assert.ErrorAs(t, err, &apiErr) {
+				assert.Equal(t, ErrTrialInvalid, apiErr.Type)
+			}
+		})
+	}
+}
+
+func TestNormalizeTrialValues(t *testing.T) {
+	metrics := []Metric{
+		{Name: "latency", Unit: api.UnitMilliseconds},
+		{Name: "cost", Unit: api.UnitDollarsPerMonth},
+		{Name: "score"},
+	}
+
+	cases := []struct {
+		desc     string
+		values   []Value
+		expected []Value
+		invalid  bool
+	}{
+		{
+			desc:     "unitless",
+			values:   []Value{{MetricName: "latency", Value: 250}, {MetricName: "score", Value: 1}},
+			expected: []Value{{MetricName: "latency", Value: 250, Unit: api.UnitMilliseconds}, {MetricName: "score", Value: 1}},
+		},
+		{
+			desc:     "converted",
+			values:   []Value{{MetricName: "latency", Value: 0.25, Error: 0.01, Unit: api.UnitSeconds}},
+			expected: []Value{{MetricName: "latency", Value: 250, Error: 10, Unit: api.UnitMilliseconds}},
+		},
+		{
+			desc:     "hourly cost",
+			values:   []Value{{MetricName: "cost", Value: 0.5, Unit: api.UnitDollarsPerHour}},
+			expected: []Value{{MetricName: "cost", Value: 365, Unit: api.UnitDollarsPerMonth}},
+		},
+		{
+			desc:    "incompatible",
+			values:  []Value{{MetricName: "latency", Value: 1, Unit: api.UnitMebibytes}},
+			invalid: true,
+		},
+		{
+			desc:    "metric without unit",
+			values:  []Value{{MetricName: "score", Value: 1, Unit: api.UnitSeconds}},
+			invalid: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			vls := TrialValues{Values: c.values}
+			err := NormalizeTrialValues(&vls, metrics)
+			if c.invalid {
+				var apiErr *api.Error
+				if assert.ErrorAs(t, err, &apiErr) {
+					assert.Equal(t, ErrTrialInvalid, apiErr.Type)
+				}
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, vls.Values)
+			}
+		})
+	}
+}
+
+func TestNewValue(t *testing.T) {
+	cases := []struct {
+		desc     string
+		value    api.NumberOrString
+		expected Value
+		invalid  bool
+	}{
+		{
+			desc:     "number",
+			value:    api.FromFloat64(1.5),
+			expected: Value{MetricName: "m", Value: 1.5},
+		},
+		{
+			desc:     "numeric string",
+			value:    api.FromString("1.5"),
+			expected: Value{MetricName: "m", Value: 1.5},
+		},
+		{
+			desc:     "with unit",
+			value:    api.FromString("250ms"),
+			expected: Value{MetricName: "m", Value: 250, Unit: api.UnitMilliseconds},
+		},
+		{
+			desc:    "unknown unit",
+			value:   api.FromString("250furlongs"),
+			invalid: true,
+		},
+		{
+			desc:    "nan",
+			value:   api.FromString("NaN"),
+			invalid: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			v, err := NewValue("m", c.value)
+			if c.invalid {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, v)
+			}
+		})
+	}
+}
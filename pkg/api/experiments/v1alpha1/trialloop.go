@@ -0,0 +1,258 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+const (
+	// FailureReasonTimeout is the failure reason reported for trials abandoned by the watchdog.
+	FailureReasonTimeout = "TrialTimeout"
+	// FailureReasonError is the failure reason reported for trials whose executor returned an error.
+	FailureReasonError = "TrialError"
+)
+
+// TrialFunc executes a single trial using the supplied assignments and returns the observed values.
+type TrialFunc func(context.Context, *TrialAssignments) (TrialValues, error)
+
+// TrialLoop is a helper to repeatedly fetch, execute and report trials until the experiment stops.
+type TrialLoop struct {
+	// API is the Experiment API used to fetch and report trials.
+	API API
+	// TrialTimeout is the maximum amount of time a single trial may execute before it is abandoned.
+	TrialTimeout time.Duration
+	// Rounding is applied to the observed metric values before they are reported.
+	Rounding api.RoundingPolicy
+	// UnavailableDelay is the amount of time to wait when no trial is available and the server does not specify.
+	UnavailableDelay time.Duration
+	// Parallelism is the number of trials to execute concurrently, defaults to 1.
+	Parallelism int
+	// Retries is the number of times a trial function returning an error is re-invoked before the trial is
+	// reported as failed. Trials which time out are not retried.
+	Retries int
+	// MaxTrials is the maximum number of trials to execute before returning, ignored if zero. The loop still
+	// returns when the experiment is stopped (e.g. the server side trial budget is exhausted).
+	MaxTrials int
+	// Metrics are the experiment metrics, when set reported values are normalized to the metric units.
+	Metrics []Metric
+	// Annotations are added to the values of every reported trial.
+	Annotations map[string]string
+	// OnTrialStart is an optional hook invoked before each trial is executed.
+	OnTrialStart func(*TrialAssignments)
+	// OnTrialFinish is an optional hook invoked with the values of each trial before they are reported.
+	OnTrialFinish func(*TrialAssignments, *TrialValues)
+}
+
+// Run executes trials obtained from the supplied "next trial" URL until the experiment is stopped.
+func (l *TrialLoop) Run(ctx context.Context, u string, f TrialFunc) error {
+	b := &trialBudget{max: l.MaxTrials}
+
+	n := l.Parallelism
+	if n <= 1 {
+		return l.run(ctx, u, f, b)
+	}
+
+	// Run multiple workers, the first error stops all of them
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.run(ctx, u, f, b); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// run is a single worker executing trials in sequence.
+func (l *TrialLoop) run(ctx context.Context, u string, f TrialFunc, b *trialBudget) error {
+	for b.claim() {
+		ta, err := l.API.NextTrial(ctx, u)
+		if err != nil {
+			b.release()
+
+			var apiErr *api.Error
+			if !errors.As(err, &apiErr) {
+				return err
+			}
+
+			switch apiErr.Type {
+			case ErrExperimentStopped:
+				return nil
+			case ErrTrialUnavailable:
+				if err := sleep(ctx, l.retryAfter(apiErr)); err != nil {
+					return err
+				}
+				continue
+			default:
+				return err
+			}
+		}
+
+		if err := l.RunTrial(ctx, &ta, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trialBudget tracks the number of trials started by the workers of a single run.
+type trialBudget struct {
+	mu      sync.Mutex
+	max     int
+	started int
+}
+
+// claim reserves one trial from the budget, returning false if the budget is exhausted.
+func (b *trialBudget) claim() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.max > 0 && b.started >= b.max {
+		return false
+	}
+	b.started++
+	return true
+}
+
+// release returns an unused trial to the budget.
+func (b *trialBudget) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.started--
+}
+
+// RunTrial executes a single trial and reports the result. If the trial does not complete within the
+// configured timeout, it is reported as failed and the function returns without waiting for it.
+func (l *TrialLoop) RunTrial(ctx context.Context, ta *TrialAssignments, f TrialFunc) error {
+	trialCtx, cancel := ctx, context.CancelFunc(func() {})
+	if l.TrialTimeout > 0 {
+		trialCtx, cancel = context.WithTimeout(ctx, l.TrialTimeout)
+	}
+	defer cancel()
+
+	type result struct {
+		vls TrialValues
+		err error
+	}
+
+	if l.OnTrialStart != nil {
+		l.OnTrialStart(ta)
+	}
+
+	// Run the trial asynchronously so a hung executor cannot block the loop
+	done := make(chan result, 1)
+	go func() {
+		vls, err := f(trialCtx, ta)
+		for i := 0; i < l.Retries && err != nil && trialCtx.Err() == nil; i++ {
+			vls, err = f(trialCtx, ta)
+		}
+		done <- result{vls: vls, err: err}
+	}()
+
+	var vls TrialValues
+	select {
+	case r := <-done:
+		vls = r.vls
+		if r.err != nil {
+			vls = TrialValues{
+				Failed:         true,
+				FailureReason:  FailureReasonError,
+				FailureMessage: r.err.Error(),
+			}
+		}
+
+	case <-trialCtx.Done():
+		// Do not treat a cancellation of the whole loop as a trial failure
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		vls = TrialValues{
+			Failed:         true,
+			FailureReason:  FailureReasonTimeout,
+			FailureMessage: fmt.Sprintf("trial did not complete within %s", l.TrialTimeout),
+		}
+	}
+
+	if len(l.Metrics) > 0 {
+		if err := NormalizeTrialValues(&vls, l.Metrics); err != nil {
+			// Report the values as a failure instead of recording them in the wrong unit
+			vls = TrialValues{
+				Failed:         true,
+				FailureReason:  FailureReasonError,
+				FailureMessage: err.Error(),
+			}
+		}
+	}
+
+	RoundTrialValues(&vls, l.Rounding)
+	vls.Annotate(l.Annotations)
+
+	if l.OnTrialFinish != nil {
+		l.OnTrialFinish(ta, &vls)
+	}
+
+	err := l.API.ReportTrial(ctx, ta.Location(), vls)
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.Type == ErrTrialNotFound {
+		// The trial is already gone (e.g. the server abandoned it), keep going
+		return nil
+	}
+	return err
+}
+
+func (l *TrialLoop) retryAfter(err *api.Error) time.Duration {
+	if err.RetryAfter > 0 {
+		return err.RetryAfter
+	}
+	if l.UnavailableDelay > 0 {
+		return l.UnavailableDelay
+	}
+	return 5 * time.Second
+}
+
+// sleep waits for the specified duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// trialLoopAPI serves a fixed number of trials and records the reported values.
+type trialLoopAPI struct {
+	API
+	mu       sync.Mutex
+	trials   int
+	reported []TrialValues
+}
+
+func (a *trialLoopAPI) NextTrial(context.Context, string) (TrialAssignments, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.trials == 0 {
+		return TrialAssignments{}, &api.Error{Type: ErrExperimentStopped}
+	}
+	a.trials--
+	ta := TrialAssignments{}
+	ta.Metadata = api.Metadata{"Location": []string{"trial"}}
+	return ta, nil
+}
+
+func (a *trialLoopAPI) ReportTrial(_ context.Context, _ string, vls TrialValues) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reported = append(a.reported, vls)
+	return nil
+}
+
+func TestTrialLoop_Run(t *testing.T) {
+	var calls int32
+	f := func(ctx context.Context, _ *TrialAssignments) (TrialValues, error) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			<-make(chan struct{}) // Hang forever, ignoring the context
+		case 2:
+			return TrialValues{}, errors.New("boom")
+		}
+		return TrialValues{Values: []Value{{MetricName: "m", Value: 1}}, Annotations: map[string]string{"commit": "abc"}}, nil
+	}
+
+	fake := &trialLoopAPI{trials: 3}
+	l := &TrialLoop{
+		API:          fake,
+		TrialTimeout: 10 * time.Millisecond,
+		Annotations:  map[string]string{"commit": "def", "cluster": "dev"},
+	}
+	require.NoError(t, l.Run(context.Background(), "next", f))
+
+	require.Len(t, fake.reported, 3)
+	assert.Equal(t, FailureReasonTimeout, fake.reported[0].FailureReason)
+	assert.True(t, fake.reported[0].Failed)
+	assert.Equal(t, FailureReasonError, fake.reported[1].FailureReason)
+	assert.Equal(t, "boom", fake.reported[1].FailureMessage)
+	assert.False(t, fake.reported[2].Failed)
+	assert.Equal(t, map[string]string{"cluster": "dev", "commit": "def"}, fake.reported[0].Annotations)
+	assert.Equal(t, map[string]string{"cluster": "dev", "commit": "abc"}, fake.reported[2].Annotations)
+}
+
+func TestTrialLoop_RunOptions(t *testing.T) {
+	cases := []struct {
+		desc          string
+		loop          TrialLoop
+		trials        int
+		failures      int32
+		expectedCalls int32
+		expectedRuns  int
+		expectFailed  int
+	}{
+		{
+			desc:          "parallel",
+			loop:          TrialLoop{Parallelism: 4},
+			trials:        10,
+			expectedCalls: 10,
+			expectedRuns:  10,
+		},
+		{
+			desc:          "retries",
+			loop:          TrialLoop{Retries: 2},
+			trials:        2,
+			failures:      2,
+			expectedCalls: 4,
+			expectedRuns:  2,
+		},
+		{
+			desc:          "retries exhausted",
+			loop:          TrialLoop{Retries: 1},
+			trials:        1,
+			failures:      5,
+			expectedCalls: 2,
+			expectedRuns:  1,
+			expectFailed:  1,
+		},
+		{
+			desc:          "max trials",
+			loop:          TrialLoop{Parallelism: 3, MaxTrials: 5},
+			trials:        10,
+			expectedCalls: 5,
+			expectedRuns:  5,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var calls, failures int32
+			f := func(context.Context, *TrialAssignments) (TrialValues, error) {
+				atomic.AddInt32(&calls, 1)
+				if atomic.AddInt32(&failures, 1) <= c.failures {
+					return TrialValues{}, errors.New("boom")
+				}
+				return TrialValues{}, nil
+			}
+
+			var started, finished int32
+			fake := &trialLoopAPI{trials: c.trials}
+			l := &TrialLoop{
+				API:           fake,
+				Parallelism:   c.loop.Parallelism,
+				Retries:       c.loop.Retries,
+				MaxTrials:     c.loop.MaxTrials,
+				OnTrialStart:  func(*TrialAssignments) { atomic.AddInt32(&started, 1) },
+				OnTrialFinish: func(*TrialAssignments, *TrialValues) { atomic.AddInt32(&finished, 1) },
+			}
+			require.NoError(t, l.Run(context.Background(), "next", f))
+
+			assert.Equal(t, c.expectedCalls, calls)
+			assert.Len(t, fake.reported, c.expectedRuns)
+			assert.Equal(t, int32(c.expectedRuns), started)
+			assert.Equal(t, int32(c.expectedRuns), finished)
+
+			failed := 0
+			for _, vls := range fake.reported {
+				if vls.Failed {
+					failed++
+				}
+			}
+			assert.Equal(t, c.expectFailed, failed)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// TypedParameter is a strongly typed parameter definition: one of
+// `*IntParameter`, `*DoubleParameter` or `*CategoricalParameter`.
+type TypedParameter interface {
+	// ParameterName returns the name of the parameter.
+	ParameterName() string
+	// Parameter returns the loosely typed representation used by the API.
+	Parameter() Parameter
+}
+
+// IntParameter is an integer parameter with inclusive bounds.
+type IntParameter struct {
+	Name string
+	Min  int64
+	Max  int64
+}
+
+// NewIntParameter returns a new integer parameter, an error is returned if
+// the parameter would be rejected by the server.
+func NewIntParameter(name string, min, max int64) (*IntParameter, error) {
+	p := &IntParameter{Name: name, Min: min, Max: max}
+	return p, validateTypedParameter(p)
+}
+
+func (p *IntParameter) ParameterName() string { return p.Name }
+
+func (p *IntParameter) Parameter() Parameter {
+	return Parameter{
+		Name: p.Name,
+		Type: ParameterTypeInteger,
+		Bounds: &Bounds{
+			Min: json.Number(strconv.FormatInt(p.Min, 10)),
+			Max: json.Number(strconv.FormatInt(p.Max, 10)),
+		},
+	}
+}
+
+// DoubleParameter is a floating point parameter with inclusive bounds.
+type DoubleParameter struct {
+	Name string
+	Min  float64
+	Max  float64
+}
+
+// NewDoubleParameter returns a new floating point parameter, an error is
+// returned if the parameter would be rejected by the server.
+func NewDoubleParameter(name string, min, max float64) (*DoubleParameter, error) {
+	p := &DoubleParameter{Name: name, Min: min, Max: max}
+	return p, validateTypedParameter(p)
+}
+
+func (p *DoubleParameter) ParameterName() string { return p.Name }
+
+func (p *DoubleParameter) Parameter() Parameter {
+	return Parameter{
+		Name: p.Name,
+		Type: ParameterTypeDouble,
+		Bounds: &Bounds{
+			Min: json.Number(strconv.FormatFloat(p.Min, 'g', -1, 64)),
+			Max: json.Number(strconv.FormatFloat(p.Max, 'g', -1, 64)),
+		},
+	}
+}
+
+// CategoricalParameter is a parameter with a discrete list of string values.
+type CategoricalParameter struct {
+	Name   string
+	Values []string
+}
+
+// NewCategoricalParameter returns a new categorical parameter, an error is
+// returned if the parameter would be rejected by the server.
+func NewCategoricalParameter(name string, values ...string) (*CategoricalParameter, error) {
+	p := &CategoricalParameter{Name: name, Values: values}
+	return p, validateTypedParameter(p)
+}
+
+func (p *CategoricalParameter) ParameterName() string { return p.Name }
+
+func (p *CategoricalParameter) Parameter() Parameter {
+	return Parameter{
+		Name:   p.Name,
+		Type:   ParameterTypeCategorical,
+		Values: append([]string(nil), p.Values...),
+	}
+}
+
+// Typed returns the strongly typed version of this parameter. An error is
+// returned if the parameter is not valid for its type.
+func (p *Parameter) Typed() (TypedParameter, error) {
+	var errs api.FieldErrorList
+	validateParameter(&errs, "parameter", p)
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	switch p.Type {
+	case ParameterTypeInteger:
+		min, _ := p.Bounds.Min.Int64()
+		max, _ := p.Bounds.Max.Int64()
+		return &IntParameter{Name: p.Name, Min: min, Max: max}, nil
+	case ParameterTypeDouble:
+		min, _ := p.Bounds.Min.Float64()
+		max, _ := p.Bounds.Max.Float64()
+		return &DoubleParameter{Name: p.Name, Min: min, Max: max}, nil
+	default:
+		return &CategoricalParameter{Name: p.Name, Values: append([]string(nil), p.Values...)}, nil
+	}
+}
+
+// TypedParameters returns the strongly typed parameters of the experiment.
+func (e *Experiment) TypedParameters() ([]TypedParameter, error) {
+	result := make([]TypedParameter, 0, len(e.Parameters))
+	for i := range e.Parameters {
+		tp, err := e.Parameters[i].Typed()
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter %q: %w", e.Parameters[i].Name, err)
+		}
+		result = append(result, tp)
+	}
+	return result, nil
+}
+
+// validateTypedParameter checks the parameter using the same rules as `Validate`.
+func validateTypedParameter(tp TypedParameter) error {
+	var errs api.FieldErrorList
+	p := tp.Parameter()
+	if p.Name == "" {
+		errs.Add("name", "name is required")
+	}
+	validateParameter(&errs, "parameter", &p)
+	return errs.Err()
+}
+
+// Get returns the assigned value of the named parameter.
+func (ta *TrialAssignments) Get(name string) (*api.NumberOrString, error) {
+	for i := range ta.Assignments {
+		if ta.Assignments[i].ParameterName == name {
+			return &ta.Assignments[i].Value, nil
+		}
+	}
+	return nil, fmt.Errorf("no assignment for parameter %q", name)
+}
+
+// GetInt returns the assigned value of the named integer parameter.
+func (ta *TrialAssignments) GetInt(name string) (int64, error) {
+	v, err := ta.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	i, err := v.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("invalid assignment for parameter %q: %w", name, err)
+	}
+	return i, nil
+}
+
+// GetFloat returns the assigned value of the named numeric parameter.
+func (ta *TrialAssignments) GetFloat(name string) (float64, error) {
+	v, err := ta.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	f, err := v.Float64()
+	if err != nil {
+		return 0, fmt.Errorf("invalid assignment for parameter %q: %w", name, err)
+	}
+	return f, nil
+}
+
+// GetString returns the assigned value of the named categorical parameter.
+func (ta *TrialAssignments) GetString(name string) (string, error) {
+	v, err := ta.Get(name)
+	if err != nil {
+		return "", err
+	}
+	if !v.IsString {
+		return "", fmt.Errorf("invalid assignment for parameter %q: value is not a string: %s", name, v.String())
+	}
+	return v.StrVal, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+func TestNewTypedParameters(t *testing.T) {
+	cases := []struct {
+		desc     string
+		newParam func() (TypedParameter, error)
+		expected Parameter
+		err      bool
+	}{
+		{
+			desc:     "int",
+			newParam: func() (TypedParameter, error) { return NewIntParameter("a", 1, 10) },
+			expected: Parameter{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+		},
+		{
+			desc:     "int invalid bounds",
+			newParam: func() (TypedParameter, error) { return NewIntParameter("a", 10, 10) },
+			err:      true,
+		},
+		{
+			desc:     "double",
+			newParam: func() (TypedParameter, error) { return NewDoubleParameter("b", 0.1, 2) },
+			expected: Parameter{Name: "b", Type: ParameterTypeDouble, Bounds: &Bounds{Min: "0.1", Max: "2"}},
+		},
+		{
+			desc:     "double missing name",
+			newParam: func() (TypedParameter, error) { return NewDoubleParameter("", 0.1, 2) },
+			err:      true,
+		},
+		{
+			desc:     "categorical",
+			newParam: func() (TypedParameter, error) { return NewCategoricalParameter("c", "x", "y") },
+			expected: Parameter{Name: "c", Type: ParameterTypeCategorical, Values: []string{"x", "y"}},
+		},
+		{
+			desc:     "categorical duplicate values",
+			newParam: func() (TypedParameter, error) { return NewCategoricalParameter("c", "x", "x") },
+			err:      true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			tp, err := c.newParam()
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				p := tp.Parameter()
+				assert.Equal(t, c.expected, p)
+
+				// Converting back must produce the same typed parameter
+				actual, err := p.Typed()
+				if assert.NoError(t, err) {
+					assert.Equal(t, tp, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestParameter_Typed(t *testing.T) {
+	cases := []struct {
+		desc      string
+		parameter Parameter
+		expected  TypedParameter
+		err       bool
+	}{
+		{
+			desc:      "int",
+			parameter: Parameter{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "-5", Max: "5"}},
+			expected:  &IntParameter{Name: "a", Min: -5, Max: 5},
+		},
+		{
+			desc:      "int with fractional bounds",
+			parameter: Parameter{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "0.5", Max: "5"}},
+			err:       true,
+		},
+		{
+			desc:      "double without bounds",
+			parameter: Parameter{Name: "b", Type: ParameterTypeDouble},
+			err:       true,
+		},
+		{
+			desc:      "categorical",
+			parameter: Parameter{Name: "c", Type: ParameterTypeCategorical, Values: []string{"x"}},
+			expected:  &CategoricalParameter{Name: "c", Values: []string{"x"}},
+		},
+		{
+			desc:      "unknown type",
+			parameter: Parameter{Name: "d", Type: "bool"},
+			err:       true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := c.parameter.Typed()
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTrialAssignments_Get(t *testing.T) {
+	ta := &TrialAssignments{
+		Assignments: []Assignment{
+			{ParameterName: "a", Value: api.FromInt64(5)},
+			{ParameterName: "b", Value: api.FromFloat64(0.5)},
+			{ParameterName: "c", Value: api.FromString("x")},
+		},
+	}
+
+	i, err := ta.GetInt("a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), i)
+
+	f, err := ta.GetFloat("b")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, f)
+
+	s, err := ta.GetString("c")
+	assert.NoError(t, err)
+	assert.Equal(t, "x", s)
+
+	_, err = ta.GetInt("b")
+	assert.Error(t, err)
+
+	_, err = ta.GetString("a")
+	assert.Error(t, err)
+
+	_, err = ta.GetInt("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// Validate checks the experiment definition for problems that would cause the
+// server to reject it. The result is an `api.FieldErrorList` describing each
+// problem, or nil if the experiment is valid.
+func Validate(exp *Experiment) error {
+	var errs api.FieldErrorList
+
+	// Index the parameters for checking constraint references
+	params := make(map[string]*Parameter, len(exp.Parameters))
+	if len(exp.Parameters) == 0 {
+		errs.Add("parameters", "at least one parameter is required")
+	}
+	for i := range exp.Parameters {
+		p := &exp.Parameters[i]
+		field := fmt.Sprintf("parameters[%d]", i)
+		if p.Name == "" {
+			errs.Add(field+".name", "name is required")
+		} else if _, ok := params[p.Name]; ok {
+			errs.Add(field+".name", "duplicate parameter name %q", p.Name)
+		} else {
+			params[p.Name] = p
+		}
+
+		validateParameter(&errs, field, p)
+	}
+
+	// Check the metrics
+	optimized := 0
+	names := make(map[string]struct{}, len(exp.Metrics))
+	if len(exp.Metrics) == 0 {
+		errs.Add("metrics", "at least one metric is required")
+	}
+	for i, m := range exp.Metrics {
+		field := fmt.Sprintf("metrics[%d]", i)
+		if m.Name == "" {
+			errs.Add(field+".name", "name is required")
+		} else if _, ok := names[m.Name]; ok {
+			errs.Add(field+".name", "duplicate metric name %q", m.Name)
+		} else {
+			names[m.Name] = struct{}{}
+		}
+
+		if m.Optimize == nil || *m.Optimize {
+			optimized++
+		}
+	}
+	if len(exp.Metrics) > 0 && optimized == 0 {
+		errs.Add("metrics", "at least one metric must be optimized")
+	}
+
+	// Check the constraints
+	for i := range exp.Constraints {
+		validateConstraint(&errs, fmt.Sprintf("constraints[%d]", i), &exp.Constraints[i], params)
+	}
+
+	return errs.Err()
+}
+
+// ValidateBaseline checks that the supplied baseline assignments include a
+// valid value for every parameter of the experiment.
+func ValidateBaseline(exp *Experiment, baseline []Assignment) error {
+	var errs api.FieldErrorList
+
+	values := make(map[string]*api.NumberOrString, len(baseline))
+	for i := range baseline {
+		values[baseline[i].ParameterName] = &baseline[i].Value
+	}
+
+	for i := range exp.Parameters {
+		p := &exp.Parameters[i]
+		if err := CheckParameterValue(p, values[p.Name]); err != nil {
+			errs.Add(fmt.Sprintf("baseline[%s]", p.Name), "%s", err.Error())
+		}
+		delete(values, p.Name)
+	}
+
+	for name := range values {
+		errs.Add(fmt.Sprintf("baseline[%s]", name), "unknown parameter")
+	}
+
+	if len(errs) == 0 {
+		if err := CheckParameterConstraints(baseline, exp.Constraints); err != nil {
+			errs.Add("baseline", "%s", err.Error())
+		}
+	}
+
+	return errs.Err()
+}
+
+func validateConstraint(errs *api.FieldErrorList, field string, c *Constraint, params map[string]*Parameter) {
+	checkRef := func(field, name string) {
+		p, ok := params[name]
+		switch {
+		case name == "":
+			errs.Add(field, "parameter name is required")
+		case !ok:
+			errs.Add(field, "unknown parameter %q", name)
+		case p.Type == ParameterTypeCategorical:
+			errs.Add(field, "categorical parameter %q cannot be constrained", name)
+		}
+	}
+
+	switch c.ConstraintType {
+	case ConstraintOrder:
+		if c.OrderConstraint == nil {
+			errs.Add(field, "missing order constraint")
+			return
+		}
+		checkRef(field+".lowerParameter", c.LowerParameter)
+		checkRef(field+".upperParameter", c.UpperParameter)
+		if c.LowerParameter != "" && c.LowerParameter == c.UpperParameter {
+			errs.Add(field, "lower and upper parameters must be different")
+		}
+
+	case ConstraintSum:
+		if c.SumConstraint == nil || len(c.SumConstraint.Parameters) == 0 {
+			errs.Add(field+".parameters", "at least one parameter is required")
+			return
+		}
+		for j, p := range c.SumConstraint.Parameters {
+			checkRef(fmt.Sprintf("%s.parameters[%d].parameterName", field, j), p.ParameterName)
+		}
+
+	default:
+		errs.Add(field+".constraintType", "unknown constraint type %q", c.ConstraintType)
+	}
+}
+
+func validateParameter(errs *api.FieldErrorList, field string, p *Parameter) {
+	switch p.Type {
+	case ParameterTypeInteger, ParameterTypeDouble:
+		if p.Bounds == nil {
+			errs.Add(field+".bounds", "bounds are required for %s parameters", p.Type)
+			return
+		}
+
+		min, err := p.Bounds.Min.Float64()
+		if err != nil {
+			errs.Add(field+".bounds.min", "numeric bound is required: %q", p.Bounds.Min)
+			return
+		}
+		max, err := p.Bounds.Max.Float64()
+		if err != nil {
+			errs.Add(field+".bounds.max", "numeric bound is required: %q", p.Bounds.Max)
+			return
+		}
+
+		if p.Type == ParameterTypeInteger {
+			if _, err := p.Bounds.Min.Int64(); err != nil {
+				errs.Add(field+".bounds.min", "integer bound is required: %s", p.Bounds.Min)
+			}
+			if _, err := p.Bounds.Max.Int64(); err != nil {
+				errs.Add(field+".bounds.max", "integer bound is required: %s", p.Bounds.Max)
+			}
+		}
+
+		if min >= max {
+			errs.Add(field+".bounds", "minimum (%s) must be less than maximum (%s)", p.Bounds.Min, p.Bounds.Max)
+		}
+
+		if len(p.Values) > 0 {
+			errs.Add(field+".values", "values are not allowed for %s parameters", p.Type)
+		}
+
+	case ParameterTypeCategorical:
+		if len(p.Values) == 0 {
+			errs.Add(field+".values", "at least one value is required for categorical parameters")
+		}
+		seen := make(map[string]struct{}, len(p.Values))
+		for j, v := range p.Values {
+			if _, ok := seen[v]; ok {
+				errs.Add(fmt.Sprintf("%s.values[%d]", field, j), "duplicate value %q", v)
+			}
+			seen[v] = struct{}{}
+		}
+
+		if p.Bounds != nil {
+			errs.Add(field+".bounds", "bounds are not allowed for categorical parameters")
+		}
+
+	default:
+		errs.Add(field+".type", "unknown parameter type %q", p.Type)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+func TestValidate(t *testing.T) {
+	falseValue := false
+	validParameters := []Parameter{
+		{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+		{Name: "b", Type: ParameterTypeDouble, Bounds: &Bounds{Min: "0.1", Max: "0.9"}},
+		{Name: "c", Type: ParameterTypeCategorical, Values: []string{"x", "y"}},
+	}
+	validMetrics := []Metric{
+		{Name: "cost", Minimize: true},
+	}
+
+	cases := []struct {
+		desc   string
+		exp    Experiment
+		fields []string
+	}{
+		{
+			desc:   "empty",
+			fields: []string{"parameters", "metrics"},
+		},
+		{
+			desc: "valid",
+			exp: Experiment{
+				Parameters: validParameters,
+				Metrics:    validMetrics,
+				Constraints: []Constraint{
+					{
+						ConstraintType:  ConstraintOrder,
+						OrderConstraint: &OrderConstraint{LowerParameter: "a", UpperParameter: "b"},
+					},
+				},
+			},
+		},
+		{
+			desc: "bad parameters",
+			exp: Experiment{
+				Parameters: []Parameter{
+					{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "10", Max: "1"}},
+					{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1.5", Max: "2"}},
+					{Name: "b", Type: ParameterTypeCategorical, Values: []string{"x", "x"}},
+					{Name: "c", Type: "foo"},
+				},
+				Metrics: validMetrics,
+			},
+			fields: []string{
+				"parameters[0].bounds",
+				"parameters[1].name",
+				"parameters[1].bounds.min",
+				"parameters[2].values[1]",
+				"parameters[3].type",
+			},
+		},
+		{
+			desc: "bad metrics",
+			exp: Experiment{
+				Parameters: validParameters,
+				Metrics: []Metric{
+					{Name: "cost", Optimize: &falseValue},
+					{Name: "cost", Optimize: &falseValue},
+				},
+			},
+			fields: []string{"metrics[1].name", "metrics"},
+		},
+		{
+			desc: "bad constraints",
+			exp: Experiment{
+				Parameters: validParameters,
+				Metrics:    validMetrics,
+				Constraints: []Constraint{
+					{
+						ConstraintType:  ConstraintOrder,
+						OrderConstraint: &OrderConstraint{LowerParameter: "a", UpperParameter: "z"},
+					},
+					{
+						ConstraintType: ConstraintSum,
+						SumConstraint: &SumConstraint{
+							Parameters: []SumConstraintParameter{{ParameterName: "c", Weight: 1}},
+						},
+					},
+				},
+			},
+			fields: []string{
+				"constraints[0].upperParameter",
+				"constraints[1].parameters[0].parameterName",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := Validate(&c.exp)
+			if len(c.fields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var errs api.FieldErrorList
+			if assert.True(t, errors.As(err, &errs), "expected field errors, got %v", err) {
+				var fields []string
+				for _, e := range errs {
+					fields = append(fields, e.Field)
+				}
+				assert.Equal(t, c.fields, fields)
+			}
+		})
+	}
+}
+
+func TestValidateBaseline(t *testing.T) {
+	exp := Experiment{
+		Parameters: []Parameter{
+			{Name: "a", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+			{Name: "b", Type: ParameterTypeInteger, Bounds: &Bounds{Min: "1", Max: "10"}},
+		},
+	}
+
+	assert.NoError(t, ValidateBaseline(&exp, []Assignment{
+		{ParameterName: "a", Value: api.FromInt64(1)},
+		{ParameterName: "b", Value: api.FromInt64(10)},
+	}))
+
+	assert.Error(t, ValidateBaseline(&exp, []Assignment{
+		{ParameterName: "a", Value: api.FromInt64(1)},
+	}))
+
+	assert.Error(t, ValidateBaseline(&exp, []Assignment{
+		{ParameterName: "a", Value: api.FromInt64(1)},
+		{ParameterName: "b", Value: api.FromInt64(11)},
+	}))
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// WaitForTrialOptions controls how a trial is polled for completion.
+type WaitForTrialOptions struct {
+	// Initial time between checks. Defaults to 5 seconds.
+	PollInterval time.Duration
+	// Maximum time between checks, the interval doubles after each check until
+	// this limit is reached. Defaults to 1 minute.
+	MaxPollInterval time.Duration
+	// Adjust each interval by a random amount up to this fraction of the
+	// interval. Defaults to 0.2.
+	JitterFactor float64
+}
+
+// WaitForTrialCompletion blocks until the trial is finished (i.e. it has been
+// completed, failed or abandoned) or until the context is done. The final state
+// of the trial is returned.
+func WaitForTrialCompletion(ctx context.Context, expAPI API, trialURL string, opts WaitForTrialOptions) (TrialItem, error) {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	maxInterval := opts.MaxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = 1 * time.Minute
+	}
+
+	jitterFactor := opts.JitterFactor
+	if jitterFactor <= 0 {
+		jitterFactor = 0.2
+	}
+
+	for {
+		t, err := expAPI.GetTrial(ctx, trialURL)
+		if err != nil {
+			return t, err
+		}
+
+		if t.Finished() {
+			return t, nil
+		}
+
+		jitter := time.Duration(rand.Float64() * jitterFactor * float64(interval))
+		if err := sleep(ctx, interval+jitter); err != nil {
+			return t, err
+		}
+
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
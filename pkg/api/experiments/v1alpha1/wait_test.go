@@ -0,0 +1,83 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitAPI returns a fixed sequence of trial states.
+type waitAPI struct {
+	API
+	statuses []TrialStatus
+	calls    int
+}
+
+func (a *waitAPI) GetTrial(context.Context, string) (TrialItem, error) {
+	s := a.statuses[a.calls]
+	if a.calls < len(a.statuses)-1 {
+		a.calls++
+	}
+	return TrialItem{Status: s}, nil
+}
+
+func TestWaitForTrialCompletion(t *testing.T) {
+	cases := []struct {
+		desc      string
+		statuses  []TrialStatus
+		expected  TrialStatus
+		expectErr bool
+	}{
+		{
+			desc:     "completed",
+			statuses: []TrialStatus{TrialActive, TrialActive, TrialCompleted},
+			expected: TrialCompleted,
+		},
+		{
+			desc:     "failed",
+			statuses: []TrialStatus{TrialStaged, TrialFailed},
+			expected: TrialFailed,
+		},
+		{
+			desc:      "timeout",
+			statuses:  []TrialStatus{TrialActive},
+			expected:  TrialActive,
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			ti, err := WaitForTrialCompletion(ctx, &waitAPI{statuses: c.statuses}, "trial", WaitForTrialOptions{
+				PollInterval:    time.Millisecond,
+				MaxPollInterval: 4 * time.Millisecond,
+			})
+			if c.expectErr {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.expected, ti.Status)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Extensions holds the JSON fields of a resource that are not known to this
+// client (e.g. fields added by a newer version of the API). Resources keep
+// their extensions so that updating a resource that was previously fetched
+// does not drop fields the client does not understand.
+type Extensions map[string]json.RawMessage
+
+// UnmarshalJSONWithExtensions decodes the JSON object into the supplied value
+// (which must not implement `json.Unmarshaler` itself, e.g. use a local type
+// definition) and returns the fields that do not correspond to any of the
+// value's struct fields. Field names are matched case-insensitively, the same
+// as `json.Unmarshal`; the "_metadata" field is never an extension since it is
+// handled by `UnmarshalJSON`.
+func UnmarshalJSONWithExtensions(data []byte, v interface{}) (Extensions, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	return ExtractExtensions(data, v)
+}
+
+// ExtractExtensions returns the fields of the JSON object that do not correspond
+// to any of the struct fields of the supplied value, without decoding anything
+// into the value itself.
+func ExtractExtensions(data []byte, v interface{}) (Extensions, error) {
+	// Only objects can have extension fields
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v))
+	var ext Extensions
+	for k, raw := range fields {
+		if k == "_metadata" || known[strings.ToLower(k)] {
+			continue
+		}
+		if ext == nil {
+			ext = make(Extensions)
+		}
+		ext[k] = raw
+	}
+	return ext, nil
+}
+
+// MarshalJSONWithExtensions encodes the supplied value (which must not implement
+// `json.Marshaler` itself) as a JSON object including the extension fields.
+// Known fields always take precedence over extension fields of the same name.
+func MarshalJSONWithExtensions(v interface{}, ext Extensions) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(ext) == 0 {
+		return data, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v))
+	for k, raw := range ext {
+		if known[strings.ToLower(k)] {
+			continue
+		}
+		fields[k] = raw
+	}
+	return json.Marshal(fields)
+}
+
+// jsonFieldCache holds the lower case JSON field names of each struct type.
+var jsonFieldCache sync.Map
+
+// jsonFieldNames returns the set of lower case JSON field names of a struct
+// type, including the fields of embedded structs.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if names, ok := jsonFieldCache.Load(t); ok {
+		return names.(map[string]bool)
+	}
+
+	names := make(map[string]bool)
+	if t.Kind() == reflect.Struct {
+		addJSONFieldNames(names, t)
+	}
+	jsonFieldCache.Store(t, names)
+	return names
+}
+
+func addJSONFieldNames(names map[string]bool, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" && f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addJSONFieldNames(names, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type extensionsEmbedded struct {
+	Kind string `json:"kind,omitempty"`
+}
+
+type extensionsThing struct {
+	extensionsEmbedded
+	Metadata   `json:"-"`
+	Name       string     `json:"name"`
+	Count      int        `json:"count,omitempty"`
+	Untagged   bool       `json:",omitempty"`
+	Extensions Extensions `json:"-"`
+}
+
+func TestUnmarshalJSONWithExtensions(t *testing.T) {
+	cases := []struct {
+		desc     string
+		data     string
+		expected Extensions
+		err      bool
+	}{
+		{
+			desc: "known fields",
+			data: `{"name":"a","count":1,"kind":"x","untagged":true}`,
+		},
+		{
+			desc: "case-insensitive",
+			data: `{"NAME":"a","Kind":"x"}`,
+		},
+		{
+			desc: "unknown fields",
+			data: `{"name":"a","newField":{"nested":[1,2]},"other":null}`,
+			expected: Extensions{
+				"newField": json.RawMessage(`{"nested":[1,2]}`),
+				"other":    json.RawMessage(`null`),
+			},
+		},
+		{
+			desc: "ignored fields are unknown",
+			data: `{"name":"a","Metadata":{},"extensions":1}`,
+			expected: Extensions{
+				"Metadata":   json.RawMessage(`{}`),
+				"extensions": json.RawMessage(`1`),
+			},
+		},
+		{
+			desc: "metadata",
+			data: `{"name":"a","_metadata":{"Link":"</a>; rel=self"}}`,
+		},
+		{
+			desc: "null",
+			data: `null`,
+		},
+		{
+			desc: "invalid",
+			data: `{"name":1}`,
+			err:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			v := extensionsThing{}
+			ext, err := UnmarshalJSONWithExtensions([]byte(c.data), &v)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, ext)
+			}
+		})
+	}
+}
+
+func TestMarshalJSONWithExtensions(t *testing.T) {
+	cases := []struct {
+		desc     string
+		value    extensionsThing
+		expected string
+	}{
+		{
+			desc:     "no extensions",
+			value:    extensionsThing{Name: "a"},
+			expected: `{"name":"a"}`,
+		},
+		{
+			desc: "extensions",
+			value: extensionsThing{Name: "a", Extensions: Extensions{
+				"newField": json.RawMessage(`{"nested":[1,2]}`),
+			}},
+			expected: `{"name":"a","newField":{"nested":[1,2]}}`,
+		},
+		{
+			desc: "known fields win",
+			value: extensionsThing{Name: "a", Extensions: Extensions{
+				"Name":  json.RawMessage(`"b"`),
+				"count": json.RawMessage(`2`),
+			}},
+			expected: `{"name":"a"}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			data, err := MarshalJSONWithExtensions(&c.value, c.value.Extensions)
+			if assert.NoError(t, err) {
+				assert.JSONEq(t, c.expected, string(data))
+			}
+		})
+	}
+}
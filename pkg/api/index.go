@@ -17,6 +17,7 @@ limitations under the License.
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"sort"
@@ -28,8 +29,33 @@ const (
 	ParamOffset        = "offset"
 	ParamLimit         = "limit"
 	ParamLabelSelector = "labelSelector"
+	ParamSearch        = "search"
+	ParamNames         = "names"
 )
 
+// ErrStopPaging may be returned from a PageFunc to stop iterating without an error.
+var ErrStopPaging = errors.New("stop paging")
+
+// PageFunc is invoked after each page of an index has been visited with the
+// zero-based page index, the number of items on the page and the URL of the
+// next page (empty on the last page). Returning an error stops the iteration.
+type PageFunc func(pageIndex, itemCount int, nextURL string) error
+
+// Next invokes the function (if it is not nil) and returns the URL of the next
+// page to visit, the URL is empty if iteration should stop.
+func (f PageFunc) Next(pageIndex, itemCount int, nextURL string) (string, error) {
+	if f == nil {
+		return nextURL, nil
+	}
+	if err := f(pageIndex, itemCount, nextURL); err != nil {
+		if errors.Is(err, ErrStopPaging) {
+			return "", nil
+		}
+		return "", err
+	}
+	return nextURL, nil
+}
+
 // IndexQuery represents the query parameter of an index resource.
 type IndexQuery map[string][]string
 
@@ -72,6 +98,33 @@ func (q *IndexQuery) SetLabelSelector(kv map[string]string) {
 	}
 }
 
+// SetSearch sets the free-text search used to filter the index, typically
+// matched against the name or title of the items.
+func (q *IndexQuery) SetSearch(text string) {
+	if *q == nil {
+		*q = IndexQuery{}
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		url.Values(*q).Set(ParamSearch, text)
+	} else {
+		url.Values(*q).Del(ParamSearch)
+	}
+}
+
+// SetNames restricts the index to the items with the supplied names, allowing
+// multiple items to be fetched in a single request. Servers that do not support
+// this parameter ignore it, so callers must check the names of the returned items.
+func (q *IndexQuery) SetNames(names ...string) {
+	if *q == nil {
+		*q = IndexQuery{}
+	}
+	if len(names) > 0 {
+		url.Values(*q).Set(ParamNames, strings.Join(names, ","))
+	} else {
+		url.Values(*q).Del(ParamNames)
+	}
+}
+
 // AppendToURL adds this index query to an existing URL.
 func (q *IndexQuery) AppendToURL(u string) (string, error) {
 	if q == nil || len(*q) == 0 {
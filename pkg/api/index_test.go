@@ -17,6 +17,7 @@ limitations under the License.
 package api
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,6 +49,26 @@ func TestIndexQuery_SetLabelSelector(t *testing.T) {
 	assert.Equal(t, []string{"application=my-app,scenario=cyber-monday", "best=true"}, q[ParamLabelSelector])
 }
 
+func TestIndexQuery_SetSearch(t *testing.T) {
+	q := IndexQuery{}
+
+	q.SetSearch(" payments ")
+	assert.Equal(t, []string{"payments"}, q[ParamSearch])
+
+	q.SetSearch("")
+	assert.NotContains(t, q, ParamSearch)
+}
+
+func TestIndexQuery_SetNames(t *testing.T) {
+	q := IndexQuery{}
+
+	q.SetNames("a", "b", "c")
+	assert.Equal(t, []string{"a,b,c"}, q[ParamNames])
+
+	q.SetNames()
+	assert.NotContains(t, q, ParamNames)
+}
+
 func TestIndexQuery_nil(t *testing.T) {
 	// Ensure the setter on a nil value allocates a map, otherwise embedding the
 	// IndexQuery will have unexpected results
@@ -125,3 +146,31 @@ func TestIndexQuery_AppendToURL(t *testing.T) {
 		})
 	}
 }
+
+func TestPageFunc_Next(t *testing.T) {
+	var nilFunc PageFunc
+	next, err := nilFunc.Next(0, 10, "next")
+	assert.NoError(t, err)
+	assert.Equal(t, "next", next)
+
+	budget := 15
+	f := PageFunc(func(_, itemCount int, _ string) error {
+		if budget -= itemCount; budget <= 0 {
+			return ErrStopPaging
+		}
+		return nil
+	})
+
+	next, err = f.Next(0, 10, "next")
+	assert.NoError(t, err)
+	assert.Equal(t, "next", next)
+
+	next, err = f.Next(1, 10, "next")
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+
+	boom := errors.New("boom")
+	next, err = PageFunc(func(int, int, string) error { return boom }).Next(0, 10, "next")
+	assert.ErrorIs(t, err, boom)
+	assert.Empty(t, next)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// unknownField is the name of a field injected into JSON objects to verify it is preserved.
+const unknownField = "zzUnknownField"
+
+// CheckJSONRoundTrip verifies that the JSON representation of an API type is
+// lossless: data which decodes into a new value (obtained from `newValue`) must
+// encode to a representation that decodes and encodes to identical bytes. When
+// `preserveUnknown` is set, an unknown field added to the data must also
+// survive the round trip. Data which cannot be decoded is not checked.
+func CheckJSONRoundTrip(data []byte, newValue func() interface{}, preserveUnknown bool) error {
+	var unknown json.RawMessage
+	if preserveUnknown {
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+			return nil
+		}
+		for k := range fields {
+			if bytes.EqualFold([]byte(k), []byte(unknownField)) {
+				delete(fields, k)
+			}
+		}
+		unknown = json.RawMessage(`{"list":[1,"two",null],"flag":true}`)
+		fields[unknownField] = unknown
+
+		var err error
+		if data, err = json.Marshal(fields); err != nil {
+			return nil
+		}
+	}
+
+	v1 := newValue()
+	if err := json.Unmarshal(data, v1); err != nil {
+		return nil
+	}
+	b1, err := json.Marshal(v1)
+	if err != nil {
+		return fmt.Errorf("unable to encode decoded value: %w", err)
+	}
+
+	v2 := newValue()
+	if err := json.Unmarshal(b1, v2); err != nil {
+		return fmt.Errorf("unable to decode encoded value %s: %w", b1, err)
+	}
+	b2, err := json.Marshal(v2)
+	if err != nil {
+		return fmt.Errorf("unable to re-encode value: %w", err)
+	}
+
+	if !bytes.Equal(b1, b2) {
+		return fmt.Errorf("encoding is not stable:\n%s\n%s", b1, b2)
+	}
+
+	if unknown != nil {
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(b1, &fields); err != nil {
+			return fmt.Errorf("encoded value is not an object: %s", b1)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, fields[unknownField]); err != nil || !bytes.Equal(compact.Bytes(), unknown) {
+			return fmt.Errorf("unknown field was not preserved: %s", b1)
+		}
+	}
+
+	return nil
+}
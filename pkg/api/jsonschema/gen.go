@@ -0,0 +1,60 @@
+//go:build ignore
+
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This program generates the JSON Schema documents for the API types.
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/api/jsonschema"
+)
+
+func main() {
+	// Keep this list in sync with TestGeneratedSchemas
+	types := map[string]interface{}{
+		"application":       applications.Application{},
+		"scenario":          applications.Scenario{},
+		"experiment":        experiments.Experiment{},
+		"trial-assignments": experiments.TrialAssignments{},
+		"trial-values":      experiments.TrialValues{},
+	}
+
+	if err := os.MkdirAll("schemas", 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	for name, v := range types {
+		s := jsonschema.Reflect(v)
+		s.ID = "https://stormforge.io/schemas/" + name + ".json"
+
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join("schemas", name+".json"), append(data, '\n'), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
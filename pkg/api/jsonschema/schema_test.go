@@ -0,0 +1,129 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestReflect(t *testing.T) {
+	s := Reflect(&experiments.Constraint{})
+	assert.Equal(t, "Constraint", s.Title)
+	assert.Equal(t, []string{"constraintType"}, s.Required)
+	assert.Contains(t, s.Properties, "bound")
+	assert.Contains(t, s.Properties, "lowerParameter")
+}
+
+func TestGeneratedSchemas(t *testing.T) {
+	// Keep this list in sync with gen.go
+	cases := []struct {
+		desc string
+		v    interface{}
+	}{
+		{desc: "application", v: applications.Application{}},
+		{desc: "scenario", v: applications.Scenario{}},
+		{desc: "experiment", v: experiments.Experiment{}},
+		{desc: "trial-assignments", v: experiments.TrialAssignments{}},
+		{desc: "trial-values", v: experiments.TrialValues{}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			s := Reflect(c.v)
+			s.ID = "https://stormforge.io/schemas/" + c.desc + ".json"
+			data, err := json.MarshalIndent(s, "", "  ")
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			golden, err := os.ReadFile(filepath.Join("schemas", c.desc+".json"))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, string(golden), string(append(data, '\n')), "schemas/%s.json is out of date, run go generate", c.desc)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		desc     string
+		schema   *Schema
+		data     string
+		expected []string
+	}{
+		{
+			desc:   "valid assignments",
+			schema: Reflect(experiments.TrialAssignments{}),
+			data:   `{"assignments":[{"parameterName":"a","value":1},{"parameterName":"b","value":"x"}]}`,
+		},
+		{
+			desc:     "missing required",
+			schema:   Reflect(experiments.TrialAssignments{}),
+			data:     `{"assignments":[{"value":1}]}`,
+			expected: []string{"assignments[0].parameterName: is required"},
+		},
+		{
+			desc:     "wrong type",
+			schema:   Reflect(experiments.TrialValues{}),
+			data:     `{"values":[{"metricName":"cost","value":"high"}],"failed":"no"}`,
+			expected: []string{"failed: expected boolean, got string", "values[0].value: expected number, got string"},
+		},
+		{
+			desc:     "invalid time",
+			schema:   Reflect(experiments.TrialValues{}),
+			data:     `{"startTime":"yesterday"}`,
+			expected: []string{`startTime: invalid date-time "yesterday"`},
+		},
+		{
+			desc:     "invalid one of",
+			schema:   Reflect(experiments.TrialAssignments{}),
+			data:     `{"assignments":[{"parameterName":"a","value":true}]}`,
+			expected: []string{"assignments[0].value: must match exactly one allowed schema"},
+		},
+		{
+			desc:   "any value",
+			schema: &Schema{},
+			data:   `[1, "two", null]`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := Validate(c.schema, []byte(c.data))
+			if len(c.expected) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var msgs []string
+			if el, ok := err.(api.FieldErrorList); assert.True(t, ok) {
+				for _, fe := range el {
+					msgs = append(msgs, fe.Error())
+				}
+			}
+			assert.Equal(t, c.expected, msgs)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// Validate checks the JSON document against the schema. The result is an
+// `api.FieldErrorList` describing each problem, or nil if the document is valid.
+func Validate(s *Schema, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	var errs api.FieldErrorList
+	validate(&errs, "", s, v)
+	return errs.Err()
+}
+
+// validate recursively checks the value against the schema.
+func validate(errs *api.FieldErrorList, field string, s *Schema, v interface{}) {
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, ss := range s.OneOf {
+			var el api.FieldErrorList
+			validate(&el, field, ss, v)
+			if len(el) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			errs.Add(field, "must match exactly one allowed schema")
+		}
+	}
+
+	if s.Type == "" {
+		return
+	}
+
+	if actual := typeOf(v); !typeMatches(s.Type, actual) {
+		errs.Add(field, "expected %s, got %s", s.Type, actual)
+		return
+	}
+
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := vv[name]; !ok {
+				errs.Add(join(field, name), "is required")
+			}
+		}
+
+		keys := make([]string, 0, len(vv))
+		for k := range vv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if ps, ok := s.Properties[k]; ok {
+				validate(errs, join(field, k), ps, vv[k])
+			} else if s.AdditionalProperties != nil {
+				validate(errs, join(field, k), s.AdditionalProperties, vv[k])
+			}
+		}
+
+	case []interface{}:
+		if s.Items != nil {
+			for i := range vv {
+				validate(errs, fmt.Sprintf("%s[%d]", field, i), s.Items, vv[i])
+			}
+		}
+
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, vv); err != nil {
+				errs.Add(field, "invalid date-time %q", vv)
+			}
+		}
+	}
+}
+
+// typeOf returns the JSON Schema type name of a decoded value.
+func typeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// typeMatches checks if the actual type satisfies the expected type, every integer is also a number.
+func typeMatches(expected, actual string) bool {
+	return expected == actual || (expected == "number" && actual == "integer")
+}
+
+// join returns the path to a named property.
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Link is a single link from a `Link` header (see RFC 8288).
+type Link struct {
+	// The target of the link, it may be relative to the request URL.
+	URL string
+	// The relation types of the link, as they appeared in the header.
+	Rel []string
+	// The other target attributes (e.g. "title" or "type") keyed by lower case
+	// name. Extended values (e.g. `title*=UTF-8''...`) are decoded and take
+	// precedence over the plain value of the same attribute.
+	Params map[string]string
+}
+
+// HasRel checks if the link has the supplied relation type, relation types are
+// compared case-insensitively after being canonicalized.
+func (l *Link) HasRel(rel string) bool {
+	rel = CanonicalLinkRelation(rel)
+	for _, r := range l.Rel {
+		if strings.EqualFold(CanonicalLinkRelation(r), rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the link formatted as a `Link` header value.
+func (l *Link) String() string {
+	var sb strings.Builder
+	sb.WriteString("<" + l.URL + ">")
+	if len(l.Rel) > 0 {
+		sb.WriteString("; rel=" + quoteLinkParam(strings.Join(l.Rel, " ")))
+	}
+
+	keys := make([]string, 0, len(l.Params))
+	for k := range l.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sb.WriteString("; " + k + "=" + quoteLinkParam(l.Params[k]))
+	}
+	return sb.String()
+}
+
+// ParseLinks parses a `Link` header value into the individual links. Parsing is
+// lenient: malformed links are skipped and reported using the returned error,
+// all the well-formed links are still returned.
+func ParseLinks(value string) ([]Link, error) {
+	p := &linkParser{s: value}
+
+	var links []Link
+	var firstErr error
+	for {
+		// Empty list elements are allowed (e.g. ", ,")
+		p.skip(" \t,")
+		if p.eof() {
+			break
+		}
+
+		start := p.pos
+		l, err := p.link()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("invalid link at offset %d: %w", start, err)
+			}
+			p.recover()
+			continue
+		}
+		links = append(links, l)
+	}
+	return links, firstErr
+}
+
+// linkParser is a scanner for `Link` header values.
+type linkParser struct {
+	s   string
+	pos int
+}
+
+func (p *linkParser) eof() bool { return p.pos >= len(p.s) }
+
+func (p *linkParser) peek() byte { return p.s[p.pos] }
+
+// skip advances past any of the supplied characters.
+func (p *linkParser) skip(chars string) {
+	for !p.eof() && strings.IndexByte(chars, p.peek()) >= 0 {
+		p.pos++
+	}
+}
+
+// recover advances to the end of the current link, ignoring commas in quoted strings or URLs.
+func (p *linkParser) recover() {
+	for inQuote, inURL := false, false; !p.eof(); p.pos++ {
+		switch c := p.peek(); {
+		case inQuote && c == '\\':
+			p.pos++
+		case c == '"' && !inURL:
+			inQuote = !inQuote
+		case c == '<' && !inQuote:
+			inURL = true
+		case c == '>' && !inQuote:
+			inURL = false
+		case c == ',' && !inQuote && !inURL:
+			return
+		}
+	}
+}
+
+// link parses a single link value.
+func (p *linkParser) link() (Link, error) {
+	l := Link{}
+	if p.peek() != '<' {
+		return l, fmt.Errorf("expected '<'")
+	}
+	end := strings.IndexByte(p.s[p.pos:], '>')
+	if end < 0 {
+		return l, fmt.Errorf("missing '>'")
+	}
+	l.URL = strings.TrimSpace(p.s[p.pos+1 : p.pos+end])
+	p.pos += end + 1
+
+	hasRel := false
+	extended := make(map[string]bool)
+	for {
+		p.skip(" \t")
+		if p.eof() || p.peek() == ',' {
+			return l, nil
+		}
+		if p.peek() != ';' {
+			return l, fmt.Errorf("expected ';' or ','")
+		}
+		p.pos++
+		p.skip(" \t")
+
+		// Tolerate a trailing (or repeated) semicolon
+		if p.eof() || p.peek() == ',' || p.peek() == ';' {
+			continue
+		}
+
+		name := strings.ToLower(p.token())
+		if name == "" {
+			return l, fmt.Errorf("expected parameter name")
+		}
+
+		var value string
+		p.skip(" \t")
+		if !p.eof() && p.peek() == '=' {
+			p.pos++
+			p.skip(" \t")
+			var err error
+			if value, err = p.value(); err != nil {
+				return l, err
+			}
+		}
+
+		// Extended values are percent-encoded with a character set and language
+		ext := strings.HasSuffix(name, "*")
+		if ext {
+			if name = strings.TrimRight(name, "*"); name == "" {
+				return l, fmt.Errorf("expected parameter name")
+			}
+			value = decodeExtValue(value)
+		}
+
+		switch {
+		case name == "rel":
+			// Occurrences after the first must be ignored
+			if !hasRel {
+				hasRel = true
+				if rels := strings.Fields(value); len(rels) > 0 {
+					l.Rel = rels
+				}
+			}
+		case ext && !extended[name]:
+			extended[name] = true
+			if l.Params == nil {
+				l.Params = make(map[string]string)
+			}
+			l.Params[name] = value
+		case !extended[name]:
+			if _, ok := l.Params[name]; ok {
+				continue
+			}
+			if l.Params == nil {
+				l.Params = make(map[string]string)
+			}
+			l.Params[name] = value
+		}
+	}
+}
+
+// token reads a parameter name.
+func (p *linkParser) token() string {
+	start := p.pos
+	for !p.eof() && strings.IndexByte("=;, \t\"<>", p.peek()) < 0 {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// value reads a quoted string or an unquoted parameter value. Unquoted values
+// are not restricted to token characters since (for example) gateways may strip
+// the quotes from URI relation types.
+func (p *linkParser) value() (string, error) {
+	if p.eof() || p.peek() != '"' {
+		start := p.pos
+		for !p.eof() && strings.IndexByte(";, \t", p.peek()) < 0 {
+			p.pos++
+		}
+		return p.s[start:p.pos], nil
+	}
+
+	var sb strings.Builder
+	for p.pos++; !p.eof(); p.pos++ {
+		switch c := p.peek(); c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			if p.pos++; p.eof() {
+				return "", fmt.Errorf("unterminated quoted string")
+			}
+			sb.WriteByte(p.peek())
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated quoted string")
+}
+
+// decodeExtValue decodes an RFC 8187 extended value (e.g. `UTF-8'en'%E2%82%AC`),
+// values that cannot be decoded are returned unchanged.
+func decodeExtValue(value string) string {
+	parts := strings.SplitN(value, "'", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[0], "UTF-8") {
+		return value
+	}
+	v, err := url.PathUnescape(parts[2])
+	if err != nil {
+		return value
+	}
+	return v
+}
+
+// quoteLinkParam returns the value as a quoted string.
+func quoteLinkParam(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(value) + `"`
+}
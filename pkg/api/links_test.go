@@ -0,0 +1,150 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinks(t *testing.T) {
+	cases := []struct {
+		desc     string
+		value    string
+		expected []Link
+		err      bool
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:     "simple",
+			value:    `</foo>; rel="next"`,
+			expected: []Link{{URL: "/foo", Rel: []string{"next"}}},
+		},
+		{
+			desc:  "multiple links",
+			value: `</a>;rel=prev, </b>;rel=next`,
+			expected: []Link{
+				{URL: "/a", Rel: []string{"prev"}},
+				{URL: "/b", Rel: []string{"next"}},
+			},
+		},
+		{
+			desc:     "multiple relation types",
+			value:    `</a>; rel="next  last"`,
+			expected: []Link{{URL: "/a", Rel: []string{"next", "last"}}},
+		},
+		{
+			desc:     "comma in URL",
+			value:    `</a?ids=1,2>; rel=self`,
+			expected: []Link{{URL: "/a?ids=1,2", Rel: []string{"self"}}},
+		},
+		{
+			desc:     "quoted parameters",
+			value:    `</a>; rel="item"; title="a, \"quoted\"; title"`,
+			expected: []Link{{URL: "/a", Rel: []string{"item"}, Params: map[string]string{"title": `a, "quoted"; title`}}},
+		},
+		{
+			desc:     "unquoted URI relation",
+			value:    `</a/scenarios/>;rel=https://stormforge.io/rel/scenarios`,
+			expected: []Link{{URL: "/a/scenarios/", Rel: []string{"https://stormforge.io/rel/scenarios"}}},
+		},
+		{
+			desc:     "first rel wins",
+			value:    `</a>; rel=next; REL=prev`,
+			expected: []Link{{URL: "/a", Rel: []string{"next"}}},
+		},
+		{
+			desc:     "extension parameters",
+			value:    `</a>; rel=alternate; type=text/html; hreflang=de; Anchor="#x"; crossorigin`,
+			expected: []Link{{URL: "/a", Rel: []string{"alternate"}, Params: map[string]string{"type": "text/html", "hreflang": "de", "anchor": "#x", "crossorigin": ""}}},
+		},
+		{
+			desc:     "extended value",
+			value:    `</a>; title="EUR"; title*=UTF-8'en'%E2%82%AC%20rates`,
+			expected: []Link{{URL: "/a", Params: map[string]string{"title": "€ rates"}}},
+		},
+		{
+			desc:     "white space and empty elements",
+			value:    ` ,  < /a > ;  rel = "next" ; , ,`,
+			expected: []Link{{URL: "/a", Rel: []string{"next"}}},
+		},
+		{
+			desc:     "malformed link",
+			value:    `/a; rel=prev, </b>; rel=next`,
+			expected: []Link{{URL: "/b", Rel: []string{"next"}}},
+			err:      true,
+		},
+		{
+			desc:  "unterminated quoted string",
+			value: `</a>; rel="next, </b>; rel=prev`,
+			err:   true,
+		},
+		{
+			desc:  "missing bracket",
+			value: `</a; rel=next`,
+			err:   true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			links, err := ParseLinks(c.value)
+			if c.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.expected, links)
+		})
+	}
+}
+
+func TestLink_HasRel(t *testing.T) {
+	l := Link{URL: "/a", Rel: []string{"Previous", "https://example.com/rel/Custom"}}
+	assert.True(t, l.HasRel(RelationPrev))
+	assert.True(t, l.HasRel("https://example.com/rel/custom"))
+	assert.False(t, l.HasRel(RelationNext))
+}
+
+func FuzzParseLinks(f *testing.F) {
+	for _, seed := range []string{
+		`</foo>; rel="next"`,
+		`</a>;rel=prev, </b>;rel=next`,
+		`</a?ids=1,2>; rel="self item"; title="a, \"b\""`,
+		`</a>; title*=UTF-8'en'%E2%82%AC; type=text/html`,
+		`<>;;rel=,<`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		links, _ := ParseLinks(value)
+
+		// Formatting the parsed links must produce a value that parses to the same links
+		formatted := make([]string, 0, len(links))
+		for i := range links {
+			formatted = append(formatted, links[i].String())
+		}
+		reparsed, err := ParseLinks(strings.Join(formatted, ", "))
+		if assert.NoError(t, err) {
+			assert.Equal(t, links, reparsed)
+		}
+	})
+}
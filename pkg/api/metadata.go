@@ -22,30 +22,53 @@ import (
 	"net/http"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	// Registered relations
 
-	RelationSelf      = "self"
-	RelationNext      = "next"
-	RelationPrev      = "prev"
-	RelationAlternate = "alternate"
-	RelationUp        = "up"
+	RelationSelf       = "self"
+	RelationNext       = "next"
+	RelationPrev       = "prev"
+	RelationFirst      = "first"
+	RelationLast       = "last"
+	RelationAlternate  = "alternate"
+	RelationUp         = "up"
+	RelationRelated    = "related"
+	RelationCollection = "collection"
+	RelationItem       = "item"
+	RelationEdit       = "edit"
 
 	// StormForge extension relations
 
-	RelationExperiments     = "https://stormforge.io/rel/experiments"
-	RelationLabels          = "https://stormforge.io/rel/labels"
-	RelationNextTrial       = "https://stormforge.io/rel/next-trial"
-	RelationRecommendations = "https://stormforge.io/rel/recommendations"
-	RelationScenarios       = "https://stormforge.io/rel/scenarios"
-	RelationTemplate        = "https://stormforge.io/rel/template"
-	RelationTrials          = "https://stormforge.io/rel/trials"
+	RelationActivity          = "https://stormforge.io/rel/activity"
+	RelationApplications      = "https://stormforge.io/rel/applications"
+	RelationClusters          = "https://stormforge.io/rel/clusters"
+	RelationExperiments       = "https://stormforge.io/rel/experiments"
+	RelationFeed              = "https://stormforge.io/rel/feed"
+	RelationLabels            = "https://stormforge.io/rel/labels"
+	RelationMachineManagement = "https://stormforge.io/rel/machine-management"
+	RelationNextTrial         = "https://stormforge.io/rel/next-trial"
+	RelationRecommendations   = "https://stormforge.io/rel/recommendations"
+	RelationScenarios         = "https://stormforge.io/rel/scenarios"
+	RelationTemplate          = "https://stormforge.io/rel/template"
+	RelationTrials            = "https://stormforge.io/rel/trials"
+	RelationWorkloads         = "https://stormforge.io/rel/workloads"
 )
 
+// HeaderOrganization is the request header used to select the organization
+// (tenant) that requests apply to when the caller has access to more than one.
+const HeaderOrganization = "Stormforge-Organization"
+
+// HeaderIdempotencyKey is the request header used to identify retries of the
+// same request so the server only applies it once.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
 // Metadata is used to hold single or multi-value metadata from list responses.
 type Metadata map[string][]string
 
@@ -57,45 +80,235 @@ func (m Metadata) Location() string {
 	return http.Header(m).Get("Location")
 }
 
+// ETag returns the entity tag identifying the version of the resource.
+func (m Metadata) ETag() string {
+	return http.Header(m).Get("ETag")
+}
+
+// Preconditions returns new metadata containing only the entity tag, it can be
+// supplied with a partial update so it only applies to this version of the resource.
+func (m Metadata) Preconditions() Metadata {
+	md := Metadata{}
+	if etag := m.ETag(); etag != "" {
+		http.Header(md).Set("ETag", etag)
+	}
+	return md
+}
+
+// LastModified returns the time the resource was last modified, or the zero
+// time if it is not available.
 func (m Metadata) LastModified() time.Time {
-	value, _ := http.ParseTime(http.Header(m).Get("Last-Modified"))
-	return value
+	return m.time("Last-Modified")
 }
 
-func (m Metadata) Link(rel string) string {
-	for _, rh := range http.Header(m).Values("Link") {
-		for _, h := range strings.Split(rh, ",") {
-			r, l := splitLink(h)
-			if strings.EqualFold(rel, r) {
-				return l
+// CreationTime returns the time the resource was created, or the zero time if
+// it is not available.
+func (m Metadata) CreationTime() time.Time {
+	return m.time("Creation-Time")
+}
+
+// TotalCount returns the total number of items in a list and a flag indicating
+// if the total is known. The count may exceed the number of items on a page.
+func (m Metadata) TotalCount() (int, bool) {
+	for _, k := range []string{"Total-Count", "X-Total-Count"} {
+		if v := http.Header(m).Get(k); v != "" {
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n >= 0 {
+				return n, true
 			}
 		}
 	}
+	return 0, false
+}
+
+// requestIDHeaders are the response headers used to identify a request on the
+// server, in order of preference.
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "Request-Id", "X-Amzn-Requestid", "X-Amzn-Trace-Id"}
+
+// RequestID returns the identifier assigned to the request by the server (or
+// a gateway in front of it), or an empty string if none was reported. The
+// identifier should be included when escalating problems to support.
+func (m Metadata) RequestID() string {
+	for _, k := range requestIDHeaders {
+		if v := strings.TrimSpace(http.Header(m).Get(k)); v != "" {
+			return v
+		}
+	}
 	return ""
 }
 
-func splitLink(value string) (rel, link string) {
-	for _, l := range strings.Split(value, ";") {
-		l = strings.Trim(l, " ")
-		if l == "" {
+// RateLimit is the request quota reported by the server.
+type RateLimit struct {
+	// The maximum number of requests allowed in the current window.
+	Limit int `json:"limit"`
+	// The number of requests remaining in the current window.
+	Remaining int `json:"remaining"`
+	// The time at which the current window resets, if known.
+	Reset *time.Time `json:"reset,omitempty"`
+}
+
+// RateLimit returns the request quota from the `X-RateLimit-*` (or the
+// standardized `RateLimit-*`) headers and a flag indicating if the server
+// reported a quota. The reset time may be either a Unix timestamp or a number
+// of seconds relative to the response date.
+func (m Metadata) RateLimit() (RateLimit, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		limit, ok := m.int(prefix + "Limit")
+		if !ok {
 			continue
 		}
 
-		if l[0] == '<' && l[len(l)-1] == '>' {
-			link = strings.Trim(l, "<>")
-			continue
+		rl := RateLimit{Limit: limit}
+		rl.Remaining, _ = m.int(prefix + "Remaining")
+		if reset, ok := m.int(prefix + "Reset"); ok {
+			var t time.Time
+			if reset > 1e9 {
+				t = time.Unix(int64(reset), 0)
+			} else {
+				t = m.time("Date")
+				if t.IsZero() {
+					t = time.Now()
+				}
+				t = t.Add(time.Duration(reset) * time.Second)
+			}
+			rl.Reset = &t
 		}
+		return rl, true
+	}
+	return RateLimit{}, false
+}
 
-		p := strings.SplitN(l, "=", 2)
-		if len(p) == 2 && strings.ToLower(p[0]) == "rel" {
-			rel = strings.Trim(p[1], "\"")
-			continue
+// Next returns the URL of the next page of a list, or an empty string on the last page.
+func (m Metadata) Next() string {
+	return m.Link(RelationNext)
+}
+
+// Prev returns the URL of the previous page of a list, or an empty string on the first page.
+func (m Metadata) Prev() string {
+	return m.Link(RelationPrev)
+}
+
+// Link returns the URL of the first link with the supplied relation.
+func (m Metadata) Link(rel string) string {
+	rel = strings.ToLower(CanonicalLinkRelation(rel))
+	for _, rh := range http.Header(m).Values("Link") {
+		if l, ok := parsedLinks(rh)[rel]; ok {
+			return l
 		}
 	}
+	return ""
+}
 
-	rel = CanonicalLinkRelation(rel)
+// Relations returns the sorted, lower case relation types of all the links.
+// Relation types are compared case-insensitively (per RFC 8288), so any of the
+// returned values (including unrecognized extension relations) can be passed
+// to `Link` to obtain the target URL.
+func (m Metadata) Relations() []string {
+	seen := make(map[string]struct{})
+	for _, rh := range http.Header(m).Values("Link") {
+		for rel := range parsedLinks(rh) {
+			seen[rel] = struct{}{}
+		}
+	}
 
-	return
+	rels := make([]string, 0, len(seen))
+	for rel := range seen {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	return rels
+}
+
+// int returns the parsed non-negative integer value of the specified key. Only
+// the first item of a list value (e.g. "100, 100;w=60") is considered.
+func (m Metadata) int(key string) (int, bool) {
+	v := http.Header(m).Get(key)
+	if v == "" {
+		return 0, false
+	}
+	v, _, _ = strings.Cut(v, ",")
+	v, _, _ = strings.Cut(v, ";")
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	return n, err == nil && n >= 0
+}
+
+// time returns the parsed HTTP date (or RFC 3339) value of the specified key.
+func (m Metadata) time(key string) time.Time {
+	v := http.Header(m).Get(key)
+	if v == "" {
+		return time.Time{}
+	}
+
+	if t, ok := metadataCache.Load(key, v); ok {
+		return t.(time.Time)
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		// Metadata from JSON representations may use RFC 3339 instead of HTTP dates
+		t, _ = time.Parse(time.RFC3339, v)
+	}
+	metadataCache.Store(key, v, t)
+	return t
+}
+
+// Links returns all the links from the `Link` headers, in order.
+func (m Metadata) Links() []Link {
+	var links []Link
+	for _, rh := range http.Header(m).Values("Link") {
+		l, _ := ParseLinks(rh)
+		links = append(links, l...)
+	}
+	return links
+}
+
+// parsedLinks returns a mapping of lower case relation to URL for a single
+// Link header value; the first link for each relation wins. A link with
+// multiple space separated relation types is included for each type.
+func parsedLinks(value string) map[string]string {
+	if links, ok := metadataCache.Load("Link", value); ok {
+		return links.(map[string]string)
+	}
+
+	links := make(map[string]string)
+	parsed, _ := ParseLinks(value)
+	for _, l := range parsed {
+		for _, r := range l.Rel {
+			r = strings.ToLower(CanonicalLinkRelation(r))
+			if _, ok := links[r]; !ok {
+				links[r] = l.URL
+			}
+		}
+	}
+	metadataCache.Store("Link", value, links)
+	return links
+}
+
+// metadataCache holds parsed metadata values so that repeatedly accessing the
+// same metadata (e.g. when sorting or formatting lists) does not re-parse it.
+var metadataCache = &parseCache{max: 1024}
+
+// parseCache is a bounded cache of parsed values keyed by the raw value.
+type parseCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[[2]string]interface{}
+}
+
+// Load returns the cached value for the supplied key and raw value.
+func (c *parseCache) Load(key, value string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.entries[[2]string{key, value}]
+	return v, ok
+}
+
+// Store caches a parsed value, the cache is reset once it fills up.
+func (c *parseCache) Store(key, value string, parsed interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil || len(c.entries) >= c.max {
+		c.entries = make(map[[2]string]interface{})
+	}
+	c.entries[[2]string{key, value}] = parsed
 }
 
 var linkURL = regexp.MustCompile("<[^>]+>")
@@ -181,6 +394,10 @@ func findMetadataField(rv reflect.Value) reflect.Value {
 			if result := findMetadataField(rv.Field(i)); result.IsValid() {
 				return result
 			}
+		} else if ft.Anonymous && ft.Type.Kind() == reflect.Ptr && ft.Type.Elem().Kind() == reflect.Struct && !rv.Field(i).IsNil() {
+			if result := findMetadataField(rv.Field(i)); result.IsValid() {
+				return result
+			}
 		}
 	}
 	return reflect.Value{}
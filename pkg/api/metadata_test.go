@@ -58,6 +58,191 @@ func TestMetadata(t *testing.T) {
 	assert.Equal(t, "/list?offset=10", md.Link(RelationNext))
 }
 
+func TestMetadata_Relations(t *testing.T) {
+	md := Metadata{
+		"Link": []string{
+			`</apps/a>;rel="self", </apps/a/scenarios/>;rel="https://stormforge.io/rel/scenarios"`,
+			`</apps/?offset=10>;rel="next last", </apps/?offset=0>;rel=Previous`,
+			`</custom>;rel="https://example.com/rel/Custom"`,
+		},
+	}
+
+	assert.Equal(t, []string{
+		"https://example.com/rel/custom",
+		"https://stormforge.io/rel/scenarios",
+		"last",
+		"next",
+		"prev",
+		"self",
+	}, md.Relations())
+
+	// Relation types are case-insensitive
+	assert.Equal(t, "/custom", md.Link("https://example.com/rel/CUSTOM"))
+	assert.Equal(t, "/apps/a/scenarios/", md.Link(RelationScenarios))
+
+	// Multiple relation types on a single link
+	assert.Equal(t, "/apps/?offset=10", md.Link(RelationLast))
+
+	// Previously accepted relation names are canonicalized
+	assert.Equal(t, "/apps/?offset=0", md.Link("previous"))
+}
+
+func TestMetadata_Accessors(t *testing.T) {
+	cases := []struct {
+		desc         string
+		md           Metadata
+		lastModified time.Time
+		creationTime time.Time
+		totalCount   int
+		hasTotal     bool
+		next         string
+		prev         string
+		requestID    string
+	}{
+		{
+			desc: "empty",
+			md:   Metadata{},
+		},
+		{
+			desc: "all values",
+			md: Metadata{
+				"Last-Modified": []string{"Tue, 03 Jan 2023 04:05:06 GMT"},
+				"Creation-Time": []string{"Mon, 02 Jan 2023 03:04:05 GMT"},
+				"Total-Count":   []string{"42"},
+				"Link":          []string{`</list?offset=0>;rel="previous",</list?offset=20>;rel="next"`},
+			},
+			lastModified: time.Date(2023, 1, 3, 4, 5, 6, 0, time.UTC),
+			creationTime: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			totalCount:   42,
+			hasTotal:     true,
+			next:         "/list?offset=20",
+			prev:         "/list?offset=0",
+		},
+		{
+			desc: "RFC 3339 times",
+			md: Metadata{
+				"Creation-Time": []string{"2023-01-02T03:04:05Z"},
+			},
+			creationTime: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			desc: "legacy total count",
+			md: Metadata{
+				"X-Total-Count": []string{"7"},
+			},
+			totalCount: 7,
+			hasTotal:   true,
+		},
+		{
+			desc: "request ID",
+			md: Metadata{
+				"X-Request-Id": []string{" abc123 "},
+			},
+			requestID: "abc123",
+		},
+		{
+			desc: "correlation ID",
+			md: Metadata{
+				"X-Correlation-Id": []string{"def456"},
+			},
+			requestID: "def456",
+		},
+		{
+			desc: "invalid values",
+			md: Metadata{
+				"Creation-Time": []string{"yesterday"},
+				"Total-Count":   []string{"-1"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			// Call twice to exercise the cache
+			for i := 0; i < 2; i++ {
+				assert.Equal(t, c.lastModified, c.md.LastModified())
+				assert.Equal(t, c.creationTime, c.md.CreationTime())
+				n, ok := c.md.TotalCount()
+				assert.Equal(t, c.totalCount, n)
+				assert.Equal(t, c.hasTotal, ok)
+				assert.Equal(t, c.next, c.md.Next())
+				assert.Equal(t, c.prev, c.md.Prev())
+				assert.Equal(t, c.requestID, c.md.RequestID())
+			}
+		})
+	}
+}
+
+func TestMetadata_RateLimit(t *testing.T) {
+	date := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	reset := date.Add(30 * time.Second)
+	epoch := time.Unix(1700000000, 0)
+
+	cases := []struct {
+		desc     string
+		md       Metadata
+		expected RateLimit
+		ok       bool
+	}{
+		{
+			desc: "none",
+			md:   Metadata{},
+		},
+		{
+			desc: "relative reset",
+			md: Metadata{
+				"Date":                  []string{"Mon, 02 Jan 2023 03:04:05 GMT"},
+				"X-Ratelimit-Limit":     []string{"100"},
+				"X-Ratelimit-Remaining": []string{"42"},
+				"X-Ratelimit-Reset":     []string{"30"},
+			},
+			expected: RateLimit{Limit: 100, Remaining: 42, Reset: &reset},
+			ok:       true,
+		},
+		{
+			desc: "epoch reset",
+			md: Metadata{
+				"X-Ratelimit-Limit":     []string{"100"},
+				"X-Ratelimit-Remaining": []string{"0"},
+				"X-Ratelimit-Reset":     []string{"1700000000"},
+			},
+			expected: RateLimit{Limit: 100, Reset: &epoch},
+			ok:       true,
+		},
+		{
+			desc: "standard headers",
+			md: Metadata{
+				"Ratelimit-Limit":     []string{"100, 100;w=60"},
+				"Ratelimit-Remaining": []string{"7"},
+			},
+			expected: RateLimit{Limit: 100, Remaining: 7},
+			ok:       true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rl, ok := c.md.RateLimit()
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.expected.Limit, rl.Limit)
+			assert.Equal(t, c.expected.Remaining, rl.Remaining)
+			if c.expected.Reset != nil && assert.NotNil(t, rl.Reset) {
+				assert.True(t, c.expected.Reset.Equal(*rl.Reset))
+			} else {
+				assert.Nil(t, rl.Reset)
+			}
+		})
+	}
+}
+
+func TestMetadata_Preconditions(t *testing.T) {
+	md := Metadata{}
+	http.Header(md).Set("ETag", `"v1"`)
+	http.Header(md).Set("Title", "Testing")
+
+	assert.Equal(t, `"v1"`, md.ETag())
+	assert.Equal(t, Metadata{"Etag": []string{`"v1"`}}, md.Preconditions())
+	assert.Equal(t, Metadata{}, Metadata{}.Preconditions())
+}
+
 func TestJsonMetadata_UnmarshalJSON(t *testing.T) {
 	// Verify last-entry-wins
 	data := []byte(`
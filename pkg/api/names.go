@@ -0,0 +1,121 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// NameOptions controls how multiple named resources are resolved.
+type NameOptions struct {
+	// The maximum number of names to resolve concurrently, values less than two
+	// resolve each name immediately before it is visited.
+	Parallelism int
+	// Visit every name that could be resolved, reporting all the failures as a
+	// `NameErrorList` instead of stopping at the first failure.
+	ContinueOnError bool
+}
+
+// NameError describes a failure to resolve or visit a specific named resource.
+type NameError struct {
+	// The name of the resource.
+	Name string
+	// The cause of the failure.
+	Err error
+}
+
+// Error returns the name and the cause of the failure.
+func (e *NameError) Error() string {
+	return e.Name + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause of the failure.
+func (e *NameError) Unwrap() error {
+	return e.Err
+}
+
+// NameErrorList is a collection of name errors.
+type NameErrorList []*NameError
+
+// Err returns nil for an empty list.
+func (el NameErrorList) Err() error {
+	if len(el) == 0 {
+		return nil
+	}
+	return el
+}
+
+// Error returns each of the name errors on a separate line.
+func (el NameErrorList) Error() string {
+	if len(el) == 0 {
+		panic("use NameErrorList.Err() to ignore an empty error list")
+	}
+
+	msgs := make([]string, 0, len(el))
+	for _, err := range el {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ForEachName resolves and visits each of the names in order. The resolve
+// function is invoked with the index of each name and may be called concurrently
+// (it should store its result by index); the visit function is always called
+// sequentially, in the order of the names, for each name that was resolved.
+func ForEachName(ctx context.Context, names []string, opts NameOptions, resolve func(ctx context.Context, i int) error, visit func(i int) error) error {
+	errs := make([]error, len(names))
+
+	if opts.Parallelism > 1 {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, opts.Parallelism)
+		for i := range names {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer func() { <-sem; wg.Done() }()
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					return
+				}
+				errs[i] = resolve(ctx, i)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	var result NameErrorList
+	for i := range names {
+		err := errs[i]
+		if opts.Parallelism <= 1 {
+			err = resolve(ctx, i)
+		}
+		if err == nil {
+			err = visit(i)
+		}
+		if err == nil {
+			continue
+		}
+
+		if !opts.ContinueOnError {
+			return err
+		}
+		result = append(result, &NameError{Name: names[i], Err: err})
+	}
+	return result.Err()
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachName(t *testing.T) {
+	errBad := errors.New("bad name")
+
+	cases := []struct {
+		desc     string
+		names    []string
+		opts     NameOptions
+		visited  []string
+		expected error
+	}{
+		{
+			desc:    "sequential",
+			names:   []string{"a", "b", "c"},
+			visited: []string{"a", "b", "c"},
+		},
+		{
+			desc:    "parallel",
+			names:   []string{"a", "b", "c", "d"},
+			opts:    NameOptions{Parallelism: 3},
+			visited: []string{"a", "b", "c", "d"},
+		},
+		{
+			desc:     "stop on error",
+			names:    []string{"a", "bad", "c"},
+			opts:     NameOptions{Parallelism: 2},
+			visited:  []string{"a"},
+			expected: errBad,
+		},
+		{
+			desc:     "continue on error",
+			names:    []string{"bad", "b", "bad", "d"},
+			opts:     NameOptions{Parallelism: 2, ContinueOnError: true},
+			visited:  []string{"b", "d"},
+			expected: NameErrorList{{Name: "bad", Err: errBad}, {Name: "bad", Err: errBad}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			resolved := make([]string, len(c.names))
+			var visited []string
+			var mu sync.Mutex
+			var active, maxActive int
+
+			err := ForEachName(context.Background(), c.names, c.opts,
+				func(_ context.Context, i int) error {
+					mu.Lock()
+					if active++; active > maxActive {
+						maxActive = active
+					}
+					mu.Unlock()
+					defer func() { mu.Lock(); active--; mu.Unlock() }()
+
+					if c.names[i] == "bad" {
+						return errBad
+					}
+					resolved[i] = c.names[i]
+					return nil
+				},
+				func(i int) error {
+					visited = append(visited, resolved[i])
+					return nil
+				})
+
+			assert.Equal(t, c.expected, err)
+			assert.Equal(t, c.visited, visited)
+			if c.opts.Parallelism > 0 {
+				assert.LessOrEqual(t, maxActive, c.opts.Parallelism)
+			}
+		})
+	}
+}
+
+func TestNameErrorList_Error(t *testing.T) {
+	err := NameErrorList{
+		{Name: "a", Err: &Error{Type: "application-not-found", Message: "not found"}},
+		{Name: "b", Err: errors.New("failed")},
+	}
+	assert.Equal(t, "a: not found\nb: failed", err.Error())
+	assert.True(t, IsNotFound(err[0]))
+}
@@ -18,6 +18,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"math"
 	"math/big"
 	"regexp"
@@ -98,6 +99,46 @@ func (s *NumberOrString) Float64Value() float64 {
 	return v
 }
 
+// Int64 explicitly converts the value to an int64, returning an error if the
+// value does not represent an integer.
+func (s *NumberOrString) Int64() (int64, error) {
+	if s.IsString {
+		v, err := strconv.ParseInt(s.StrVal, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value is not an integer: %q", s.StrVal)
+		}
+		return v, nil
+	}
+	v, err := s.NumVal.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("value is not an integer: %s", s.NumVal)
+	}
+	return v, nil
+}
+
+// Float64 explicitly converts the value to a float64, returning an error if
+// the value does not represent a number.
+func (s *NumberOrString) Float64() (float64, error) {
+	if s.IsString {
+		v, err := strconv.ParseFloat(s.StrVal, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value is not a number: %q", s.StrVal)
+		}
+		return v, nil
+	}
+	v, err := s.NumVal.Float64()
+	if err != nil {
+		return 0, fmt.Errorf("value is not a number: %s", s.NumVal)
+	}
+	return v, nil
+}
+
+// IsFinite returns true if the value is a number that is neither infinite nor NaN.
+func (s *NumberOrString) IsFinite() bool {
+	v, err := s.Float64()
+	return err == nil && !math.IsInf(v, 0) && !math.IsNaN(v)
+}
+
 // MarshalJSON writes the value with the appropriate type.
 func (s NumberOrString) MarshalJSON() ([]byte, error) {
 	if s.IsString {
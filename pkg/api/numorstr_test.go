@@ -187,6 +187,90 @@ func TestNumberOrString_Float64Value(t *testing.T) {
 	})
 }
 
+func TestNumberOrString_Int64(t *testing.T) {
+	cases := []struct {
+		desc     string
+		value    NumberOrString
+		expected int64
+		err      bool
+	}{
+		{
+			desc:  "string",
+			value: FromString("foobar"),
+			err:   true,
+		},
+		{
+			desc:     "integer",
+			value:    FromInt64(1),
+			expected: 1,
+		},
+		{
+			desc:  "float",
+			value: FromFloat64(1.1),
+			err:   true,
+		},
+		{
+			desc:     "numeric string",
+			value:    FromString("1"),
+			expected: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual, err := tc.value.Int64()
+			if tc.err {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNumberOrString_Float64(t *testing.T) {
+	cases := []struct {
+		desc     string
+		value    NumberOrString
+		expected float64
+		err      bool
+		finite   bool
+	}{
+		{
+			desc:  "string",
+			value: FromString("foobar"),
+			err:   true,
+		},
+		{
+			desc:     "integer",
+			value:    FromInt64(1),
+			expected: 1.0,
+			finite:   true,
+		},
+		{
+			desc:     "float",
+			value:    FromFloat64(1.1),
+			expected: 1.1,
+			finite:   true,
+		},
+		{
+			desc:     "infinity",
+			value:    FromFloat64(math.Inf(1)),
+			expected: math.Inf(1),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual, err := tc.value.Float64()
+			if tc.err {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, actual)
+			}
+			assert.Equal(t, tc.finite, tc.value.IsFinite())
+		})
+	}
+}
+
 func TestNumberOrString_MarshalJSON(t *testing.T) {
 	cases := []struct {
 		desc     string
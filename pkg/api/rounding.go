@@ -0,0 +1,127 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rounding describes how a numeric value is rounded before it is reported or displayed.
+type Rounding struct {
+	// Round to the nearest multiple of this increment, ignored if zero.
+	Increment float64
+	// Limit the value to this many significant digits, ignored if zero.
+	SignificantDigits int
+}
+
+// Round applies the rounding rules to the supplied value.
+func (r Rounding) Round(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+
+	if r.Increment > 0 {
+		v = math.Round(v/r.Increment) * r.Increment
+	}
+
+	digits := r.SignificantDigits
+	if digits <= 0 {
+		// Always trim the noise introduced by floating point multiplication
+		digits = 12
+	}
+	v, _ = strconv.ParseFloat(strconv.FormatFloat(v, 'g', digits, 64), 64)
+	return v
+}
+
+// RoundingPolicy maps names (e.g. metric names or resource names like "cpu" and "memory") to rounding rules.
+type RoundingPolicy map[string]Rounding
+
+// Round applies the rounding rules for the named value, values without rules are returned unchanged.
+func (p RoundingPolicy) Round(name string, v float64) float64 {
+	if r, ok := p[name]; ok {
+		return r.Round(v)
+	}
+	return v
+}
+
+// SetIncrement parses a quantity (e.g. "16Mi" or "5m") to use as the rounding increment of the named value.
+func (p RoundingPolicy) SetIncrement(name, quantity string) error {
+	inc, err := ParseQuantity(quantity)
+	if err != nil {
+		return err
+	}
+	if inc <= 0 {
+		return fmt.Errorf("rounding increment for %q must be positive: %s", name, quantity)
+	}
+
+	r := p[name]
+	r.Increment = inc
+	p[name] = r
+	return nil
+}
+
+// SetSignificantDigits sets the precision of the named value.
+func (p RoundingPolicy) SetSignificantDigits(name string, digits int) error {
+	if digits <= 0 {
+		return fmt.Errorf("significant digits for %q must be positive: %d", name, digits)
+	}
+
+	r := p[name]
+	r.SignificantDigits = digits
+	p[name] = r
+	return nil
+}
+
+// quantitySuffixes are the multipliers for the Kubernetes style quantity suffixes.
+var quantitySuffixes = map[string]float64{
+	"n":  1e-9,
+	"u":  1e-6,
+	"m":  1e-3,
+	"k":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"P":  1e15,
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+}
+
+// ParseQuantity parses a number with an optional Kubernetes style suffix (e.g. "5m" or "16Mi").
+func ParseQuantity(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	num := strings.TrimRightFunc(s, func(r rune) bool { return r >= 'A' && r <= 'z' })
+	mult := 1.0
+	if suffix := s[len(num):]; suffix != "" {
+		m, ok := quantitySuffixes[suffix]
+		if !ok {
+			return 0, fmt.Errorf("invalid quantity suffix: %s", s)
+		}
+		mult = m
+	}
+
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity: %s", s)
+	}
+	return v * mult, nil
+}
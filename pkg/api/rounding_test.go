@@ -0,0 +1,51 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundingPolicy_Round(t *testing.T) {
+	p := RoundingPolicy{}
+	require.NoError(t, p.SetIncrement("memory", "16Mi"))
+	require.NoError(t, p.SetIncrement("cpu", "5m"))
+	require.NoError(t, p.SetSignificantDigits("cost", 2))
+	require.Error(t, p.SetIncrement("bad", "16Xi"))
+	require.Error(t, p.SetSignificantDigits("bad", 0))
+
+	cases := []struct {
+		name     string
+		value    float64
+		expected float64
+	}{
+		{name: "memory", value: 100 * (1 << 20), expected: 96 * (1 << 20)},
+		{name: "memory", value: 120 * (1 << 20), expected: 128 * (1 << 20)},
+		{name: "cpu", value: 0.1234, expected: 0.125},
+		{name: "cpu", value: 0.3, expected: 0.3},
+		{name: "cost", value: 1234.5, expected: 1200},
+		{name: "other", value: 1234.5, expected: 1234.5},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, p.Round(c.name, c.value))
+		})
+	}
+}
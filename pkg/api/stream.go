@@ -0,0 +1,155 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// StreamingClient is implemented by clients which can return a response without
+// buffering the body, allowing large lists to be decoded as they arrive.
+type StreamingClient interface {
+	Client
+	// Stream sends an HTTP request and returns the response with an unread body,
+	// the caller is responsible for closing the body.
+	Stream(context.Context, *http.Request) (*http.Response, error)
+}
+
+// DecodeList incrementally decodes the items of the named array field from a
+// JSON object, invoking the supplied function for each item as it is read.
+// Other fields of the object are ignored.
+func DecodeList(r io.Reader, field string, f func(json.RawMessage) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if key != field {
+			var ignored json.RawMessage
+			if err := dec.Decode(&ignored); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Tolerate an explicit null instead of an empty list
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok == nil {
+			continue
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return fmt.Errorf("expected an array for %q", field)
+		}
+
+		for dec.More() {
+			var item json.RawMessage
+			if err := dec.Decode(&item); err != nil {
+				return err
+			}
+			if err := f(item); err != nil {
+				return err
+			}
+		}
+
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token and verifies it is the expected delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("invalid JSON, expected %q", delim)
+	}
+	return nil
+}
+
+// streamBody is a response body which releases the request context when it is
+// closed. If there is a timeout, each read must complete within it.
+type streamBody struct {
+	io.Reader
+	body    io.Closer
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+// Read reads from the response body, cancelling the request if the read does not complete in time.
+func (b *streamBody) Read(p []byte) (int, error) {
+	if b.timeout <= 0 {
+		return b.Reader.Read(p)
+	}
+
+	timer := time.AfterFunc(b.timeout, b.cancel)
+	n, err := b.Reader.Read(p)
+	if !timer.Stop() && err != nil && err != io.EOF {
+		err = context.DeadlineExceeded
+	}
+	return n, err
+}
+
+// Close closes the underlying response body.
+func (b *streamBody) Close() error {
+	defer b.cancel()
+	return b.body.Close()
+}
+
+// limitedReader is like `io.LimitedReader` except it fails with an error when
+// the limit is exceeded instead of reporting the end of the stream.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	err       error
+}
+
+// Read reads from the underlying reader, failing if more than the remaining bytes are available.
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err
+	}
+
+	// Read one byte past the limit to detect an oversized response
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), l.err
+	}
+	return n, err
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeList(t *testing.T) {
+	cases := []struct {
+		desc     string
+		data     string
+		expected []string
+		err      bool
+	}{
+		{
+			desc:     "items",
+			data:     `{"items":[{"a":1},{"b":2}]}`,
+			expected: []string{`{"a":1}`, `{"b":2}`},
+		},
+		{
+			desc:     "other fields",
+			data:     `{"before":{"x":[1,2]},"items":["a"],"after":null}`,
+			expected: []string{`"a"`},
+		},
+		{
+			desc: "null list",
+			data: `{"items":null}`,
+		},
+		{
+			desc: "missing list",
+			data: `{}`,
+		},
+		{
+			desc: "not an object",
+			data: `[]`,
+			err:  true,
+		},
+		{
+			desc: "not a list",
+			data: `{"items":{}}`,
+			err:  true,
+		},
+		{
+			desc:     "truncated",
+			data:     `{"items":[{"a":1},{"b"`,
+			expected: []string{`{"a":1}`},
+			err:      true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var actual []string
+			err := DecodeList(strings.NewReader(c.data), "items", func(item json.RawMessage) error {
+				actual = append(actual, string(item))
+				return nil
+			})
+			if c.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestHttpClient_MaxResponseSize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"items":["abcdefghij"]}`))
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		desc string
+		size int64
+		err  bool
+	}{
+		{desc: "unlimited"},
+		{desc: "exact", size: 24},
+		{desc: "too large", size: 23, err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			client, err := NewClient(srv.URL, nil, WithMaxResponseSize(c.size))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			_, _, err = client.Do(context.Background(), req)
+			assertTooLarge(t, c.err, err)
+
+			resp, err := client.(StreamingClient).Stream(context.Background(), req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			_, err = io.ReadAll(resp.Body)
+			assertTooLarge(t, c.err, err)
+		})
+	}
+}
+
+func assertTooLarge(t *testing.T, expected bool, err error) {
+	t.Helper()
+	if !expected {
+		assert.NoError(t, err)
+		return
+	}
+
+	var apiErr *Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, ErrTooLarge, apiErr.Type)
+	}
+}
+
+func TestHttpClient_StreamTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stalled" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+
+		flusher := w.(http.Flusher)
+		for i := 0; i < 3; i++ {
+			_, _ = w.Write([]byte("{}\n"))
+			flusher.Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		desc    string
+		path    string
+		consume time.Duration
+		err     bool
+	}{
+		{
+			desc:    "slow consumer",
+			path:    "/",
+			consume: 100 * time.Millisecond,
+		},
+		{
+			desc: "slow server",
+			path: "/stalled",
+			err:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			client, err := NewClient(srv.URL, nil, WithTimeout(50*time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+c.path, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			resp, err := client.(StreamingClient).Stream(context.Background(), req)
+			if c.err {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			dec := json.NewDecoder(resp.Body)
+			for i := 0; i < 3; i++ {
+				var v map[string]interface{}
+				if !assert.NoError(t, dec.Decode(&v)) {
+					return
+				}
+				time.Sleep(c.consume)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ReadOnlyTransport returns a round tripper that rejects any request using a
+// method which may modify server state. Rejected requests fail with an
+// `ErrReadOnly` error before they are sent.
+func ReadOnlyTransport(base http.RoundTripper) http.RoundTripper {
+	return &readOnlyTransport{Base: base}
+}
+
+type readOnlyTransport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip only allows safe methods through to the base transport.
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, "":
+	default:
+		// The round tripper contract requires we close the body
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, &Error{
+			Type:     ErrReadOnly,
+			Message:  fmt.Sprintf("read-only client cannot send %s requests", req.Method),
+			Location: req.URL.String(),
+		}
+	}
+
+	if t.Base != nil {
+		return t.Base.RoundTrip(req)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// DialerOptions control how network connections to the API server are established.
+type DialerOptions struct {
+	// The address ("host:port") of a DNS server to use instead of the system resolver.
+	Resolver string
+	// Restrict connections to a single address family, one of "ipv4" or "ipv6".
+	IPFamily string
+	// The maximum amount of time to wait for a connection to be established.
+	Timeout time.Duration
+}
+
+// IsZero returns true if the options do not change the default dialer behavior.
+func (o DialerOptions) IsZero() bool {
+	return o == DialerOptions{}
+}
+
+// DialContext returns a dial function that respects the options.
+func (o DialerOptions) DialContext() (func(ctx context.Context, network, address string) (net.Conn, error), error) {
+	d := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if o.Timeout > 0 {
+		d.Timeout = o.Timeout
+	}
+
+	if o.Resolver != "" {
+		resolver := o.Resolver
+		if _, _, err := net.SplitHostPort(resolver); err != nil {
+			resolver = net.JoinHostPort(resolver, "53")
+		}
+		d.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{Timeout: d.Timeout}).DialContext(ctx, network, resolver)
+			},
+		}
+	}
+
+	var suffix string
+	switch o.IPFamily {
+	case "":
+	case "ipv4", "4":
+		suffix = "4"
+	case "ipv6", "6":
+		suffix = "6"
+	default:
+		return nil, fmt.Errorf("invalid IP family %q, expected ipv4 or ipv6", o.IPFamily)
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		// Only constrain the generic networks, e.g. "tcp" becomes "tcp4"
+		if suffix != "" && (network == "tcp" || network == "udp") {
+			network += suffix
+		}
+		return d.DialContext(ctx, network, address)
+	}, nil
+}
+
+// DialerTransport returns a copy of the base transport that uses the dialer
+// options. If the base is nil, the default transport is used; any other base
+// that is not an `*http.Transport` cannot be customized and results in an error.
+func DialerTransport(base http.RoundTripper, opts DialerOptions) (http.RoundTripper, error) {
+	if opts.IsZero() {
+		if base == nil {
+			return http.DefaultTransport, nil
+		}
+		return base, nil
+	}
+
+	t, err := cloneTransport(base, "dialer options")
+	if err != nil {
+		return nil, err
+	}
+
+	t.DialContext, err = opts.DialContext()
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// FIPSTLSConfig returns a TLS configuration restricted to FIPS 140 approved
+// protocol versions, cipher suites and curves.
+func FIPSTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// TLS 1.3 suites are not configurable and include ChaCha20-Poly1305
+		MaxVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		},
+		CurvePreferences: []tls.CurveID{
+			tls.CurveP256,
+			tls.CurveP384,
+			tls.CurveP521,
+		},
+	}
+}
+
+// FIPSTransport returns a copy of the base transport that only negotiates FIPS
+// approved TLS connections. If the base is nil, the default transport is used;
+// any other base that is not an `*http.Transport` results in an error.
+func FIPSTransport(base http.RoundTripper) (http.RoundTripper, error) {
+	t, err := cloneTransport(base, "FIPS TLS configuration")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := FIPSTLSConfig()
+	if t.TLSClientConfig != nil {
+		// Preserve the trust and identity settings of the base transport
+		cfg.RootCAs = t.TLSClientConfig.RootCAs
+		cfg.Certificates = t.TLSClientConfig.Certificates
+		cfg.ServerName = t.TLSClientConfig.ServerName
+	}
+
+	t.TLSClientConfig = cfg
+	t.ForceAttemptHTTP2 = true
+	return t, nil
+}
+
+// TLSOptions control the trust and identity used for TLS connections to the API
+// server. Each value may be either PEM encoded or the path to a PEM encoded file.
+type TLSOptions struct {
+	// Additional certificate authorities trusted when verifying the server.
+	CABundle string
+	// The client certificate presented to servers requiring mutual TLS.
+	ClientCertificate string
+	// The private key of the client certificate.
+	ClientKey string
+}
+
+// IsZero returns true if the options do not change the default TLS behavior.
+func (o TLSOptions) IsZero() bool {
+	return o == TLSOptions{}
+}
+
+// TLSTransport returns a copy of the base transport that uses the TLS options.
+// If the base is nil, the default transport is used; any other base that is
+// not an `*http.Transport` cannot be customized and results in an error.
+func TLSTransport(base http.RoundTripper, opts TLSOptions) (http.RoundTripper, error) {
+	if opts.IsZero() {
+		if base == nil {
+			return http.DefaultTransport, nil
+		}
+		return base, nil
+	}
+
+	t, err := cloneTransport(base, "TLS options")
+	if err != nil {
+		return nil, err
+	}
+
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if opts.CABundle != "" {
+		data, err := readPEM(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+		}
+
+		pool := t.TLSClientConfig.RootCAs
+		if pool == nil {
+			if pool, err = x509.SystemCertPool(); err != nil {
+				pool = x509.NewCertPool()
+			}
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA bundle does not contain any certificates")
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+
+	if opts.ClientCertificate != "" || opts.ClientKey != "" {
+		certPEM, err := readPEM(opts.ClientCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client certificate: %w", err)
+		}
+		keyPEM, err := readPEM(opts.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client key: %w", err)
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		t.TLSClientConfig.Certificates = append(t.TLSClientConfig.Certificates, cert)
+	}
+
+	return t, nil
+}
+
+// readPEM returns PEM encoded data from either the supplied value or the file it names.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN ") {
+		return []byte(value), nil
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	return os.ReadFile(value)
+}
+
+// cloneTransport returns a copy of the base transport so it can be customized.
+func cloneTransport(base http.RoundTripper, what string) (*http.Transport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unable to apply %s to %T", what, base)
+	}
+	return t.Clone(), nil
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlyTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, ReadOnlyTransport(nil))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cases := []struct {
+		method  string
+		allowed bool
+	}{
+		{method: http.MethodGet, allowed: true},
+		{method: http.MethodHead, allowed: true},
+		{method: http.MethodPost},
+		{method: http.MethodPut},
+		{method: http.MethodPatch},
+		{method: http.MethodDelete},
+	}
+	for _, c := range cases {
+		t.Run(c.method, func(t *testing.T) {
+			req, err := http.NewRequest(c.method, client.URL("test").String(), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			_, _, err = client.Do(context.Background(), req)
+			if c.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, IsReadOnly(err), "expected read-only error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDialerTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		desc      string
+		base      http.RoundTripper
+		opts      DialerOptions
+		expectErr bool
+		dialErr   bool
+	}{
+		{
+			desc: "default",
+		},
+		{
+			desc: "ipv4",
+			opts: DialerOptions{IPFamily: "ipv4", Timeout: time.Second},
+		},
+		{
+			desc:    "ipv6",
+			opts:    DialerOptions{IPFamily: "ipv6", Timeout: time.Second},
+			dialErr: true,
+		},
+		{
+			desc:      "invalid family",
+			opts:      DialerOptions{IPFamily: "ipx"},
+			expectErr: true,
+		},
+		{
+			desc:      "unsupported base",
+			base:      ReadOnlyTransport(nil),
+			opts:      DialerOptions{Timeout: time.Second},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rt, err := DialerTransport(c.base, c.opts)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			client, err := NewClient(srv.URL, rt)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			req, err := http.NewRequest(http.MethodGet, client.URL("test").String(), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			_, _, err = client.Do(context.Background(), req)
+			if c.dialErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFIPSTransport(t *testing.T) {
+	cases := []struct {
+		desc      string
+		serverTLS *tls.Config
+		expectErr bool
+	}{
+		{
+			desc: "approved",
+		},
+		{
+			desc: "chacha only",
+			serverTLS: &tls.Config{
+				MaxVersion:   tls.VersionTLS12,
+				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256},
+			},
+			expectErr: true,
+		},
+		{
+			desc:      "tls 1.3 only",
+			serverTLS: &tls.Config{MinVersion: tls.VersionTLS13},
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if assert.NotNil(t, r.TLS) {
+					assert.Contains(t, FIPSTLSConfig().CipherSuites, r.TLS.CipherSuite)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			srv.TLS = c.serverTLS
+			srv.StartTLS()
+			defer srv.Close()
+
+			// Use the test server transport to trust the test certificate
+			rt, err := FIPSTransport(srv.Client().Transport)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			client, err := NewClient(srv.URL, rt)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			req, err := http.NewRequest(http.MethodGet, client.URL("test").String(), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			_, _, err = client.Do(context.Background(), req)
+			if c.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	_, err := FIPSTransport(ReadOnlyTransport(nil))
+	assert.Error(t, err)
+}
+
+func TestTLSTransport(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// Re-use the test server certificate as both the CA and the client certificate
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+	key, err := x509.MarshalPKCS8PrivateKey(srv.TLS.Certificates[0].PrivateKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}))
+
+	cases := []struct {
+		desc       string
+		opts       []ClientOption
+		statusCode int
+		doErr      bool
+		clientErr  bool
+	}{
+		{
+			desc:  "untrusted",
+			doErr: true,
+		},
+		{
+			desc:       "trusted",
+			opts:       []ClientOption{WithCABundle(caPEM)},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			desc:       "client certificate",
+			opts:       []ClientOption{WithCABundle(caPEM), WithClientCertificate(caPEM, keyPEM)},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			desc:      "missing CA bundle",
+			opts:      []ClientOption{WithCABundle("testdata/missing.pem")},
+			clientErr: true,
+		},
+		{
+			desc:      "missing key",
+			opts:      []ClientOption{WithClientCertificate(caPEM, "")},
+			clientErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rt := http.DefaultTransport.(*http.Transport).Clone()
+			client, err := NewClient(srv.URL, rt, c.opts...)
+			if c.clientErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			req, err := http.NewRequest(http.MethodGet, client.URL("test").String(), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			resp, _, err := client.Do(context.Background(), req)
+			if c.doErr {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, c.statusCode, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewULID(t *testing.T) {
+	// Example timestamp from the ULID specification
+	ts := time.UnixMilli(1469922850259)
+
+	id, err := NewULID(ts)
+	if assert.NoError(t, err) {
+		assert.Len(t, id, 26)
+		assert.Equal(t, "01ARZ3NDEK", id[:10])
+	}
+
+	later, err := NewULID(ts.Add(time.Millisecond))
+	if assert.NoError(t, err) {
+		assert.Less(t, id, later)
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unit is the unit of measure of a numeric value.
+type Unit string
+
+const (
+	UnitMilliseconds    Unit = "ms"
+	UnitSeconds         Unit = "s"
+	UnitMinutes         Unit = "min"
+	UnitMillicores      Unit = "millicores"
+	UnitCores           Unit = "cores"
+	UnitBytes           Unit = "B"
+	UnitKibibytes       Unit = "KiB"
+	UnitMebibytes       Unit = "MiB"
+	UnitGibibytes       Unit = "GiB"
+	UnitDollarsPerHour  Unit = "$/hour"
+	UnitDollarsPerMonth Unit = "$/month"
+)
+
+// hoursPerMonth is the average number of hours in a month used to convert hourly costs.
+const hoursPerMonth = 730
+
+// unitInfo is the dimension of a unit and its scale relative to the base unit of that dimension.
+type unitInfo struct {
+	dimension string
+	scale     float64
+}
+
+// units are the known units.
+var units = map[Unit]unitInfo{
+	UnitMilliseconds:    {dimension: "time", scale: 1e-3},
+	UnitSeconds:         {dimension: "time", scale: 1},
+	UnitMinutes:         {dimension: "time", scale: 60},
+	UnitMillicores:      {dimension: "cpu", scale: 1e-3},
+	UnitCores:           {dimension: "cpu", scale: 1},
+	UnitBytes:           {dimension: "memory", scale: 1},
+	UnitKibibytes:       {dimension: "memory", scale: 1 << 10},
+	UnitMebibytes:       {dimension: "memory", scale: 1 << 20},
+	UnitGibibytes:       {dimension: "memory", scale: 1 << 30},
+	UnitDollarsPerHour:  {dimension: "cost", scale: hoursPerMonth},
+	UnitDollarsPerMonth: {dimension: "cost", scale: 1},
+}
+
+// unitAliases are alternate spellings of the known units.
+var unitAliases = map[string]Unit{
+	"millisecond":  UnitMilliseconds,
+	"milliseconds": UnitMilliseconds,
+	"sec":          UnitSeconds,
+	"second":       UnitSeconds,
+	"seconds":      UnitSeconds,
+	"m":            UnitMillicores,
+	"millicore":    UnitMillicores,
+	"core":         UnitCores,
+	"cpu":          UnitCores,
+	"bytes":        UnitBytes,
+	"ki":           UnitKibibytes,
+	"mi":           UnitMebibytes,
+	"gi":           UnitGibibytes,
+	"$/hr":         UnitDollarsPerHour,
+	"$/mo":         UnitDollarsPerMonth,
+}
+
+// ParseUnit returns the unit with the supplied name or alias.
+func ParseUnit(s string) (Unit, error) {
+	s = strings.TrimSpace(s)
+	if _, ok := units[Unit(s)]; ok || s == "" {
+		return Unit(s), nil
+	}
+	for u := range units {
+		if strings.EqualFold(string(u), s) {
+			return u, nil
+		}
+	}
+	if u, ok := unitAliases[strings.ToLower(s)]; ok {
+		return u, nil
+	}
+	return "", fmt.Errorf("unknown unit: %s", s)
+}
+
+// Compatible checks if values of this unit can be converted to the other unit.
+// The empty unit is only compatible with itself.
+func (u Unit) Compatible(other Unit) bool {
+	if u == other {
+		return true
+	}
+	ui, ok := units[u]
+	oi, ook := units[other]
+	return ok && ook && ui.dimension == oi.dimension
+}
+
+// Convert converts a value of this unit to the other unit.
+func (u Unit) Convert(v float64, to Unit) (float64, error) {
+	if u == to {
+		return v, nil
+	}
+	if !u.Compatible(to) {
+		return 0, fmt.Errorf("cannot convert %s to %s", u.name(), to.name())
+	}
+	return v * units[u].scale / units[to].scale, nil
+}
+
+// Format returns the value formatted with this unit.
+func (u Unit) Format(v float64) string {
+	num := strconv.FormatFloat(v, 'f', -1, 64)
+	switch {
+	case u == "":
+		return num
+	case strings.HasPrefix(string(u), "$"):
+		return "$" + num + string(u[1:])
+	case u == UnitCores || u == UnitMillicores:
+		return num + " " + string(u)
+	default:
+		return num + string(u)
+	}
+}
+
+// name returns a description of the unit for error messages.
+func (u Unit) name() string {
+	if u == "" {
+		return "a unitless value"
+	}
+	return string(u)
+}
+
+// ParseValueWithUnit parses a number followed by an optional unit (e.g. "250ms",
+// "1.5 cores", "512MiB" or "$12.50/month").
+func ParseValueWithUnit(s string) (float64, Unit, error) {
+	s = strings.TrimSpace(s)
+
+	// Costs carry the currency symbol in front of the number
+	prefix := ""
+	if strings.HasPrefix(s, "$") {
+		prefix, s = "$", strings.TrimSpace(s[1:])
+	}
+
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '.' && r != '-' && r != '+' && r != 'e' && r != 'E'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	// Do not mistake the start of a unit (e.g. "5ms") for an exponent
+	for i > 0 && (s[i-1] == 'e' || s[i-1] == 'E') {
+		i--
+	}
+
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid value: %s", prefix+s)
+	}
+
+	u, err := ParseUnit(prefix + strings.TrimSpace(s[i:]))
+	if err != nil {
+		return 0, "", err
+	}
+	return v, u, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseValueWithUnit(t *testing.T) {
+	cases := []struct {
+		desc          string
+		input         string
+		expectedValue float64
+		expectedUnit  Unit
+		invalid       bool
+	}{
+		{desc: "plain", input: "42", expectedValue: 42},
+		{desc: "milliseconds", input: "250ms", expectedValue: 250, expectedUnit: UnitMilliseconds},
+		{desc: "seconds", input: "1.5 s", expectedValue: 1.5, expectedUnit: UnitSeconds},
+		{desc: "exponent", input: "1e3ms", expectedValue: 1000, expectedUnit: UnitMilliseconds},
+		{desc: "cores", input: "1.5 cores", expectedValue: 1.5, expectedUnit: UnitCores},
+		{desc: "millicores", input: "500m", expectedValue: 500, expectedUnit: UnitMillicores},
+		{desc: "mebibytes", input: "512MiB", expectedValue: 512, expectedUnit: UnitMebibytes},
+		{desc: "case insensitive", input: "512mib", expectedValue: 512, expectedUnit: UnitMebibytes},
+		{desc: "monthly cost", input: "$12.50/month", expectedValue: 12.5, expectedUnit: UnitDollarsPerMonth},
+		{desc: "hourly cost", input: "$0.02/hr", expectedValue: 0.02, expectedUnit: UnitDollarsPerHour},
+		{desc: "unknown unit", input: "3 parsecs", invalid: true},
+		{desc: "not a number", input: "fast", invalid: true},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			v, u, err := ParseValueWithUnit(c.input)
+			if c.invalid {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expectedValue, v)
+				assert.Equal(t, c.expectedUnit, u)
+			}
+		})
+	}
+}
+
+func TestUnit_Convert(t *testing.T) {
+	cases := []struct {
+		desc     string
+		value    float64
+		from     Unit
+		to       Unit
+		expected float64
+		invalid  bool
+	}{
+		{desc: "same", value: 5, from: UnitSeconds, to: UnitSeconds, expected: 5},
+		{desc: "time", value: 1.5, from: UnitSeconds, to: UnitMilliseconds, expected: 1500},
+		{desc: "cpu", value: 250, from: UnitMillicores, to: UnitCores, expected: 0.25},
+		{desc: "memory", value: 2, from: UnitGibibytes, to: UnitMebibytes, expected: 2048},
+		{desc: "cost", value: 1, from: UnitDollarsPerHour, to: UnitDollarsPerMonth, expected: 730},
+		{desc: "incompatible", value: 1, from: UnitSeconds, to: UnitCores, invalid: true},
+		{desc: "unitless", value: 1, from: "", to: UnitCores, invalid: true},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			v, err := c.from.Convert(c.value, c.to)
+			if c.invalid {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.InDelta(t, c.expected, v, 1e-9)
+			}
+		})
+	}
+}
+
+func TestUnit_Format(t *testing.T) {
+	assert.Equal(t, "250ms", UnitMilliseconds.Format(250))
+	assert.Equal(t, "1.5 cores", UnitCores.Format(1.5))
+	assert.Equal(t, "$12.5/month", UnitDollarsPerMonth.Format(12.5))
+	assert.Equal(t, "42", Unit("").Format(42))
+}
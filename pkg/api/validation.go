@@ -0,0 +1,67 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a problem with a specific field of a resource.
+type FieldError struct {
+	// The path to the field, e.g. `parameters[0].bounds`.
+	Field string
+	// The description of the problem.
+	Message string
+}
+
+// Error returns the field path and message.
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// FieldErrorList is a collection of field errors.
+type FieldErrorList []*FieldError
+
+// Add appends a new field error using the supplied format.
+func (el *FieldErrorList) Add(field, format string, a ...interface{}) {
+	*el = append(*el, &FieldError{Field: field, Message: fmt.Sprintf(format, a...)})
+}
+
+// Err returns nil for an empty list.
+func (el FieldErrorList) Err() error {
+	if len(el) == 0 {
+		return nil
+	}
+	return el
+}
+
+// Error returns each of the field errors on a separate line.
+func (el FieldErrorList) Error() string {
+	if len(el) == 0 {
+		return "no field errors"
+	}
+
+	msgs := make([]string, 0, len(el))
+	for _, err := range el {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldErrorList_Error(t *testing.T) {
+	cases := []struct {
+		desc     string
+		errs     FieldErrorList
+		expected string
+	}{
+		{
+			desc:     "empty",
+			expected: "no field errors",
+		},
+		{
+			desc:     "single",
+			errs:     FieldErrorList{{Field: "name", Message: "name is required"}},
+			expected: "name: name is required",
+		},
+		{
+			desc:     "multiple",
+			errs:     FieldErrorList{{Field: "name", Message: "name is required"}, {Message: "invalid"}},
+			expected: "name: name is required\ninvalid",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.errs.Error())
+		})
+	}
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package command
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
@@ -26,36 +29,52 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/thestormforge/optimize-go/pkg/api"
 	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	"github.com/thestormforge/optimize-go/pkg/notify"
 	"golang.org/x/oauth2"
 	"gopkg.in/square/go-jose.v2/jwt"
 )
 
-// NewGetActivityCommand returns a command for getting activity feed items.
+// NewGetActivityCommand returns a command for getting activity feed items. If
+// an application is named, its historical activity (including recommendation
+// deploys) is listed in chronological order.
 func NewGetActivityCommand(cfg Config, p Printer) *cobra.Command {
 	var (
-		tags []string
+		tags  []string
+		since string
+		until string
 	)
 
 	cmd := &cobra.Command{
-		Use:     "activity-feed",
-		Aliases: []string{"activity", "feed"},
+		Use:               "activity-feed [APP_NAME]",
+		Annotations:       map[string]string{annotationOutput: "activity"},
+		Aliases:           []string{"activity", "feed"},
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: validApplicationArgs(cfg),
 	}
 
 	cmd.Flags().StringSliceVar(&tags, "tags", nil, "limit activity items to the specified `tag`s")
+	cmd.Flags().StringVar(&since, "since", "", "limit activity to items after this `time` (RFC 3339 or a duration ago, e.g. 7d)")
+	cmd.Flags().StringVar(&until, "until", "", "limit activity to items before this `time` (RFC 3339 or a duration ago)")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
+
+		appAPI := applications.NewAPI(client)
+
+		sinceTime, err := parseTimeOrAgo(since)
+		if err != nil {
+			return err
+		}
+		untilTime, err := parseTimeOrAgo(until)
 		if err != nil {
 			return err
 		}
-
-		appAPI := applications.NewAPI(client)
 
 		q := applications.ActivityFeedQuery{}
 		if len(tags) > 0 {
 			q.SetType(tags...)
 		}
+		q.SetRange(sinceTime, untilTime)
 
 		md, err := appAPI.CheckEndpoint(ctx)
 		if err != nil {
@@ -67,7 +86,12 @@ func NewGetActivityCommand(cfg Config, p Printer) *cobra.Command {
 			return fmt.Errorf("missing activity feed URL")
 		}
 
-		feed, err := appAPI.ListActivity(ctx, u, q)
+		var feed applications.ActivityFeed
+		if len(args) > 0 || !sinceTime.IsZero() || !untilTime.IsZero() {
+			feed, err = activityHistory(ctx, appAPI, u, q, args, tags, sinceTime, untilTime)
+		} else {
+			feed, err = appAPI.ListActivity(ctx, u, q)
+		}
 		if err != nil {
 			return err
 		}
@@ -80,10 +104,82 @@ func NewGetActivityCommand(cfg Config, p Printer) *cobra.Command {
 			result.Add(&feed.Items[i])
 		}
 		return p.Fprint(out, result)
-	}
+	})
 	return cmd
 }
 
+// tagDeploy is the tag used for the activity items created from deployed recommendations.
+const tagDeploy = "deploy"
+
+// activityHistory collects all the activity in the specified time range (optionally limited
+// to a single application) and returns it as a single feed in chronological order.
+func activityHistory(ctx context.Context, appAPI applications.API, u string, q applications.ActivityFeedQuery, args, tags []string, since, until time.Time) (applications.ActivityFeed, error) {
+	l := applications.Lister{API: appAPI}
+	result := applications.ActivityFeed{FeedURL: u}
+
+	// The server may not honor the time range so we also need to check it here
+	inRange := func(t time.Time) bool {
+		return (since.IsZero() || !t.Before(since)) && (until.IsZero() || !t.After(until))
+	}
+
+	var app *applications.Application
+	if len(args) > 0 {
+		a, err := appAPI.GetApplicationByName(ctx, applications.ApplicationName(args[0]))
+		if err != nil {
+			return result, err
+		}
+		app = &a
+	}
+
+	err := l.ForEachActivity(ctx, u, q, func(item *applications.ActivityItem) error {
+		if app != nil && !item.RefersTo(app.Link(api.RelationSelf)) {
+			return nil
+		}
+		if inRange(item.DatePublished) {
+			result.Items = append(result.Items, *item)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Recommendation deploys do not appear in the feed, add them from the application
+	if app != nil && (len(tags) == 0 || containsFold(tags, tagDeploy)) {
+		err := l.ForEachRecommendation(ctx, app, func(item *applications.RecommendationItem) error {
+			if item.DeployedAt == nil || !inRange(*item.DeployedAt) {
+				return nil
+			}
+			result.Items = append(result.Items, applications.ActivityItem{
+				ID:            item.Link(api.RelationSelf),
+				ExternalURL:   item.Link(api.RelationSelf),
+				Title:         fmt.Sprintf("Deployed recommendation %s", item.Name),
+				DatePublished: *item.DeployedAt,
+				Tags:          []string{tagDeploy},
+			})
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		return result.Items[i].DatePublished.Before(result.Items[j].DatePublished)
+	})
+	return result, nil
+}
+
+// containsFold checks if the values contain the supplied string, ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
 // NewWatchActivityCommand returns a command for watching the activity feed.
 func NewWatchActivityCommand(cfg Config) *cobra.Command {
 	var (
@@ -95,6 +191,8 @@ func NewWatchActivityCommand(cfg Config) *cobra.Command {
 
 		feedTemplateText string
 		itemTemplateText string
+
+		notifyOpts notifyOptions
 	)
 
 	cmd := &cobra.Command{
@@ -110,13 +208,10 @@ func NewWatchActivityCommand(cfg Config) *cobra.Command {
 	cmd.Flags().StringVar(&itemTemplateText, "item-template", `{{ template "ActivityItem" . }}`, "the item `template` used to render the items")
 	cmd.Flag("feed-template").Hidden = true
 	cmd.Flag("item-template").Hidden = true
+	addNotifyFlags(cmd, &notifyOpts, false)
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		// Create the templates for rendering activities
 		tmpl := template.New("activity").Funcs(map[string]interface{}{
@@ -148,6 +243,9 @@ func NewWatchActivityCommand(cfg Config) *cobra.Command {
 			PollInterval:           pollInterval,
 			JitterFactor:           jitterFactor,
 			ReportFailedActivities: !hideFailedActivities,
+			OnError: func(err error) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to fetch activity, retrying: %v\n", err)
+			},
 		}
 
 		q := applications.ActivityFeedQuery{}
@@ -175,6 +273,8 @@ func NewWatchActivityCommand(cfg Config) *cobra.Command {
 			return err
 		}
 
+		n := notifyOpts.notifier(cmd)
+
 		// Create a channel to watch for new items
 		activity := make(chan applications.ActivityItem)
 		go func() {
@@ -184,6 +284,10 @@ func NewWatchActivityCommand(cfg Config) *cobra.Command {
 					_, _ = fmt.Fprintf(out, "Error: failed to render activity %q: %v", item.URL, err)
 				}
 
+				if e, ok := notify.ActivityEvent(&item); ok {
+					n.Notify(ctx, e)
+				}
+
 				// If requested, delete the item to prevent it from being processed again
 				if deleteItems {
 					if err := s.API.DeleteActivity(ctx, item.URL); err != nil {
@@ -196,7 +300,92 @@ func NewWatchActivityCommand(cfg Config) *cobra.Command {
 		// Set the feed URL and start polling
 		s.FeedURL = feed.FeedURL
 		return s.Subscribe(ctx, activity)
+	})
+	return cmd
+}
+
+// NewServeActivityWebhookCommand returns a command for forwarding activity items to a webhook.
+func NewServeActivityWebhookCommand(cfg Config) *cobra.Command {
+	var (
+		webhookURL           string
+		secretFile           string
+		pollInterval         time.Duration
+		jitterFactor         float64
+		hideFailedActivities bool
+		tags                 []string
+		retries              int
+		deleteItems          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:  "activity-webhook",
+		Args: cobra.NoArgs,
 	}
+
+	cmd.Flags().StringVar(&webhookURL, "url", "", "webhook `URL` each activity item is posted to")
+	cmd.Flags().StringVar(&secretFile, "secret-file", "", "`file` containing the secret used to sign requests (defaults to $STORMFORGE_WEBHOOK_SECRET)")
+	cmd.Flags().DurationVar(&pollInterval, "poll", 30*time.Second, "polling `interval` to refresh the feed")
+	cmd.Flags().Float64Var(&jitterFactor, "jitter", 1.0, "polling jitter `factor` to refresh the feed")
+	cmd.Flags().BoolVar(&hideFailedActivities, "no-failed", false, "do not forward items with a failure reason")
+	cmd.Flags().StringSliceVar(&tags, "tags", nil, "limit activity items to the specified `tag`s")
+	cmd.Flags().IntVar(&retries, "retries", 3, "`number` of times a failed delivery is retried")
+	cmd.Flags().BoolVar(&deleteItems, "delete", false, "delete items once they are delivered")
+	_ = cmd.MarkFlagRequired("url")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		secret := []byte(os.Getenv("STORMFORGE_WEBHOOK_SECRET"))
+		if secretFile != "" {
+			data, err := os.ReadFile(secretFile)
+			if err != nil {
+				return err
+			}
+			secret = bytes.TrimSpace(data)
+		}
+
+		if retries == 0 {
+			retries = -1 // The forwarder treats zero as "use the default"
+		}
+
+		appAPI := applications.NewAPI(client)
+
+		sub, err := appAPI.SubscribeActivity(ctx, applications.ActivityFeedQuery{})
+		if err != nil {
+			return err
+		}
+		if s, ok := sub.(*applications.PollingSubscriber); ok {
+			s.PollInterval = pollInterval
+			s.JitterFactor = jitterFactor
+			s.ReportFailedActivities = !hideFailedActivities
+			s.OnError = func(err error) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to fetch activity, retrying: %v\n", err)
+			}
+		}
+
+		f := &applications.ActivityWebhookForwarder{
+			Subscriber: sub,
+			URL:        webhookURL,
+			Secret:     secret,
+			Tags:       tags,
+			MaxRetries: retries,
+			OnDelivery: func(item *applications.ActivityItem) {
+				_, _ = fmt.Fprintf(out, "delivered activity %q.\n", item.ID)
+
+				// If requested, delete the item to prevent it from being delivered again
+				if deleteItems {
+					if err := appAPI.DeleteActivity(ctx, item.URL); err != nil {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: failed to delete activity %q: %v\n", item.URL, err)
+					}
+				}
+			},
+			OnError: func(item *applications.ActivityItem, err error) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: failed to deliver activity %q: %v\n", item.ID, err)
+			},
+		}
+
+		return f.Forward(ctx)
+	})
 	return cmd
 }
 
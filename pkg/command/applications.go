@@ -17,12 +17,16 @@ limitations under the License.
 package command
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/thestormforge/optimize-go/pkg/api"
 	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
 	"github.com/thestormforge/optimize-go/pkg/command/recommendation"
 )
 
@@ -43,13 +47,10 @@ func NewCreateApplicationCommand(cfg Config, p Printer) *cobra.Command {
 	cmd.Flags().StringArrayVar(&resource.Kubernetes.Namespaces, "namespace", nil, "select application resources from a specific `namespace`")
 	cmd.Flags().StringVar(&resource.Kubernetes.NamespaceSelector, "ns-selector", "", "`sel`ect application resources from labeled namespaces")
 	cmd.Flags().StringVarP(&resource.Kubernetes.Selector, "selector", "l", "", "`sel`ect only labeled application resources")
+	cmd.MarkFlagsMutuallyExclusive("namespace", "ns-selector")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		appAPI := applications.NewAPI(client)
 
@@ -58,10 +59,14 @@ func NewCreateApplicationCommand(cfg Config, p Printer) *cobra.Command {
 			DisplayName: title,
 		}
 
-		if r, ok := normalizeResource(resource); ok {
+		if r, ok := applications.MergeResources(resource); ok {
 			app.Resources = append(app.Resources, r)
 		}
 
+		if err := applications.Validate(&app); err != nil {
+			return fmt.Errorf("invalid application definition:\n%s", indent(err.Error()))
+		}
+
 		// Upsert the application if we have a name, otherwise create it with a generated name
 		var selfURL string
 		if len(args) > 0 && args[0] != "" {
@@ -87,7 +92,7 @@ func NewCreateApplicationCommand(cfg Config, p Printer) *cobra.Command {
 		}
 
 		return p.Fprint(out, NewApplicationRow(&applications.ApplicationItem{Application: app}))
-	}
+	})
 	return cmd
 }
 
@@ -109,16 +114,14 @@ func NewEditApplicationCommand(cfg Config, p Printer) *cobra.Command {
 	cmd.Flags().StringArrayVar(&resource.Kubernetes.Namespaces, "namespace", nil, "select application resources from a specific `namespace`")
 	cmd.Flags().StringVar(&resource.Kubernetes.NamespaceSelector, "ns-selector", "", "`sel`ect application resources from labeled namespaces")
 	cmd.Flags().StringVarP(&resource.Kubernetes.Selector, "selector", "l", "", "`sel`ect only labeled application resources")
+	cmd.MarkFlagsMutuallyExclusive("namespace", "ns-selector")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := applications.Lister{
-			API: applications.NewAPI(client),
+			API:        applications.NewAPI(client),
+			ExactNames: true,
 		}
 
 		return l.ForEachNamedApplication(ctx, args, false, func(item *applications.ApplicationItem) error {
@@ -127,21 +130,25 @@ func NewEditApplicationCommand(cfg Config, p Printer) *cobra.Command {
 				return fmt.Errorf("malformed response, missing self link")
 			}
 
+			// Only the changed fields are included in the patch
+			patch := applications.Application{Metadata: item.Preconditions()}
 			var needsUpdate bool
 
 			// Update the title
 			if title != "" {
 				item.Application.DisplayName = title
+				patch.DisplayName = title
 				needsUpdate = true
 			}
 
 			// Update the resource
-			if r, ok := normalizeResource(resource); ok {
+			if r, ok := applications.MergeResources(resource); ok {
 				if len(item.Application.Resources) > 0 {
 					item.Application.Resources[0] = r
 				} else {
 					item.Application.Resources = append(item.Application.Resources, r)
 				}
+				patch.Resources = item.Application.Resources
 				needsUpdate = true
 			}
 
@@ -149,12 +156,18 @@ func NewEditApplicationCommand(cfg Config, p Printer) *cobra.Command {
 				return nil
 			}
 
-			if _, err := l.API.UpdateApplication(ctx, selfURL, item.Application); err != nil {
+			if err := applications.Validate(&item.Application); err != nil {
+				return fmt.Errorf("invalid application definition:\n%s", indent(err.Error()))
+			}
+
+			if err := l.API.PatchApplication(ctx, selfURL, patch); api.IsConflict(err) {
+				return fmt.Errorf("application %q was modified while it was being edited, try again: %w", item.Name, err)
+			} else if err != nil {
 				return err
 			}
 			return p.Fprint(out, NewApplicationRow(item))
 		})
-	}
+	})
 	return cmd
 }
 
@@ -177,12 +190,8 @@ func NewEnableApplicationRecommendationsCommand(cfg Config, p Printer) *cobra.Co
 
 	_ = cmd.RegisterFlagCompletionFunc("cluster", validClusterArgs(cfg, applications.ClusterRecommendations))
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		appAPI := applications.NewAPI(client)
 
@@ -222,7 +231,7 @@ func NewEnableApplicationRecommendationsCommand(cfg Config, p Printer) *cobra.Co
 		result.SetRecommendationsConfiguration(recs.Configuration)
 		result.SetBackfillProgress(recs.BackfillProgress)
 		return p.Fprint(out, result)
-	}
+	})
 	return cmd
 }
 
@@ -235,12 +244,8 @@ func NewDisableApplicationRecommendationsCommand(cfg Config, p Printer) *cobra.C
 		ValidArgsFunction: validApplicationArgs(cfg),
 	}
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		appAPI := applications.NewAPI(client)
 
@@ -266,7 +271,7 @@ func NewDisableApplicationRecommendationsCommand(cfg Config, p Printer) *cobra.C
 		}
 
 		return p.Fprint(out, NewApplicationRow(&applications.ApplicationItem{Application: app}))
-	}
+	})
 	return cmd
 }
 
@@ -275,21 +280,33 @@ func NewGetApplicationsCommand(cfg Config, p Printer) *cobra.Command {
 	var (
 		product   string
 		batchSize int
+		selector  string
+		search    string
 		sortBy    string
 
+		continueOnError bool
+
 		pageOffset              int
 		skipRecommendationLimit int
+
+		estimateCost bool
+		costOpts     costOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:               "applications [NAME ...]",
+		Annotations:       map[string]string{annotationOutput: "application"},
 		Aliases:           []string{"application", "apps", "app"},
 		ValidArgsFunction: validApplicationArgs(cfg),
 	}
 
 	cmd.Flags().StringVar(&product, "for", product, "show only clusters for a specific `product`; one of: optimize-pro|optimize-live")
 	cmd.Flags().IntVar(&batchSize, "chunk-size", 500, "fetch large lists in chu`n`ks rather then all at once")
+	cmd.Flags().StringVarP(&selector, "selector", "l", selector, "selector (label `query`) to filter on")
+	cmd.Flags().StringVar(&search, "search", search, "show only applications whose name or title contains the `text`")
 	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+	cmd.Flags().BoolVar(&estimateCost, "cost", false, "estimate the monthly cost savings of the latest recommendations")
+	addCostFlags(cmd, &costOpts)
 
 	// Hidden flags to deal with large application lists
 	cmd.Flags().IntVar(&pageOffset, "page-offset", pageOffset, "fetch a partial list starti`n`g from the specified offset")
@@ -303,25 +320,36 @@ func NewGetApplicationsCommand(cfg Config, p Printer) *cobra.Command {
 		return []string{"optimize-pro", "optimize-live"}, cobra.ShellCompDirectiveDefault
 	})
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	addContinueOnErrorFlag(cmd, &continueOnError)
+
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
+
+		pricing, err := costOpts.pricing()
 		if err != nil {
 			return err
 		}
 
 		l := applications.Lister{
-			API:       applications.NewAPI(client),
-			BatchSize: batchSize,
+			API:             applications.NewAPI(newMemoClient(client)),
+			BatchSize:       batchSize,
+			Parallelism:     nameParallelism,
+			ContinueOnError: continueOnError,
 		}
 
 		result := &ApplicationOutput{Items: make([]ApplicationRow, 0, len(args))}
+		var nameErrs error
 		if len(args) > 0 {
-			if err := l.ForEachNamedApplication(ctx, args, false, result.Add); err != nil {
+			var err error
+			if nameErrs, err = splitNameErrors(l.ForEachNamedApplication(ctx, args, false, result.Add)); err != nil {
 				return err
 			}
 		} else {
 			q := applications.ApplicationListQuery{}
+			q.SetLabelSelector(parseLabelSelector(selector))
+			q.SetSearch(search)
 
 			// Hack to explicitly support --page-offset 0
 			if cmd.Flag("page-offset").Changed {
@@ -337,27 +365,17 @@ func NewGetApplicationsCommand(cfg Config, p Printer) *cobra.Command {
 			}
 		}
 
-		// This is a hack to get around the fact that we cannot provide recommendation configurations in a reasonable amount of time
-		var skipRecommendations bool
-		if len(result.Items) > skipRecommendationLimit {
-			_, _ = fmt.Fprintf(cmd.OutOrStderr(), "WARNING: Too many applications to fetch recommendations (%d, limit is %d), try fetching individual applications\n", len(result.Items), skipRecommendationLimit)
-			skipRecommendations = true
-		}
-
-		for i := range result.Items {
-			u := result.Items[i].ApplicationItem.Link(api.RelationRecommendations)
-			if u == "" || result.Items[i].Recommendations == applications.RecommendationsDisabled || skipRecommendations {
-				continue
-			}
-
-			rl, err := l.API.ListRecommendations(ctx, u)
-			if err != nil {
-				return err
+		// Filter applications by search text, in case the server does not support searching
+		if search != "" {
+			text := strings.ToLower(search)
+			items := make([]ApplicationRow, 0, len(result.Items))
+			for i := range result.Items {
+				if strings.Contains(strings.ToLower(result.Items[i].Name), text) ||
+					strings.Contains(strings.ToLower(result.Items[i].Title), text) {
+					items = append(items, result.Items[i])
+				}
 			}
-
-			result.Items[i].SetRecommendationsDeployConfig(rl.DeployConfiguration)
-			result.Items[i].SetRecommendationsConfiguration(rl.Configuration)
-			result.Items[i].SetBackfillProgress(rl.BackfillProgress)
+			result.Items = items
 		}
 
 		// Filter applications by product
@@ -386,20 +404,72 @@ func NewGetApplicationsCommand(cfg Config, p Printer) *cobra.Command {
 			result.Items = items
 		}
 
+		// This is a hack to get around the fact that we cannot provide recommendation configurations in a reasonable amount of time
+		var skipRecommendations bool
+		if len(result.Items) > skipRecommendationLimit {
+			_, _ = fmt.Fprintf(cmd.OutOrStderr(), "WARNING: Too many applications to fetch recommendations (%d, limit is %d), try fetching individual applications\n", len(result.Items), skipRecommendationLimit)
+			skipRecommendations = true
+		}
+
+		if err := forEachIndex(ctx, len(result.Items), fetchParallelism, func(ctx context.Context, i int) error {
+			u := result.Items[i].ApplicationItem.Link(api.RelationRecommendations)
+			if u == "" || result.Items[i].Recommendations == applications.RecommendationsDisabled || skipRecommendations {
+				return nil
+			}
+
+			rl, err := l.API.ListRecommendations(ctx, u)
+			if err != nil {
+				return err
+			}
+
+			result.Items[i].SetRecommendationsDeployConfig(rl.DeployConfiguration)
+			result.Items[i].SetRecommendationsConfiguration(rl.Configuration)
+			result.Items[i].SetBackfillProgress(rl.BackfillProgress)
+
+			if !estimateCost || len(rl.Recommendations) == 0 {
+				return nil
+			}
+
+			// The index does not include the parameters, fetch the full recommendation
+			rec, err := l.API.GetRecommendation(ctx, rl.Recommendations[0].Link(api.RelationSelf))
+			if err != nil {
+				return err
+			}
+
+			workloads, err := listWorkloads(ctx, l, &result.Items[i].ApplicationItem.Application)
+			if err != nil {
+				return err
+			}
+
+			if e, ok := pricing.EstimateRecommendation(workloads, &rec); ok {
+				result.Items[i].SetCostEstimate(e)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
 		// Sort the rows
 		if err := result.SortBy(sortBy); err != nil {
 			return err
 		}
 
-		return p.Fprint(out, result)
-	}
+		if err := p.Fprint(out, result); err != nil {
+			return err
+		}
+		return nameErrs
+	})
 	return cmd
 }
 
 // NewDeleteApplicationsCommand returns a command for deleting applications.
 func NewDeleteApplicationsCommand(cfg Config, p Printer) *cobra.Command {
 	var (
-		ignoreNotFound bool
+		ignoreNotFound  bool
+		glob            bool
+		continueOnError bool
+		waitForIdle     bool
+		idleTimeout     = 5 * time.Minute
 	)
 
 	cmd := &cobra.Command{
@@ -409,16 +479,22 @@ func NewDeleteApplicationsCommand(cfg Config, p Printer) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&ignoreNotFound, "ignore-not-found", ignoreNotFound, "treat not found errors as successful deletes")
+	cmd.Flags().BoolVar(&glob, "glob", glob, "allow application names to be glob patterns")
+	cmd.Flags().BoolVar(&waitForIdle, "wait-for-idle", waitForIdle, "wait for pending scans, runs and experiments to finish before deleting")
+	cmd.Flags().DurationVar(&idleTimeout, "idle-timeout", idleTimeout, "maximum `duration` to wait for an application to become idle")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	addContinueOnErrorFlag(cmd, &continueOnError)
+
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := applications.Lister{
-			API: applications.NewAPI(client),
+			API:             applications.NewAPI(client),
+			ExactNames:      !glob,
+			Parallelism:     nameParallelism,
+			ContinueOnError: continueOnError,
 		}
 
 		return l.ForEachNamedApplication(ctx, args, ignoreNotFound, func(item *applications.ApplicationItem) error {
@@ -427,42 +503,63 @@ func NewDeleteApplicationsCommand(cfg Config, p Printer) *cobra.Command {
 				return fmt.Errorf("malformed response, missing self link")
 			}
 
+			if waitForIdle {
+				idleCtx, cancel := context.WithTimeout(ctx, idleTimeout)
+				defer cancel()
+
+				opts := applications.WaitForIdleOptions{Pending: activeExperiments(experiments.NewAPI(client))}
+				if err := applications.WaitForIdle(idleCtx, l.API, &item.Application, opts); err != nil {
+					if errors.Is(err, context.DeadlineExceeded) {
+						return fmt.Errorf("application %q did not become idle within %s", item.Name, idleTimeout)
+					}
+					return err
+				}
+			}
+
 			if err := l.API.DeleteApplication(ctx, selfURL); err != nil {
 				return err
 			}
 
 			return p.Fprint(out, NewApplicationRow(item))
 		})
-	}
+	})
 	return cmd
 }
 
 func validApplicationArgs(cfg Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
 	return validArgs(cfg, func(l *completionLister, toComplete string) (completions []string, directive cobra.ShellCompDirective) {
 		directive |= cobra.ShellCompDirectiveNoFileComp
-		l.forAllApplications(func(item *applications.ApplicationItem) {
-			if strings.HasPrefix(item.Name.String(), toComplete) {
-				completions = append(completions, item.Name.String())
-			}
-		})
+		completions = filterPrefix(l.applicationNames(), toComplete)
 		return
 	})
 }
 
-func normalizeResource(r applications.Resource) (applications.Resource, bool) {
-	if r.Kubernetes.Namespace == "" && len(r.Kubernetes.Namespaces) == 0 && r.Kubernetes.NamespaceSelector == "" {
-		return r, false
-	}
-
-	if r.Kubernetes.Namespace == "" && len(r.Kubernetes.Namespaces) == 1 {
-		r.Kubernetes.Namespace = r.Kubernetes.Namespaces[0]
-		r.Kubernetes.Namespaces = nil
-	}
+// activeExperiments returns a function for counting the experiments of an application that have staged or active trials.
+func activeExperiments(expAPI experiments.API) func(context.Context, *applications.Application) (int, error) {
+	return func(ctx context.Context, app *applications.Application) (int, error) {
+		l := experiments.Lister{API: expAPI}
+		q := experiments.ExperimentListQuery{}
+		q.SetLabelSelector(map[string]string{"application": app.Name.String()})
+
+		active := 0
+		err := l.ForEachExperiment(ctx, q, func(item *experiments.ExperimentItem) error {
+			trialsURL := item.Link(api.RelationTrials)
+			if trialsURL == "" {
+				return nil
+			}
 
-	if r.Kubernetes.Namespace != "" && len(r.Kubernetes.Namespaces) > 0 {
-		r.Kubernetes.Namespaces = append(r.Kubernetes.Namespaces, r.Kubernetes.Namespace)
-		r.Kubernetes.Namespace = ""
+			tq := experiments.TrialListQuery{}
+			tq.SetStatus(experiments.TrialStaged, experiments.TrialActive)
+			tq.SetLimit(1)
+			tl, err := expAPI.GetAllTrials(ctx, trialsURL, tq)
+			if err != nil {
+				return err
+			}
+			if len(tl.Trials) > 0 {
+				active++
+			}
+			return nil
+		})
+		return active, err
 	}
-
-	return r, true
 }
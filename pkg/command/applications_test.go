@@ -0,0 +1,43 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationCommands_NamespaceSelector(t *testing.T) {
+	cfg := staticConfig("https://api.example.com/")
+	for desc, cmd := range map[string]*cobra.Command{
+		"create": NewCreateApplicationCommand(cfg, nil),
+		"edit":   NewEditApplicationCommand(cfg, nil),
+	} {
+		t.Run(desc, func(t *testing.T) {
+			cmd.SetArgs([]string{"my-app", "--namespace", "default", "--ns-selector", "env=prod"})
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			err := cmd.Execute()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), "[namespace ns-selector]")
+			}
+		})
+	}
+}
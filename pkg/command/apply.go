@@ -0,0 +1,142 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/manifest"
+)
+
+// NewApplyCommand returns a command for making the current resources match a
+// file (possibly containing multiple YAML documents) or a directory of manifests.
+func NewApplyCommand(cfg Config) *cobra.Command {
+	var (
+		filename string
+		prune    bool
+		dryRun   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:  "apply -f PATH",
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "`path` to a file or directory of JSON or YAML manifests, \"-\" reads from stdin")
+	cmd.Flags().BoolVar(&prune, "prune", false, "delete previously applied applications, scenarios of declared applications and experiments which are no longer declared")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only print the plan, do not make any changes")
+	_ = cmd.MarkFlagRequired("filename")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		var docs []manifest.Document
+		var err error
+		if filename == "-" {
+			docs, err = manifest.Read(cmd.InOrStdin(), "stdin")
+		} else {
+			docs, err = manifest.Load(filename)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Scenario changes can regenerate the template, record it so it can be rolled back
+		appAPI, err := templateRecorder(cfg, applications.NewAPI(client))
+		if err != nil {
+			return err
+		}
+
+		a := &manifest.Applier{
+			ApplicationsAPI: appAPI,
+			ExperimentsAPI:  experiments.NewAPI(client),
+			OnChange: func(c *manifest.Change) {
+				_, _ = fmt.Fprintf(out, "%s %q %s.\n", strings.ToLower(string(c.Kind)), c.Name, pastTense(c.Action))
+			},
+		}
+
+		// Pruning requires the state of everything, otherwise only fetch what is declared
+		var current manifest.State
+		if prune {
+			current, err = manifest.Fetch(ctx, a.ApplicationsAPI, a.ExperimentsAPI)
+		} else {
+			current, err = manifest.FetchDocuments(ctx, a.ApplicationsAPI, a.ExperimentsAPI, docs)
+		}
+		if err != nil {
+			return err
+		}
+
+		plan, err := manifest.NewPlan(docs, current, prune)
+		if err != nil {
+			return err
+		}
+
+		if err := writePlan(out, plan); err != nil {
+			return err
+		}
+
+		if dryRun || len(plan.Changes) == plan.Count(manifest.ActionNone) {
+			return nil
+		}
+
+		_, _ = fmt.Fprintln(out)
+		return a.Apply(ctx, plan)
+	})
+	return cmd
+}
+
+// writePlan prints a summary of the planned changes.
+func writePlan(out io.Writer, plan *manifest.Plan) error {
+	symbols := map[manifest.Action]string{
+		manifest.ActionCreate: "+",
+		manifest.ActionUpdate: "~",
+		manifest.ActionDelete: "-",
+	}
+
+	for _, c := range plan.Changes {
+		if c.Action == manifest.ActionNone {
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "%s %s %s %q\n", symbols[c.Action], c.Action, strings.ToLower(string(c.Kind)), c.Name); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(out, "Plan: %d to create, %d to update, %d to delete, %d unchanged.\n",
+		plan.Count(manifest.ActionCreate), plan.Count(manifest.ActionUpdate), plan.Count(manifest.ActionDelete), plan.Count(manifest.ActionNone))
+	return err
+}
+
+// pastTense returns the action as it is reported once it has been made.
+func pastTense(action manifest.Action) string {
+	switch action {
+	case manifest.ActionCreate:
+		return "created"
+	case manifest.ActionUpdate:
+		return "updated"
+	case manifest.ActionDelete:
+		return "deleted"
+	default:
+		return string(action)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// fetchParallelism is the number of linked resources fetched concurrently,
+// e.g. the recommendations of each application in a list.
+const fetchParallelism = 8
+
+// memoClient is an API client which remembers the responses to GET requests
+// for the duration of a single command invocation so repeated fetches of the
+// same URL (including concurrent fetches) only reach the server once. Only
+// successful responses are remembered, any other request method clears the
+// remembered responses.
+type memoClient struct {
+	api.Client
+
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+}
+
+// memoEntry is a remembered (or in-flight) response.
+type memoEntry struct {
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// memoStreamingClient is a memoizing client that also supports streaming
+// responses, streamed requests are never remembered.
+type memoStreamingClient struct {
+	*memoClient
+	streaming api.StreamingClient
+}
+
+// Stream sends the request using the wrapped streaming client.
+func (c *memoStreamingClient) Stream(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.streaming.Stream(ctx, req)
+}
+
+// newMemoClient returns a memoizing wrapper around the supplied client.
+func newMemoClient(client api.Client) api.Client {
+	mc := &memoClient{Client: client, entries: make(map[string]*memoEntry)}
+	if sc, ok := client.(api.StreamingClient); ok {
+		return &memoStreamingClient{memoClient: mc, streaming: sc}
+	}
+	return mc
+}
+
+// Do sends the request, or returns the remembered response for a GET request.
+func (c *memoClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	if req.Method != http.MethodGet {
+		c.mu.Lock()
+		c.entries = make(map[string]*memoEntry)
+		c.mu.Unlock()
+		return c.Client.Do(ctx, req)
+	}
+
+	key := memoKey(req.URL)
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &memoEntry{done: make(chan struct{})}
+		c.entries[key] = e
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		e.resp, e.body, e.err = c.Client.Do(ctx, req)
+		close(e.done)
+
+		// Only remember successful responses, failures may be transient (or caused by our context)
+		if e.err != nil || e.resp.StatusCode < 200 || e.resp.StatusCode >= 300 {
+			c.mu.Lock()
+			if c.entries[key] == e {
+				delete(c.entries, key)
+			}
+			c.mu.Unlock()
+		}
+	} else {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	if e.err != nil {
+		return nil, nil, e.err
+	}
+
+	// Callers may modify the response headers (e.g. resolving links)
+	resp := *e.resp
+	resp.Header = e.resp.Header.Clone()
+	return &resp, e.body, nil
+}
+
+// memoKey returns the key used to remember the response for a URL.
+func memoKey(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+// forEachIndex invokes the supplied function for each index in [0, n) using a
+// bounded number of concurrent workers. The first error cancels the remaining
+// work and is returned.
+func forEachIndex(ctx context.Context, n, parallelism int, f func(ctx context.Context, i int) error) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	indexes := make(chan int)
+	for w := 0; w < parallelism && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := f(ctx, i); err != nil {
+					once.Do(func() { firstErr = err; cancel() })
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingClient is a fake API client which counts the requests for each path.
+type countingClient struct {
+	mu       sync.Mutex
+	requests map[string]int
+	release  chan struct{}
+}
+
+func (c *countingClient) URL(endpoint string) *url.URL {
+	return &url.URL{Scheme: "https", Host: "api.example.com", Path: endpoint}
+}
+
+func (c *countingClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	c.mu.Lock()
+	c.requests[req.URL.Path]++
+	c.mu.Unlock()
+
+	if c.release != nil {
+		<-c.release
+	}
+
+	switch req.URL.Path {
+	case "/error":
+		return nil, nil, errors.New("connection refused")
+	case "/missing":
+		return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}}, nil, nil
+	default:
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, []byte(req.URL.Path), nil
+	}
+}
+
+func (c *countingClient) count(path string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests[path]
+}
+
+func TestMemoClient(t *testing.T) {
+	ctx := context.Background()
+	cc := &countingClient{requests: make(map[string]int)}
+	c := newMemoClient(cc)
+
+	do := func(method, path string) (*http.Response, []byte, error) {
+		req, err := http.NewRequest(method, c.URL(path).String(), nil)
+		require.NoError(t, err)
+		return c.Do(ctx, req)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		resp, body, err := do(http.MethodGet, "/ok")
+		require.NoError(t, err)
+		resp.Header.Set("Link", "modified")
+		assert.Equal(t, "/ok", string(body))
+
+		resp, body, err = do(http.MethodGet, "/ok")
+		require.NoError(t, err)
+		assert.Empty(t, resp.Header.Get("Link"), "headers are copied")
+		assert.Equal(t, "/ok", string(body))
+		assert.Equal(t, 1, cc.count("/ok"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			resp, _, err := do(http.MethodGet, "/missing")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		}
+		assert.Equal(t, 2, cc.count("/missing"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, _, err := do(http.MethodGet, "/error")
+			assert.Error(t, err)
+		}
+		assert.Equal(t, 2, cc.count("/error"))
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		_, _, err := do(http.MethodPost, "/other")
+		require.NoError(t, err)
+
+		_, _, err = do(http.MethodGet, "/ok")
+		require.NoError(t, err)
+		assert.Equal(t, 2, cc.count("/ok"))
+	})
+}
+
+func TestMemoClient_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	cc := &countingClient{requests: make(map[string]int), release: make(chan struct{})}
+	c := newMemoClient(cc)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, c.URL("/ok").String(), nil)
+			_, body, err := c.Do(ctx, req)
+			assert.NoError(t, err)
+			assert.Equal(t, "/ok", string(body))
+		}()
+	}
+
+	// Wait for the first request to reach the server before releasing it
+	for cc.count("/ok") == 0 {
+		runtime.Gosched()
+	}
+	close(cc.release)
+	wg.Wait()
+
+	assert.Equal(t, 1, cc.count("/ok"))
+}
+
+func TestForEachIndex(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("all", func(t *testing.T) {
+		var running, maxRunning int32
+		visited := make([]bool, 20)
+		err := forEachIndex(ctx, len(visited), 3, func(ctx context.Context, i int) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			visited[i] = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, visited, false)
+		assert.LessOrEqual(t, maxRunning, int32(3))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.NoError(t, forEachIndex(ctx, 0, 0, func(ctx context.Context, i int) error {
+			return errors.New("unexpected call")
+		}))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var calls int32
+		err := forEachIndex(ctx, 100, 1, func(ctx context.Context, i int) error {
+			atomic.AddInt32(&calls, 1)
+			if i == 2 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		assert.EqualError(t, err, "failed")
+		assert.Less(t, calls, int32(100), "stops after the first error")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := forEachIndex(ctx, 10, 2, func(ctx context.Context, i int) error { return nil })
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/snapshot"
+)
+
+// NewSyncCommand returns a command for recording a snapshot of the current resources.
+func NewSyncCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		dir string
+	)
+
+	cmd := &cobra.Command{
+		Use:  "sync",
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVar(&dir, "snapshot-dir", "", "`directory` used to store snapshots")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		store, err := snapshotStore(cfg, dir)
+		if err != nil {
+			return err
+		}
+
+		appAPI, err := templateRecorder(cfg, applications.NewAPI(client))
+		if err != nil {
+			return err
+		}
+
+		snap, err := snapshot.Capture(ctx, appAPI, experiments.NewAPI(client))
+		if err != nil {
+			return err
+		}
+
+		if err := store.Save(snap); err != nil {
+			return err
+		}
+
+		// Also capture any template changes made outside of this tool
+		if err := appAPI.RecordAll(ctx); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(out, "recorded snapshot %s\n", snap.Timestamp.Format(time.RFC3339))
+		return err
+	})
+	return cmd
+}
+
+// NewChangesCommand returns a command for reporting changes between two snapshots.
+func NewChangesCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		dir    string
+		since  string
+		until  string
+		sortBy string
+	)
+
+	cmd := &cobra.Command{
+		Use:         "changes",
+		Annotations: map[string]string{annotationOutput: "change"},
+		Args:        cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVar(&dir, "snapshot-dir", "", "`directory` used to store snapshots")
+	cmd.Flags().StringVar(&since, "since", "", "report changes after this `time` (RFC 3339 or a duration ago)")
+	cmd.Flags().StringVar(&until, "until", "", "report changes up to this `time` (RFC 3339 or a duration ago), defaults to the latest snapshot")
+	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+	_ = cmd.MarkFlagRequired("since")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+
+		store, err := snapshotStore(cfg, dir)
+		if err != nil {
+			return err
+		}
+
+		sinceTime, err := parseTimeOrAgo(since)
+		if err != nil {
+			return err
+		}
+		untilTime, err := parseTimeOrAgo(until)
+		if err != nil {
+			return err
+		}
+
+		from, err := store.LoadAt(sinceTime)
+		if err != nil {
+			return err
+		}
+		to, err := store.LoadAt(untilTime)
+		if err != nil {
+			return err
+		}
+
+		result := &ChangeOutput{From: from.Timestamp, To: to.Timestamp}
+		for _, c := range snapshot.Diff(from, to) {
+			result.Items = append(result.Items, *NewChangeRow(c))
+		}
+
+		if err := result.SortBy(sortBy); err != nil {
+			return err
+		}
+
+		return p.Fprint(out, result)
+	}
+	return cmd
+}
+
+// snapshotStore returns the store for the configured server.
+func snapshotStore(cfg Config, dir string) (*snapshot.Store, error) {
+	if dir != "" {
+		return &snapshot.Store{Dir: dir}, nil
+	}
+
+	dir, err := serverConfigDir(cfg, "snapshots")
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot.Store{Dir: dir}, nil
+}
+
+// serverConfigDir returns a named configuration directory for the configured
+// server, keeping the local state for different servers separate.
+func serverConfigDir(cfg Config, name string) (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	host := "default"
+	if u, err := url.Parse(cfg.Address()); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	return filepath.Join(base, "stormforge", name, host), nil
+}
+
+// parseTimeOrAgo parses an RFC 3339 timestamp or a duration before now (which
+// may also be expressed in days, e.g. "7d"). An empty string returns the zero time.
+func parseTimeOrAgo(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if days, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil && strings.HasSuffix(s, "d") {
+		return time.Now().AddDate(0, 0, -days), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 or a duration", s)
+	}
+	return t, nil
+}
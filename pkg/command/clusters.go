@@ -39,12 +39,8 @@ func NewEditClusterCommand(cfg Config, p Printer) *cobra.Command {
 
 	cmd.Flags().StringVar(&title, "title", "", "update the `title` value")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := applications.Lister{
 			API: applications.NewAPI(client),
@@ -65,7 +61,7 @@ func NewEditClusterCommand(cfg Config, p Printer) *cobra.Command {
 
 			return p.Fprint(out, NewClusterRow(item))
 		})
-	}
+	})
 	return cmd
 }
 
@@ -74,10 +70,13 @@ func NewGetClustersCommand(cfg Config, p Printer) *cobra.Command {
 	var (
 		product string
 		sortBy  string
+
+		continueOnError bool
 	)
 
 	cmd := &cobra.Command{
 		Use:               "clusters [NAME ...]",
+		Annotations:       map[string]string{annotationOutput: "cluster"},
 		Aliases:           []string{"cluster"},
 		ValidArgsFunction: validClusterArgs(cfg),
 	}
@@ -89,20 +88,24 @@ func NewGetClustersCommand(cfg Config, p Printer) *cobra.Command {
 		return []string{"optimize-pro", "optimize-live"}, cobra.ShellCompDirectiveDefault
 	})
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	addContinueOnErrorFlag(cmd, &continueOnError)
+
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := applications.Lister{
-			API: applications.NewAPI(client),
+			API:             applications.NewAPI(client),
+			Parallelism:     nameParallelism,
+			ContinueOnError: continueOnError,
 		}
 
 		result := &ClusterOutput{Items: make([]ClusterRow, 0, len(args))}
+		var nameErrs error
 		if len(args) > 0 {
-			if err := l.ForEachNamedCluster(ctx, args, false, result.Add); err != nil {
+			var err error
+			if nameErrs, err = splitNameErrors(l.ForEachNamedCluster(ctx, args, false, result.Add)); err != nil {
 				return err
 			}
 		} else {
@@ -122,15 +125,19 @@ func NewGetClustersCommand(cfg Config, p Printer) *cobra.Command {
 			return err
 		}
 
-		return p.Fprint(out, result)
-	}
+		if err := p.Fprint(out, result); err != nil {
+			return err
+		}
+		return nameErrs
+	})
 	return cmd
 }
 
 // NewDeleteClustersCommand returns a command for deleting clusters.
 func NewDeleteClustersCommand(cfg Config, p Printer) *cobra.Command {
 	var (
-		ignoreNotFound bool
+		ignoreNotFound  bool
+		continueOnError bool
 	)
 
 	cmd := &cobra.Command{
@@ -141,15 +148,17 @@ func NewDeleteClustersCommand(cfg Config, p Printer) *cobra.Command {
 
 	cmd.Flags().BoolVar(&ignoreNotFound, "ignore-not-found", ignoreNotFound, "treat not found errors as successful deletes")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	addContinueOnErrorFlag(cmd, &continueOnError)
+
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := applications.Lister{
-			API: applications.NewAPI(client),
+			API:             applications.NewAPI(client),
+			Parallelism:     nameParallelism,
+			ContinueOnError: continueOnError,
 		}
 
 		return l.ForEachNamedCluster(ctx, args, ignoreNotFound, func(item *applications.ClusterItem) error {
@@ -164,7 +173,7 @@ func NewDeleteClustersCommand(cfg Config, p Printer) *cobra.Command {
 
 			return p.Fprint(out, NewClusterRow(item))
 		})
-	}
+	})
 	return cmd
 }
 
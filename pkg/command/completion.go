@@ -0,0 +1,315 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// defaultCompletionCacheTTL is the amount of time listed names are reused for completions.
+const defaultCompletionCacheTTL = 5 * time.Minute
+
+// labelSampleSize is the number of items sampled when looking for label keys.
+const labelSampleSize = 100
+
+// completionCache is the on-disk representation of cached completion values.
+type completionCache struct {
+	Entries map[string]completionCacheEntry `json:"entries"`
+}
+
+type completionCacheEntry struct {
+	Values  []string  `json:"values"`
+	Expires time.Time `json:"expires"`
+}
+
+// completionCacheTTL returns the amount of time cached completions remain valid, it
+// may be overridden using the `STORMFORGE_COMPLETION_CACHE_TTL` environment variable.
+func completionCacheTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("STORMFORGE_COMPLETION_CACHE_TTL")); err == nil {
+		return d
+	}
+	return defaultCompletionCacheTTL
+}
+
+// completionCacheFile returns the cache file name for the configured server
+// address and organization.
+func completionCacheFile(cfg Config) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	// Keep the completions for different servers and organizations separate
+	org, _ := SelectedOrganization(cfg)
+	sum := sha256.Sum256([]byte(cfg.Address() + "\n" + org))
+	return filepath.Join(dir, "stormforge", "completion", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// ClearCompletionCache removes the cached completions for the configured server,
+// it should be called after commands which modify the server state.
+func ClearCompletionCache(cfg Config) error {
+	filename, err := completionCacheFile(cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// cached returns the values for the supplied key, invoking the list function if necessary.
+// Only successful, non-empty results are cached. Errors reading or writing the cache are
+// ignored, in the worst case values are always listed.
+func (c *completionLister) cached(key string, list func() ([]string, error)) []string {
+	ttl := completionCacheTTL()
+	if ttl <= 0 {
+		values, _ := list()
+		return values
+	}
+
+	filename, err := completionCacheFile(c.cfg)
+	if err != nil {
+		values, _ := list()
+		return values
+	}
+
+	cache := completionCache{}
+	if data, err := os.ReadFile(filename); err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+
+	now := time.Now()
+	if e, ok := cache.Entries[key]; ok && now.Before(e.Expires) {
+		return e.Values
+	}
+
+	values, err := list()
+	if err != nil || len(values) == 0 {
+		return values
+	}
+
+	// Drop expired entries while we are updating the cache
+	if cache.Entries == nil {
+		cache.Entries = make(map[string]completionCacheEntry)
+	}
+	for k, e := range cache.Entries {
+		if !now.Before(e.Expires) {
+			delete(cache.Entries, k)
+		}
+	}
+	cache.Entries[key] = completionCacheEntry{Values: values, Expires: now.Add(ttl)}
+
+	if data, err := json.Marshal(&cache); err == nil {
+		if err := os.MkdirAll(filepath.Dir(filename), 0700); err == nil {
+			_ = os.WriteFile(filename, data, 0600)
+		}
+	}
+
+	return values
+}
+
+// applicationNames returns the names of all applications.
+func (c *completionLister) applicationNames() []string {
+	return c.cached("applications", func() (names []string, err error) {
+		err = c.forAllApplications(func(item *applications.ApplicationItem) {
+			names = append(names, item.Name.String())
+		})
+		return
+	})
+}
+
+// experimentNames returns the names of all experiments.
+func (c *completionLister) experimentNames() []string {
+	return c.cached("experiments", func() (names []string, err error) {
+		err = c.forAllExperiments(func(item *experiments.ExperimentItem) {
+			names = append(names, item.Name.String())
+		})
+		return
+	})
+}
+
+// trialNumbers returns the numbers of all the trials for an experiment.
+func (c *completionLister) trialNumbers(name experiments.ExperimentName) []string {
+	return c.cached("trials "+name.String(), func() (numbers []string, err error) {
+		l := experiments.Lister{API: experiments.NewAPI(c.client)}
+		exp, err := l.API.GetExperimentByName(c.ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		err = l.ForEachTrial(c.ctx, &exp, experiments.TrialListQuery{}, func(item *experiments.TrialItem) error {
+			numbers = append(numbers, strconv.FormatInt(item.Number, 10))
+			return nil
+		})
+		return
+	})
+}
+
+// scenarioNames returns the "APP_NAME/NAME" names of all the scenarios for an application.
+func (c *completionLister) scenarioNames(name applications.ApplicationName) []string {
+	return c.cached("scenarios "+name.String(), func() (names []string, err error) {
+		l := applications.Lister{API: applications.NewAPI(c.client)}
+		app, err := l.API.GetApplicationByName(c.ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		err = l.ForEachScenario(c.ctx, &app, applications.ScenarioListQuery{}, func(item *applications.ScenarioItem) error {
+			names = append(names, name.String()+"/"+item.Name.String())
+			return nil
+		})
+		return
+	})
+}
+
+// experimentLabelKeys returns the label keys found on a sample of experiments.
+func (c *completionLister) experimentLabelKeys() []string {
+	return c.cached("experiment-labels", func() ([]string, error) {
+		l := experiments.Lister{API: experiments.NewAPI(c.client), BatchSize: labelSampleSize}
+		keys := make(map[string]struct{})
+		n := 0
+		err := l.ForEachExperiment(c.ctx, experiments.ExperimentListQuery{}, func(item *experiments.ExperimentItem) error {
+			for k := range item.Labels {
+				keys[k] = struct{}{}
+			}
+			if n++; n >= labelSampleSize {
+				return errStopSampling
+			}
+			return nil
+		})
+		if errors.Is(err, errStopSampling) {
+			err = nil
+		}
+		return sortedKeys(keys), err
+	})
+}
+
+// trialLabelKeys returns the label keys found on a sample of trials from an experiment.
+func (c *completionLister) trialLabelKeys(name experiments.ExperimentName) []string {
+	return c.cached("trial-labels "+name.String(), func() ([]string, error) {
+		l := experiments.Lister{API: experiments.NewAPI(c.client), BatchSize: labelSampleSize}
+		exp, err := l.API.GetExperimentByName(c.ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		keys := make(map[string]struct{})
+		n := 0
+		err = l.ForEachTrial(c.ctx, &exp, experiments.TrialListQuery{}, func(item *experiments.TrialItem) error {
+			for k := range item.Labels {
+				keys[k] = struct{}{}
+			}
+			if n++; n >= labelSampleSize {
+				return errStopSampling
+			}
+			return nil
+		})
+		if errors.Is(err, errStopSampling) {
+			err = nil
+		}
+		return sortedKeys(keys), err
+	})
+}
+
+// errStopSampling is used to terminate list iteration early.
+var errStopSampling = errors.New("stop sampling")
+
+// validScenarioArgs completes application names followed by scenario names.
+func validScenarioArgs(cfg Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return validArgs(cfg, func(l *completionLister, toComplete string) (completions []string, directive cobra.ShellCompDirective) {
+		directive |= cobra.ShellCompDirectiveNoFileComp
+
+		// Once there is a slash, we know which application to list scenarios for
+		if appName, _ := applications.SplitScenarioName(toComplete); strings.Contains(toComplete, "/") {
+			return filterPrefix(l.scenarioNames(appName), toComplete), directive
+		}
+
+		for _, name := range filterPrefix(l.applicationNames(), toComplete) {
+			completions = append(completions, name+"/")
+		}
+		directive |= cobra.ShellCompDirectiveNoSpace
+		return
+	})
+}
+
+// validLabelArgs completes "key=" for label flags using keys sampled from existing resources.
+func validLabelArgs(cfg Config, keys func(l *completionLister, args []string) []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// Only complete the last key of a comma separated list
+		prefix := ""
+		if p := strings.LastIndex(toComplete, ","); p >= 0 {
+			prefix, toComplete = toComplete[:p+1], toComplete[p+1:]
+		}
+		if strings.Contains(toComplete, "=") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return validArgs(cfg, func(l *completionLister, toComplete string) (completions []string, directive cobra.ShellCompDirective) {
+			directive |= cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+			for _, k := range filterPrefix(keys(l, args), toComplete) {
+				completions = append(completions, prefix+k+"=")
+			}
+			return
+		})(cmd, args, toComplete)
+	}
+}
+
+// experimentLabelKeys is used with validLabelArgs to complete experiment label keys.
+func experimentLabelKeys(l *completionLister, _ []string) []string {
+	return l.experimentLabelKeys()
+}
+
+// trialLabelKeys is used with validLabelArgs to complete trial label keys of the experiment in the first argument.
+func trialLabelKeys(l *completionLister, args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	expName, _ := experiments.SplitTrialName(args[0])
+	return l.trialLabelKeys(expName)
+}
+
+// filterPrefix returns the values with the supplied prefix.
+func filterPrefix(values []string, prefix string) []string {
+	var result []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the sorted keys of a set.
+func sortedKeys(m map[string]struct{}) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
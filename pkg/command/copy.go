@@ -0,0 +1,147 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// ConfigLoader loads an alternate configuration (e.g. for a different server
+// or tenant) along with a transport that authorizes requests using the
+// alternate credentials.
+type ConfigLoader func(ctx context.Context, filename string) (Config, http.RoundTripper, error)
+
+// copyOptions holds the flags shared by the copy commands.
+type copyOptions struct {
+	toConfig   string
+	name       string
+	onConflict string
+}
+
+func (o *copyOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.toConfig, "to-config", "", "configuration `file` for the destination server")
+	cmd.Flags().StringVar(&o.name, "name", "", "`name` of the copy, defaults to the source name")
+	cmd.Flags().StringVar(&o.onConflict, "on-conflict", string(api.ConflictFail), "`policy` for existing names; one of: fail|skip|overwrite|rename|suffix|adopt")
+	_ = cmd.MarkFlagRequired("to-config")
+}
+
+// destination returns the destination client along with the conflict policy.
+func (o *copyOptions) destination(ctx context.Context, load ConfigLoader) (dst api.Client, onConflict api.ConflictPolicy, err error) {
+	if onConflict, err = api.ParseConflictPolicy(o.onConflict); err != nil {
+		return
+	}
+
+	dstCfg, dstTransport, err := load(ctx, o.toConfig)
+	if err != nil {
+		return
+	}
+
+	// Log the destination requests along with the source requests
+	if w, ok := ctx.Value(debugKey{}).(io.Writer); ok {
+		dstTransport = &debugTransport{Base: dstTransport, Out: w}
+	}
+
+	dst, err = api.NewClient(dstCfg.Address(), dstTransport)
+	return
+}
+
+// NewCopyApplicationCommand returns a command for copying an application to a different server.
+func NewCopyApplicationCommand(cfg Config, load ConfigLoader, p Printer) *cobra.Command {
+	var (
+		opts copyOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:               "application NAME",
+		Aliases:           []string{"app"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validApplicationArgs(cfg),
+	}
+
+	opts.addFlags(cmd)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, src api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+		dst, onConflict, err := opts.destination(ctx, load)
+		if err != nil {
+			return err
+		}
+
+		dstAPI := applications.NewAPI(dst)
+		name, err := applications.CopyApplication(ctx, applications.NewAPI(src), dstAPI, applications.ApplicationName(args[0]), applications.CopyOptions{
+			Name:       applications.ApplicationName(opts.name),
+			OnConflict: onConflict,
+		})
+		if err != nil || name == "" {
+			return err
+		}
+
+		app, err := dstAPI.GetApplicationByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		return p.Fprint(out, NewApplicationRow(&applications.ApplicationItem{Application: app}))
+	})
+	return cmd
+}
+
+// NewCopyExperimentCommand returns a command for copying an experiment to a different server.
+func NewCopyExperimentCommand(cfg Config, load ConfigLoader, p Printer) *cobra.Command {
+	var (
+		opts copyOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:               "experiment NAME",
+		Aliases:           []string{"exp"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validExperimentArgs(cfg),
+	}
+
+	opts.addFlags(cmd)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, src api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+		dst, onConflict, err := opts.destination(ctx, load)
+		if err != nil {
+			return err
+		}
+
+		dstAPI := experiments.NewAPI(dst)
+		name, err := experiments.CopyExperiment(ctx, experiments.NewAPI(src), dstAPI, experiments.ExperimentName(args[0]), experiments.CopyOptions{
+			Name:       experiments.ExperimentName(opts.name),
+			OnConflict: onConflict,
+		})
+		if err != nil || name == "" {
+			return err
+		}
+
+		exp, err := dstAPI.GetExperimentByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		return p.Fprint(out, NewExperimentRow(&experiments.ExperimentItem{Experiment: exp}))
+	})
+	return cmd
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	"github.com/thestormforge/optimize-go/pkg/cost"
+)
+
+// costOptions are the flags used to configure cost estimates.
+type costOptions struct {
+	provider string
+	prices   map[string]string
+}
+
+// addCostFlags adds the cost estimate flags to the command.
+func addCostFlags(cmd *cobra.Command, opts *costOptions) {
+	cmd.Flags().StringVar(&opts.provider, "pricing", cost.DefaultProvider, "cloud `provider` prices used to estimate cost savings; one of: aws|azure|gcp")
+	cmd.Flags().StringToStringVar(&opts.prices, "price", nil, "override the monthly `resource=price` used to estimate cost savings (e.g. cpu=20)")
+	_ = cmd.RegisterFlagCompletionFunc("pricing", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return []string{"aws", "azure", "gcp"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// pricing returns the configured pricing table.
+func (opts *costOptions) pricing() (cost.Pricing, error) {
+	return cost.NewPricing(opts.provider, opts.prices)
+}
+
+// listWorkloads returns all the workloads of an application.
+func listWorkloads(ctx context.Context, l applications.Lister, app *applications.Application) ([]applications.WorkloadItem, error) {
+	var workloads []applications.WorkloadItem
+	err := l.ForEachWorkload(ctx, app, func(w *applications.WorkloadItem) error {
+		workloads = append(workloads, *w)
+		return nil
+	})
+	return workloads, err
+}
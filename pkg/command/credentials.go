@@ -0,0 +1,182 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+// NewCreateCredentialCommand returns a command for creating a machine credential.
+func NewCreateCredentialCommand(cfg Config) *cobra.Command {
+	var (
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:  "credential NAME",
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "env", "the output `format` to use; one of: env|json")
+
+	_ = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"env", "json"}, cobra.ShellCompDirectiveDefault
+	})
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		appAPI := applications.NewAPI(client)
+
+		if output != "env" && output != "json" {
+			return fmt.Errorf("unknown output format: %s", output)
+		}
+
+		c, err := appAPI.CreateCredentialByName(ctx, applications.CredentialName(args[0]), applications.Credential{})
+		if err != nil {
+			return err
+		}
+
+		// The secret is only returned once, make sure the user knows to keep it
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "created credential %q, the client secret cannot be retrieved again.\n", args[0])
+
+		if output == "json" {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(&c)
+		}
+
+		_, err = fmt.Fprintf(out, "STORMFORGE_CLIENT_ID=%s\nSTORMFORGE_CLIENT_SECRET=%s\n", c.ClientID, c.ClientSecret)
+		return err
+	})
+	return cmd
+}
+
+// NewGetCredentialsCommand returns a command for getting machine credentials.
+func NewGetCredentialsCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		sortBy string
+
+		continueOnError bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "credentials [NAME ...]",
+		Annotations:       map[string]string{annotationOutput: "credential"},
+		Aliases:           []string{"credential"},
+		ValidArgsFunction: validCredentialArgs(cfg),
+	}
+
+	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+
+	addContinueOnErrorFlag(cmd, &continueOnError)
+
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := applications.Lister{
+			API:             applications.NewAPI(client),
+			Parallelism:     nameParallelism,
+			ContinueOnError: continueOnError,
+		}
+
+		result := &CredentialOutput{Items: make([]CredentialRow, 0, len(args))}
+		var nameErrs error
+		if len(args) > 0 {
+			var err error
+			if nameErrs, err = splitNameErrors(l.ForEachNamedCredential(ctx, args, false, result.Add)); err != nil {
+				return err
+			}
+		} else if err := l.ForEachCredential(ctx, result.Add); err != nil {
+			return err
+		}
+
+		if err := result.SortBy(sortBy); err != nil {
+			return err
+		}
+
+		if err := p.Fprint(out, result); err != nil {
+			return err
+		}
+		return nameErrs
+	})
+	return cmd
+}
+
+// NewRevokeCredentialsCommand returns a command for revoking machine credentials.
+func NewRevokeCredentialsCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		ignoreNotFound  bool
+		continueOnError bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "credentials [NAME ...]",
+		Aliases:           []string{"credential"},
+		ValidArgsFunction: validCredentialArgs(cfg),
+	}
+
+	cmd.Flags().BoolVar(&ignoreNotFound, "ignore-not-found", ignoreNotFound, "treat not found errors as successful revocations")
+
+	addContinueOnErrorFlag(cmd, &continueOnError)
+
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := applications.Lister{
+			API:             applications.NewAPI(client),
+			Parallelism:     nameParallelism,
+			ContinueOnError: continueOnError,
+		}
+
+		return l.ForEachNamedCredential(ctx, args, ignoreNotFound, func(item *applications.CredentialItem) error {
+			selfURL := item.Link(api.RelationSelf)
+			if selfURL == "" {
+				return fmt.Errorf("malformed response, missing self link")
+			}
+
+			if err := l.API.RevokeCredential(ctx, selfURL); err != nil {
+				return err
+			}
+
+			return p.Fprint(out, NewCredentialRow(item))
+		})
+	})
+	return cmd
+}
+
+func validCredentialArgs(cfg Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return validArgs(cfg, func(l *completionLister, toComplete string) (completions []string, directive cobra.ShellCompDirective) {
+		directive |= cobra.ShellCompDirectiveNoFileComp
+		l.forAllCredentials(func(item *applications.CredentialItem) {
+			if strings.HasPrefix(item.Name.String(), toComplete) {
+				completions = append(completions, item.Name.String())
+			}
+		})
+		return
+	})
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// Description is a detailed, human-readable view of a resource assembled from
+// one or more API calls.
+type Description struct {
+	// The top level fields of the description.
+	Fields []DescriptionField `json:"fields"`
+}
+
+// DescriptionField is a labeled value, optionally with nested fields.
+type DescriptionField struct {
+	Label  string             `json:"label"`
+	Value  string             `json:"value,omitempty"`
+	Fields []DescriptionField `json:"fields,omitempty"`
+}
+
+// Add appends a new field and returns it so nested fields may be added.
+func (d *DescriptionField) Add(label string, value interface{}) *DescriptionField {
+	d.Fields = append(d.Fields, DescriptionField{Label: label, Value: describeValue(value)})
+	return &d.Fields[len(d.Fields)-1]
+}
+
+// Add appends a new top level field and returns it so nested fields may be added.
+func (d *Description) Add(label string, value interface{}) *DescriptionField {
+	d.Fields = append(d.Fields, DescriptionField{Label: label, Value: describeValue(value)})
+	return &d.Fields[len(d.Fields)-1]
+}
+
+// describeValue formats a value for display.
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *time.Time:
+		return formatTime(v, time.RFC3339)
+	case time.Time:
+		return formatTime(&v, time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// DescriberPrinter renders descriptions as indented, multi-section text. Any
+// other object is rendered using the fallback printer.
+type DescriberPrinter struct {
+	// The printer used for everything that is not a description.
+	Fallback Printer
+}
+
+// Fprint renders the supplied object.
+func (p *DescriberPrinter) Fprint(out io.Writer, obj interface{}) error {
+	d, ok := obj.(*Description)
+	if !ok {
+		if p.Fallback == nil {
+			return fmt.Errorf("unable to describe %T", obj)
+		}
+		return p.Fallback.Fprint(out, obj)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	writeDescriptionFields(w, d.Fields, 0)
+	return w.Flush()
+}
+
+func writeDescriptionFields(w io.Writer, fields []DescriptionField, level int) {
+	prefix := strings.Repeat("  ", level)
+	for _, f := range fields {
+		if f.Label == "" {
+			_, _ = fmt.Fprintf(w, "%s%s\n", prefix, f.Value)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s%s:\t%s\n", prefix, f.Label, f.Value)
+		}
+		writeDescriptionFields(w, f.Fields, level+1)
+	}
+}
+
+// NewDescribeApplicationCommand returns a command for describing an application.
+func NewDescribeApplicationCommand(cfg Config, p Printer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "application NAME",
+		Aliases:           []string{"app"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validApplicationArgs(cfg),
+	}
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := applications.Lister{
+			API: applications.NewAPI(client),
+		}
+
+		app, err := l.API.GetApplicationByName(ctx, applications.ApplicationName(args[0]))
+		if err != nil {
+			return err
+		}
+
+		d := &Description{}
+		d.Add("Name", args[0])
+		d.Add("Title", app.DisplayName)
+		d.Add("Created", app.CreatedAt)
+
+		resources := d.Add("Resources", "")
+		for _, r := range app.Resources {
+			k := r.Kubernetes
+			ns := k.Namespace
+			if len(k.Namespaces) > 0 {
+				ns = strings.Join(k.Namespaces, ", ")
+			}
+			rd := resources.Add("Namespace", ns)
+			if k.NamespaceSelector != "" {
+				rd.Add("Namespace Selector", k.NamespaceSelector)
+			}
+			if k.Selector != "" {
+				rd.Add("Selector", k.Selector)
+			}
+			if len(k.Types) > 0 {
+				rd.Add("Types", strings.Join(k.Types, ", "))
+			}
+		}
+
+		scenarios := d.Add("Scenarios", "")
+		if err := l.ForEachScenario(ctx, &app, applications.ScenarioListQuery{}, func(item *applications.ScenarioItem) error {
+			scenarios.Add("", item.Name.String())
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if u := app.Link(api.RelationRecommendations); u != "" {
+			recs, err := l.API.ListRecommendations(ctx, u)
+			if err != nil {
+				return err
+			}
+
+			rd := d.Add("Recommendations", "")
+			if recs.DeployConfiguration != nil {
+				rd.Add("Mode", string(recs.DeployConfiguration.Mode))
+				rd.Add("Interval", recs.DeployConfiguration.Interval)
+				if len(recs.DeployConfiguration.Clusters) > 0 {
+					rd.Add("Clusters", strings.Join(recs.DeployConfiguration.Clusters, ", "))
+				}
+			}
+			for _, rec := range recs.Recommendations {
+				deployed := "not deployed"
+				if rec.DeployedAt != nil {
+					deployed = "deployed " + formatTime(rec.DeployedAt, "ago")
+				}
+				rd.Add(rec.Name, deployed)
+			}
+		}
+
+		return p.Fprint(out, d)
+	})
+	return cmd
+}
+
+// NewDescribeExperimentCommand returns a command for describing an experiment.
+func NewDescribeExperimentCommand(cfg Config, p Printer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "experiment NAME",
+		Aliases:           []string{"exp"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validExperimentArgs(cfg),
+	}
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := experiments.Lister{
+			API: experiments.NewAPI(client),
+		}
+
+		exp, err := l.API.GetExperimentByName(ctx, experiments.ExperimentName(args[0]))
+		if err != nil {
+			return err
+		}
+
+		d := &Description{}
+		d.Add("Name", exp.Name)
+		d.Add("Display Name", exp.DisplayName)
+		d.Add("Budget", exp.Budget)
+		d.Add("Observations", exp.Observations)
+
+		labels := d.Add("Labels", "")
+		keys := make([]string, 0, len(exp.Labels))
+		for k := range exp.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			labels.Add("", k+"="+exp.Labels[k])
+		}
+
+		params := d.Add("Parameters", "")
+		for _, p := range exp.Parameters {
+			switch {
+			case p.Bounds != nil:
+				params.Add(p.Name, fmt.Sprintf("%s [%s, %s]", p.Type, p.Bounds.Min, p.Bounds.Max))
+			case len(p.Values) > 0:
+				params.Add(p.Name, fmt.Sprintf("%s {%s}", p.Type, strings.Join(p.Values, ", ")))
+			default:
+				params.Add(p.Name, p.Type)
+			}
+		}
+
+		metrics := d.Add("Metrics", "")
+		for _, m := range exp.Metrics {
+			goal := "maximize"
+			if m.Minimize {
+				goal = "minimize"
+			}
+			if m.Optimize != nil && !*m.Optimize {
+				goal = "not optimized"
+			}
+			metrics.Add(m.Name, goal)
+		}
+
+		// Summarize the trials by status
+		counts := make(map[experiments.TrialStatus]int)
+		total := 0
+		if err := l.ForEachTrial(ctx, &exp, experiments.TrialListQuery{}, func(item *experiments.TrialItem) error {
+			counts[item.Status]++
+			total++
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		trials := d.Add("Trials", total)
+		for _, s := range []experiments.TrialStatus{
+			experiments.TrialStaged,
+			experiments.TrialActive,
+			experiments.TrialCompleted,
+			experiments.TrialFailed,
+			experiments.TrialAbandoned,
+		} {
+			if counts[s] > 0 {
+				trials.Add(string(s), counts[s])
+			}
+		}
+
+		return p.Fprint(out, d)
+	})
+	return cmd
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/snapshot"
+	"sigs.k8s.io/yaml"
+)
+
+// NewDiffExperimentsCommand returns a command for comparing the definitions of two experiments.
+func NewDiffExperimentsCommand(cfg Config) *cobra.Command {
+	var (
+		contextLines int
+	)
+
+	cmd := &cobra.Command{
+		Use:               "experiments NAME1 NAME2",
+		Aliases:           []string{"experiment", "exp"},
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: validExperimentArgs(cfg),
+	}
+
+	cmd.Flags().IntVarP(&contextLines, "unified", "U", 3, "the `number` of context lines to show")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		expAPI := experiments.NewAPI(client)
+
+		var defs []experimentDefinition
+		for _, name := range args {
+			exp, err := expAPI.GetExperimentByName(ctx, experiments.ExperimentName(name))
+			if err != nil {
+				return err
+			}
+			defs = append(defs, newExperimentDefinition(&exp))
+		}
+
+		return writeUnifiedDiff(out, args[0], args[1], defs[0], defs[1], contextLines)
+	})
+	return cmd
+}
+
+// NewDiffApplicationCommand returns a command for comparing an application to a previously recorded snapshot.
+func NewDiffApplicationCommand(cfg Config) *cobra.Command {
+	var (
+		dir          string
+		since        string
+		contextLines int
+	)
+
+	cmd := &cobra.Command{
+		Use:               "application NAME",
+		Aliases:           []string{"app"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validApplicationArgs(cfg),
+	}
+
+	cmd.Flags().StringVar(&dir, "snapshot-dir", "", "`directory` used to store snapshots")
+	cmd.Flags().StringVar(&since, "since", "", "compare to the snapshot at this `time` (RFC 3339 or a duration ago), defaults to the latest snapshot")
+	cmd.Flags().IntVarP(&contextLines, "unified", "U", 3, "the `number` of context lines to show")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		store, err := snapshotStore(cfg, dir)
+		if err != nil {
+			return err
+		}
+
+		sinceTime, err := parseTimeOrAgo(since)
+		if err != nil {
+			return err
+		}
+
+		snap, err := store.LoadAt(sinceTime)
+		if err != nil {
+			return err
+		}
+
+		from, err := snapshotApplicationDefinition(snap, args[0])
+		if err != nil {
+			return err
+		}
+
+		l := applications.Lister{
+			API: applications.NewAPI(client),
+		}
+
+		app, err := l.API.GetApplicationByName(ctx, applications.ApplicationName(args[0]))
+		if err != nil {
+			return err
+		}
+
+		to := applicationDefinition{Title: app.DisplayName, Resources: app.Resources}
+		if err := l.ForEachScenario(ctx, &app, applications.ScenarioListQuery{}, func(item *applications.ScenarioItem) error {
+			to.addScenario(&item.Scenario)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return writeUnifiedDiff(out,
+			fmt.Sprintf("%s (%s)", args[0], snap.Timestamp.Format(time.RFC3339)),
+			fmt.Sprintf("%s (current)", args[0]),
+			from, to, contextLines)
+	})
+	return cmd
+}
+
+// applicationDefinition is the comparable portion of an application and its scenarios.
+type applicationDefinition struct {
+	Title     string                           `json:"title,omitempty"`
+	Resources []applications.Resource          `json:"resources,omitempty"`
+	Scenarios map[string]applications.Scenario `json:"scenarios,omitempty"`
+}
+
+// addScenario includes a scenario in the application definition.
+func (def *applicationDefinition) addScenario(scn *applications.Scenario) {
+	if def.Scenarios == nil {
+		def.Scenarios = make(map[string]applications.Scenario)
+	}
+	def.Scenarios[scn.Name.String()] = *scn
+}
+
+// snapshotApplicationDefinition returns the definition of the named application as it was recorded in a snapshot.
+func snapshotApplicationDefinition(snap *snapshot.Snapshot, name string) (applicationDefinition, error) {
+	var def applicationDefinition
+
+	app := applications.Application{}
+	if ok, err := snap.Get(snapshot.KindApplication, name, &app); err != nil {
+		return def, err
+	} else if !ok {
+		return def, fmt.Errorf("application %q was not recorded in the snapshot at %s, run sync to record it",
+			name, snap.Timestamp.Format(time.RFC3339))
+	}
+
+	def.Title = app.DisplayName
+	def.Resources = app.Resources
+
+	prefix := name + "/"
+	for _, key := range snap.Names(snapshot.KindScenario, prefix) {
+		scn := applications.Scenario{}
+		if _, err := snap.Get(snapshot.KindScenario, key, &scn); err != nil {
+			return def, err
+		}
+		if scn.Name == "" {
+			scn.Name = applications.ScenarioName(strings.TrimPrefix(key, prefix))
+		}
+		def.addScenario(&scn)
+	}
+
+	return def, nil
+}
+
+// experimentDefinition is the comparable portion of an experiment.
+type experimentDefinition struct {
+	Parameters   []experiments.Parameter    `json:"parameters,omitempty"`
+	Metrics      []experiments.Metric       `json:"metrics,omitempty"`
+	Constraints  []experiments.Constraint   `json:"constraints,omitempty"`
+	Optimization []experiments.Optimization `json:"optimization,omitempty"`
+	Budget       int64                      `json:"budget,omitempty"`
+	Labels       map[string]string          `json:"labels,omitempty"`
+}
+
+// newExperimentDefinition returns the definition of an experiment, lists which
+// are not order sensitive are sorted so only meaningful differences are reported.
+func newExperimentDefinition(exp *experiments.Experiment) experimentDefinition {
+	def := experimentDefinition{
+		Parameters:   append([]experiments.Parameter(nil), exp.Parameters...),
+		Metrics:      append([]experiments.Metric(nil), exp.Metrics...),
+		Constraints:  exp.Constraints,
+		Optimization: append([]experiments.Optimization(nil), exp.Optimization...),
+		Budget:       exp.Budget,
+		Labels:       exp.Labels,
+	}
+
+	sort.SliceStable(def.Parameters, func(i, j int) bool { return def.Parameters[i].Name < def.Parameters[j].Name })
+	sort.SliceStable(def.Metrics, func(i, j int) bool { return def.Metrics[i].Name < def.Metrics[j].Name })
+	sort.SliceStable(def.Optimization, func(i, j int) bool { return def.Optimization[i].Name < def.Optimization[j].Name })
+	return def
+}
+
+// writeUnifiedDiff writes the unified diff of the YAML representations of the supplied values.
+func writeUnifiedDiff(out io.Writer, fromName, toName string, from, to interface{}, contextLines int) error {
+	a, err := yaml.Marshal(from)
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(to)
+	if err != nil {
+		return err
+	}
+
+	return difflib.WriteUnifiedDiff(out, difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(a)),
+		B:        difflib.SplitLines(string(b)),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  contextLines,
+	})
+}
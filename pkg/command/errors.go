@@ -0,0 +1,125 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// Exit codes used to distinguish the type of failure.
+const (
+	ExitError    = 1
+	ExitNotFound = 2
+	ExitConflict = 3
+	ExitAuth     = 4
+	ExitServer   = 5
+)
+
+// ErrorOutput is the machine-readable representation of a command error.
+type ErrorOutput struct {
+	Message   string `json:"error"`
+	Type      string `json:"type,omitempty"`
+	Location  string `json:"location,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	ExitCode  int    `json:"exitCode"`
+}
+
+// ExitCode returns the process exit code for the supplied error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	// Multiple failures only have a specific exit code if they all agree
+	var nameErrs api.NameErrorList
+	if errors.As(err, &nameErrs) {
+		code := ExitCode(nameErrs[0])
+		for _, e := range nameErrs[1:] {
+			if ExitCode(e) != code {
+				return ExitError
+			}
+		}
+		return code
+	}
+
+	if api.IsUnauthorized(err) {
+		return ExitAuth
+	}
+	if api.IsNotFound(err) {
+		return ExitNotFound
+	}
+	if api.IsConflict(err) {
+		return ExitConflict
+	}
+	if api.IsUnavailable(err) {
+		return ExitServer
+	}
+
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusForbidden:
+			return ExitAuth
+		case apiErr.StatusCode == http.StatusConflict,
+			strings.HasSuffix(string(apiErr.Type), "-exists"),
+			strings.HasSuffix(string(apiErr.Type), "-conflict"):
+			return ExitConflict
+		case apiErr.StatusCode >= http.StatusInternalServerError:
+			return ExitServer
+		}
+	}
+
+	return ExitError
+}
+
+// HandleError reports a failure of the supplied root command using the format
+// selected by the `--error-format` flag and returns the process exit code.
+func HandleError(cmd *cobra.Command, err error) int {
+	code := ExitCode(err)
+	if code == 0 {
+		return 0
+	}
+
+	out := ErrorOutput{Message: err.Error(), ExitCode: code}
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		out.Type = string(apiErr.Type)
+		out.Location = apiErr.Location
+		out.RequestID = apiErr.RequestID
+	}
+
+	w := cmd.ErrOrStderr()
+	if f := cmd.PersistentFlags().Lookup("error-format"); f == nil || f.Value.String() != "json" {
+		_, _ = fmt.Fprintln(w, "Error:", out.Message)
+		if out.RequestID != "" {
+			_, _ = fmt.Fprintln(w, "server request ID:", out.RequestID)
+		}
+		return code
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(&out)
+	return code
+}
@@ -18,16 +18,127 @@ package command
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/thestormforge/optimize-go/pkg/api"
 	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/preset"
+	"sigs.k8s.io/yaml"
 )
 
+// NewCreateExperimentCommand returns a command for creating an experiment from a file.
+func NewCreateExperimentCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		filename   string
+		dryRun     bool
+		onConflict = string(api.ConflictOverwrite)
+		presetOpts presetOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:     "experiment NAME",
+		Aliases: []string{"exp"},
+		Args:    cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "`file` containing the JSON or YAML experiment definition")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "validate the experiment definition without creating it")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", onConflict, "`policy` for an existing name; one of: fail|skip|overwrite|rename|suffix|adopt")
+	addPresetFlags(cmd, &presetOpts)
+	cmd.MarkFlagsMutuallyExclusive("filename", "preset")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		policy, err := api.ParseConflictPolicy(onConflict)
+		if err != nil {
+			return err
+		}
+
+		var exp experiments.Experiment
+		switch {
+		case presetOpts.name != "":
+			exp, err = presetOpts.experiment(args[0])
+		case filename != "":
+			exp, err = readExperiment(cmd.InOrStdin(), filename)
+		default:
+			err = fmt.Errorf("either --filename or --preset is required")
+		}
+		if err != nil {
+			return err
+		}
+
+		// Validate locally so all the problems are reported at once
+		if err := experiments.Validate(&exp); err != nil {
+			return fmt.Errorf("invalid experiment definition:\n%s", indent(err.Error()))
+		}
+
+		name := experiments.ExperimentName(args[0])
+		if dryRun {
+			exp.Name = name
+			return p.Fprint(out, NewExperimentRow(&experiments.ExperimentItem{Experiment: exp}))
+		}
+
+		expAPI := experiments.NewAPI(client)
+
+		exp, err = experiments.CreateExperimentByNameWithOptions(ctx, expAPI, name, exp, experiments.CreateOptions{OnConflict: policy})
+		if err != nil {
+			return err
+		}
+
+		return p.Fprint(out, NewExperimentRow(&experiments.ExperimentItem{Experiment: exp}))
+	})
+	return cmd
+}
+
+// presetOptions are the flags used to generate an experiment from a preset.
+type presetOptions struct {
+	name        string
+	cpu         string
+	memory      string
+	maxReplicas int64
+}
+
+// addPresetFlags adds the preset flags to the command.
+func addPresetFlags(cmd *cobra.Command, opts *presetOptions) {
+	cmd.Flags().StringVar(&opts.name, "preset", "", fmt.Sprintf("generate the experiment from a preset `name`; one of: %s", strings.Join(preset.Names(), "|")))
+	cmd.Flags().StringVar(&opts.cpu, "cpu", "", "current CPU request `quantity` of the workload used to center the preset (e.g. 500m)")
+	cmd.Flags().StringVar(&opts.memory, "memory", "", "current memory request `quantity` of the workload used to center the preset (e.g. 2Gi)")
+	cmd.Flags().Int64Var(&opts.maxReplicas, "max-replicas", 0, "maximum `number` of replicas for the hpa preset")
+	_ = cmd.RegisterFlagCompletionFunc("preset", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return preset.Names(), cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// experiment returns the experiment generated from the preset.
+func (opts *presetOptions) experiment(workload string) (experiments.Experiment, error) {
+	p, err := preset.Get(opts.name)
+	if err != nil {
+		return experiments.Experiment{}, err
+	}
+
+	w := preset.Workload{Name: workload, MaxReplicas: opts.maxReplicas}
+	if opts.cpu != "" {
+		if w.CPU, err = api.ParseQuantity(opts.cpu); err != nil {
+			return experiments.Experiment{}, fmt.Errorf("invalid CPU quantity %q: %w", opts.cpu, err)
+		}
+	}
+	if opts.memory != "" {
+		if w.Memory, err = api.ParseQuantity(opts.memory); err != nil {
+			return experiments.Experiment{}, fmt.Errorf("invalid memory quantity %q: %w", opts.memory, err)
+		}
+	}
+
+	return p.Experiment(w)
+}
+
 // NewEditExperimentCommand returns a command for editing an experiment.
 func NewEditExperimentCommand(cfg Config, p Printer) *cobra.Command {
 	var (
+		title  string
+		budget int64
 		labels map[string]string
 	)
 
@@ -38,20 +149,43 @@ func NewEditExperimentCommand(cfg Config, p Printer) *cobra.Command {
 		ValidArgsFunction: validExperimentArgs(cfg),
 	}
 
+	cmd.Flags().StringVar(&title, "title", "", "human readable `name` for the experiment")
+	cmd.Flags().Int64Var(&budget, "budget", 0, "target `number` of observations for the experiment")
 	cmd.Flags().StringToStringVar(&labels, "set-label", nil, "label `key=value` pairs to assign")
+	_ = cmd.RegisterFlagCompletionFunc("set-label", validLabelArgs(cfg, experimentLabelKeys))
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := experiments.Lister{
 			API: experiments.NewAPI(client),
 		}
 
 		return l.ForEachNamedExperiment(ctx, args, false, func(item *experiments.ExperimentItem) error {
+			// Patch only the changed fields
+			if title != "" || budget > 0 {
+				selfURL := item.Link(api.RelationSelf)
+				if selfURL == "" {
+					return fmt.Errorf("malformed response, missing self link")
+				}
+
+				patch := experiments.ExperimentPatch{
+					Metadata:    item.Preconditions(),
+					DisplayName: title,
+					Budget:      budget,
+				}
+				if err := l.API.PatchExperiment(ctx, selfURL, patch); err != nil {
+					return err
+				}
+
+				if title != "" {
+					item.DisplayName = title
+				}
+				if budget > 0 {
+					item.Budget = budget
+				}
+			}
+
 			// Apply label changes
 			if len(labels) > 0 {
 				labelsURL := item.Link(api.RelationLabels)
@@ -66,43 +200,54 @@ func NewEditExperimentCommand(cfg Config, p Printer) *cobra.Command {
 
 			return p.Fprint(out, NewExperimentRow(item))
 		})
-	}
+	})
 	return cmd
 }
 
 // NewGetExperimentsCommand returns a command for getting experiments.
 func NewGetExperimentsCommand(cfg Config, p Printer) *cobra.Command {
 	var (
-		batchSize int
-		selector  string
-		sortBy    string
+		batchSize       int
+		selector        string
+		sortBy          string
+		includeArchived bool
+
+		continueOnError bool
 	)
 
 	cmd := &cobra.Command{
 		Use:               "experiments [NAME ...]",
+		Annotations:       map[string]string{annotationOutput: "experiment"},
 		Aliases:           []string{"experiment", "exps", "exp"},
 		ValidArgsFunction: validExperimentArgs(cfg),
 	}
 
 	cmd.Flags().IntVar(&batchSize, "chunk-size", 500, "fetch large lists in chu`n`ks rather then all at once")
 	cmd.Flags().StringVarP(&selector, "selector", "l", selector, "selector (label `query`) to filter on")
+	_ = cmd.RegisterFlagCompletionFunc("selector", validLabelArgs(cfg, experimentLabelKeys))
 	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+	cmd.Flags().BoolVar(&includeArchived, "include-archived", false, "include archived experiments")
+
+	addContinueOnErrorFlag(cmd, &continueOnError)
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := experiments.Lister{
-			API:       experiments.NewAPI(client),
-			BatchSize: batchSize,
+			API:             experiments.NewAPI(client),
+			BatchSize:       batchSize,
+			Parallelism:     nameParallelism,
+			ContinueOnError: continueOnError,
+			ExcludeArchived: !includeArchived,
 		}
 
 		result := &ExperimentOutput{Items: make([]ExperimentRow, 0, len(args))}
+		var nameErrs error
 		if len(args) > 0 {
-			if err := l.ForEachNamedExperiment(ctx, args, false, result.Add); err != nil {
+			var err error
+			if nameErrs, err = splitNameErrors(l.ForEachNamedExperiment(ctx, args, false, result.Add)); err != nil {
 				return err
 			}
 		} else {
@@ -117,15 +262,19 @@ func NewGetExperimentsCommand(cfg Config, p Printer) *cobra.Command {
 			return err
 		}
 
-		return p.Fprint(out, result)
-	}
+		if err := p.Fprint(out, result); err != nil {
+			return err
+		}
+		return nameErrs
+	})
 	return cmd
 }
 
 // NewDeleteExperimentsCommand returns a command for deleting experiments.
 func NewDeleteExperimentsCommand(cfg Config, p Printer) *cobra.Command {
 	var (
-		ignoreNotFound bool
+		ignoreNotFound  bool
+		continueOnError bool
 	)
 
 	cmd := &cobra.Command{
@@ -136,15 +285,17 @@ func NewDeleteExperimentsCommand(cfg Config, p Printer) *cobra.Command {
 
 	cmd.Flags().BoolVar(&ignoreNotFound, "ignore-not-found", ignoreNotFound, "treat not found errors as successful deletes")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	addContinueOnErrorFlag(cmd, &continueOnError)
+
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := experiments.Lister{
-			API: experiments.NewAPI(client),
+			API:             experiments.NewAPI(client),
+			Parallelism:     nameParallelism,
+			ContinueOnError: continueOnError,
 		}
 
 		return l.ForEachNamedExperiment(ctx, args, ignoreNotFound, func(item *experiments.ExperimentItem) error {
@@ -159,18 +310,78 @@ func NewDeleteExperimentsCommand(cfg Config, p Printer) *cobra.Command {
 
 			return p.Fprint(out, NewExperimentRow(item))
 		})
+	})
+	return cmd
+}
+
+// NewArchiveExperimentsCommand returns a command for archiving experiments.
+func NewArchiveExperimentsCommand(cfg Config, p Printer) *cobra.Command {
+	return newArchiveExperimentsCommand(cfg, p, true)
+}
+
+// NewUnarchiveExperimentsCommand returns a command for restoring archived experiments.
+func NewUnarchiveExperimentsCommand(cfg Config, p Printer) *cobra.Command {
+	return newArchiveExperimentsCommand(cfg, p, false)
+}
+
+// newArchiveExperimentsCommand returns a command for changing the archived state of experiments.
+func newArchiveExperimentsCommand(cfg Config, p Printer, archived bool) *cobra.Command {
+	var (
+		continueOnError bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "experiments [NAME ...]",
+		Aliases:           []string{"experiment", "exps", "exp"},
+		ValidArgsFunction: validExperimentArgs(cfg),
 	}
+
+	addContinueOnErrorFlag(cmd, &continueOnError)
+
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := experiments.Lister{
+			API:             experiments.NewAPI(client),
+			Parallelism:     nameParallelism,
+			ContinueOnError: continueOnError,
+		}
+
+		return l.ForEachNamedExperiment(ctx, args, false, func(item *experiments.ExperimentItem) error {
+			if item.IsArchived() != archived {
+				if err := experiments.ArchiveExperiment(ctx, l.API, &item.Experiment, archived); err != nil {
+					return err
+				}
+			}
+
+			return p.Fprint(out, NewExperimentRow(item))
+		})
+	})
 	return cmd
 }
 
 func validExperimentArgs(cfg Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
 	return validArgs(cfg, func(l *completionLister, toComplete string) (completions []string, directive cobra.ShellCompDirective) {
 		directive |= cobra.ShellCompDirectiveNoFileComp
-		l.forAllExperiments(func(item *experiments.ExperimentItem) {
-			if strings.HasPrefix(item.Name.String(), toComplete) {
-				completions = append(completions, item.Name.String())
-			}
-		})
+		completions = filterPrefix(l.experimentNames(), toComplete)
 		return
 	})
 }
+
+// readExperiment reads an experiment definition from a file, "-" is used for stdin.
+func readExperiment(in io.Reader, filename string) (experiments.Experiment, error) {
+	var exp experiments.Experiment
+
+	data, err := readInput(in, filename)
+	if err != nil {
+		return exp, err
+	}
+
+	// YAML is a superset of JSON, this handles both
+	if err := yaml.Unmarshal(data, &exp); err != nil {
+		return exp, fmt.Errorf("failed to read experiment definition: %w", err)
+	}
+	return exp, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// NewGCExperimentsCommand returns a command for deleting experiments which
+// are no longer retained by the retention policy of a scenario.
+func NewGCExperimentsCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		scenario   string
+		keepLast   int
+		maxAgeDays int
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:         "experiments --scenario APP_NAME/NAME",
+		Aliases:     []string{"experiment", "exp"},
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{annotationOutput: "experiment"},
+	}
+
+	cmd.Flags().StringVar(&scenario, "scenario", "", "scenario `name` whose retention policy is enforced")
+	cmd.Flags().IntVar(&keepLast, "keep-last", 0, "override the retained `number` of experiments")
+	cmd.Flags().IntVar(&maxAgeDays, "max-age-days", 0, "override the number of `days` experiments are retained")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the experiments that would be deleted")
+	_ = cmd.MarkFlagRequired("scenario")
+	_ = cmd.RegisterFlagCompletionFunc("scenario", validScenarioArgs(cfg))
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		appLister := applications.Lister{
+			API: applications.NewAPI(client),
+		}
+
+		var rp applications.RetentionPolicy
+		if err := appLister.ForEachNamedScenario(ctx, []string{scenario}, false, func(item *applications.ScenarioItem) error {
+			if item.Retention != nil {
+				rp = *item.Retention
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("keep-last") {
+			rp.KeepLast = keepLast
+		}
+		if cmd.Flags().Changed("max-age-days") {
+			rp.MaxAgeDays = maxAgeDays
+		}
+		if rp.KeepLast <= 0 && rp.MaxAgeDays <= 0 {
+			return fmt.Errorf("scenario %q does not have a retention policy", scenario)
+		}
+
+		l := experiments.Lister{
+			API: experiments.NewAPI(client),
+		}
+
+		appName, scnName := applications.SplitScenarioName(scenario)
+		q := experiments.ExperimentListQuery{}
+		q.SetLabelSelector(map[string]string{
+			"application": appName.String(),
+			"scenario":    scnName.String(),
+		})
+
+		var items []experiments.ExperimentItem
+		if err := l.ForEachExperiment(ctx, q, func(item *experiments.ExperimentItem) error {
+			items = append(items, *item)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, item := range experiments.ExpiredExperiments(items, rp.KeepLast, rp.MaxAge(), time.Now()) {
+			item := item
+			if !dryRun {
+				selfURL := item.Link(api.RelationSelf)
+				if selfURL == "" {
+					return fmt.Errorf("malformed response, missing self link")
+				}
+
+				if err := l.API.DeleteExperiment(ctx, selfURL); err != nil {
+					return err
+				}
+			}
+
+			if err := p.Fprint(out, NewExperimentRow(&item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return cmd
+}
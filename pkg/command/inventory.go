@@ -0,0 +1,241 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+// NewExportInventoryCommand returns a command for exporting an inventory of the optimized workloads.
+func NewExportInventoryCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		batchSize int
+		sortBy    string
+	)
+
+	cmd := &cobra.Command{
+		Use:               "inventory [APP_NAME ...]",
+		Annotations:       map[string]string{annotationOutput: "inventory"},
+		ValidArgsFunction: validApplicationArgs(cfg),
+	}
+
+	cmd.Flags().IntVar(&batchSize, "chunk-size", 500, "fetch large lists in chu`n`ks rather then all at once")
+	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := applications.Lister{
+			API:       applications.NewAPI(newMemoClient(client)),
+			BatchSize: batchSize,
+		}
+
+		var items []applications.ApplicationItem
+		addApplication := func(item *applications.ApplicationItem) error {
+			items = append(items, *item)
+			return nil
+		}
+
+		var err error
+		if len(args) > 0 {
+			err = l.ForEachNamedApplication(ctx, args, false, addApplication)
+		} else {
+			err = l.ForEachApplication(ctx, applications.ApplicationListQuery{}, addApplication)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Fetch the latest recommendation and the current workloads of each application concurrently
+		recs := make([]*applications.Recommendation, len(items))
+		workloads := make([][]applications.WorkloadItem, len(items))
+		if err := forEachIndex(ctx, len(items), fetchParallelism, func(ctx context.Context, i int) (err error) {
+			if recs[i], err = latestRecommendation(ctx, l, &items[i].Application); err != nil {
+				return err
+			}
+			workloads[i], err = listWorkloads(ctx, l, &items[i].Application)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		result := &InventoryOutput{}
+		for i := range items {
+			result.Add(&items[i], workloads[i], recs[i])
+		}
+
+		if err := result.SortBy(sortBy); err != nil {
+			return err
+		}
+
+		return p.Fprint(out, result)
+	})
+	return cmd
+}
+
+// latestRecommendation returns the details of the most recent recommendation for an application, if any.
+func latestRecommendation(ctx context.Context, l applications.Lister, app *applications.Application) (*applications.Recommendation, error) {
+	u := app.Link(api.RelationRecommendations)
+	if u == "" {
+		return nil, nil
+	}
+
+	recs, err := l.API.ListRecommendations(ctx, u)
+	if err != nil || len(recs.Recommendations) == 0 {
+		return nil, err
+	}
+
+	// The index does not include the parameters, fetch the full recommendation
+	rec, err := l.API.GetRecommendation(ctx, recs.Recommendations[0].Link(api.RelationSelf))
+	if err != nil {
+		return nil, err
+	}
+	if rec.Name == "" {
+		rec.Name = recs.Recommendations[0].Name
+	}
+	return &rec, nil
+}
+
+// InventoryRow is a single current and recommended resource value of a
+// workload container. The columns of this row are a stable schema for
+// consumption by other tools.
+type InventoryRow struct {
+	Application    string   `table:"application" csv:"application" json:"application"`
+	Status         string   `table:"status" csv:"status" json:"status"`
+	Recommendation string   `table:"recommendation,wide" csv:"recommendation" json:"recommendation,omitempty"`
+	DeployedAt     string   `table:"deployed,wide" csv:"deployed_at" json:"deployedAt,omitempty"`
+	Kind           string   `table:"kind" csv:"kind" json:"kind,omitempty"`
+	Namespace      string   `table:"namespace" csv:"namespace" json:"namespace,omitempty"`
+	Workload       string   `table:"workload" csv:"workload" json:"workload,omitempty"`
+	Container      string   `table:"container" csv:"container" json:"container,omitempty"`
+	Resource       string   `table:"resource" csv:"resource" json:"resource,omitempty"`
+	Current        *float64 `table:"current" csv:"current" json:"current,omitempty"`
+	Recommended    *float64 `table:"recommended" csv:"recommended" json:"recommended,omitempty"`
+}
+
+func (r *InventoryRow) Lookup(key string) (interface{}, bool) {
+	switch SortByKey(key) {
+	case "application", "app":
+		return r.Application, true
+	case "status":
+		return r.Status, true
+	case "kind":
+		return r.Kind, true
+	case "namespace":
+		return r.Namespace, true
+	case "workload":
+		return r.Workload, true
+	case "container":
+		return r.Container, true
+	case "resource":
+		return r.Resource, true
+	default:
+		return nil, false
+	}
+}
+
+// InventoryOutput wraps an inventory for output.
+type InventoryOutput struct {
+	Items []InventoryRow `json:"items"`
+}
+
+// Add the workloads of an application to the inventory. Applications without
+// workloads or recommendations are still included so the inventory covers the
+// whole tenant.
+func (o *InventoryOutput) Add(item *applications.ApplicationItem, workloads []applications.WorkloadItem, rec *applications.Recommendation) {
+	status := string(item.Recommendations)
+	if status == "" {
+		status = string(applications.RecommendationsDisabled)
+	}
+
+	base := InventoryRow{
+		Application: item.Name.String(),
+		Status:      status,
+	}
+	if rec != nil && len(rec.Parameters) > 0 {
+		base.Recommendation = rec.Name
+		base.DeployedAt = formatTime(rec.DeployedAt, time.RFC3339)
+	}
+
+	// Join the current and recommended values the same way the workloads output does
+	wo := &WorkloadOutput{}
+	wo.Add(item, workloads, rec)
+	if len(wo.Items) == 0 {
+		o.Items = append(o.Items, base)
+		return
+	}
+
+	for _, w := range wo.Items {
+		row := base
+		row.Kind = w.Kind
+		row.Namespace = w.Namespace
+		row.Workload = w.Workload
+		row.Container = w.Container
+		row.Resource = w.Resource
+		row.Current = w.Current
+		row.Recommended = w.Recommended
+		o.Items = append(o.Items, row)
+	}
+}
+
+// Len returns the number of items being output.
+func (o *InventoryOutput) Len() int { return len(o.Items) }
+
+// Swap exchanges the order of the two specified items.
+func (o *InventoryOutput) Swap(i, j int) { o.Items[i], o.Items[j] = o.Items[j], o.Items[i] }
+
+// Item returns the specified row value.
+func (o *InventoryOutput) Item(i int) Row { return &o.Items[i] }
+
+// SortBy sorts the output by the named value.
+func (o *InventoryOutput) SortBy(key string) error { return SortBy(o, key) }
+
+// flattenValues collects the numeric values of a nested map using dotted paths (e.g. `requests.cpu`).
+func flattenValues(values map[string]float64, prefix string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flattenValues(values, k, v)
+		}
+	case []interface{}:
+		for i, v := range value {
+			flattenValues(values, prefix+"["+strconv.Itoa(i)+"]", v)
+		}
+	case float64:
+		values[prefix] = value
+	}
+}
+
+// sortedValueKeys returns the sorted keys of the values map.
+func sortedValueKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
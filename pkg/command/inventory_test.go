@@ -0,0 +1,65 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+func TestInventoryOutput_Add(t *testing.T) {
+	target := applications.TargetRef{Kind: "Deployment", Namespace: "default", Workload: "app"}
+	cpu := api.FromString("250m")
+	workloads := []applications.WorkloadItem{{Workload: applications.Workload{
+		Target:     target,
+		Containers: []applications.WorkloadContainer{{Name: "main", Requests: &applications.ResourceList{CPU: &cpu}}},
+	}}}
+	rec := &applications.Recommendation{Name: "rec-1", Parameters: []applications.Parameter{{
+		Target: target,
+		ContainerResources: []interface{}{map[string]interface{}{
+			"containerName": "main",
+			"requests":      map[string]interface{}{"cpu": 500.0},
+		}},
+	}}}
+
+	o := &InventoryOutput{}
+	o.Add(&applications.ApplicationItem{Application: applications.Application{Name: "optimized"}, Recommendations: applications.RecommendationsAuto}, workloads, rec)
+	o.Add(&applications.ApplicationItem{Application: applications.Application{Name: "empty"}}, nil, nil)
+
+	current, recommended := 0.25, 0.5
+	assert.Equal(t, []InventoryRow{
+		{
+			Application:    "optimized",
+			Status:         "auto",
+			Recommendation: "rec-1",
+			Kind:           "Deployment",
+			Namespace:      "default",
+			Workload:       "app",
+			Container:      "main",
+			Resource:       "requests.cpu",
+			Current:        &current,
+			Recommended:    &recommended,
+		},
+		{
+			Application: "empty",
+			Status:      "disabled",
+		},
+	}, o.Items)
+}
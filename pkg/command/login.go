@@ -0,0 +1,83 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/config"
+)
+
+// NewLoginCommand returns a command for interactively obtaining credentials.
+func NewLoginCommand(cfg Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in using a web browser",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		lcfg, ok := cfg.(interface {
+			Login(context.Context, func(*config.DeviceCode)) error
+		})
+		if !ok {
+			return fmt.Errorf("the configuration does not support interactive login")
+		}
+
+		if err := lcfg.Login(ctx, func(dc *config.DeviceCode) {
+			u := dc.VerificationURIComplete
+			if u == "" {
+				u = dc.VerificationURI
+			}
+			_, _ = fmt.Fprintf(out, "Open %s in a browser and enter the code: %s\n", u, dc.UserCode)
+		}); err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(out, "Logged in to %s.\n", cfg.Address())
+		return nil
+	}
+	return cmd
+}
+
+// NewLogoutCommand returns a command for removing stored credentials.
+func NewLogoutCommand(cfg Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove stored login credentials",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		lcfg, ok := cfg.(interface{ Logout() error })
+		if !ok {
+			return fmt.Errorf("the configuration does not support interactive login")
+		}
+
+		if err := lcfg.Logout(); err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Logged out of %s.\n", cfg.Address())
+		return nil
+	}
+	return cmd
+}
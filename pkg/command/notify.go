@@ -0,0 +1,96 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/notify"
+)
+
+// notifyOptions are the flags used to configure notifications.
+type notifyOptions struct {
+	webhooks     []string
+	slackURLs    []string
+	slackChannel string
+	failures     int
+
+	grafanaURL       string
+	grafanaDashboard string
+	grafanaTags      []string
+}
+
+// addNotifyFlags adds the notification flags to the command; the trial specific
+// flags (failure threshold and Grafana annotations) are only added if the command runs trials.
+func addNotifyFlags(cmd *cobra.Command, opts *notifyOptions, trials bool) {
+	cmd.Flags().StringArrayVar(&opts.webhooks, "notify-webhook", nil, "`URL` to post JSON notifications to")
+	cmd.Flags().StringArrayVar(&opts.slackURLs, "notify-slack", nil, "Slack incoming webhook `URL` to send notifications to")
+	cmd.Flags().StringVar(&opts.slackChannel, "notify-slack-channel", "", "Slack `channel` to send notifications to, defaults to the channel of the webhook")
+	if trials {
+		cmd.Flags().IntVar(&opts.failures, "notify-failures", 3, "send a notification after this `number` of consecutive trial failures")
+		cmd.Flags().StringVar(&opts.grafanaURL, "grafana-url", "", "Grafana server `URL` to annotate with trial start and finish times (token read from $GRAFANA_TOKEN)")
+		cmd.Flags().StringVar(&opts.grafanaDashboard, "grafana-dashboard", "", "`uid` of the Grafana dashboard to annotate, defaults to organization wide annotations")
+		cmd.Flags().StringSliceVar(&opts.grafanaTags, "grafana-tags", nil, "additional `tag`s for Grafana annotations")
+	}
+}
+
+// attachTrialLoop adds the configured notification hooks to a trial loop.
+func (opts *notifyOptions) attachTrialLoop(cmd *cobra.Command, l *experiments.TrialLoop, name string) *notify.Notifier {
+	ctx := cmd.Context()
+
+	n := opts.notifier(cmd)
+	n.AttachTrialLoop(ctx, l, name, opts.failures)
+
+	if opts.grafanaURL != "" {
+		g := &notify.GrafanaAnnotator{
+			URL:          opts.grafanaURL,
+			Token:        os.Getenv("GRAFANA_TOKEN"),
+			DashboardUID: opts.grafanaDashboard,
+			Tags:         opts.grafanaTags,
+			OnError: func(err error) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+			},
+		}
+		g.AttachTrialLoop(ctx, l, name)
+	}
+
+	return n
+}
+
+// notifier returns the configured notifier or nil if no sinks were configured.
+func (opts *notifyOptions) notifier(cmd *cobra.Command) *notify.Notifier {
+	n := &notify.Notifier{
+		OnError: func(err error) {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+		},
+	}
+
+	for _, u := range opts.webhooks {
+		n.Sinks = append(n.Sinks, &notify.WebhookSink{URL: u})
+	}
+	for _, u := range opts.slackURLs {
+		n.Sinks = append(n.Sinks, &notify.SlackSink{WebhookURL: u, Channel: opts.slackChannel})
+	}
+
+	if len(n.Sinks) == 0 {
+		return nil
+	}
+	return n
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+const (
+	// defaultResponseCacheMaxAge is the maximum age of a cached response served while the server is unreachable.
+	defaultResponseCacheMaxAge = 7 * 24 * time.Hour
+	// responseCacheMaxSize is the maximum total size of the cached responses for a server.
+	responseCacheMaxSize = 32 << 20
+)
+
+// addCachedFlag adds a persistent `--cached` flag to the supplied command, the
+// API responses of the command (and any of its sub-commands) are remembered so
+// they can be served when the server is unreachable.
+func addCachedFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.PersistentFlags().Bool("cached", false, "show the last known results if the API server is unreachable")
+	return cmd
+}
+
+// newCacheTransport returns the transport used to cache the API responses of
+// the command, or nil if the user has not opted in to caching. Responses are
+// only remembered when the `--cached` flag is set, or when the
+// `STORMFORGE_RESPONSE_CACHE` environment variable is true so the cache is
+// already populated by the time the flag is needed.
+func newCacheTransport(cmd *cobra.Command, cfg Config, base http.RoundTripper) *cacheTransport {
+	flag := cmd.Flag("cached")
+	if flag == nil {
+		return nil
+	}
+
+	fallback := flag.Value.String() == "true"
+	remember, _ := strconv.ParseBool(os.Getenv("STORMFORGE_RESPONSE_CACHE"))
+	maxAge := responseCacheMaxAge()
+	if !fallback && !remember || maxAge <= 0 {
+		return nil
+	}
+
+	dir, err := responseCacheDir(cfg)
+	if err != nil {
+		return nil
+	}
+	return &cacheTransport{Base: base, Dir: dir, MaxAge: maxAge, MaxSize: responseCacheMaxSize, Fallback: fallback}
+}
+
+// responseCacheMaxAge returns the maximum age of cached responses, it may be overridden
+// using the `STORMFORGE_RESPONSE_CACHE_MAX_AGE` environment variable (zero disables the cache).
+func responseCacheMaxAge() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("STORMFORGE_RESPONSE_CACHE_MAX_AGE")); err == nil {
+		return d
+	}
+	return defaultResponseCacheMaxAge
+}
+
+// responseCacheDir returns the cache directory for the supplied server address
+// and organization, the cached responses of each are kept separate.
+func responseCacheDir(cfg Config) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	org, _ := SelectedOrganization(cfg)
+	sum := sha256.Sum256([]byte(cfg.Address() + "\n" + org))
+	return filepath.Join(dir, "stormforge", "responses", hex.EncodeToString(sum[:8])), nil
+}
+
+// cachedResponse is the on-disk representation of a cached response.
+type cachedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Date       time.Time   `json:"date"`
+}
+
+// cacheTransport remembers successful GET responses on disk and, if fallback
+// is enabled, serves them when the server cannot be reached (or is failing).
+// Responses older than the maximum age are pruned, as are the oldest responses
+// when the cache exceeds the maximum size. Errors reading or writing the cache are ignored.
+type cacheTransport struct {
+	Base     http.RoundTripper
+	Dir      string
+	MaxAge   time.Duration
+	MaxSize  int64
+	Fallback bool
+
+	mu     sync.Mutex
+	stale  time.Time
+	pruned bool
+}
+
+// RoundTrip sends the request using the base transport, updating or falling back to the cache.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if req.Method != http.MethodGet {
+		return base.RoundTrip(req)
+	}
+
+	filename := t.filename(req)
+	resp, err := base.RoundTrip(req)
+	switch {
+	case err == nil && resp.StatusCode == http.StatusOK:
+		return t.store(filename, resp)
+	case err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests:
+		return resp, nil
+	case !t.Fallback:
+		return resp, err
+	}
+
+	cr, ok := t.load(filename)
+	if !ok {
+		return resp, err
+	}
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+
+	t.mu.Lock()
+	if t.stale.IsZero() || cr.Date.Before(t.stale) {
+		t.stale = cr.Date
+	}
+	t.mu.Unlock()
+
+	return &http.Response{
+		Status:     http.StatusText(cr.StatusCode),
+		StatusCode: cr.StatusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     cr.Header,
+		Body:       io.NopCloser(bytes.NewReader(cr.Body)),
+		Request:    req,
+	}, nil
+}
+
+// Stale returns the date of the oldest cached response that was served, or the
+// zero time if all the responses came from the server.
+func (t *cacheTransport) Stale() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stale
+}
+
+// filename returns the cache file for a request, the organization is part of the key.
+func (t *cacheTransport) filename(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get(api.HeaderOrganization)))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// store saves the response to the cache, returning a response with an unread copy of the body.
+func (t *cacheTransport) store(filename string, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	data, err := json.Marshal(&cachedResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body, Date: time.Now().UTC()})
+	if err == nil {
+		if err := os.MkdirAll(t.Dir, 0700); err == nil {
+			_ = os.WriteFile(filename, data, 0600)
+		}
+	}
+
+	// Pruning once per invocation is enough to keep the cache bounded
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.pruned {
+		t.prune(filename)
+		t.pruned = true
+	}
+	return resp, nil
+}
+
+// prune removes the cached responses which are too old to be served, followed
+// by the least recently stored responses until the cache fits in the maximum
+// size. The supplied file (the response just stored) is always kept.
+func (t *cacheTransport) prune(keep string) {
+	entries, err := os.ReadDir(t.Dir)
+	if err != nil {
+		return
+	}
+
+	files := make([]os.FileInfo, 0, len(entries))
+	var size int64
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || info.IsDir() || filepath.Join(t.Dir, info.Name()) == keep {
+			continue
+		}
+
+		if time.Since(info.ModTime()) > t.MaxAge {
+			_ = os.Remove(filepath.Join(t.Dir, info.Name()))
+			continue
+		}
+
+		files = append(files, info)
+		size += info.Size()
+	}
+
+	if info, err := os.Stat(keep); err == nil {
+		size += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, info := range files {
+		if t.MaxSize <= 0 || size <= t.MaxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(t.Dir, info.Name())); err == nil {
+			size -= info.Size()
+		}
+	}
+}
+
+// load returns the cached response, if it is not too old.
+func (t *cacheTransport) load(filename string) (*cachedResponse, bool) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, false
+	}
+
+	cr := &cachedResponse{}
+	if err := json.Unmarshal(data, cr); err != nil || time.Since(cr.Date) > t.MaxAge {
+		return nil, false
+	}
+	return cr, true
+}
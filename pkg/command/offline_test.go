@@ -0,0 +1,145 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticConfig is a command configuration with a fixed address.
+type staticConfig string
+
+func (c staticConfig) Address() string { return string(c) }
+
+func TestNewCacheTransport(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	cfg := staticConfig("https://api.example.com/")
+
+	cmd := addCachedFlag(&cobra.Command{Use: "get"})
+	assert.Nil(t, newCacheTransport(&cobra.Command{Use: "create"}, cfg, nil), "without the flag")
+	assert.Nil(t, newCacheTransport(cmd, cfg, nil), "without opting in")
+
+	t.Setenv("STORMFORGE_RESPONSE_CACHE", "true")
+	if ct := newCacheTransport(cmd, cfg, nil); assert.NotNil(t, ct, "opted in using the environment") {
+		assert.False(t, ct.Fallback)
+	}
+
+	t.Setenv("STORMFORGE_RESPONSE_CACHE", "")
+	require.NoError(t, cmd.PersistentFlags().Set("cached", "true"))
+	if ct := newCacheTransport(cmd, cfg, nil); assert.NotNil(t, ct, "opted in using the flag") {
+		assert.True(t, ct.Fallback)
+	}
+
+	t.Setenv("STORMFORGE_RESPONSE_CACHE_MAX_AGE", "0")
+	assert.Nil(t, newCacheTransport(cmd, cfg, nil), "disabled")
+}
+
+func TestCacheTransport(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	get := func(ct *cacheTransport, path string) (int, string, error) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := ct.RoundTrip(req)
+		if err != nil {
+			return 0, "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body), err
+	}
+
+	dir := t.TempDir()
+	ct := &cacheTransport{Dir: dir, MaxAge: time.Hour, Fallback: true}
+
+	code, body, err := get(ct, "/a")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "/a", body)
+	assert.True(t, ct.Stale().IsZero())
+
+	// Failures are served from the cache, if the response was cached
+	status = http.StatusServiceUnavailable
+	code, body, err = get(ct, "/a")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "/a", body)
+	assert.False(t, ct.Stale().IsZero())
+
+	code, _, err = get(ct, "/b")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	// Client errors are never served from the cache
+	status = http.StatusNotFound
+	code, _, err = get(ct, "/a")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Responses which are too old are not served
+	status = http.StatusServiceUnavailable
+	ct = &cacheTransport{Dir: dir, MaxAge: time.Nanosecond, Fallback: true}
+	time.Sleep(time.Millisecond)
+	code, _, err = get(ct, "/a")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func TestCacheTransport_Prune(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	write := func(name string, size int, age time.Duration) {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(filename, []byte(strings.Repeat("x", size)), 0600))
+		require.NoError(t, os.Chtimes(filename, now.Add(-age), now.Add(-age)))
+	}
+
+	write("expired.json", 10, 2*time.Hour)
+	write("oldest.json", 40, 30*time.Minute)
+	write("older.json", 40, 20*time.Minute)
+	write("newer.json", 40, 10*time.Minute)
+	write("current.json", 40, 0)
+
+	ct := &cacheTransport{Dir: dir, MaxAge: time.Hour, MaxSize: 100}
+	ct.prune(filepath.Join(dir, "current.json"))
+
+	var names []string
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"newer.json", "current.json"}, names)
+}
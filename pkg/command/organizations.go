@@ -0,0 +1,200 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// NewConfigGetOrgsCommand returns a command for listing the organizations available to the current identity.
+func NewConfigGetOrgsCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		sortBy string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "get-orgs",
+		Aliases: []string{"get-org"},
+		Args:    cobra.NoArgs,
+		Annotations: map[string]string{
+			annotationOutput: "organization",
+		},
+	}
+
+	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		orgs, err := tokenOrganizations(ctx, cfg)
+		if err != nil {
+			return err
+		}
+
+		current, err := SelectedOrganization(cfg)
+		if err != nil {
+			return err
+		}
+
+		result := &OrganizationOutput{}
+		for _, org := range orgs {
+			result.Items = append(result.Items, OrganizationRow{Name: org, Current: org == current})
+		}
+
+		if err := result.SortBy(sortBy); err != nil {
+			return err
+		}
+
+		return p.Fprint(out, result)
+	}
+	return cmd
+}
+
+// NewConfigUseOrgCommand returns a command for selecting the organization used for API requests.
+func NewConfigUseOrgCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		unset bool
+	)
+
+	cmd := &cobra.Command{
+		Use:  "use-org ORG_NAME",
+		Args: cobra.MaximumNArgs(1),
+	}
+
+	cmd.Flags().BoolVar(&unset, "unset", false, "clear the selected organization")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		if unset {
+			if len(args) > 0 {
+				return fmt.Errorf("cannot select an organization with --unset")
+			}
+			return saveOrganization(cfg, "")
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("missing organization name")
+		}
+
+		// Only verify the name if the token actually lists organizations
+		if orgs, err := tokenOrganizations(ctx, cfg); err == nil && len(orgs) > 0 {
+			i := sort.SearchStrings(orgs, args[0])
+			if i == len(orgs) || orgs[i] != args[0] {
+				return fmt.Errorf("organization %q is not available, expected one of %v", args[0], orgs)
+			}
+		}
+
+		if err := saveOrganization(cfg, args[0]); err != nil {
+			return err
+		}
+
+		return p.Fprint(out, &OrganizationRow{Name: args[0], Current: true})
+	}
+	return cmd
+}
+
+// SelectedOrganization returns the organization previously selected for the
+// configured server, or an empty string if no organization was selected.
+func SelectedOrganization(cfg Config) (string, error) {
+	orgs, filename, err := readOrganizations()
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %w", filename, err)
+	}
+	return orgs[cfg.Address()], nil
+}
+
+// saveOrganization records the selected organization for the configured server.
+func saveOrganization(cfg Config, org string) error {
+	orgs, filename, err := readOrganizations()
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", filename, err)
+	}
+
+	if org == "" {
+		delete(orgs, cfg.Address())
+	} else {
+		orgs[cfg.Address()] = org
+	}
+
+	data, err := json.Marshal(orgs)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0600)
+}
+
+// readOrganizations returns the selected organizations keyed by server address.
+func readOrganizations() (map[string]string, string, error) {
+	orgs := make(map[string]string)
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, "", err
+	}
+	filename := filepath.Join(dir, "stormforge", "organizations.json")
+
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return orgs, filename, nil
+	} else if err != nil {
+		return nil, filename, err
+	}
+	if err := json.Unmarshal(data, &orgs); err != nil {
+		return nil, filename, err
+	}
+	return orgs, filename, nil
+}
+
+// tokenOrganizations returns the sorted names of the organizations listed in the
+// claims of the current access token.
+func tokenOrganizations(ctx context.Context, cfg Config) ([]string, error) {
+	tok, err := token(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ignore the signature, just extract the claims
+	accessToken, err := jwt.ParseSigned(tok.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	claims := struct {
+		Organizations []string `json:"https://stormforge.io/organizations"`
+		OrgID         string   `json:"org_id"`
+	}{}
+	if err := accessToken.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return nil, err
+	}
+
+	orgs := claims.Organizations
+	if len(orgs) == 0 && claims.OrgID != "" {
+		orgs = append(orgs, claims.OrgID)
+	}
+	sort.Strings(orgs)
+	return orgs, nil
+}
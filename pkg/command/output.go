@@ -18,16 +18,23 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/analysis"
+	"github.com/thestormforge/optimize-go/pkg/api"
 	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
 	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/cost"
+	"github.com/thestormforge/optimize-go/pkg/snapshot"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/collate"
 	"golang.org/x/text/language"
@@ -39,6 +46,55 @@ type Printer interface {
 	Fprint(out io.Writer, obj interface{}) error
 }
 
+// addNameOutputFlags adds the `--quiet` and `--output=name` flags to the command,
+// when either is set the returned printer only prints the resource names (one
+// per line) instead of using the supplied printer.
+func addNameOutputFlags(cmd *cobra.Command, p Printer) Printer {
+	np := &namePrinter{Printer: p}
+	cmd.Flags().BoolVarP(&np.quiet, "quiet", "q", false, "only print resource names")
+	cmd.Flags().StringVarP(&np.output, "output", "o", "", "the output `format` to use; one of: name")
+	return np
+}
+
+// namePrinter is a printer which can be switched to only print resource names.
+type namePrinter struct {
+	Printer
+	quiet  bool
+	output string
+}
+
+// Fprint renders the names of the resources if enabled, otherwise it delegates to the wrapped printer.
+func (p *namePrinter) Fprint(out io.Writer, obj interface{}) error {
+	switch {
+	case p.output == "name" || (p.output == "" && p.quiet):
+	case p.output == "":
+		return p.Printer.Fprint(out, obj)
+	default:
+		return fmt.Errorf("unknown output format: %s", p.output)
+	}
+
+	var rows []Row
+	switch obj := obj.(type) {
+	case Output:
+		for i := 0; i < obj.Len(); i++ {
+			rows = append(rows, obj.Item(i))
+		}
+	case Row:
+		rows = append(rows, obj)
+	}
+
+	for _, r := range rows {
+		name, ok := r.Lookup("name")
+		if !ok {
+			return fmt.Errorf("unable to print names of %T", r)
+		}
+		if _, err := fmt.Fprintln(out, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // formatTime is a helper that returns empty strings for zero times and adds
 // support for a humanized format (if the layout is empty).
 func formatTime(t *time.Time, layout string) string {
@@ -54,20 +110,30 @@ func formatTime(t *time.Time, layout string) string {
 	}
 }
 
+// creationTime returns the creation time from the resource metadata, or the
+// fallback time if the metadata does not include it.
+func creationTime(md api.Metadata, fallback *time.Time) *time.Time {
+	if t := md.CreationTime(); !t.IsZero() {
+		return &t
+	}
+	return fallback
+}
+
 // NOTE: All the "*Row" structs have `json:"-"` for everything EXCEPT their
 // inline "*Item" field so when the row is marshalled as JSON it appears the
 // same as what the item would have been.
 
 // ApplicationRow is a table row representation of an application.
 type ApplicationRow struct {
-	Name                string `table:"name" csv:"name" json:"-"`
-	Title               string `table:"title" csv:"title" json:"title,omitempty"`
-	ScenarioCount       int    `table:"scenarios" csv:"scenario_count" json:"-"`
-	RecommendationMode  string `table:"recommendations" csv:"recommendations" json:"-"`
-	DeployInterval      string `table:"deploy_interval,wide" csv:"deploy_interval" json:"-"`
-	LastDeployedMachine string `table:"-" csv:"last_deployed" json:"-"`
-	LastDeployedHuman   string `table:"last_deployed,wide" csv:"-" json:"-"`
-	Age                 string `table:"age,wide" csv:"-" json:"-"`
+	Name                string     `table:"name" csv:"name" json:"-"`
+	Title               string     `table:"title" csv:"title" json:"title,omitempty"`
+	ScenarioCount       int        `table:"scenarios" csv:"scenario_count" json:"-"`
+	RecommendationMode  string     `table:"recommendations" csv:"recommendations" json:"-"`
+	DeployInterval      string     `table:"deploy_interval,wide" csv:"deploy_interval" json:"-"`
+	LastDeployedMachine string     `table:"-" csv:"last_deployed" json:"-"`
+	LastDeployedHuman   string     `table:"last_deployed,wide" csv:"-" json:"-"`
+	CostSavings         string     `table:"cost_savings,wide" csv:"cost_savings" json:"-"`
+	Age                 *time.Time `table:"age,wide" csv:"-" json:"-"`
 
 	applications.ApplicationItem `table:"-" csv:"-"`
 
@@ -76,6 +142,7 @@ type ApplicationRow struct {
 	RecommendationsDeployConfig     *applications.DeployConfiguration `table:"-" csv:"-" json:"recommendationsDeployConfig,omitempty"`
 	RecommendationsConfiguration    []applications.Configuration      `table:"-" csv:"-" json:"recommendationsConfiguration,omitempty"`
 	RecommendationsBackfillProgress *applications.BackfillProgress    `table:"-" csv:"-" json:"recommendationsBackfillProgress,omitempty"`
+	RecommendationsCostEstimate     *cost.Estimate                    `table:"-" csv:"-" json:"recommendationsCostEstimate,omitempty"`
 }
 
 func NewApplicationRow(item *applications.ApplicationItem) *ApplicationRow {
@@ -86,12 +153,23 @@ func NewApplicationRow(item *applications.ApplicationItem) *ApplicationRow {
 		RecommendationMode:  "Disabled",
 		LastDeployedMachine: formatTime(item.LastDeployedAt, time.RFC3339),
 		LastDeployedHuman:   formatTime(item.LastDeployedAt, "ago"),
-		Age:                 formatTime(item.CreatedAt, ""),
+		Age:                 item.CreatedAt,
 
 		ApplicationItem: *item,
 	}
 }
 
+// MarshalJSON encodes the application item together with the row level fields, the
+// encoding promoted from the embedded item would otherwise omit them.
+func (r ApplicationRow) MarshalJSON() ([]byte, error) {
+	type t ApplicationRow
+	v := struct {
+		*t
+		MarshalJSON, UnmarshalJSON struct{} `json:"-"`
+	}{t: (*t)(&r)}
+	return api.MarshalJSONWithExtensions(&v, r.Extensions)
+}
+
 func (r *ApplicationRow) Lookup(key string) (interface{}, bool) {
 	switch SortByKey(key) {
 	case "name":
@@ -106,6 +184,8 @@ func (r *ApplicationRow) Lookup(key string) (interface{}, bool) {
 		return r.DeployInterval, true
 	case "last_deployed":
 		return r.ApplicationItem.LastDeployedAt, true
+	case "cost_savings":
+		return costSavingsCents(r.RecommendationsCostEstimate), true
 	case "age":
 		return r.ApplicationItem.CreatedAt, true
 	default:
@@ -139,6 +219,11 @@ func (r *ApplicationRow) SetRecommendationsConfiguration(config []applications.C
 	}
 }
 
+func (r *ApplicationRow) SetCostEstimate(estimate cost.Estimate) {
+	r.CostSavings = estimate.String()
+	r.RecommendationsCostEstimate = &estimate
+}
+
 func (r *ApplicationRow) SetBackfillProgress(progress *applications.BackfillProgress) {
 	if progress == nil {
 		return
@@ -173,13 +258,20 @@ func (o *ApplicationOutput) SortBy(key string) error { return SortBy(o, key) }
 // ScenarioRow is a table row representation of a scenario.
 type ScenarioRow struct {
 	Name string `table:"name" csv:"name" json:"-"`
+	Kind string `table:"kind,wide" csv:"kind" json:"-"`
 
 	applications.ScenarioItem `table:"-" csv:"-"`
 }
 
 func NewScenarioRow(item *applications.ScenarioItem) *ScenarioRow {
+	var kind string
+	if kinds := item.Kinds(); len(kinds) > 0 {
+		kind = string(kinds[0])
+	}
+
 	return &ScenarioRow{
 		Name: item.Name.String(),
+		Kind: kind,
 
 		ScenarioItem: *item,
 	}
@@ -189,6 +281,8 @@ func (r *ScenarioRow) Lookup(key string) (interface{}, bool) {
 	switch SortByKey(key) {
 	case "name":
 		return r.Name, true
+	case "kind":
+		return r.Kind, true
 	default:
 		return nil, false
 	}
@@ -222,8 +316,11 @@ type RecommendationRow struct {
 	Name              string `table:"name" csv:"name" json:"-"`
 	DeployedAtMachine string `table:"-" csv:"last_deployed" json:"-"`
 	DeployedAtHuman   string `table:"last_deployed" csv:"-" json:"-"`
+	CostSavings       string `table:"cost_savings" csv:"cost_savings" json:"-"`
 
 	applications.RecommendationItem `table:"-" csv:"-"`
+
+	CostEstimate *cost.Estimate `table:"-" csv:"-" json:"costEstimate,omitempty"`
 }
 
 func NewRecommendationRow(item *applications.RecommendationItem) *RecommendationRow {
@@ -248,19 +345,51 @@ func (r *RecommendationRow) Lookup(key string) (interface{}, bool) {
 		return r.Name, true
 	case "last_deployed":
 		return r.RecommendationItem.DeployedAt, true
+	case "cost_savings":
+		return costSavingsCents(r.CostEstimate), true
 	default:
 		return nil, false
 	}
 }
 
+// costSavingsCents returns the estimated savings as a sortable number of cents.
+func costSavingsCents(estimate *cost.Estimate) int {
+	if estimate == nil {
+		return 0
+	}
+	return int(math.Round(estimate.Savings() * 100))
+}
+
 // RecommendationOutput wraps a recommendation list for output.
 type RecommendationOutput struct {
 	Items []RecommendationRow `json:"items"`
+	// Rounding is applied to the container resources of each added recommendation.
+	Rounding api.RoundingPolicy `json:"-"`
+	// Estimate returns the cost estimate of each added recommendation, if available.
+	Estimate func(*applications.RecommendationItem) (cost.Estimate, bool) `json:"-"`
 }
 
 // Add a recommendation item to the output.
 func (o *RecommendationOutput) Add(item *applications.RecommendationItem) error {
-	o.Items = append(o.Items, *NewRecommendationRow(item))
+	// Estimate before the row adjusts the units of the container resources
+	var estimate *cost.Estimate
+	if o.Estimate != nil {
+		if e, ok := o.Estimate(item); ok {
+			estimate = &e
+		}
+	}
+
+	row := NewRecommendationRow(item)
+	if estimate != nil {
+		row.CostSavings = estimate.String()
+		row.CostEstimate = estimate
+	}
+	if len(o.Rounding) > 0 {
+		for i := range row.Parameters {
+			roundResources(row.Parameters[i].ContainerResources, o.Rounding)
+		}
+	}
+	o.Items = append(o.Items, *row)
 	return nil
 }
 
@@ -282,6 +411,7 @@ type ExperimentRow struct {
 	DisplayName  string            `table:"Name,custom" json:"-"`
 	Observations int64             `table:"observations,wide" csv:"observations" json:"-"`
 	Labels       map[string]string `table:"labels,labels" csv:"label_,labels,flatten" json:"-"`
+	Age          *time.Time        `table:"age,wide" csv:"-" json:"-"`
 
 	experiments.ExperimentItem `table:"-" csv:"-"`
 }
@@ -292,6 +422,7 @@ func NewExperimentRow(item *experiments.ExperimentItem) *ExperimentRow {
 		DisplayName:  item.DisplayName,
 		Observations: item.Observations,
 		Labels:       item.Labels,
+		Age:          creationTime(item.Metadata, nil),
 
 		ExperimentItem: *item,
 	}
@@ -303,6 +434,8 @@ func (r *ExperimentRow) Lookup(key string) (interface{}, bool) {
 		return r.Name, true
 	case "observations":
 		return r.Observations, true
+	case "age":
+		return r.Age, true
 	default:
 		return nil, false
 	}
@@ -341,7 +474,10 @@ type TrialRow struct {
 	Values         map[string]string `csv:"metric_,flatten" json:"-"`
 	FailureReason  string            `table:"failure_reason,wide" csv:"failure_reason" json:"-"`
 	FailureMessage string            `table:"failure_message,wide" csv:"failure_message" json:"-"`
+	Outlier        string            `table:"outlier,wide" csv:"outlier" json:"outlier,omitempty"`
 	Labels         map[string]string `table:"labels,labels" csv:"label_,labels,flatten" json:"-"`
+	Annotations    map[string]string `table:"annotations,labels,wide" csv:"annotation_,labels,flatten" json:"-"`
+	Age            *time.Time        `table:"age,wide" csv:"-" json:"-"`
 
 	experiments.TrialItem `table:"-" csv:"-"`
 }
@@ -379,6 +515,8 @@ func NewTrialRow(item *experiments.TrialItem) *TrialRow {
 		Assignments:    assignments,
 		Values:         values,
 		Labels:         item.Labels,
+		Annotations:    item.Annotations,
+		Age:            creationTime(item.Metadata, item.StartTime),
 
 		TrialItem: *item,
 	}
@@ -392,6 +530,8 @@ func (r *TrialRow) Lookup(key string) (interface{}, bool) {
 		return r.Status, true
 	case "failure_reason":
 		return r.FailureReason, true
+	case "age":
+		return r.Age, true
 	default:
 		return nil, false
 	}
@@ -408,6 +548,48 @@ func (o *TrialOutput) Add(item *experiments.TrialItem) error {
 	return nil
 }
 
+// FlagOutliers marks the rows of trials whose metric values are outliers
+// relative to the other listed trials of the same experiment.
+func (o *TrialOutput) FlagOutliers(opts analysis.OutlierOptions) {
+	// Group the rows by experiment, outliers are only relative to the listed trials
+	type group struct {
+		exp  *experiments.Experiment
+		rows map[int64]*TrialRow
+	}
+	var groups []*group
+	byName := make(map[experiments.ExperimentName]*group)
+	for i := range o.Items {
+		r := &o.Items[i]
+		if r.TrialItem.Experiment == nil {
+			continue
+		}
+		g, ok := byName[r.TrialItem.Experiment.Name]
+		if !ok {
+			g = &group{exp: r.TrialItem.Experiment, rows: make(map[int64]*TrialRow)}
+			byName[r.TrialItem.Experiment.Name] = g
+			groups = append(groups, g)
+		}
+		g.rows[r.Number] = r
+	}
+
+	for _, g := range groups {
+		trials := make([]experiments.TrialItem, 0, len(g.rows))
+		for _, r := range g.rows {
+			trials = append(trials, r.TrialItem)
+		}
+
+		for _, outlier := range analysis.FindOutliers(g.exp, trials, opts) {
+			r := g.rows[outlier.Number]
+			if r.Outlier == "" {
+				r.Status += " (outlier)"
+			} else {
+				r.Outlier += ", "
+			}
+			r.Outlier += outlier.String()
+		}
+	}
+}
+
 // Len returns the number of items being output.
 func (o *TrialOutput) Len() int { return len(o.Items) }
 
@@ -420,17 +602,73 @@ func (o *TrialOutput) Item(i int) Row { return &o.Items[i] }
 // SortBy sorts the output by the named value.
 func (o *TrialOutput) SortBy(key string) error { return SortBy(o, key) }
 
+// ImportanceRow is a table row representation of the importance of a parameter for a metric.
+type ImportanceRow struct {
+	Metric                 string   `table:"metric" csv:"metric" json:"metric"`
+	Parameter              string   `table:"parameter" csv:"parameter" json:"parameter"`
+	Importance             float64  `table:"-" csv:"importance" json:"importance"`
+	ImportanceHuman        string   `table:"importance" csv:"-" json:"-"`
+	VarianceExplained      float64  `table:"-" csv:"variance_explained" json:"varianceExplained"`
+	VarianceExplainedHuman string   `table:"variance_explained,wide" csv:"-" json:"-"`
+	Correlation            *float64 `table:"-" csv:"correlation" json:"correlation,omitempty"`
+	CorrelationHuman       string   `table:"correlation" csv:"-" json:"-"`
+}
+
+func NewImportanceRow(pi *analysis.ParameterImportance) *ImportanceRow {
+	r := &ImportanceRow{
+		Metric:                 pi.MetricName,
+		Parameter:              pi.ParameterName,
+		Importance:             pi.Importance,
+		ImportanceHuman:        fmt.Sprintf("%.0f%%", 100*pi.Importance),
+		VarianceExplained:      pi.VarianceExplained,
+		VarianceExplainedHuman: fmt.Sprintf("%.0f%%", 100*pi.VarianceExplained),
+		Correlation:            pi.Correlation,
+	}
+	if pi.Correlation != nil {
+		r.CorrelationHuman = fmt.Sprintf("%+.2f", *pi.Correlation)
+	}
+	return r
+}
+
+func (r *ImportanceRow) Lookup(key string) (interface{}, bool) {
+	switch SortByKey(key) {
+	case "metric":
+		return r.Metric, true
+	case "parameter":
+		return r.Parameter, true
+	default:
+		return nil, false
+	}
+}
+
+// ImportanceOutput wraps a parameter importance list for output.
+type ImportanceOutput struct {
+	Items []ImportanceRow `json:"items"`
+}
+
+// Len returns the number of items being output.
+func (o *ImportanceOutput) Len() int { return len(o.Items) }
+
+// Swap exchanges the order of the two specified items.
+func (o *ImportanceOutput) Swap(i, j int) { o.Items[i], o.Items[j] = o.Items[j], o.Items[i] }
+
+// Item returns the specified row value.
+func (o *ImportanceOutput) Item(i int) Row { return &o.Items[i] }
+
+// SortBy sorts the output by the named value.
+func (o *ImportanceOutput) SortBy(key string) error { return SortBy(o, key) }
+
 // ClusterRow is a table row representation of a cluster.
 type ClusterRow struct {
-	Name                   string `table:"name" csv:"name" json:"-"`
-	DisplayName            string `table:"title" csv:"title" json:"title,omitempty"`
-	OptimizeProVersion     string `table:"optimize_pro" csv:"optimize_pro_version" json:"-"`
-	OptimizeLiveVersion    string `table:"optimize_live" csv:"optimize_live_version" json:"-"`
-	PerformanceTestVersion string `table:"performance_test,wide" csv:"performance_test_version" json:"-"`
-	KubernetesVersion      string `table:"kubernetes,wide" csv:"kubernetes_version" json:"-"`
-	LastSeenMachine        string `table:"-" csv:"last_seen" json:"-"`
-	LastSeenHuman          string `table:"last_seen" csv:"-" json:"-"`
-	Age                    string `table:"age,wide" csv:"-" json:"-"`
+	Name                   string     `table:"name" csv:"name" json:"-"`
+	DisplayName            string     `table:"title" csv:"title" json:"title,omitempty"`
+	OptimizeProVersion     string     `table:"optimize_pro" csv:"optimize_pro_version" json:"-"`
+	OptimizeLiveVersion    string     `table:"optimize_live" csv:"optimize_live_version" json:"-"`
+	PerformanceTestVersion string     `table:"performance_test,wide" csv:"performance_test_version" json:"-"`
+	KubernetesVersion      string     `table:"kubernetes,wide" csv:"kubernetes_version" json:"-"`
+	LastSeenMachine        string     `table:"-" csv:"last_seen" json:"-"`
+	LastSeenHuman          string     `table:"last_seen" csv:"-" json:"-"`
+	Age                    *time.Time `table:"age,wide" csv:"-" json:"-"`
 
 	applications.ClusterItem `table:"-" csv:"-"`
 }
@@ -445,7 +683,7 @@ func NewClusterRow(item *applications.ClusterItem) *ClusterRow {
 		KubernetesVersion:      item.KubernetesVersion,
 		LastSeenMachine:        formatTime(item.LastSeen, time.RFC3339),
 		LastSeenHuman:          formatTime(item.LastSeen, "ago"),
-		Age:                    formatTime(item.CreatedAt, ""),
+		Age:                    item.CreatedAt,
 
 		ClusterItem: *item,
 	}
@@ -495,6 +733,212 @@ func (o *ClusterOutput) Item(i int) Row { return &o.Items[i] }
 // SortBy sorts the output by the named value.
 func (o *ClusterOutput) SortBy(key string) error { return SortBy(o, key) }
 
+// CredentialRow is a table row representation of a machine credential.
+type CredentialRow struct {
+	Name            string     `table:"name" csv:"name" json:"-"`
+	ClientID        string     `table:"client_id" csv:"client_id" json:"-"`
+	LastUsedMachine string     `table:"-" csv:"last_used" json:"-"`
+	LastUsedHuman   string     `table:"last_used" csv:"-" json:"-"`
+	Age             *time.Time `table:"age" csv:"-" json:"-"`
+
+	applications.CredentialItem `table:"-" csv:"-"`
+}
+
+func NewCredentialRow(item *applications.CredentialItem) *CredentialRow {
+	return &CredentialRow{
+		Name:            item.Name.String(),
+		ClientID:        item.ClientID,
+		LastUsedMachine: formatTime(item.LastUsed, time.RFC3339),
+		LastUsedHuman:   formatTime(item.LastUsed, "ago"),
+		Age:             item.CreatedAt,
+
+		CredentialItem: *item,
+	}
+}
+
+func (r *CredentialRow) Lookup(key string) (interface{}, bool) {
+	switch SortByKey(key) {
+	case "name":
+		return r.Name, true
+	case "client_id":
+		return r.ClientID, true
+	case "last_used":
+		return r.CredentialItem.LastUsed, true
+	case "age":
+		return r.CredentialItem.CreatedAt, true
+	default:
+		return nil, false
+	}
+}
+
+// CredentialOutput wraps a credential list for output.
+type CredentialOutput struct {
+	Items []CredentialRow `json:"items"`
+}
+
+// Add a credential item to the output.
+func (o *CredentialOutput) Add(item *applications.CredentialItem) error {
+	o.Items = append(o.Items, *NewCredentialRow(item))
+	return nil
+}
+
+// Len returns the number of items being output.
+func (o *CredentialOutput) Len() int { return len(o.Items) }
+
+// Swap exchanges the order of the two specified items.
+func (o *CredentialOutput) Swap(i, j int) { o.Items[i], o.Items[j] = o.Items[j], o.Items[i] }
+
+// Item returns the specified row value.
+func (o *CredentialOutput) Item(i int) Row { return &o.Items[i] }
+
+// SortBy sorts the output by the named value.
+func (o *CredentialOutput) SortBy(key string) error { return SortBy(o, key) }
+
+// OrganizationRow is a table row representation of an organization.
+type OrganizationRow struct {
+	Name    string `table:"name" csv:"name" json:"name"`
+	Current bool   `table:"current" csv:"current" json:"current,omitempty"`
+}
+
+func (r *OrganizationRow) Lookup(key string) (interface{}, bool) {
+	switch SortByKey(key) {
+	case "name":
+		return r.Name, true
+	default:
+		return nil, false
+	}
+}
+
+// OrganizationOutput wraps an organization list for output.
+type OrganizationOutput struct {
+	Items []OrganizationRow `json:"items"`
+}
+
+// Len returns the number of items being output.
+func (o *OrganizationOutput) Len() int { return len(o.Items) }
+
+// Swap exchanges the order of the two specified items.
+func (o *OrganizationOutput) Swap(i, j int) { o.Items[i], o.Items[j] = o.Items[j], o.Items[i] }
+
+// Item returns the specified row value.
+func (o *OrganizationOutput) Item(i int) Row { return &o.Items[i] }
+
+// SortBy sorts the output by the named value.
+func (o *OrganizationOutput) SortBy(key string) error { return SortBy(o, key) }
+
+// ChangeRow is a table row representation of a change between snapshots.
+type ChangeRow struct {
+	Kind string `table:"kind" csv:"kind" json:"-"`
+	Name string `table:"name" csv:"name" json:"-"`
+	Type string `table:"change" csv:"change" json:"-"`
+
+	snapshot.Change `table:"-" csv:"-"`
+}
+
+func NewChangeRow(c snapshot.Change) *ChangeRow {
+	return &ChangeRow{
+		Kind: string(c.Kind),
+		Name: c.Name,
+		Type: string(c.Type),
+
+		Change: c,
+	}
+}
+
+func (r *ChangeRow) Lookup(key string) (interface{}, bool) {
+	switch SortByKey(key) {
+	case "kind":
+		return r.Kind, true
+	case "name":
+		return r.Name, true
+	case "change":
+		return r.Type, true
+	default:
+		return nil, false
+	}
+}
+
+// ChangeOutput wraps a list of changes for output.
+type ChangeOutput struct {
+	From  time.Time   `json:"from"`
+	To    time.Time   `json:"to"`
+	Items []ChangeRow `json:"items"`
+}
+
+// Len returns the number of items being output.
+func (o *ChangeOutput) Len() int { return len(o.Items) }
+
+// Swap exchanges the order of the two specified items.
+func (o *ChangeOutput) Swap(i, j int) { o.Items[i], o.Items[j] = o.Items[j], o.Items[i] }
+
+// Item returns the specified row value.
+func (o *ChangeOutput) Item(i int) Row { return &o.Items[i] }
+
+// SortBy sorts the output by the named value.
+func (o *ChangeOutput) SortBy(key string) error { return SortBy(o, key) }
+
+// TemplateRevisionRow is a table row representation of a recorded scenario template.
+type TemplateRevisionRow struct {
+	Name       string     `table:"name" csv:"name" json:"name"`
+	Number     int        `table:"revision" csv:"revision" json:"-"`
+	Parameters int        `table:"parameters" csv:"parameters" json:"-"`
+	Metrics    int        `table:"metrics" csv:"metrics" json:"-"`
+	Recorded   string     `table:"-" csv:"recorded" json:"-"`
+	Age        *time.Time `table:"age" csv:"-" json:"-"`
+
+	snapshot.Revision `table:"-" csv:"-"`
+}
+
+func NewTemplateRevisionRow(name string, rev *snapshot.Revision) *TemplateRevisionRow {
+	t := applications.Template{}
+	_ = json.Unmarshal(rev.Data, &t)
+
+	return &TemplateRevisionRow{
+		Name:       name,
+		Number:     rev.Number,
+		Parameters: len(t.Parameters),
+		Metrics:    len(t.Metrics),
+		Recorded:   rev.Timestamp.Format(time.RFC3339),
+		Age:        &rev.Timestamp,
+
+		Revision: *rev,
+	}
+}
+
+func (r *TemplateRevisionRow) Lookup(key string) (interface{}, bool) {
+	switch SortByKey(key) {
+	case "name":
+		return r.Name, true
+	case "revision":
+		return r.Number, true
+	case "parameters":
+		return r.Parameters, true
+	case "metrics":
+		return r.Metrics, true
+	case "age", "recorded":
+		return r.Age, true
+	default:
+		return nil, false
+	}
+}
+
+// TemplateRevisionOutput wraps a list of template revisions for output.
+type TemplateRevisionOutput struct {
+	Items []TemplateRevisionRow `json:"items"`
+}
+
+// Len returns the number of items being output.
+func (o *TemplateRevisionOutput) Len() int { return len(o.Items) }
+
+// Swap exchanges the order of the two specified items.
+func (o *TemplateRevisionOutput) Swap(i, j int) { o.Items[i], o.Items[j] = o.Items[j], o.Items[i] }
+
+// Item returns the specified row value.
+func (o *TemplateRevisionOutput) Item(i int) Row { return &o.Items[i] }
+
+// SortBy sorts the output by the named value.
+func (o *TemplateRevisionOutput) SortBy(key string) error { return SortBy(o, key) }
+
 type ActivityRow struct {
 	ID               string `table:"id" csv:"id" json:"-"`
 	Title            string `table:"title" csv:"title" json:"-"`
@@ -588,7 +1032,12 @@ func SortBy(o Output, name string) error {
 		case int:
 			s.keys[i] = c.KeyFromString(buf, strconv.Itoa(value))
 		case *time.Time:
-			s.keys[i] = c.KeyFromString(buf, strconv.FormatInt(value.Unix(), 10))
+			// Missing timestamps are typed nils, they sort after everything else
+			if value != nil {
+				s.keys[i] = c.KeyFromString(buf, strconv.FormatInt(value.Unix(), 10))
+			} else {
+				s.keys[i] = c.KeyFromString(buf, "")
+			}
 			reverse = true
 		default:
 			// If you get this panic, add support for the missing type!
@@ -623,6 +1072,24 @@ func (s *sorter) Swap(i, j int) {
 	s.Output.Swap(i, j)
 }
 
+// roundResources applies the rounding policy to the named numeric resource values for display.
+func roundResources(value interface{}, p api.RoundingPolicy) {
+	switch value := value.(type) {
+	case []interface{}:
+		for i := range value {
+			roundResources(value[i], p)
+		}
+	case map[string]interface{}:
+		for k, v := range value {
+			if f, ok := v.(float64); ok {
+				value[k] = p.Round(k, f)
+			} else {
+				roundResources(v, p)
+			}
+		}
+	}
+}
+
 // fixCPU is a hack to adjust the CPU value for display.
 func fixCPU(value interface{}) {
 	switch value := value.(type) {
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+func TestApplicationRow_MarshalJSON(t *testing.T) {
+	cases := []struct {
+		desc     string
+		item     string
+		deploy   *applications.DeployConfiguration
+		expected string
+	}{
+		{
+			desc:     "item only",
+			item:     `{"name":"app","scenarioCount":1}`,
+			expected: `{"name":"app","scenarioCount":1}`,
+		},
+		{
+			desc:     "recommendations",
+			item:     `{"name":"app"}`,
+			deploy:   &applications.DeployConfiguration{Mode: "auto"},
+			expected: `{"name":"app","recommendationsDeployConfig":{"mode":"auto"}}`,
+		},
+		{
+			desc:     "extensions",
+			item:     `{"name":"app","futureField":true}`,
+			expected: `{"name":"app","futureField":true}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			item := &applications.ApplicationItem{}
+			if !assert.NoError(t, json.Unmarshal([]byte(c.item), item)) {
+				return
+			}
+
+			row := NewApplicationRow(item)
+			row.RecommendationsDeployConfig = c.deploy
+
+			actual, err := json.Marshal(row)
+			if assert.NoError(t, err) {
+				assert.JSONEq(t, c.expected, string(actual))
+			}
+
+			// Rows are also encoded by value as part of the list output
+			actual, err = json.Marshal(&ApplicationOutput{Items: []ApplicationRow{*row}})
+			if assert.NoError(t, err) {
+				assert.JSONEq(t, `{"items":[`+c.expected+`]}`, string(actual))
+			}
+		})
+	}
+}
+
+func TestSortBy_MissingTime(t *testing.T) {
+	older := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	t.Run("experiments", func(t *testing.T) {
+		o := &ExperimentOutput{Items: []ExperimentRow{
+			{Name: "missing"},
+			{Name: "older", Age: &older},
+			{Name: "newer", Age: &newer},
+		}}
+		require.NoError(t, o.SortBy("age"))
+
+		var names []string
+		for _, r := range o.Items {
+			names = append(names, r.Name)
+		}
+		assert.Equal(t, []string{"newer", "older", "missing"}, names)
+	})
+
+	t.Run("trials", func(t *testing.T) {
+		o := &TrialOutput{Items: []TrialRow{
+			{Name: "missing"},
+			{Name: "older", Age: &older},
+		}}
+		require.NoError(t, o.SortBy("age"))
+		assert.Equal(t, "older", o.Items[0].Name)
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		o := &CredentialOutput{Items: []CredentialRow{
+			{Name: "unused"},
+			{Name: "used", CredentialItem: applications.CredentialItem{Credential: applications.Credential{LastUsed: &older}}},
+		}}
+		require.NoError(t, o.SortBy("last_used"))
+		assert.Equal(t, "used", o.Items[0].Name)
+	})
+}
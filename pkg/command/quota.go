@@ -0,0 +1,102 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+// NewAPIQuotaCommand returns a command for showing the remaining API request quota.
+func NewAPIQuotaCommand(cfg Config, p Printer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "quota",
+		Annotations: map[string]string{annotationOutput: "quota"},
+		Args:        cobra.NoArgs,
+	}
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		rl, err := rateLimit(ctx, client)
+		if err != nil {
+			return err
+		}
+		if rl == nil {
+			return fmt.Errorf("the server does not report a request quota")
+		}
+
+		row := &QuotaRow{}
+		row.SetRateLimit(rl)
+		return p.Fprint(out, row)
+	})
+	return cmd
+}
+
+// rateLimit returns the request quota reported by the server, or nil if the server does not report one.
+func rateLimit(ctx context.Context, client api.Client) (*api.RateLimit, error) {
+	md, err := applications.NewAPI(client).CheckEndpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rl, ok := md.RateLimit()
+	if !ok {
+		return nil, nil
+	}
+	return &rl, nil
+}
+
+// QuotaRow is a table row representation of the API request quota.
+type QuotaRow struct {
+	Limit        *int       `table:"limit" csv:"limit" json:"limit,omitempty"`
+	Remaining    *int       `table:"remaining" csv:"remaining" json:"remaining,omitempty"`
+	ResetMachine string     `table:"-" csv:"reset" json:"-"`
+	ResetHuman   string     `table:"reset" csv:"-" json:"-"`
+	Reset        *time.Time `table:"-" csv:"-" json:"reset,omitempty"`
+}
+
+func (r *QuotaRow) Lookup(key string) (interface{}, bool) {
+	switch SortByKey(key) {
+	case "limit":
+		return r.Limit, true
+	case "remaining":
+		return r.Remaining, true
+	case "reset":
+		return r.Reset, true
+	default:
+		return nil, false
+	}
+}
+
+// SetRateLimit records the request quota on the row.
+func (r *QuotaRow) SetRateLimit(rl *api.RateLimit) {
+	if rl == nil {
+		return
+	}
+
+	r.Limit = &rl.Limit
+	r.Remaining = &rl.Remaining
+	r.ResetMachine = formatTime(rl.Reset, time.RFC3339)
+	r.ResetHuman = formatTime(rl.Reset, "ago")
+	r.Reset = rl.Reset
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommendation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+// StepLimits is the maximum relative change allowed for a single apply, keyed
+// by resource name (e.g. "cpu" or "memory"). For example, a limit of 0.3
+// will never change a value by more than 30% of the current value.
+type StepLimits map[string]float64
+
+// ParseStepLimits parses step limits from `resource=limit` pairs, where the
+// limit is either a fraction (e.g. "0.3") or a percentage (e.g. "30%").
+func ParseStepLimits(values map[string]string) (StepLimits, error) {
+	limits := make(StepLimits, len(values))
+	for k, v := range values {
+		s, scale := strings.TrimSpace(v), 1.0
+		if strings.HasSuffix(s, "%") {
+			s, scale = strings.TrimSuffix(s, "%"), 0.01
+		}
+
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f <= 0 {
+			return nil, fmt.Errorf("invalid step limit for %q: %s", k, v)
+		}
+		limits[strings.ToLower(k)] = f * scale
+	}
+	return limits, nil
+}
+
+// ClampedValue describes a recommended value that was limited by the guardrails,
+// the values are in cores or bytes.
+type ClampedValue struct {
+	Container   string
+	Path        string
+	Current     float64
+	Recommended float64
+	Applied     float64
+}
+
+// String returns a warning message for the clamped value.
+func (c *ClampedValue) String() string {
+	return fmt.Sprintf("recommended %s for container %q changed from %g to %g (limited to %g)",
+		c.Path, c.Container, c.Current, c.Recommended, c.Applied)
+}
+
+// Clamp returns a patch of the recommended container resources in which
+// every value is limited to the allowed step from the current container
+// resources. Values without a current value or a limit are passed through
+// unchanged; the clamped values are returned so the caller can warn about them.
+func (l StepLimits) Clamp(current, recommended []interface{}) ([]interface{}, []ClampedValue) {
+	// Index the current container resources by container name
+	currentByName := make(map[string]interface{}, len(current))
+	for _, c := range current {
+		if m, ok := c.(map[string]interface{}); ok {
+			currentByName[applications.ContainerName(m)] = m
+		}
+	}
+
+	var clamped []ClampedValue
+	patch := make([]interface{}, 0, len(recommended))
+	for _, r := range recommended {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			patch = append(patch, r)
+			continue
+		}
+
+		name := applications.ContainerName(m)
+		patch = append(patch, l.clamp(&clamped, name, "", currentByName[name], m))
+	}
+	return patch, clamped
+}
+
+// clamp recursively limits the recommended value using the current value.
+func (l StepLimits) clamp(clamped *[]ClampedValue, container, path string, current, recommended interface{}) interface{} {
+	switch rec := recommended.(type) {
+	case map[string]interface{}:
+		cur, _ := current.(map[string]interface{})
+		result := make(map[string]interface{}, len(rec))
+		for k, v := range rec {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			result[k] = l.clamp(clamped, container, p, cur[k], v)
+		}
+		return result
+
+	default:
+		// The resource name is the last element of the path (e.g. "requests.cpu")
+		name := path[strings.LastIndex(path, ".")+1:]
+		limit, ok := l[name]
+		if !ok {
+			return recommended
+		}
+
+		cv, ok := quantityValue(name, current)
+		if !ok || cv == 0 {
+			return recommended
+		}
+		rv, ok := quantityValue(name, recommended)
+		if !ok {
+			return recommended
+		}
+
+		lo, hi := cv*(1-limit), cv*(1+limit)
+		av := math.Max(lo, math.Min(hi, rv))
+		if av == rv {
+			return recommended
+		}
+
+		*clamped = append(*clamped, ClampedValue{
+			Container:   container,
+			Path:        path,
+			Current:     cv,
+			Recommended: rv,
+			Applied:     av,
+		})
+
+		// Keep the representation of the recommended value
+		if _, ok := recommended.(string); ok {
+			return strconv.FormatFloat(av, 'f', -1, 64)
+		}
+		if name == "cpu" {
+			return av * 1000
+		}
+		return av
+	}
+}
+
+// quantityValue returns the amount of a loosely typed resource value in cores
+// or bytes. Consistent with the API, numeric CPU values are in millicores.
+func quantityValue(name string, v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		if name == "cpu" {
+			v /= 1000
+		}
+		return v, true
+	case string:
+		f, err := api.ParseQuantity(v)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// CurrentResources returns the current container resources of the targeted
+// workload in the same form as the recommended container resources, suitable
+// for use with `StepLimits.Clamp`. Nil is returned for unknown workloads.
+func CurrentResources(workloads []applications.WorkloadItem, target applications.TargetRef) ([]interface{}, error) {
+	for i := range workloads {
+		if workloads[i].Target != target {
+			continue
+		}
+
+		data, err := json.Marshal(workloads[i].Containers)
+		if err != nil {
+			return nil, err
+		}
+		var current []interface{}
+		if err := json.Unmarshal(data, &current); err != nil {
+			return nil, err
+		}
+		return current, nil
+	}
+	return nil, nil
+}
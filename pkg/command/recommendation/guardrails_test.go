@@ -0,0 +1,183 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommendation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+func TestParseStepLimits(t *testing.T) {
+	cases := []struct {
+		desc     string
+		values   map[string]string
+		expected StepLimits
+		err      bool
+	}{
+		{
+			desc:     "empty",
+			expected: StepLimits{},
+		},
+		{
+			desc:     "fraction",
+			values:   map[string]string{"cpu": "0.3"},
+			expected: StepLimits{"cpu": 0.3},
+		},
+		{
+			desc:     "percentage",
+			values:   map[string]string{"memory": " 20% "},
+			expected: StepLimits{"memory": 0.2},
+		},
+		{
+			desc:     "case insensitive",
+			values:   map[string]string{"CPU": "0.5"},
+			expected: StepLimits{"cpu": 0.5},
+		},
+		{
+			desc:   "zero",
+			values: map[string]string{"cpu": "0"},
+			err:    true,
+		},
+		{
+			desc:   "negative",
+			values: map[string]string{"cpu": "-10%"},
+			err:    true,
+		},
+		{
+			desc:   "invalid",
+			values: map[string]string{"cpu": "lots"},
+			err:    true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := ParseStepLimits(c.values)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDeltaMapValues(t, c.expected, actual, 1e-9)
+		})
+	}
+}
+
+func TestStepLimits_Clamp(t *testing.T) {
+	limits := StepLimits{"cpu": 0.5, "memory": 0.25}
+	container := func(name string, requests map[string]interface{}) interface{} {
+		return map[string]interface{}{"containerName": name, "requests": requests}
+	}
+
+	cases := []struct {
+		desc        string
+		current     []interface{}
+		recommended []interface{}
+		expected    []interface{}
+		clamped     []string
+	}{
+		{
+			desc:        "within limits",
+			current:     []interface{}{container("app", map[string]interface{}{"cpu": 1000.0, "memory": "1Gi"})},
+			recommended: []interface{}{container("app", map[string]interface{}{"cpu": 1200.0, "memory": "1.1Gi"})},
+			expected:    []interface{}{container("app", map[string]interface{}{"cpu": 1200.0, "memory": "1.1Gi"})},
+		},
+		{
+			desc:        "increase",
+			current:     []interface{}{container("app", map[string]interface{}{"cpu": 1000.0})},
+			recommended: []interface{}{container("app", map[string]interface{}{"cpu": 4000.0})},
+			expected:    []interface{}{container("app", map[string]interface{}{"cpu": 1500.0})},
+			clamped:     []string{"requests.cpu"},
+		},
+		{
+			desc:        "decrease",
+			current:     []interface{}{container("app", map[string]interface{}{"memory": "1000"})},
+			recommended: []interface{}{container("app", map[string]interface{}{"memory": "100"})},
+			expected:    []interface{}{container("app", map[string]interface{}{"memory": "750"})},
+			clamped:     []string{"requests.memory"},
+		},
+		{
+			desc:        "mixed units",
+			current:     []interface{}{container("app", map[string]interface{}{"cpu": "1"})},
+			recommended: []interface{}{container("app", map[string]interface{}{"cpu": 100.0})},
+			expected:    []interface{}{container("app", map[string]interface{}{"cpu": 500.0})},
+			clamped:     []string{"requests.cpu"},
+		},
+		{
+			desc:        "unknown current value",
+			current:     []interface{}{container("other", map[string]interface{}{"cpu": 1000.0})},
+			recommended: []interface{}{container("app", map[string]interface{}{"cpu": 4000.0})},
+			expected:    []interface{}{container("app", map[string]interface{}{"cpu": 4000.0})},
+		},
+		{
+			desc:        "no current resources",
+			recommended: []interface{}{container("app", map[string]interface{}{"cpu": 4000.0})},
+			expected:    []interface{}{container("app", map[string]interface{}{"cpu": 4000.0})},
+		},
+		{
+			desc:        "no limit",
+			current:     []interface{}{container("app", map[string]interface{}{"gpu": 1.0})},
+			recommended: []interface{}{container("app", map[string]interface{}{"gpu": 4.0})},
+			expected:    []interface{}{container("app", map[string]interface{}{"gpu": 4.0})},
+		},
+		{
+			desc:        "not a container",
+			recommended: []interface{}{"app"},
+			expected:    []interface{}{"app"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, clamped := limits.Clamp(c.current, c.recommended)
+			assert.Equal(t, c.expected, actual)
+
+			var paths []string
+			for _, cv := range clamped {
+				assert.Equal(t, "app", cv.Container)
+				paths = append(paths, cv.Path)
+			}
+			assert.Equal(t, c.clamped, paths)
+		})
+	}
+}
+
+func TestCurrentResources(t *testing.T) {
+	target := applications.TargetRef{Kind: "Deployment", Namespace: "default", Workload: "app"}
+	cpu := api.FromValue("500m")
+	workloads := []applications.WorkloadItem{{Workload: applications.Workload{
+		Target: target,
+		Containers: []applications.WorkloadContainer{{
+			Name:     "app",
+			Requests: &applications.ResourceList{CPU: &cpu},
+		}},
+	}}}
+
+	current, err := CurrentResources(workloads, target)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "app", "requests": map[string]interface{}{"cpu": "500m"}}}, current)
+
+	// The current resources can be used to clamp recommendations
+	patch, _ := StepLimits{"cpu": 0.5}.Clamp(current, []interface{}{map[string]interface{}{"containerName": "app", "requests": map[string]interface{}{"cpu": 100.0}}})
+	assert.Equal(t, []interface{}{map[string]interface{}{"containerName": "app", "requests": map[string]interface{}{"cpu": 250.0}}}, patch)
+
+	current, err = CurrentResources(workloads, applications.TargetRef{Kind: "Deployment", Namespace: "default", Workload: "other"})
+	require.NoError(t, err)
+	assert.Nil(t, current)
+}
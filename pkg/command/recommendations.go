@@ -17,31 +17,47 @@ limitations under the License.
 package command
 
 import (
-	"strings"
+	"context"
+	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/thestormforge/optimize-go/pkg/api"
 	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	"github.com/thestormforge/optimize-go/pkg/cost"
 )
 
 // NewGetRecommendationsCommand returns a command for getting recommendations.
 func NewGetRecommendationsCommand(cfg Config, p Printer) *cobra.Command {
 	var (
-		sortBy string
+		sortBy    string
+		round     map[string]string
+		precision map[string]int
+
+		estimateCost bool
+		costOpts     costOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:               "recommendations APP_NAME | APP_NAME/NAME ...",
+		Annotations:       map[string]string{annotationOutput: "recommendation"},
 		Aliases:           []string{"recommendation", "recs", "rec"},
 		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: validRecommendationArgs(cfg),
 	}
 
 	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+	cmd.Flags().StringToStringVar(&round, "round", nil, "round resource values to the nearest `resource=increment` (e.g. memory=16Mi)")
+	cmd.Flags().StringToIntVar(&precision, "precision", nil, "limit resource values to `resource=digits` significant digits")
+	cmd.Flags().BoolVar(&estimateCost, "cost", false, "estimate the monthly cost savings of the recommendations")
+	addCostFlags(cmd, &costOpts)
+
+	p = addNameOutputFlags(cmd, p)
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
+
+		rounding, err := newRoundingPolicy(round, precision)
 		if err != nil {
 			return err
 		}
@@ -50,28 +66,213 @@ func NewGetRecommendationsCommand(cfg Config, p Printer) *cobra.Command {
 			API: applications.NewAPI(client),
 		}
 
-		result := &RecommendationOutput{Items: make([]RecommendationRow, 0, len(args))}
-		if err := l.ForEachNamedRecommendation(ctx, args, false, result.Add); err != nil {
+		pricing, err := costOpts.pricing()
+		if err != nil {
 			return err
 		}
 
+		result := &RecommendationOutput{Items: make([]RecommendationRow, 0, len(args)), Rounding: rounding}
+		workloads := make(map[applications.ApplicationName][]applications.WorkloadItem)
+		for _, arg := range args {
+			if estimateCost {
+				// Fetch the current workloads once per application to compare against
+				appName, _ := applications.SplitRecommendationName(arg)
+				if _, ok := workloads[appName]; !ok {
+					app, err := l.API.GetApplicationByName(ctx, appName)
+					if err != nil {
+						return err
+					}
+					if workloads[appName], err = listWorkloads(ctx, l, &app); err != nil {
+						return err
+					}
+				}
+
+				result.Estimate = func(item *applications.RecommendationItem) (cost.Estimate, bool) {
+					return pricing.EstimateRecommendation(workloads[appName], &item.Recommendation)
+				}
+			}
+			if err := l.ForEachNamedRecommendation(ctx, []string{arg}, false, result.Add); err != nil {
+				return err
+			}
+		}
+
 		if err := result.SortBy(sortBy); err != nil {
 			return err
 		}
 
 		return p.Fprint(out, result)
-	}
+	})
 	return cmd
 }
 
 func validRecommendationArgs(cfg Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
 	return validArgs(cfg, func(l *completionLister, toComplete string) (completions []string, directive cobra.ShellCompDirective) {
 		directive |= cobra.ShellCompDirectiveNoFileComp
-		l.forAllApplications(func(item *applications.ApplicationItem) {
-			if strings.HasPrefix(item.Name.String(), toComplete) {
-				completions = append(completions, item.Name.String())
-			}
-		})
+		completions = filterPrefix(l.applicationNames(), toComplete)
 		return
 	})
 }
+
+// NewExportRecommendationsCommand returns a command for exporting the latest
+// recommendations as a flat dataset of current and recommended resources.
+func NewExportRecommendationsCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		all       bool
+		batchSize int
+		sortBy    string
+	)
+
+	cmd := &cobra.Command{
+		Use:               "recommendations [APP_NAME ...]",
+		Annotations:       map[string]string{annotationOutput: "recommendation-export"},
+		Aliases:           []string{"recommendation", "recs", "rec"},
+		ValidArgsFunction: validApplicationArgs(cfg),
+	}
+
+	cmd.Flags().BoolVarP(&all, "all", "A", false, "export the recommendations of every application")
+	cmd.Flags().IntVar(&batchSize, "chunk-size", 500, "fetch large lists in chu`n`ks rather then all at once")
+	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		switch {
+		case all && len(args) > 0:
+			return fmt.Errorf("application names cannot be specified with --all")
+		case !all && len(args) == 0:
+			return fmt.Errorf("at least one application name is required, or use --all")
+		}
+		return nil
+	}
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := applications.Lister{
+			API:       applications.NewAPI(newMemoClient(client)),
+			BatchSize: batchSize,
+		}
+
+		var items []applications.ApplicationItem
+		addApplication := func(item *applications.ApplicationItem) error {
+			items = append(items, *item)
+			return nil
+		}
+
+		var err error
+		if all {
+			err = l.ForEachApplication(ctx, applications.ApplicationListQuery{}, addApplication)
+		} else {
+			err = l.ForEachNamedApplication(ctx, args, false, addApplication)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Fetch the latest recommendation and the current workloads of each application concurrently
+		recs := make([]*applications.Recommendation, len(items))
+		workloads := make([][]applications.WorkloadItem, len(items))
+		if err := forEachIndex(ctx, len(items), fetchParallelism, func(ctx context.Context, i int) (err error) {
+			recs[i], err = latestRecommendation(ctx, l, &items[i].Application)
+			if err != nil || recs[i] == nil {
+				return err
+			}
+			workloads[i], err = listWorkloads(ctx, l, &items[i].Application)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		result := &RecommendationExportOutput{}
+		for i := range items {
+			result.Add(&items[i], workloads[i], recs[i])
+		}
+
+		if err := result.SortBy(sortBy); err != nil {
+			return err
+		}
+
+		return p.Fprint(out, result)
+	})
+	return cmd
+}
+
+// RecommendationExportRow is a single current and recommended resource value
+// of a workload container. The columns of this row are a stable schema for
+// consumption by other tools.
+type RecommendationExportRow struct {
+	Application    string   `table:"application" csv:"application" json:"application"`
+	Recommendation string   `table:"recommendation,wide" csv:"recommendation" json:"recommendation"`
+	DeployedAt     string   `table:"deployed,wide" csv:"deployed_at" json:"deployedAt,omitempty"`
+	Kind           string   `table:"kind" csv:"kind" json:"kind,omitempty"`
+	Namespace      string   `table:"namespace" csv:"namespace" json:"namespace,omitempty"`
+	Workload       string   `table:"workload" csv:"workload" json:"workload,omitempty"`
+	Container      string   `table:"container" csv:"container" json:"container,omitempty"`
+	Resource       string   `table:"resource" csv:"resource" json:"resource,omitempty"`
+	Current        *float64 `table:"current" csv:"current" json:"current,omitempty"`
+	Recommended    *float64 `table:"recommended" csv:"recommended" json:"recommended,omitempty"`
+}
+
+func (r *RecommendationExportRow) Lookup(key string) (interface{}, bool) {
+	switch SortByKey(key) {
+	case "application", "app":
+		return r.Application, true
+	case "recommendation":
+		return r.Recommendation, true
+	case "deployed", "deployed_at":
+		return r.DeployedAt, true
+	case "kind":
+		return r.Kind, true
+	case "namespace":
+		return r.Namespace, true
+	case "workload":
+		return r.Workload, true
+	case "container":
+		return r.Container, true
+	case "resource":
+		return r.Resource, true
+	default:
+		return nil, false
+	}
+}
+
+// RecommendationExportOutput wraps a recommendation dataset for output.
+type RecommendationExportOutput struct {
+	Items []RecommendationExportRow `json:"items"`
+}
+
+// Add the latest recommendation of an application to the output. Applications
+// without a recommendation are skipped.
+func (o *RecommendationExportOutput) Add(item *applications.ApplicationItem, workloads []applications.WorkloadItem, rec *applications.Recommendation) {
+	if rec == nil {
+		return
+	}
+
+	// Join the current and recommended values the same way the workloads output does
+	wo := &WorkloadOutput{}
+	wo.Add(item, workloads, rec)
+	for _, w := range wo.Items {
+		o.Items = append(o.Items, RecommendationExportRow{
+			Application:    w.Application,
+			Recommendation: rec.Name,
+			DeployedAt:     formatTime(rec.DeployedAt, time.RFC3339),
+			Kind:           w.Kind,
+			Namespace:      w.Namespace,
+			Workload:       w.Workload,
+			Container:      w.Container,
+			Resource:       w.Resource,
+			Current:        w.Current,
+			Recommended:    w.Recommended,
+		})
+	}
+}
+
+// Len returns the number of items being output.
+func (o *RecommendationExportOutput) Len() int { return len(o.Items) }
+
+// Swap exchanges the order of the two specified items.
+func (o *RecommendationExportOutput) Swap(i, j int) { o.Items[i], o.Items[j] = o.Items[j], o.Items[i] }
+
+// Item returns the specified row value.
+func (o *RecommendationExportOutput) Item(i int) Row { return &o.Items[i] }
+
+// SortBy sorts the output by the named value.
+func (o *RecommendationExportOutput) SortBy(key string) error { return SortBy(o, key) }
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/patch"
+)
+
+// templateOptions are the flags used to render the manifest of a trial.
+type templateOptions struct {
+	manifest string
+	patch    string
+}
+
+// addTemplateFlags adds the manifest template flags to the command.
+func addTemplateFlags(cmd *cobra.Command, opts *templateOptions) {
+	cmd.Flags().StringVar(&opts.manifest, "manifest", "", "template manifest `file` rendered with the parameter assignments of each trial")
+	cmd.Flags().StringVar(&opts.patch, "patch", "", "JSON patch `file` applied to the rendered manifest")
+	_ = cmd.MarkFlagFilename("manifest", "yaml", "yml", "json")
+	_ = cmd.MarkFlagFilename("patch", "yaml", "yml", "json")
+}
+
+// template returns the manifest template, or nil if no manifest was specified.
+func (opts *templateOptions) template() (*patch.Template, error) {
+	if opts.manifest == "" {
+		if opts.patch != "" {
+			return nil, fmt.Errorf("a patch requires a manifest")
+		}
+		return nil, nil
+	}
+
+	t := &patch.Template{}
+	var err error
+	if t.Manifest, err = os.ReadFile(opts.manifest); err != nil {
+		return nil, err
+	}
+	if opts.patch != "" {
+		if t.Patch, err = os.ReadFile(opts.patch); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// NewRenderTrialCommand returns a command for rendering the manifest of a trial.
+func NewRenderTrialCommand(cfg Config) *cobra.Command {
+	var (
+		opts templateOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:               "trial EXP_NAME/TRIAL_NUM",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validTrialArgs(cfg),
+	}
+
+	addTemplateFlags(cmd, &opts)
+	_ = cmd.MarkFlagRequired("manifest")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		t, err := opts.template()
+		if err != nil {
+			return err
+		}
+
+		l := experiments.Lister{
+			API: experiments.NewAPI(client),
+		}
+
+		return l.ForEachNamedTrial(ctx, args, experiments.TrialListQuery{}, false, func(item *experiments.TrialItem) error {
+			data, err := t.Render(&item.TrialAssignments)
+			if err != nil {
+				return err
+			}
+
+			_, err = out.Write(data)
+			return err
+		})
+	})
+	return cmd
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/analysis"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/metrics"
+)
+
+// metricOptions are the flags used to collect metric values from a metric source.
+type metricOptions struct {
+	source        string
+	sourceURL     string
+	sourceOptions map[string]string
+	queries       []string
+
+	// Deprecated: use the generic metric source flags instead.
+	prometheusURL     string
+	prometheusQueries []string
+}
+
+// addMetricFlags adds the metric source flags to the command.
+func addMetricFlags(cmd *cobra.Command, opts *metricOptions) {
+	cmd.Flags().StringVar(&opts.source, "metric-source", metrics.SourcePrometheus, fmt.Sprintf("`name` of the source used to collect metric values (one of: %s)", strings.Join(metrics.DefaultRegistry.Names(), ", ")))
+	cmd.Flags().StringVar(&opts.sourceURL, "metric-source-url", "", "base `URL` of the metric source (e.g. the Prometheus server)")
+	cmd.Flags().StringToStringVar(&opts.sourceOptions, "metric-source-option", nil, "metric source specific `key=value` options (e.g. region=us-east-1)")
+	cmd.Flags().StringArrayVar(&opts.queries, "metric-query", nil, "`metric=query` used to collect a metric value, the window is available as {{ .Range }}")
+
+	// The original Prometheus specific flags imply `--metric-source=prometheus`
+	cmd.Flags().StringVar(&opts.prometheusURL, "prometheus-url", "", "Prometheus server `URL` used to collect metric values")
+	cmd.Flags().StringArrayVar(&opts.prometheusQueries, "prometheus-query", nil, "`metric=query` PromQL used to collect a metric value, the window is available as {{ .Range }}")
+	_ = cmd.Flags().MarkDeprecated("prometheus-url", "use --metric-source-url instead")
+	_ = cmd.Flags().MarkDeprecated("prometheus-query", "use --metric-query instead")
+}
+
+// metricSource returns the configured metric source and queries. The source
+// is nil if there are no queries.
+func (opts *metricOptions) metricSource() (metrics.MetricSource, []metrics.Query, error) {
+	name, u, qs := opts.source, opts.sourceURL, opts.queries
+	if opts.prometheusURL != "" || len(opts.prometheusQueries) > 0 {
+		if name != metrics.SourcePrometheus {
+			return nil, nil, fmt.Errorf("--prometheus-url and --prometheus-query cannot be used with --metric-source=%s", name)
+		}
+		if u == "" {
+			u = opts.prometheusURL
+		}
+		qs = append(append([]string{}, qs...), opts.prometheusQueries...)
+	}
+
+	if len(qs) == 0 {
+		return nil, nil, nil
+	}
+
+	queries := make([]metrics.Query, 0, len(qs))
+	for _, q := range qs {
+		name, query, ok := strings.Cut(q, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, nil, fmt.Errorf("invalid metric query %q, expected metric=query", q)
+		}
+		queries = append(queries, metrics.Query{MetricName: strings.TrimSpace(name), Query: query})
+	}
+
+	source, err := metrics.NewSource(&metrics.SourceConfig{
+		Name:    name,
+		URL:     u,
+		Options: opts.sourceOptions,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, queries, nil
+}
+
+// collect adds the metric values collected for the measurement window to the trial values.
+func (opts *metricOptions) collect(ctx context.Context, vls *experiments.TrialValues, w metrics.Window) error {
+	source, queries, err := opts.metricSource()
+	if err != nil || source == nil || vls.Failed {
+		return err
+	}
+
+	values, err := source.Query(ctx, queries, w)
+	if err != nil {
+		return err
+	}
+
+	vls.Values = append(vls.Values, values...)
+	sort.SliceStable(vls.Values, func(i, j int) bool { return vls.Values[i].MetricName < vls.Values[j].MetricName })
+	return nil
+}
+
+// NewReportTrialCommand returns a command for reporting the values of an active trial.
+func NewReportTrialCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		values         []string
+		window         time.Duration
+		failureReason  string
+		failureMessage string
+		annotations    map[string]string
+		metricOpts     metricOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:               "trial EXP_NAME/TRIAL_NUM",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validTrialArgs(cfg),
+	}
+
+	cmd.Flags().StringArrayVar(&values, "value", nil, "metric `name=value` pairs to report, values may include a unit (e.g. latency=250ms)")
+	cmd.Flags().DurationVar(&window, "window", 5*time.Minute, "the `duration` of the measurement window ending now")
+	cmd.Flags().StringVar(&failureReason, "failure-reason", "", "report the trial as failed with this `reason`")
+	cmd.Flags().StringVar(&failureMessage, "failure-message", "", "a human readable `message` describing the failure")
+	cmd.Flags().StringToStringVar(&annotations, "annotate", nil, "annotation `key=value` pairs reported with the trial")
+	addMetricFlags(cmd, &metricOpts)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		end := time.Now()
+		start := end.Add(-window)
+		vls := experiments.TrialValues{
+			Failed:         failureReason != "",
+			FailureReason:  failureReason,
+			FailureMessage: failureMessage,
+			StartTime:      &start,
+			CompletionTime: &end,
+		}
+		vls.Annotate(annotations)
+
+		if !vls.Failed {
+			for _, kv := range values {
+				k, v, ok := strings.Cut(kv, "=")
+				if !ok {
+					return fmt.Errorf("invalid value %q, expected name=value", kv)
+				}
+				value, err := experiments.NewValue(strings.TrimSpace(k), api.FromValue(strings.TrimSpace(v)))
+				if err != nil {
+					return err
+				}
+				vls.Values = append(vls.Values, value)
+			}
+
+			if err := metricOpts.collect(ctx, &vls, metrics.Window{Start: start, End: end}); err != nil {
+				return err
+			}
+
+			if len(vls.Values) == 0 {
+				return fmt.Errorf("at least one --value or --metric-query is required")
+			}
+		}
+
+		l := experiments.Lister{
+			API: experiments.NewAPI(client),
+		}
+
+		q := experiments.TrialListQuery{}
+		q.SetStatus(experiments.TrialActive)
+		return l.ForEachNamedTrial(ctx, args, q, false, func(item *experiments.TrialItem) error {
+			if item.Experiment != nil {
+				if err := experiments.NormalizeTrialValues(&vls, item.Experiment.Metrics); err != nil {
+					return err
+				}
+			}
+
+			u := item.Link(api.RelationSelf)
+			if u == "" {
+				return fmt.Errorf("malformed response, missing self link")
+			}
+
+			if err := l.API.ReportTrial(ctx, u, vls); err != nil {
+				return err
+			}
+
+			return p.Fprint(out, item)
+		})
+	})
+	return cmd
+}
+
+// NewReportImportanceCommand returns a command for reporting which parameters influence the metrics of an experiment.
+func NewReportImportanceCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		opts   analysis.ImportanceOptions
+		sortBy string
+	)
+
+	cmd := &cobra.Command{
+		Use:               "importance EXP_NAME",
+		Annotations:       map[string]string{annotationOutput: "importance"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validExperimentArgs(cfg),
+	}
+
+	cmd.Flags().IntVar(&opts.Bins, "bins", 4, "`number` of groups numeric assignments are split into when estimating the explained variance")
+	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := experiments.Lister{
+			API: experiments.NewAPI(client),
+		}
+
+		exp, err := l.API.GetExperimentByName(ctx, experiments.ExperimentName(args[0]))
+		if err != nil {
+			return err
+		}
+
+		var trials []experiments.TrialItem
+		q := experiments.TrialListQuery{}
+		q.SetStatus(experiments.TrialCompleted)
+		if err := l.ForEachTrial(ctx, &exp, q, func(item *experiments.TrialItem) error {
+			trials = append(trials, *item)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		result := &ImportanceOutput{}
+		for _, pi := range analysis.Importance(&exp, trials, opts) {
+			result.Items = append(result.Items, *NewImportanceRow(&pi))
+		}
+		if len(result.Items) == 0 {
+			return fmt.Errorf("experiment %q needs at least 3 completed trials to estimate parameter importance", args[0])
+		}
+
+		if err := result.SortBy(sortBy); err != nil {
+			return err
+		}
+
+		return p.Fprint(out, result)
+	})
+	return cmd
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/metrics"
+)
+
+func TestMetricOptions_MetricSource(t *testing.T) {
+	cases := []struct {
+		desc    string
+		args    []string
+		source  metrics.MetricSource
+		queries []metrics.Query
+		err     bool
+	}{
+		{
+			desc: "no queries",
+		},
+		{
+			desc:    "metric source",
+			args:    []string{"--metric-source-url", "http://prometheus:9090", "--metric-query", "cost=sum(cost)"},
+			source:  &metrics.Prometheus{URL: "http://prometheus:9090"},
+			queries: []metrics.Query{{MetricName: "cost", Query: "sum(cost)"}},
+		},
+		{
+			desc:    "deprecated prometheus flags",
+			args:    []string{"--prometheus-url", "http://prometheus:9090", "--prometheus-query", "cost=sum(cost)"},
+			source:  &metrics.Prometheus{URL: "http://prometheus:9090"},
+			queries: []metrics.Query{{MetricName: "cost", Query: "sum(cost)"}},
+		},
+		{
+			desc:    "mixed flags",
+			args:    []string{"--prometheus-url", "http://prometheus:9090", "--metric-query", "cost=sum(cost)", "--prometheus-query", "latency=max(latency)"},
+			source:  &metrics.Prometheus{URL: "http://prometheus:9090"},
+			queries: []metrics.Query{{MetricName: "cost", Query: "sum(cost)"}, {MetricName: "latency", Query: "max(latency)"}},
+		},
+		{
+			desc: "deprecated prometheus flags with another source",
+			args: []string{"--metric-source", "datadog", "--prometheus-query", "cost=sum(cost)"},
+			err:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			opts := metricOptions{}
+			cmd := &cobra.Command{}
+			addMetricFlags(cmd, &opts)
+			require.NoError(t, cmd.ParseFlags(c.args))
+
+			source, queries, err := opts.metricSource()
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.source, source)
+			assert.Equal(t, c.queries, queries)
+		})
+	}
+}
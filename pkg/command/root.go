@@ -0,0 +1,330 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// PrinterFactory returns the printer used by a command. The format is a message
+// template for commands which modify a resource (e.g. `created application %q.`),
+// it is empty for commands which list or describe resources.
+type PrinterFactory func(format string) Printer
+
+// RootOptions customize the command tree returned by `NewRootCommand`.
+type RootOptions struct {
+	// The name of the root command, defaults to "optimize".
+	Use string
+	// The factory used to create the printer for each command, defaults to
+	// printing messages and tables controlled by the `AddTableFlags` flags.
+	NewPrinter PrinterFactory
+	// The loader for alternate configurations, the copy commands are omitted if it is nil.
+	LoadConfig ConfigLoader
+	// An optional hook invoked before any other persistent pre-run logic, e.g. to
+	// finish loading the configuration.
+	PersistentPreRunE func(cmd *cobra.Command, args []string) error
+}
+
+// Command group identifiers used by the root command.
+const (
+	groupBasic    = "basic"
+	groupWorkflow = "workflow"
+	groupSettings = "settings"
+)
+
+// NewRootCommand returns the complete command tree. Sub-commands are grouped
+// by verb (e.g. "create", "get", "edit" and "delete") and the shared client
+// and organization flags are added to the root. Errors are not printed, use
+// `HandleError` to report them.
+func NewRootCommand(cfg Config, opts RootOptions) *cobra.Command {
+	if opts.Use == "" {
+		opts.Use = "optimize"
+	}
+	tp := &TablePrinter{}
+	if opts.NewPrinter == nil {
+		opts.NewPrinter = func(format string) Printer {
+			return &defaultPrinter{format: format, table: tp}
+		}
+	}
+	p := opts.NewPrinter
+
+	cmd := &cobra.Command{
+		Use:               opts.Use,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		PersistentPreRunE: opts.PersistentPreRunE,
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			// Completions may be stale once we have modified something
+			if modifiesResources(cmd) {
+				return ClearCompletionCache(cfg)
+			}
+			return nil
+		},
+	}
+
+	AddClientFlags(cmd)
+	AddOrganizationFlag(cmd)
+
+	AddTableFlags(cmd, tp)
+	cmd.PersistentFlags().String("error-format", "text", "the `format` used to report errors; one of: text|json")
+
+	cmd.AddGroup(
+		&cobra.Group{ID: groupBasic, Title: "Basic Commands:"},
+		&cobra.Group{ID: groupWorkflow, Title: "Workflow Commands:"},
+		&cobra.Group{ID: groupSettings, Title: "Settings Commands:"},
+	)
+
+	addGroup := func(groupID string, verb *cobra.Command, cmds ...*cobra.Command) {
+		verb.GroupID = groupID
+		verb.AddCommand(cmds...)
+		cmd.AddCommand(verb)
+	}
+
+	addGroup(groupBasic, &cobra.Command{Use: "create", Short: "Create resources"},
+		NewCreateApplicationCommand(cfg, p(`created application %q.`)),
+		NewCreateScenarioCommand(cfg, p(`created scenario %q.`)),
+		NewCreateExperimentCommand(cfg, p(`created experiment %q.`)),
+		NewCreateTrialCommand(cfg, p(`created trial %q.`)),
+		NewCreateTrialsCommand(cfg, p(`created trial %q.`)),
+		NewCreateCredentialCommand(cfg),
+	)
+
+	addGroup(groupBasic, addCachedFlag(&cobra.Command{Use: "get", Short: "Display one or more resources"}),
+		NewGetApplicationsCommand(cfg, p("")),
+		NewGetScenariosCommand(cfg, p("")),
+		NewGetRecommendationsCommand(cfg, p("")),
+		NewGetExperimentsCommand(cfg, p("")),
+		NewGetTrialsCommand(cfg, p("")),
+		NewGetClustersCommand(cfg, p("")),
+		NewGetActivityCommand(cfg, p("")),
+		NewGetWorkloadsCommand(cfg, p("")),
+		NewGetTemplateHistoryCommand(cfg, p("")),
+		NewGetCredentialsCommand(cfg, p("")),
+	)
+
+	addGroup(groupBasic, &cobra.Command{Use: "describe", Short: "Show details of a resource"},
+		NewDescribeApplicationCommand(cfg, &DescriberPrinter{Fallback: p("")}),
+		NewDescribeExperimentCommand(cfg, &DescriberPrinter{Fallback: p("")}),
+	)
+
+	addGroup(groupBasic, &cobra.Command{Use: "status", Short: "Summarize the progress of a resource"},
+		NewStatusExperimentCommand(cfg, &DescriberPrinter{Fallback: p("")}),
+	)
+
+	addGroup(groupBasic, &cobra.Command{Use: "edit", Short: "Edit resources, including their labels"},
+		NewEditApplicationCommand(cfg, p(`updated application %q.`)),
+		NewEditScenarioCommand(cfg, p(`updated scenario %q.`)),
+		NewEditExperimentCommand(cfg, p(`updated experiment %q.`)),
+		NewEditTrialCommand(cfg, p(`updated trial %q.`)),
+		NewEditClusterCommand(cfg, p(`updated cluster %q.`)),
+	)
+
+	addGroup(groupBasic, &cobra.Command{Use: "delete", Short: "Delete resources"},
+		NewDeleteApplicationsCommand(cfg, p(`deleted application %q.`)),
+		NewDeleteScenariosCommand(cfg, p(`deleted scenario %q.`)),
+		NewDeleteExperimentsCommand(cfg, p(`deleted experiment %q.`)),
+		NewDeleteTrialsCommand(cfg, p(`deleted trial %q.`)),
+		NewDeleteClustersCommand(cfg, p(`deleted cluster %q.`)),
+	)
+
+	addGroup(groupBasic, &cobra.Command{Use: "diff", Short: "Compare resources"},
+		NewDiffApplicationCommand(cfg),
+		NewDiffExperimentsCommand(cfg),
+	)
+
+	addGroup(groupBasic, &cobra.Command{Use: "lint", Short: "Check resources for problems"},
+		NewLintTemplateCommand(cfg),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "enable", Short: "Enable features of a resource"},
+		NewEnableApplicationRecommendationsCommand(cfg, p(`enabled application recommendations.`)),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "disable", Short: "Disable features of a resource"},
+		NewDisableApplicationRecommendationsCommand(cfg, p(`disabled application recommendations.`)),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "watch", Short: "Watch for changes"},
+		NewWatchActivityCommand(cfg),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "serve", Short: "Forward resources to other systems"},
+		NewServeActivityWebhookCommand(cfg),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "run", Short: "Run trials locally"},
+		NewRunExperimentCommand(cfg),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "report", Short: "Report the results of trials"},
+		NewReportTrialCommand(cfg, p(`reported trial %q.`)),
+		NewReportImportanceCommand(cfg, p("")),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "render", Short: "Render the manifests of trials"},
+		NewRenderTrialCommand(cfg),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "import", Short: "Import resources"},
+		NewImportTrialsCommand(cfg, p(`imported trials into experiment %q.`)),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "export", Short: "Export resources"},
+		NewExportInventoryCommand(cfg, p("")),
+		NewExportRecommendationsCommand(cfg, p("")),
+	)
+
+	if opts.LoadConfig != nil {
+		addGroup(groupWorkflow, &cobra.Command{Use: "copy", Short: "Copy resources to a different server"},
+			NewCopyApplicationCommand(cfg, opts.LoadConfig, p(`copied application %q.`)),
+			NewCopyExperimentCommand(cfg, opts.LoadConfig, p(`copied experiment %q.`)),
+		)
+	}
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "rollback", Short: "Restore previous revisions of resources"},
+		NewRollbackTemplateCommand(cfg, p(`rolled back template %q to revision %d.`)),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "archive", Short: "Hide resources from listings without deleting them"},
+		NewArchiveExperimentsCommand(cfg, p(`archived experiment %q.`)),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "unarchive", Short: "Restore archived resources"},
+		NewUnarchiveExperimentsCommand(cfg, p(`unarchived experiment %q.`)),
+	)
+
+	addGroup(groupWorkflow, &cobra.Command{Use: "gc", Short: "Garbage collect resources"},
+		NewGCExperimentsCommand(cfg, p("")),
+	)
+
+	applyCmd := NewApplyCommand(cfg)
+	applyCmd.GroupID = groupWorkflow
+	syncCmd := NewSyncCommand(cfg, p(""))
+	syncCmd.GroupID = groupWorkflow
+	changesCmd := NewChangesCommand(cfg, p(""))
+	changesCmd.GroupID = groupWorkflow
+
+	addGroup(groupSettings, &cobra.Command{Use: "config", Short: "Modify the configuration"},
+		NewConfigGetOrgsCommand(cfg, p("")),
+		NewConfigUseOrgCommand(cfg, p(`switched to organization %q.`)),
+	)
+
+	addGroup(groupSettings, &cobra.Command{Use: "api", Short: "Inspect API access"},
+		NewAPIQuotaCommand(cfg, p("")),
+	)
+
+	addGroup(groupSettings, &cobra.Command{Use: "revoke", Short: "Revoke access credentials"},
+		NewRevokeCredentialsCommand(cfg, p(`revoked credential %q.`)),
+	)
+
+	whoAmICmd := NewWhoAmICommand(cfg)
+	whoAmICmd.GroupID = groupSettings
+	loginCmd := NewLoginCommand(cfg)
+	loginCmd.GroupID = groupSettings
+	logoutCmd := NewLogoutCommand(cfg)
+	logoutCmd.GroupID = groupSettings
+
+	cmd.AddCommand(
+		applyCmd,
+		syncCmd,
+		changesCmd,
+		whoAmICmd,
+		loginCmd,
+		logoutCmd,
+		NewSchemaCommand(),
+	)
+
+	return cmd
+}
+
+// modifiesResources checks if the command changes resources on the server,
+// making any cached completions stale.
+func modifiesResources(cmd *cobra.Command) bool {
+	// Find the top-level command, e.g. "create" for `create application`
+	verb := cmd
+	for verb.HasParent() && verb.Parent().HasParent() {
+		verb = verb.Parent()
+	}
+	if !verb.HasParent() {
+		return false
+	}
+
+	switch verb.Name() {
+	case "create", "edit", "delete", "enable", "disable", "copy", "import", "gc", "config", "revoke",
+		"apply", "archive", "unarchive", "rollback":
+		return true
+	}
+	return false
+}
+
+// DefaultPrinter returns a printer which renders the message format using the
+// name of the resource, or renders the resource as JSON if there is no format.
+func DefaultPrinter(format string) Printer {
+	return &defaultPrinter{format: format}
+}
+
+type defaultPrinter struct {
+	format string
+	table  *TablePrinter
+}
+
+// Fprint renders the supplied object.
+func (p *defaultPrinter) Fprint(w io.Writer, obj interface{}) error {
+	if p.format != "" {
+		format := p.format + "\n"
+		var err error
+		switch obj := obj.(type) {
+		case *applications.ApplicationItem:
+			_, err = fmt.Fprintf(w, format, obj.Name)
+		case *applications.ScenarioItem:
+			_, err = fmt.Fprintf(w, format, obj.Name)
+		case *CredentialRow:
+			_, err = fmt.Fprintf(w, format, obj.Name)
+		case *applications.RecommendationList:
+			_, err = fmt.Fprint(w, format)
+		case *applications.RecommendationItem:
+			_, err = fmt.Fprintf(w, format, obj.Name)
+		case *experiments.ExperimentItem:
+			_, err = fmt.Fprintf(w, format, obj.Name)
+		case *experiments.TrialItem:
+			_, err = fmt.Fprintf(w, format, experiments.JoinTrialName(obj.Experiment, obj.Number))
+		case *OrganizationRow:
+			_, err = fmt.Fprintf(w, format, obj.Name)
+		case *TemplateRevisionRow:
+			_, err = fmt.Fprintf(w, format, obj.Name, obj.Number)
+		}
+		return err
+	}
+
+	if p.table != nil {
+		switch obj.(type) {
+		case Output, Row:
+			return p.table.Fprint(w, obj)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(obj)
+}
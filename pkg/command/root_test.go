@@ -0,0 +1,47 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifiesResources(t *testing.T) {
+	root := NewRootCommand(staticConfig("https://api.example.com/"), RootOptions{})
+	cases := []struct {
+		args     []string
+		expected bool
+	}{
+		{args: nil, expected: false},
+		{args: []string{"get", "applications"}, expected: false},
+		{args: []string{"whoami"}, expected: false},
+		{args: []string{"create", "application"}, expected: true},
+		{args: []string{"edit", "scenario"}, expected: true},
+		{args: []string{"apply"}, expected: true},
+		{args: []string{"archive", "experiments"}, expected: true},
+		{args: []string{"unarchive", "experiments"}, expected: true},
+		{args: []string{"rollback", "template"}, expected: true},
+	}
+	for _, c := range cases {
+		cmd, _, err := root.Find(c.args)
+		require.NoError(t, err)
+		assert.Equal(t, c.expected, modifiesResources(cmd), "%s", cmd.CommandPath())
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/kubernetes"
+	"github.com/thestormforge/optimize-go/pkg/metrics"
+	"github.com/thestormforge/optimize-go/pkg/notify"
+	"github.com/thestormforge/optimize-go/pkg/patch"
+)
+
+// NewRunExperimentCommand returns a command for running the trials of an experiment locally.
+func NewRunExperimentCommand(cfg Config) *cobra.Command {
+	var (
+		command      string
+		driverConfig string
+		parallelism  int
+		retries      int
+		maxTrials    int
+		trialTimeout time.Duration
+		annotations  map[string]string
+		outboxDir    string
+		notifyOpts   notifyOptions
+		templateOpts templateOptions
+		metricOpts   metricOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:               "experiment NAME",
+		Aliases:           []string{"exp"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validExperimentArgs(cfg),
+	}
+
+	cmd.Flags().StringVar(&command, "command", "", "shell `command` executed for each trial")
+	cmd.Flags().StringVar(&driverConfig, "kubernetes", "", "run each trial in a Kubernetes cluster using the driver configuration `file`")
+	cmd.Flags().IntVar(&parallelism, "parallelism", 1, "`number` of trials to run concurrently")
+	cmd.Flags().IntVar(&retries, "retries", 0, "`number` of times a failing trial command is retried")
+	cmd.Flags().IntVar(&maxTrials, "max-trials", 0, "stop after running this `number` of trials")
+	cmd.Flags().DurationVar(&trialTimeout, "trial-timeout", 0, "maximum amount of `time` a single trial may run")
+	cmd.Flags().StringToStringVar(&annotations, "annotate", nil, "annotation `key=value` pairs reported with each trial (e.g. commit=$GIT_SHA)")
+	cmd.Flags().StringVar(&outboxDir, "outbox", "", "queue trial reports that cannot be delivered in this `directory` and deliver them once the server is reachable")
+	addNotifyFlags(cmd, &notifyOpts, true)
+	addTemplateFlags(cmd, &templateOpts)
+	addMetricFlags(cmd, &metricOpts)
+	_ = cmd.MarkFlagFilename("kubernetes", "yaml", "yml", "json")
+	cmd.MarkFlagsMutuallyExclusive("command", "kubernetes")
+	cmd.MarkFlagsMutuallyExclusive("manifest", "kubernetes")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		runTrial, err := trialFunc(cmd, command, driverConfig, &templateOpts, &metricOpts)
+		if err != nil {
+			return err
+		}
+
+		expAPI := experiments.NewAPI(client)
+
+		exp, err := expAPI.GetExperimentByName(ctx, experiments.ExperimentName(args[0]))
+		if err != nil {
+			return err
+		}
+
+		u := exp.Link(api.RelationNextTrial)
+		if u == "" {
+			return fmt.Errorf("experiment %q is not accepting trials", args[0])
+		}
+
+		var reportAPI experiments.API = expAPI
+		var outbox *experiments.Outbox
+		if outboxDir != "" {
+			outbox = &experiments.Outbox{Dir: outboxDir}
+			reportAPI = &experiments.OutboxAPI{
+				API:    expAPI,
+				Outbox: outbox,
+				OnDiscard: func(err error) {
+					warnDiscardedReports(cmd.ErrOrStderr(), err)
+				},
+			}
+		}
+
+		l := &experiments.TrialLoop{
+			API:          reportAPI,
+			TrialTimeout: trialTimeout,
+			Parallelism:  parallelism,
+			Retries:      retries,
+			MaxTrials:    maxTrials,
+			Metrics:      exp.Metrics,
+			Annotations:  annotations,
+			OnTrialFinish: func(_ *experiments.TrialAssignments, vls *experiments.TrialValues) {
+				_, _ = fmt.Fprintln(out, formatTrialValues(vls))
+			},
+		}
+
+		n := notifyOpts.attachTrialLoop(cmd, l, args[0])
+
+		err = l.Run(ctx, u, runTrial)
+		if outbox != nil {
+			flushOutbox(ctx, cmd.ErrOrStderr(), outbox, expAPI)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Do not report a stopped experiment if we only ran out of local trials
+		if maxTrials == 0 && n != nil {
+			// Refresh the experiment to include the final number of observations
+			if e, err := expAPI.GetExperiment(ctx, exp.Link(api.RelationSelf)); err == nil {
+				exp = e
+			}
+			n.Notify(ctx, notify.ExperimentStopped(args[0], &exp))
+		}
+		return nil
+	})
+	return cmd
+}
+
+// flushOutbox makes a final attempt to deliver the queued trial reports,
+// warning about the reports which could not be delivered.
+func flushOutbox(ctx context.Context, w io.Writer, outbox *experiments.Outbox, expAPI experiments.API) {
+	// Reports are kept for the next run if we are shutting down because of an interrupt
+	if ctx.Err() != nil {
+		return
+	}
+
+	_, err := outbox.Flush(ctx, expAPI)
+	var discarded api.NameErrorList
+	if errors.As(err, &discarded) {
+		warnDiscardedReports(w, err)
+	} else if err != nil {
+		_, _ = fmt.Fprintf(w, "Warning: trial reports remain queued in %s: %v\n", outbox.Dir, err)
+	}
+}
+
+// warnDiscardedReports warns about queued trial reports the server will never accept.
+func warnDiscardedReports(w io.Writer, err error) {
+	_, _ = fmt.Fprintf(w, "Warning: discarded queued trial reports:\n%s\n", indent(err.Error()))
+}
+
+// trialFunc returns the function used to execute each trial, either the shell
+// command or the Kubernetes trial driver. Values collected from the metric
+// source are added to the values produced by the shell command.
+func trialFunc(cmd *cobra.Command, command, driverConfig string, templateOpts *templateOptions, metricOpts *metricOptions) (experiments.TrialFunc, error) {
+	if driverConfig != "" {
+		cfg, err := kubernetes.LoadConfig(driverConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := kubernetes.NewDriver(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return d.RunTrial, nil
+	}
+
+	if command == "" {
+		return nil, fmt.Errorf("either --command or --kubernetes is required")
+	}
+
+	tmpl, err := templateOpts.template()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := metricOpts.metricSource(); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, ta *experiments.TrialAssignments) (experiments.TrialValues, error) {
+		vls, err := runTrialCommand(ctx, command, tmpl, ta, cmd.ErrOrStderr())
+		if err != nil {
+			return vls, err
+		}
+
+		if err := metricOpts.collect(ctx, &vls, metrics.Window{Start: *vls.StartTime, End: *vls.CompletionTime}); err != nil {
+			return vls, err
+		}
+
+		if len(vls.Values) == 0 {
+			return vls, fmt.Errorf("trial command did not produce any metric values")
+		}
+		return vls, nil
+	}, nil
+}
+
+// runTrialCommand executes the shell command for a single trial. The assignments
+// are passed as `PARAM_<NAME>` environment variables and as JSON on stdin, the
+// metric values are parsed from stdout. If there is a manifest template, the
+// path to the rendered manifest is passed as the `TRIAL_MANIFEST` environment variable.
+func runTrialCommand(ctx context.Context, command string, tmpl *patch.Template, ta *experiments.TrialAssignments, stderr io.Writer) (experiments.TrialValues, error) {
+	vls := experiments.TrialValues{}
+
+	in, err := json.Marshal(ta)
+	if err != nil {
+		return vls, err
+	}
+
+	c := exec.CommandContext(ctx, "sh", "-c", command)
+	c.Env = os.Environ()
+	for _, a := range ta.Assignments {
+		c.Env = append(c.Env, "PARAM_"+envName(a.ParameterName)+"="+a.Value.String())
+	}
+
+	if tmpl != nil {
+		manifest, err := renderTrialManifest(tmpl, ta)
+		if err != nil {
+			return vls, err
+		}
+		defer os.Remove(manifest)
+		c.Env = append(c.Env, "TRIAL_MANIFEST="+manifest)
+	}
+	c.Stdin = bytes.NewReader(in)
+	c.Stderr = stderr
+
+	start := time.Now()
+	data, err := c.Output()
+	if err != nil {
+		return vls, fmt.Errorf("trial command failed: %w", err)
+	}
+	end := time.Now()
+
+	values, err := parseMetricValues(data)
+	if err != nil {
+		return vls, err
+	}
+
+	vls.Values = values
+	vls.StartTime = &start
+	vls.CompletionTime = &end
+	return vls, nil
+}
+
+// renderTrialManifest renders the manifest for a trial into a temporary file, returning the file name.
+func renderTrialManifest(tmpl *patch.Template, ta *experiments.TrialAssignments) (string, error) {
+	data, err := tmpl.Render(ta)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp("", "trial-manifest-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// envName converts a parameter name to an environment variable name.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
+
+// parseMetricValues parses the output of a trial command. The output is either
+// a JSON object mapping metric names to values, or lines of `name=value` pairs;
+// any other lines are ignored. The result may be empty.
+func parseMetricValues(data []byte) ([]experiments.Value, error) {
+	var values []experiments.Value
+
+	if m := map[string]api.NumberOrString{}; json.Unmarshal(bytes.TrimSpace(data), &m) == nil {
+		for k, v := range m {
+			value, err := experiments.NewValue(k, v)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	} else {
+		s := bufio.NewScanner(bytes.NewReader(data))
+		for s.Scan() {
+			k, v, ok := strings.Cut(s.Text(), "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				continue
+			}
+			value, err := experiments.NewValue(strings.TrimSpace(k), api.FromString(strings.TrimSpace(v)))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].MetricName < values[j].MetricName })
+	return values, nil
+}
+
+// formatTrialValues returns a single line summary of the trial values.
+func formatTrialValues(vls *experiments.TrialValues) string {
+	if vls.Failed {
+		return fmt.Sprintf("Trial failed (%s): %s", vls.FailureReason, vls.FailureMessage)
+	}
+
+	parts := make([]string, 0, len(vls.Values))
+	for _, v := range vls.Values {
+		if v.Unit != "" {
+			parts = append(parts, v.MetricName+"="+v.Unit.Format(v.Value))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%g", v.MetricName, v.Value))
+	}
+	return "Trial completed: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// reportAPI records delivered trial reports, failing with the configured error for each trial URL.
+type reportAPI struct {
+	experiments.API
+	errs     map[string]error
+	reported []string
+}
+
+func (a *reportAPI) ReportTrial(_ context.Context, u string, _ experiments.TrialValues) error {
+	if err := a.errs[u]; err != nil {
+		return err
+	}
+	a.reported = append(a.reported, u)
+	return nil
+}
+
+func TestFlushOutbox(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		desc     string
+		ctx      context.Context
+		errs     map[string]error
+		reported []string
+		pending  int
+		warning  string
+	}{
+		{
+			desc:     "delivered",
+			ctx:      context.Background(),
+			reported: []string{"t1", "t2"},
+		},
+		{
+			desc:    "offline",
+			ctx:     context.Background(),
+			errs:    map[string]error{"t1": errors.New("network is unreachable")},
+			pending: 2,
+			warning: "Warning: trial reports remain queued in",
+		},
+		{
+			desc:     "rejected",
+			ctx:      context.Background(),
+			errs:     map[string]error{"t1": &api.Error{Type: experiments.ErrTrialInvalid, StatusCode: http.StatusUnprocessableEntity}},
+			reported: []string{"t2"},
+			warning:  "Warning: discarded queued trial reports:\n  t1",
+		},
+		{
+			desc:    "interrupted",
+			ctx:     canceled,
+			pending: 2,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			outbox := &experiments.Outbox{Dir: t.TempDir()}
+			require.NoError(t, outbox.Enqueue("", "t1", experiments.TrialValues{}))
+			require.NoError(t, outbox.Enqueue("", "t2", experiments.TrialValues{}))
+
+			fake := &reportAPI{errs: c.errs}
+			var w bytes.Buffer
+			flushOutbox(c.ctx, &w, outbox, fake)
+
+			assert.Equal(t, c.reported, fake.reported)
+			pending, err := outbox.Pending()
+			require.NoError(t, err)
+			assert.Len(t, pending, c.pending)
+			if c.warning != "" {
+				assert.Contains(t, w.String(), c.warning)
+			} else {
+				assert.Empty(t, w.String())
+			}
+		})
+	}
+}
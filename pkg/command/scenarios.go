@@ -17,7 +17,9 @@ limitations under the License.
 package command
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -36,15 +38,12 @@ func NewCreateScenarioCommand(cfg Config, p Printer) *cobra.Command {
 		containerResourceSelector string
 		replicaSelector           string
 		goals                     []string
+		objectives                []string
 		perftestScenario          struct {
-			testCase string
-		}
-		locustScenario struct {
-			locustfile string
-			users      int
-			spawnRate  int
-			runTime    time.Duration
+			testCase          string
+			targetUtilization int
 		}
+		loadTest       loadTestOptions
 		customScenario struct {
 			usePushGateway     bool
 			podTemplateFile    string
@@ -65,29 +64,18 @@ func NewCreateScenarioCommand(cfg Config, p Printer) *cobra.Command {
 	cmd.Flags().StringVar(&containerResourceSelector, "container-resource-selector", "", "`selector` for application resources which should have container resource optimization applied")
 	cmd.Flags().StringVar(&replicaSelector, "replica-selector", "", "`selector` for application resources which should have replica optimization applied")
 	cmd.Flags().StringSliceVar(&goals, "goals", nil, "specify the application optimization `objectives`")
-	cmd.Flags().StringVar(&perftestScenario.testCase, "test-case", "", "`name` of the StormForge Performance test case to use")
-	cmd.Flags().StringVar(&locustScenario.locustfile, "locustfile", "", "`file` containing the Python module to run")
-	cmd.Flags().IntVar(&locustScenario.users, "locust-users", 0, "`num`ber of concurrent Locust users")
-	cmd.Flags().IntVar(&locustScenario.spawnRate, "locust-spawn-rate", 0, "`rate` per second in which users are spawned")
-	cmd.Flags().DurationVar(&locustScenario.runTime, "locust-run-time", 0, "stop after the specified amount of `time`")
+	cmd.Flags().StringArrayVar(&objectives, "objective", nil, "optimization `goal` with an optional target, e.g. \"cost\" or \"p95-latency=200ms\"")
+	cmd.Flags().StringVar(&perftestScenario.testCase, "test-case", "", "`name` of the StormForge Performance test case to use, optionally prefixed by the organization (e.g. org/case)")
+	cmd.Flags().IntVar(&perftestScenario.targetUtilization, "target-utilization", 0, "`percent`age of the test case target load used during trials")
+	loadTest.addFlags(cmd)
 	cmd.Flags().BoolVar(&customScenario.usePushGateway, "custom-use-push-gateway", false, "enables the Prometheus Push Gateway")
 	cmd.Flags().StringVar(&customScenario.podTemplateFile, "custom-pod-template", "", "`file` containing the custom trial job pod template")
 	cmd.Flags().DurationVar(&customScenario.initialDelay, "custom-initial-delay", 0, "additional `delay` before starting the trial job pod")
 	cmd.Flags().DurationVar(&customScenario.approximateRuntime, "custom-approximate-runtime", 0, "the estimated amount of `time` the trial should last")
 	cmd.Flags().StringVar(&customScenario.image, "custom-image", "", "override the image `name` of the first container in the trial job pod")
 
-	// TODO The application service will not persist these values
-	cmd.Flag("locustfile").Hidden = true
-	cmd.Flag("locust-users").Hidden = true
-	cmd.Flag("locust-spawn-rate").Hidden = true
-	cmd.Flag("locust-run-time").Hidden = true
-
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		appAPI := applications.NewAPI(client)
 
@@ -127,45 +115,33 @@ func NewCreateScenarioCommand(cfg Config, p Printer) *cobra.Command {
 			})
 		}
 
-		// Only support generating named based goals
-		var namedGoals []interface{}
-		for _, goal := range goals {
-			namedGoals = append(namedGoals, map[string]interface{}{"name": goal})
+		// Generate goals from the names and the (optionally targeted) objectives
+		scnGoals, err := parseGoals(goals, objectives)
+		if err != nil {
+			return err
 		}
-		if len(namedGoals) > 0 {
-			scn.Objective = append(scn.Objective, map[string]interface{}{"goals": namedGoals})
+		if len(scnGoals) > 0 {
+			scn.Objective = append(scn.Objective, applications.NewObjective(scnGoals...))
 		}
 
 		// Scenario settings
 		settings := make(map[string]interface{})
 		switch {
 		case perftestScenario.testCase != "":
-			settings["testCase"] = perftestScenario.testCase
-			scn.StormForgePerformance = settings
-
-		case locustScenario.locustfile != "":
-			switch strings.ToLower(strings.SplitN(locustScenario.locustfile, ":", 2)[0]) {
-			case "http", "https":
-				//	The file name field can be a URL
-				settings["locustfile"] = locustScenario.locustfile
-			default:
-				// The file contents can be inlined in the file name field
-				data, err := os.ReadFile(locustScenario.locustfile)
-				if err != nil {
-					return err
-				}
-				settings["locustfile"] = string(data)
+			scn.StormForgePerformance = &applications.StormForgePerformanceScenario{
+				TestCase:          perftestScenario.testCase,
+				TargetUtilization: perftestScenario.targetUtilization,
 			}
-			if locustScenario.users > 0 {
-				settings["users"] = locustScenario.users
-			}
-			if locustScenario.spawnRate > 0 {
-				settings["spawnRate"] = locustScenario.spawnRate
+
+		case loadTest.locustfile != "":
+			if scn.Locust, err = loadTest.locust(cmd.InOrStdin()); err != nil {
+				return err
 			}
-			if locustScenario.runTime > 0 {
-				settings["runTime"] = locustScenario.runTime.String()
+
+		case loadTest.jmeterPlan != "":
+			if scn.JMeter, err = loadTest.jmeter(cmd.InOrStdin()); err != nil {
+				return err
 			}
-			scn.Locust = settings
 
 		default:
 			if customScenario.podTemplateFile != "" {
@@ -202,6 +178,10 @@ func NewCreateScenarioCommand(cfg Config, p Printer) *cobra.Command {
 			}
 		}
 
+		if err := applications.ValidateScenario(&scn); err != nil {
+			return fmt.Errorf("invalid scenario definition:\n%s", indent(err.Error()))
+		}
+
 		var selfURL string
 		if scnName != "" {
 			md, err := appAPI.CreateScenarioByName(ctx, scenariosURL, scnName, scn)
@@ -225,35 +205,66 @@ func NewCreateScenarioCommand(cfg Config, p Printer) *cobra.Command {
 		}
 
 		return p.Fprint(out, NewScenarioRow(&applications.ScenarioItem{Scenario: scn}))
-	}
+	})
 	return cmd
 }
 
 // NewEditScenarioCommand returns a command for editing a scenario.
 func NewEditScenarioCommand(cfg Config, p Printer) *cobra.Command {
 	var (
-		title    string
-		clusters []string
+		title      string
+		clusters   []string
+		objectives []string
+		loadTest   loadTestOptions
+		keepLast   int
+		maxAgeDays int
 	)
 
 	cmd := &cobra.Command{
-		Use:     "scenario APP_NAME/NAME",
-		Aliases: []string{"scn"},
-		Args:    cobra.ExactArgs(1),
+		Use:               "scenario APP_NAME/NAME",
+		Aliases:           []string{"scn"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validScenarioArgs(cfg),
 	}
 
 	cmd.Flags().StringVar(&title, "title", "", "human readable `name` for the scenario")
 	cmd.Flags().StringArrayVar(&clusters, "cluster", nil, "cluster `name` used for experimentation")
+	cmd.Flags().StringArrayVar(&objectives, "objective", nil, "replace the optimization goals, e.g. \"cost\" or \"p95-latency=200ms\"")
+	loadTest.addFlags(cmd)
+	cmd.Flags().IntVar(&keepLast, "keep-last", 0, "retain only the most recent `number` of experiments")
+	cmd.Flags().IntVar(&maxAgeDays, "max-age-days", 0, "retain experiments for this number of `days`")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
+
+		scnGoals, err := parseGoals(nil, objectives)
+		if err != nil {
+			return err
+		}
+
+		var (
+			locust *applications.LocustScenario
+			jmeter *applications.JMeterScenario
+		)
+		if loadTest.locustfile != "" {
+			if locust, err = loadTest.locust(cmd.InOrStdin()); err != nil {
+				return err
+			}
+		}
+		if loadTest.jmeterPlan != "" {
+			if jmeter, err = loadTest.jmeter(cmd.InOrStdin()); err != nil {
+				return err
+			}
+		}
+
+		// Scenario changes can regenerate the template, record it so it can be rolled back
+		appAPI, err := templateRecorder(cfg, applications.NewAPI(client))
 		if err != nil {
 			return err
 		}
 
 		l := applications.Lister{
-			API: applications.NewAPI(client),
+			API: appAPI,
 		}
 
 		return l.ForEachNamedScenario(ctx, args, false, func(item *applications.ScenarioItem) error {
@@ -263,23 +274,189 @@ func NewEditScenarioCommand(cfg Config, p Printer) *cobra.Command {
 			}
 
 			scn := applications.Scenario{
+				Metadata:    item.Preconditions(),
 				DisplayName: title,
 				Clusters:    nil,
 			}
 
-			if scn.DisplayName == "" {
+			if cmd.Flags().Changed("keep-last") || cmd.Flags().Changed("max-age-days") {
+				scn.Retention = &applications.RetentionPolicy{}
+				if item.Retention != nil {
+					*scn.Retention = *item.Retention
+				}
+				if cmd.Flags().Changed("keep-last") {
+					scn.Retention.KeepLast = keepLast
+				}
+				if cmd.Flags().Changed("max-age-days") {
+					scn.Retention.MaxAgeDays = maxAgeDays
+				}
+			}
+
+			if len(scnGoals) > 0 {
+				scn.Objective = []interface{}{applications.NewObjective(scnGoals...)}
+			}
+			scn.Locust = locust
+			scn.JMeter = jmeter
+
+			if scn.DisplayName == "" && scn.Retention == nil && scn.Objective == nil && len(scn.Kinds()) == 0 {
 				return nil
 			}
 
+			// Validate the scenario as it will be once the patch is applied
+			merged := applications.Scenario{}
+			if err := mergePatch(item.Scenario, scn, &merged); err != nil {
+				return err
+			}
+			if err := applications.ValidateScenario(&merged); err != nil {
+				return fmt.Errorf("invalid scenario definition:\n%s", indent(err.Error()))
+			}
+
 			if err := l.API.PatchScenario(ctx, selfURL, scn); err != nil {
 				return err
 			}
 			return p.Fprint(out, NewScenarioRow(item))
 		})
-	}
+	})
 	return cmd
 }
 
+// mergePatch applies the JSON merge patch (RFC 7386) representation of the
+// patch to the target, the result is unmarshalled into `out`.
+func mergePatch(target, patch, out interface{}) error {
+	var t, p interface{}
+	if err := remarshal(target, &t); err != nil {
+		return err
+	}
+	if err := remarshal(patch, &p); err != nil {
+		return err
+	}
+	return remarshal(mergeValues(t, p), out)
+}
+
+// mergeValues merges the patch value into the target value.
+func mergeValues(target, patch interface{}) interface{} {
+	pm, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	tm, ok := target.(map[string]interface{})
+	if !ok {
+		tm = make(map[string]interface{}, len(pm))
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+		} else {
+			tm[k] = mergeValues(tm[k], v)
+		}
+	}
+	return tm
+}
+
+// remarshal converts a value by round tripping it through JSON.
+func remarshal(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// loadTestOptions are the flags used to configure the load generator of a scenario.
+type loadTestOptions struct {
+	locustfile       string
+	users            int
+	spawnRate        int
+	runTime          time.Duration
+	jmeterPlan       string
+	jmeterThreads    int
+	jmeterRampUp     time.Duration
+	jmeterDuration   time.Duration
+	jmeterProperties map[string]string
+}
+
+// addFlags registers the load test flags on the supplied command.
+func (o *loadTestOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.locustfile, "locustfile", "", "`file` containing the Python module to run")
+	cmd.Flags().IntVar(&o.users, "locust-users", 0, "`num`ber of concurrent Locust users")
+	cmd.Flags().IntVar(&o.spawnRate, "locust-spawn-rate", 0, "`rate` per second in which users are spawned")
+	cmd.Flags().DurationVar(&o.runTime, "locust-run-time", 0, "stop after the specified amount of `time`")
+	cmd.Flags().StringVar(&o.jmeterPlan, "jmeter-plan", "", "`file` containing the JMeter test plan to run, \"-\" reads from stdin")
+	cmd.Flags().IntVar(&o.jmeterThreads, "jmeter-threads", 0, "`num`ber of JMeter threads")
+	cmd.Flags().DurationVar(&o.jmeterRampUp, "jmeter-ramp-up", 0, "amount of `time` taken to start all the threads")
+	cmd.Flags().DurationVar(&o.jmeterDuration, "jmeter-duration", 0, "stop after the specified amount of `time`")
+	cmd.Flags().StringToStringVar(&o.jmeterProperties, "jmeter-property", nil, "additional `key=value` properties for the test plan")
+
+	// TODO The application service will not persist these values
+	cmd.Flag("locustfile").Hidden = true
+	cmd.Flag("locust-users").Hidden = true
+	cmd.Flag("locust-spawn-rate").Hidden = true
+	cmd.Flag("locust-run-time").Hidden = true
+}
+
+// locust returns the Locust scenario configuration.
+func (o *loadTestOptions) locust(in io.Reader) (*applications.LocustScenario, error) {
+	locustfile, err := readLoadTestFile(in, o.locustfile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &applications.LocustScenario{
+		Locustfile: locustfile,
+		Users:      o.users,
+		SpawnRate:  o.spawnRate,
+		RunTime:    api.Duration(o.runTime),
+	}, nil
+}
+
+// jmeter returns the JMeter scenario configuration.
+func (o *loadTestOptions) jmeter(in io.Reader) (*applications.JMeterScenario, error) {
+	plan, err := readLoadTestFile(in, o.jmeterPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	return &applications.JMeterScenario{
+		TestPlan:   plan,
+		Threads:    o.jmeterThreads,
+		RampUp:     api.Duration(o.jmeterRampUp),
+		Duration:   api.Duration(o.jmeterDuration),
+		Properties: o.jmeterProperties,
+	}, nil
+}
+
+// readLoadTestFile returns the load test file reference: URLs are used as-is,
+// otherwise the contents of the file (or stdin for "-") are inlined.
+func readLoadTestFile(in io.Reader, name string) (string, error) {
+	switch strings.ToLower(strings.SplitN(name, ":", 2)[0]) {
+	case "http", "https":
+		return name, nil
+	default:
+		data, err := readInput(in, name)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// parseGoals returns the goals for the supplied goal names and `name=target` objectives.
+func parseGoals(names []string, objectives []string) ([]applications.Goal, error) {
+	goals := make([]applications.Goal, 0, len(names)+len(objectives))
+	for _, name := range names {
+		goals = append(goals, applications.Goal{Name: name})
+	}
+	for _, obj := range objectives {
+		g, err := applications.ParseGoal(obj)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, g)
+	}
+	return goals, nil
+}
+
 // NewGetScenariosCommand returns a command for getting scenarios.
 func NewGetScenariosCommand(cfg Config, p Printer) *cobra.Command {
 	var (
@@ -287,19 +464,19 @@ func NewGetScenariosCommand(cfg Config, p Printer) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:     "scenarios APP_NAME | APP_NAME/NAME ...",
-		Aliases: []string{"scenario", "scn"},
-		Args:    cobra.MinimumNArgs(1),
+		Use:               "scenarios APP_NAME | APP_NAME/NAME ...",
+		Annotations:       map[string]string{annotationOutput: "scenario"},
+		Aliases:           []string{"scenario", "scn"},
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: validScenarioArgs(cfg),
 	}
 
 	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := applications.Lister{
 			API: applications.NewAPI(client),
@@ -315,7 +492,7 @@ func NewGetScenariosCommand(cfg Config, p Printer) *cobra.Command {
 		}
 
 		return p.Fprint(out, result)
-	}
+	})
 	return cmd
 }
 
@@ -326,19 +503,18 @@ func NewDeleteScenariosCommand(cfg Config, p Printer) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:     "scenarios APP_NAME | APP_NAME/NAME ...",
-		Aliases: []string{"scenario", "scn"},
-		Args:    cobra.MinimumNArgs(1),
+		Use:               "scenarios APP_NAME | APP_NAME/NAME ...",
+		Aliases:           []string{"scenario", "scn"},
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: validScenarioArgs(cfg),
 	}
 
 	cmd.Flags().BoolVar(&ignoreNotFound, "ignore-not-found", ignoreNotFound, "treat not found errors as successful deletes")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := applications.Lister{
 			API: applications.NewAPI(client),
@@ -356,6 +532,6 @@ func NewDeleteScenariosCommand(cfg Config, p Printer) *cobra.Command {
 
 			return p.Fprint(out, NewScenarioRow(item))
 		})
-	}
+	})
 	return cmd
 }
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+func TestMergeValues(t *testing.T) {
+	target := map[string]interface{}{
+		"title":     "before",
+		"retention": map[string]interface{}{"keepLast": 3.0, "maxAgeDays": 7.0},
+		"clusters":  []interface{}{"a"},
+	}
+	patch := map[string]interface{}{
+		"title":     "after",
+		"retention": map[string]interface{}{"keepLast": 5.0},
+		"clusters":  nil,
+	}
+	assert.Equal(t, map[string]interface{}{
+		"title":     "after",
+		"retention": map[string]interface{}{"keepLast": 5.0, "maxAgeDays": 7.0},
+	}, mergeValues(target, patch))
+}
+
+func TestMergePatch_Scenario(t *testing.T) {
+	current := applications.Scenario{
+		DisplayName:           "Load Test",
+		StormForgePerformance: &applications.StormForgePerformanceScenario{TestCase: "checkout"},
+		Retention:             &applications.RetentionPolicy{KeepLast: 3},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		merged := applications.Scenario{}
+		require.NoError(t, mergePatch(current, applications.Scenario{Retention: &applications.RetentionPolicy{KeepLast: 5}}, &merged))
+		assert.Equal(t, "Load Test", merged.DisplayName)
+		assert.Equal(t, 5, merged.Retention.KeepLast)
+		assert.NoError(t, applications.ValidateScenario(&merged))
+	})
+
+	t.Run("conflicting load tests", func(t *testing.T) {
+		patch := applications.Scenario{Locust: &applications.LocustScenario{Locustfile: "https://example.com/locustfile.py"}}
+		require.NoError(t, applications.ValidateScenario(&patch), "the patch on its own is valid")
+
+		merged := applications.Scenario{}
+		require.NoError(t, mergePatch(current, patch, &merged))
+		assert.Error(t, applications.ValidateScenario(&merged))
+	})
+}
+
+func TestReadLoadTestFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "locustfile.py")
+	require.NoError(t, os.WriteFile(filename, []byte("from file"), 0600))
+
+	cases := []struct {
+		desc     string
+		name     string
+		expected string
+	}{
+		{desc: "url", name: "https://example.com/locustfile.py", expected: "https://example.com/locustfile.py"},
+		{desc: "file", name: filename, expected: "from file"},
+		{desc: "stdin", name: "-", expected: "from stdin"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := readLoadTestFile(strings.NewReader("from stdin"), c.name)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// annotationOutput is the command annotation used to identify the rows produced by the command.
+const annotationOutput = "stormforge.io/output"
+
+// outputRows are the row types which can be referenced by the output annotation.
+var outputRows = map[string]interface{}{
+	"activity":              &ActivityRow{},
+	"application":           &ApplicationRow{},
+	"change":                &ChangeRow{},
+	"cluster":               &ClusterRow{},
+	"credential":            &CredentialRow{},
+	"experiment":            &ExperimentRow{},
+	"importance":            &ImportanceRow{},
+	"inventory":             &InventoryRow{},
+	"organization":          &OrganizationRow{},
+	"quota":                 &QuotaRow{},
+	"recommendation":        &RecommendationRow{},
+	"recommendation-export": &RecommendationExportRow{},
+	"scenario":              &ScenarioRow{},
+	"template-revision":     &TemplateRevisionRow{},
+	"trial":                 &TrialRow{},
+	"workload":              &WorkloadRow{},
+}
+
+// CommandSchema is the machine-readable description of a command.
+type CommandSchema struct {
+	Name     string          `json:"name"`
+	Path     string          `json:"path"`
+	Use      string          `json:"use"`
+	Aliases  []string        `json:"aliases,omitempty"`
+	Short    string          `json:"short,omitempty"`
+	Flags    []FlagSchema    `json:"flags,omitempty"`
+	Output   []ColumnSchema  `json:"output,omitempty"`
+	Commands []CommandSchema `json:"commands,omitempty"`
+}
+
+// FlagSchema is the machine-readable description of a command flag.
+type FlagSchema struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default,omitempty"`
+	Usage      string `json:"usage,omitempty"`
+	Persistent bool   `json:"persistent,omitempty"`
+}
+
+// ColumnSchema is the machine-readable description of a column in the command output.
+type ColumnSchema struct {
+	Table string `json:"table,omitempty"`
+	CSV   string `json:"csv,omitempty"`
+	JSON  string `json:"json,omitempty"`
+	Type  string `json:"type"`
+	Wide  bool   `json:"wide,omitempty"`
+}
+
+// NewSchemaCommand returns a hidden command for dumping the command tree as JSON.
+func NewSchemaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "schema",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(NewCommandSchema(cmd.Root()))
+	}
+	return cmd
+}
+
+// NewCommandSchema returns the schema for the supplied command and all of its non-hidden sub-commands.
+func NewCommandSchema(cmd *cobra.Command) CommandSchema {
+	s := CommandSchema{
+		Name:    cmd.Name(),
+		Path:    cmd.CommandPath(),
+		Use:     cmd.Use,
+		Aliases: cmd.Aliases,
+		Short:   cmd.Short,
+	}
+
+	addFlags := func(flags *pflag.FlagSet, persistent bool) {
+		flags.VisitAll(func(f *pflag.Flag) {
+			if f.Hidden {
+				return
+			}
+			s.Flags = append(s.Flags, FlagSchema{
+				Name:       f.Name,
+				Shorthand:  f.Shorthand,
+				Type:       f.Value.Type(),
+				Default:    f.DefValue,
+				Usage:      f.Usage,
+				Persistent: persistent,
+			})
+		})
+	}
+	addFlags(cmd.LocalNonPersistentFlags(), false)
+	addFlags(cmd.PersistentFlags(), true)
+
+	if row, ok := outputRows[cmd.Annotations[annotationOutput]]; ok {
+		s.Output = rowSchema(reflect.TypeOf(row).Elem())
+	}
+
+	for _, c := range cmd.Commands() {
+		if c.Hidden || !c.IsAvailableCommand() {
+			continue
+		}
+		s.Commands = append(s.Commands, NewCommandSchema(c))
+	}
+
+	return s
+}
+
+// rowSchema returns the columns of the supplied row type based on the struct field tags.
+func rowSchema(t reflect.Type) []ColumnSchema {
+	var cols []ColumnSchema
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+
+		table, wide := tagName(f.Tag.Get("table"))
+		csv, _ := tagName(f.Tag.Get("csv"))
+		js, _ := tagName(f.Tag.Get("json"))
+		if table == "" && csv == "" {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		cols = append(cols, ColumnSchema{
+			Table: table,
+			CSV:   csv,
+			JSON:  js,
+			Type:  ft.Kind().String(),
+			Wide:  wide,
+		})
+	}
+	return cols
+}
+
+// tagName returns the name from a struct tag value and a flag indicating if the "wide" option is present.
+func tagName(tag string) (string, bool) {
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		name = ""
+	}
+	return name, strings.Contains(","+opts+",", ",wide,")
+}
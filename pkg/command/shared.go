@@ -18,7 +18,13 @@ package command
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/thestormforge/optimize-go/pkg/api"
@@ -32,6 +38,144 @@ type Config interface {
 	Address() string
 }
 
+// AddClientFlags adds the persistent flags which control how API requests are
+// made by the command (or any of its sub-commands), e.g. `--timeout` and `--debug`.
+func AddClientFlags(cmd *cobra.Command) {
+	var (
+		timeout time.Duration
+		debug   bool
+	)
+
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", api.DefaultTimeout, "the maximum amount of `time` allowed for each API request; zero means no timeout")
+	cmd.PersistentFlags().BoolVar(&debug, "debug", false, "log the API requests to stderr")
+
+	preRunE := cmd.PersistentPreRunE
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		if preRunE != nil {
+			if err := preRunE(c, args); err != nil {
+				return err
+			}
+		}
+
+		ctx := c.Context()
+		if f := c.Flags().Lookup("timeout"); f != nil && f.Changed {
+			ctx = api.ContextWithTimeout(ctx, timeout)
+		}
+		if debug {
+			ctx = context.WithValue(ctx, debugKey{}, c.ErrOrStderr())
+		}
+		c.SetContext(ctx)
+		return nil
+	}
+}
+
+type debugKey struct{}
+
+// withAPIClient returns a function suitable for use as a command's `RunE` which
+// creates an API client for the supplied configuration before invoking `f`.
+// Authorization errors are decorated with hints for resolving them.
+func withAPIClient(cfg Config, f func(cmd *cobra.Command, args []string, client api.Client) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		var transport http.RoundTripper
+		if w, ok := cmd.Context().Value(debugKey{}).(io.Writer); ok {
+			transport = &debugTransport{Out: w}
+		}
+
+		// Commands supporting the `--cached` flag remember their responses, if the user opts in
+		ct := newCacheTransport(cmd, cfg, transport)
+		if ct != nil {
+			transport = ct
+		}
+
+		client, err := api.NewClient(cfg.Address(), transport)
+		if err != nil {
+			return err
+		}
+
+		err = f(cmd, args, client)
+		if ct != nil {
+			if stale := ct.Stale(); !stale.IsZero() {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: the API server is unreachable, showing cached results from %s\n", formatTime(&stale, "ago"))
+			}
+		}
+		if api.IsUnauthorized(err) {
+			return fmt.Errorf("%w (verify the STORMFORGE_CLIENT_ID and STORMFORGE_CLIENT_SECRET, or STORMFORGE_TOKEN, environment variables; use `whoami` to inspect the current identity)", err)
+		}
+		return err
+	}
+}
+
+// debugTransport logs a summary of each request and response, including the
+// server request ID when one is reported.
+type debugTransport struct {
+	Base http.RoundTripper
+	Out  io.Writer
+}
+
+// RoundTrip logs the request and response status before returning the response of the base transport.
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	_, _ = fmt.Fprintf(t.Out, "> %s %s\n", req.Method, req.URL)
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		_, _ = fmt.Fprintf(t.Out, "< %v (%s)\n", err, time.Since(start).Round(time.Millisecond))
+		return nil, err
+	}
+	if id := api.Metadata(resp.Header).RequestID(); id != "" {
+		_, _ = fmt.Fprintf(t.Out, "< %s (%s, request ID: %s)\n", resp.Status, time.Since(start).Round(time.Millisecond), id)
+		return resp, nil
+	}
+	_, _ = fmt.Fprintf(t.Out, "< %s (%s)\n", resp.Status, time.Since(start).Round(time.Millisecond))
+	return resp, nil
+}
+
+// AddOrganizationFlag adds a persistent `--org` flag to the supplied command,
+// when set the organization is attached to every API request made by the
+// command (or any of its sub-commands).
+func AddOrganizationFlag(cmd *cobra.Command) {
+	var org string
+	cmd.PersistentFlags().StringVar(&org, "org", "", "select the `organization` used for API requests")
+
+	preRunE := cmd.PersistentPreRunE
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		if preRunE != nil {
+			if err := preRunE(c, args); err != nil {
+				return err
+			}
+		}
+
+		if org != "" {
+			c.SetContext(api.WithHeader(c.Context(), api.HeaderOrganization, org))
+		}
+		return nil
+	}
+}
+
+// nameParallelism is the number of names resolved concurrently when a command
+// is given multiple resource names.
+const nameParallelism = 4
+
+// addContinueOnErrorFlag adds a flag for processing every name argument even
+// if some of them fail, the failures are reported after all the names are processed.
+func addContinueOnErrorFlag(cmd *cobra.Command, continueOnError *bool) {
+	cmd.Flags().BoolVar(continueOnError, "continue-on-error", false, "process all names, reporting any failures at the end")
+}
+
+// splitNameErrors separates the failures of individual names (which should be
+// reported after any results) from other errors.
+func splitNameErrors(err error) (nameErrs error, other error) {
+	var el api.NameErrorList
+	if errors.As(err, &el) {
+		return el, nil
+	}
+	return nil, err
+}
+
 // parseLabelSelector returns a map of simple equality based label selectors.
 func parseLabelSelector(s string) map[string]string {
 	if s == "" {
@@ -59,7 +203,7 @@ func validArgs(cfg Config, f func(*completionLister, string) ([]string, cobra.Sh
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
-		return f(&completionLister{ctx: cmd.Context(), client: client}, toComplete)
+		return f(&completionLister{ctx: cmd.Context(), client: client, cfg: cfg}, toComplete)
 	}
 }
 
@@ -67,23 +211,24 @@ func validArgs(cfg Config, f func(*completionLister, string) ([]string, cobra.Sh
 type completionLister struct {
 	ctx    context.Context
 	client api.Client
+	cfg    Config
 }
 
-// forEachApplication lists all applications, ignoring errors.
-func (c *completionLister) forAllApplications(f func(item *applications.ApplicationItem)) {
+// forEachApplication lists all applications, returning any listing error.
+func (c *completionLister) forAllApplications(f func(item *applications.ApplicationItem)) error {
 	l := applications.Lister{API: applications.NewAPI(c.client)}
 	q := applications.ApplicationListQuery{}
-	_ = l.ForEachApplication(c.ctx, q, func(item *applications.ApplicationItem) error {
+	return l.ForEachApplication(c.ctx, q, func(item *applications.ApplicationItem) error {
 		f(item)
 		return nil
 	})
 }
 
-// forEachExperiment lists all experiments, ignoring errors.
-func (c *completionLister) forAllExperiments(f func(item *experiments.ExperimentItem)) {
+// forEachExperiment lists all experiments, returning any listing error.
+func (c *completionLister) forAllExperiments(f func(item *experiments.ExperimentItem)) error {
 	l := experiments.Lister{API: experiments.NewAPI(c.client)}
 	q := experiments.ExperimentListQuery{}
-	_ = l.ForEachExperiment(c.ctx, q, func(item *experiments.ExperimentItem) error {
+	return l.ForEachExperiment(c.ctx, q, func(item *experiments.ExperimentItem) error {
 		f(item)
 		return nil
 	})
@@ -99,3 +244,41 @@ func (c *completionLister) forAllClusters(f func(item *applications.ClusterItem)
 		return nil
 	})
 }
+
+// forAllCredentials lists all machine credentials, ignoring errors.
+func (c *completionLister) forAllCredentials(f func(item *applications.CredentialItem)) {
+	l := applications.Lister{API: applications.NewAPI(c.client)}
+	_ = l.ForEachCredential(c.ctx, func(item *applications.CredentialItem) error {
+		f(item)
+		return nil
+	})
+}
+
+// newRoundingPolicy returns a rounding policy from the supplied flag values.
+func newRoundingPolicy(round map[string]string, precision map[string]int) (api.RoundingPolicy, error) {
+	p := api.RoundingPolicy{}
+	for name, quantity := range round {
+		if err := p.SetIncrement(name, quantity); err != nil {
+			return nil, err
+		}
+	}
+	for name, digits := range precision {
+		if err := p.SetSignificantDigits(name, digits); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// indent prefixes each line of the supplied text for display under a heading.
+func indent(text string) string {
+	return "  " + strings.ReplaceAll(text, "\n", "\n  ")
+}
+
+// readInput reads the contents of a file, "-" is used for stdin.
+func readInput(in io.Reader, filename string) ([]byte, error) {
+	if filename == "-" {
+		return io.ReadAll(in)
+	}
+	return os.ReadFile(filename)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/analysis"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// NewStatusExperimentCommand returns a command for summarizing the progress of an experiment.
+func NewStatusExperimentCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		advice     bool
+		adviceOpts analysis.AdviceOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:               "experiment NAME",
+		Aliases:           []string{"exp"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validExperimentArgs(cfg),
+	}
+
+	cmd.Flags().BoolVar(&advice, "advice", false, "suggest if more trials or longer measurement windows are needed")
+	cmd.Flags().Float64Var(&adviceOpts.Confidence, "confidence", 0.95, "confidence `level` of the measurement intervals")
+	cmd.Flags().Float64Var(&adviceOpts.Precision, "precision", 0.05, "acceptable measurement interval as a `fraction` of the mean metric value")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := experiments.Lister{
+			API: experiments.NewAPI(client),
+		}
+
+		exp, err := l.API.GetExperimentByName(ctx, experiments.ExperimentName(args[0]))
+		if err != nil {
+			return err
+		}
+
+		var trials []experiments.TrialItem
+		counts := make(map[experiments.TrialStatus]int)
+		if err := l.ForEachTrial(ctx, &exp, experiments.TrialListQuery{}, func(item *experiments.TrialItem) error {
+			counts[item.Status]++
+			trials = append(trials, *item)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		d := &Description{}
+		d.Add("Name", exp.Name)
+		if exp.Budget > 0 {
+			d.Add("Progress", fmt.Sprintf("%d of %d observations (%.0f%%)", exp.Observations, exp.Budget, 100*float64(exp.Observations)/float64(exp.Budget)))
+		} else {
+			d.Add("Progress", fmt.Sprintf("%d observations", exp.Observations))
+		}
+
+		summary := d.Add("Trials", len(trials))
+		for _, s := range []experiments.TrialStatus{
+			experiments.TrialStaged,
+			experiments.TrialActive,
+			experiments.TrialCompleted,
+			experiments.TrialFailed,
+			experiments.TrialAbandoned,
+		} {
+			if counts[s] > 0 {
+				summary.Add(string(s), counts[s])
+			}
+		}
+
+		if advice {
+			a := analysis.Advise(&exp, trials, adviceOpts)
+
+			precision := d.Add("Measurement Precision", "")
+			for _, m := range a.Metrics {
+				if m.Count == 0 || m.MeasurementError == 0 {
+					precision.Add(m.MetricName, "unknown")
+					continue
+				}
+				precision.Add(m.MetricName, fmt.Sprintf("%g ± %.3g (±%.1f%%)", m.Mean, m.ConfidenceInterval, 100*m.RelativePrecision))
+			}
+
+			suggestions := d.Add("Advice", "")
+			msgs := a.Messages()
+			if len(msgs) == 0 {
+				msgs = append(msgs, "the completed trials are sufficient")
+			}
+			for _, msg := range msgs {
+				suggestions.Add("", msg)
+			}
+		}
+
+		return p.Fprint(out, d)
+	})
+	return cmd
+}
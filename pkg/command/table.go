@@ -0,0 +1,325 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// minColumnWidth is the narrowest a column will be truncated to.
+const minColumnWidth = 10
+
+// columnSeparator is the padding between table columns.
+const columnSeparator = "   "
+
+// TablePrinter renders rows as a table using the `table` struct tags of the row type.
+type TablePrinter struct {
+	// Omit the header row.
+	NoHeaders bool
+	// Include the columns marked as "wide".
+	Wide bool
+	// Never truncate values to fit the table into the available width.
+	NoTruncate bool
+	// The maximum width of the table, if zero the width of the terminal is used.
+	MaxWidth int
+	// Show times as RFC 3339 timestamps instead of relative ages.
+	ShowTimestamps bool
+	// Never highlight values using color, color is also disabled if the `NO_COLOR`
+	// environment variable is set or the output is not a terminal.
+	NoColor bool
+}
+
+// statusColors are the ANSI color codes used to highlight status values.
+var statusColors = map[string]string{
+	"completed": "32", // green
+	"failed":    "31", // red
+	"stopped":   "90", // gray
+	"abandoned": "90", // gray
+}
+
+// AddTableFlags adds the persistent flags used to control the table printer to the supplied command.
+func AddTableFlags(cmd *cobra.Command, tp *TablePrinter) {
+	cmd.PersistentFlags().BoolVar(&tp.NoHeaders, "no-headers", false, "omit the header row from table output")
+	cmd.PersistentFlags().BoolVar(&tp.Wide, "wide", false, "include additional columns in table output")
+	cmd.PersistentFlags().BoolVar(&tp.NoTruncate, "no-truncate", false, "never truncate values to fit table output into the terminal width")
+	cmd.PersistentFlags().BoolVar(&tp.NoColor, "no-color", false, "never highlight table output using color")
+	cmd.PersistentFlags().BoolVar(&tp.ShowTimestamps, "show-timestamps", false, "show times in table output as RFC 3339 timestamps instead of ages")
+}
+
+// Fprint renders a row or list of rows as a table.
+func (tp *TablePrinter) Fprint(out io.Writer, obj interface{}) error {
+	var rows []Row
+	switch obj := obj.(type) {
+	case Output:
+		for i := 0; i < obj.Len(); i++ {
+			rows = append(rows, obj.Item(i))
+		}
+	case Row:
+		rows = append(rows, obj)
+	default:
+		return fmt.Errorf("unable to print %T as a table", obj)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	// Collect the cells, the first row is the header
+	cols := tp.columns(reflect.TypeOf(rows[0]))
+	var cells [][]string
+	if !tp.NoHeaders {
+		header := make([]string, len(cols))
+		for i, c := range cols {
+			header[i] = strings.ToUpper(strings.ReplaceAll(c.name, "_", " "))
+		}
+		cells = append(cells, header)
+	}
+	for _, r := range rows {
+		rv := reflect.Indirect(reflect.ValueOf(r))
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = tp.formatCell(rv.FieldByIndex(c.index))
+		}
+		cells = append(cells, row)
+	}
+
+	widths := make([]int, len(cols))
+	for _, row := range cells {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	if !tp.NoTruncate {
+		maxWidth := tp.MaxWidth
+		if maxWidth == 0 {
+			maxWidth = terminalWidth(out)
+		}
+		shrinkColumns(widths, maxWidth)
+	}
+
+	color := tp.useColor(out)
+
+	var sb strings.Builder
+	for r, row := range cells {
+		sb.Reset()
+		for i, cell := range row {
+			cell = truncate(cell, widths[i])
+			padding := widths[i] - utf8.RuneCountInString(cell)
+
+			// Highlight the status values (but not the header)
+			if color && cols[i].name == "status" && (r > 0 || tp.NoHeaders) {
+				cell = highlightStatus(cell)
+			}
+
+			sb.WriteString(cell)
+			if i < len(row)-1 {
+				sb.WriteString(strings.Repeat(" ", padding))
+				sb.WriteString(columnSeparator)
+			}
+		}
+		if _, err := fmt.Fprintln(out, strings.TrimRight(sb.String(), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tableColumn struct {
+	name  string
+	index []int
+}
+
+// columns returns the table columns of the supplied row type.
+func (tp *TablePrinter) columns(t reflect.Type) []tableColumn {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var cols []tableColumn
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+
+		tag := f.Tag.Get("table")
+		name, wide := tagName(tag)
+		if name == "" || (wide && !tp.Wide) || strings.Contains(tag, ",custom") {
+			continue
+		}
+
+		cols = append(cols, tableColumn{name: name, index: f.Index})
+	}
+	return cols
+}
+
+// formatCell returns the string representation of a cell value.
+func (tp *TablePrinter) formatCell(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+		return tp.formatCell(v.Elem())
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, fmt.Sprintf("%v=%v", iter.Key().Interface(), iter.Value().Interface()))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		switch {
+		case t.IsZero():
+			return ""
+		case tp.ShowTimestamps:
+			return t.Format(time.RFC3339)
+		default:
+			return formatAge(time.Since(t))
+		}
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// formatAge returns a short representation of an age, e.g. "45m" or "3d".
+// Negative ages (e.g. due to clock skew) are shown as "0s".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "0s"
+	case d < time.Minute:
+		return strconv.Itoa(int(d.Seconds())) + "s"
+	case d < time.Hour:
+		return strconv.Itoa(int(d.Minutes())) + "m"
+	case d < 48*time.Hour:
+		return strconv.Itoa(int(d.Hours())) + "h"
+	case d < 2*365*24*time.Hour:
+		return strconv.Itoa(int(d.Hours()/24)) + "d"
+	default:
+		return strconv.Itoa(int(d.Hours()/24/365)) + "y"
+	}
+}
+
+// shrinkColumns reduces the widest columns until the table fits in the maximum width.
+func shrinkColumns(widths []int, maxWidth int) {
+	if maxWidth <= 0 {
+		return
+	}
+
+	for {
+		total := len(columnSeparator) * (len(widths) - 1)
+		widest := 0
+		for i, w := range widths {
+			total += w
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+
+		excess := total - maxWidth
+		if excess <= 0 || widths[widest] <= minColumnWidth {
+			return
+		}
+
+		// Only shrink to the width of the next widest column so the widest columns are shared
+		next := minColumnWidth
+		for i, w := range widths {
+			if i != widest && w > next {
+				next = w
+			}
+		}
+		reduce := widths[widest] - next
+		if reduce == 0 {
+			reduce = 1
+		}
+		if reduce > excess {
+			reduce = excess
+		}
+		if widths[widest]-reduce < minColumnWidth {
+			reduce = widths[widest] - minColumnWidth
+		}
+		widths[widest] -= reduce
+	}
+}
+
+// truncate shortens the value to the supplied number of characters, ending in an ellipsis.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	return string(r[:width-1]) + "…"
+}
+
+// useColor checks if the values written to the supplied writer should be highlighted.
+func (tp *TablePrinter) useColor(out io.Writer) bool {
+	return !tp.NoColor && os.Getenv("NO_COLOR") == "" && isTerminal(out)
+}
+
+// highlightStatus wraps a known status value in the ANSI escape codes of its color.
+func highlightStatus(value string) string {
+	if code, ok := statusColors[strings.ToLower(value)]; ok {
+		return "\x1b[" + code + "m" + value + "\x1b[0m"
+	}
+	return value
+}
+
+// isTerminal checks if the supplied writer is connected to a terminal.
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns the width of the terminal the supplied writer is
+// connected to, or zero if it is not a terminal.
+func terminalWidth(out io.Writer) int {
+	if !isTerminal(out) {
+		return 0
+	}
+
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return ttyWidth(out.(*os.File))
+}
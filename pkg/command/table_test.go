@@ -0,0 +1,180 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tableRow is a row used to exercise the table printer.
+type tableRow struct {
+	Name    string `table:"name"`
+	Status  string `table:"status"`
+	Details string `table:"details,wide"`
+}
+
+func (r *tableRow) Lookup(string) (interface{}, bool) { return nil, false }
+
+func TestTablePrinter_Fprint(t *testing.T) {
+	row := &tableRow{Name: "größe", Status: "completed", Details: "extra"}
+	cases := []struct {
+		desc     string
+		printer  TablePrinter
+		expected string
+	}{
+		{
+			desc:     "default",
+			expected: "NAME    STATUS\ngröße   completed\n",
+		},
+		{
+			desc:     "no headers",
+			printer:  TablePrinter{NoHeaders: true},
+			expected: "größe   completed\n",
+		},
+		{
+			desc:     "wide",
+			printer:  TablePrinter{Wide: true},
+			expected: "NAME    STATUS      DETAILS\ngröße   completed   extra\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, c.printer.Fprint(&buf, row))
+			assert.Equal(t, c.expected, buf.String(), "values are never highlighted unless writing to a terminal")
+		})
+	}
+}
+
+func TestTablePrinter_UseColor(t *testing.T) {
+	tp := &TablePrinter{}
+	assert.False(t, tp.useColor(&bytes.Buffer{}))
+	assert.False(t, (&TablePrinter{NoColor: true}).useColor(&bytes.Buffer{}))
+}
+
+func TestHighlightStatus(t *testing.T) {
+	assert.Equal(t, "\x1b[32mcompleted\x1b[0m", highlightStatus("completed"))
+	assert.Equal(t, "\x1b[31mFailed\x1b[0m", highlightStatus("Failed"))
+	assert.Equal(t, "active", highlightStatus("active"))
+	assert.Equal(t, "", highlightStatus(""))
+}
+
+func TestFormatAge(t *testing.T) {
+	cases := []struct {
+		age      time.Duration
+		expected string
+	}{
+		{age: -time.Minute, expected: "0s"},
+		{age: 0, expected: "0s"},
+		{age: 59 * time.Second, expected: "59s"},
+		{age: 45 * time.Minute, expected: "45m"},
+		{age: 47 * time.Hour, expected: "47h"},
+		{age: 72 * time.Hour, expected: "3d"},
+		{age: 3 * 365 * 24 * time.Hour, expected: "3y"},
+	}
+	for _, c := range cases {
+		t.Run(c.age.String(), func(t *testing.T) {
+			assert.Equal(t, c.expected, formatAge(c.age))
+		})
+	}
+}
+
+func TestShrinkColumns(t *testing.T) {
+	cases := []struct {
+		desc     string
+		widths   []int
+		maxWidth int
+		expected []int
+	}{
+		{
+			desc:     "fits",
+			widths:   []int{5, 5},
+			maxWidth: 100,
+			expected: []int{5, 5},
+		},
+		{
+			desc:     "zero width",
+			widths:   []int{40, 40},
+			maxWidth: 0,
+			expected: []int{40, 40},
+		},
+		{
+			desc:     "negative width",
+			widths:   []int{40, 40},
+			maxWidth: -1,
+			expected: []int{40, 40},
+		},
+		{
+			desc:     "widest",
+			widths:   []int{40, 10},
+			maxWidth: 40,
+			expected: []int{27, 10},
+		},
+		{
+			desc:     "shared",
+			widths:   []int{30, 30, 5},
+			maxWidth: 50,
+			expected: []int{19, 20, 5},
+		},
+		{
+			desc:     "narrow columns",
+			widths:   []int{3, 50},
+			maxWidth: 20,
+			expected: []int{3, 14},
+		},
+		{
+			desc:     "minimum width",
+			widths:   []int{20, 20},
+			maxWidth: 5,
+			expected: []int{minColumnWidth, minColumnWidth},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			shrinkColumns(c.widths, c.maxWidth)
+			assert.Equal(t, c.expected, c.widths)
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		desc     string
+		value    string
+		width    int
+		expected string
+	}{
+		{desc: "fits", value: "hello", width: 5, expected: "hello"},
+		{desc: "truncated", value: "hello world", width: 5, expected: "hell…"},
+		{desc: "multibyte", value: "größenordnung", width: 4, expected: "grö…"},
+		{desc: "multibyte fits", value: "größe", width: 5, expected: "größe"},
+		{desc: "single", value: "hello", width: 1, expected: "…"},
+		{desc: "zero", value: "hello", width: 0, expected: ""},
+		{desc: "negative", value: "hello", width: -1, expected: ""},
+		{desc: "empty", value: "", width: 0, expected: ""},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.expected, truncate(c.value, c.width))
+		})
+	}
+}
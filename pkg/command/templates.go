@@ -0,0 +1,294 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	"github.com/thestormforge/optimize-go/pkg/snapshot"
+	"sigs.k8s.io/yaml"
+)
+
+// NewGetTemplateHistoryCommand returns a command for listing the recorded revisions of a scenario template.
+func NewGetTemplateHistoryCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		dir    string
+		sortBy string
+	)
+
+	cmd := &cobra.Command{
+		Use:               "template-history APP_NAME/SCENARIO_NAME",
+		Annotations:       map[string]string{annotationOutput: "template-revision"},
+		Aliases:           []string{"template-revisions"},
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validScenarioArgs(cfg),
+	}
+
+	cmd.Flags().StringVar(&dir, "history-dir", "", "`directory` used to store template history")
+	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		h, err := templateHistory(cfg, dir)
+		if err != nil {
+			return err
+		}
+
+		u, err := scenarioTemplateURL(ctx, applications.NewAPI(client), args[0])
+		if err != nil {
+			return err
+		}
+
+		revs, err := h.Revisions(snapshot.TemplateKey(u))
+		if err != nil {
+			return err
+		}
+
+		result := &TemplateRevisionOutput{Items: make([]TemplateRevisionRow, 0, len(revs))}
+		for i := range revs {
+			result.Items = append(result.Items, *NewTemplateRevisionRow(args[0], &revs[i]))
+		}
+
+		if err := result.SortBy(sortBy); err != nil {
+			return err
+		}
+
+		return p.Fprint(out, result)
+	})
+	return cmd
+}
+
+// NewRollbackTemplateCommand returns a command for restoring a recorded revision of a scenario template.
+func NewRollbackTemplateCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		dir    string
+		to     int
+		strict bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "template APP_NAME/SCENARIO_NAME",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validScenarioArgs(cfg),
+	}
+
+	cmd.Flags().StringVar(&dir, "history-dir", "", "`directory` used to store template history")
+	cmd.Flags().IntVar(&to, "to", 0, "the `revision` to restore, defaults to the most recent revision that differs from the current template")
+	cmd.Flags().BoolVar(&strict, "strict", false, "validate the restored template before it is sent to the server")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		h, err := templateHistory(cfg, dir)
+		if err != nil {
+			return err
+		}
+
+		// The current template is recorded before it is replaced so the rollback can also be undone
+		appAPI := applications.NewAPI(client)
+		if strict {
+			appAPI = &applications.StrictTemplateAPI{API: appAPI}
+		}
+		appAPI = &snapshot.TemplateRecorder{API: appAPI, History: h}
+
+		u, err := scenarioTemplateURL(ctx, appAPI, args[0])
+		if err != nil {
+			return err
+		}
+
+		current, err := appAPI.GetTemplate(ctx, u)
+		if err != nil {
+			return err
+		}
+
+		rev, err := rollbackRevision(h, snapshot.TemplateKey(u), to, &current)
+		if err != nil {
+			return err
+		}
+
+		t := applications.Template{}
+		if err := json.Unmarshal(rev.Data, &t); err != nil {
+			return err
+		}
+
+		if err := appAPI.UpdateTemplate(ctx, u, t); err != nil {
+			return err
+		}
+
+		return p.Fprint(out, NewTemplateRevisionRow(args[0], rev))
+	})
+	return cmd
+}
+
+// NewLintTemplateCommand returns a command for checking a scenario template against the experiment it generates.
+func NewLintTemplateCommand(cfg Config) *cobra.Command {
+	var filename string
+
+	cmd := &cobra.Command{
+		Use:               "template [APP_NAME/SCENARIO_NAME]",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: validScenarioArgs(cfg),
+	}
+
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "`file` containing the JSON or YAML template definition to check instead of the current scenario template")
+
+	lint := func(cmd *cobra.Command, name string, t *applications.Template) error {
+		if err := applications.ValidateTemplate(t); err != nil {
+			return fmt.Errorf("invalid template definition:\n%s", indent(err.Error()))
+		}
+		_, err := fmt.Fprintf(cmd.OutOrStdout(), "template %q is valid.\n", name)
+		return err
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if filename != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("a scenario name cannot be used with a template file")
+			}
+
+			t, err := readTemplate(cmd.InOrStdin(), filename)
+			if err != nil {
+				return err
+			}
+			return lint(cmd, filename, &t)
+		}
+
+		if len(args) == 0 {
+			return fmt.Errorf("a scenario name or template file is required")
+		}
+
+		return withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+			ctx := cmd.Context()
+			appAPI := applications.NewAPI(client)
+
+			u, err := scenarioTemplateURL(ctx, appAPI, args[0])
+			if err != nil {
+				return err
+			}
+
+			t, err := appAPI.GetTemplate(ctx, u)
+			if err != nil {
+				return err
+			}
+			return lint(cmd, args[0], &t)
+		})(cmd, args)
+	}
+	return cmd
+}
+
+// readTemplate reads a template definition from a file, "-" is used for stdin.
+func readTemplate(in io.Reader, filename string) (applications.Template, error) {
+	var t applications.Template
+
+	data, err := readInput(in, filename)
+	if err != nil {
+		return t, err
+	}
+
+	// YAML is a superset of JSON, this handles both
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return t, fmt.Errorf("failed to read template definition: %w", err)
+	}
+	return t, nil
+}
+
+// templateHistory returns the template history for the configured server.
+func templateHistory(cfg Config, dir string) (*snapshot.History, error) {
+	if dir != "" {
+		return &snapshot.History{Dir: dir}, nil
+	}
+
+	dir, err := serverConfigDir(cfg, "templates")
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot.History{Dir: dir}, nil
+}
+
+// templateRecorder wraps the application API so the templates of changed
+// scenarios are recorded in the template history for the configured server.
+func templateRecorder(cfg Config, appAPI applications.API) (*snapshot.TemplateRecorder, error) {
+	h, err := templateHistory(cfg, "")
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot.TemplateRecorder{API: appAPI, History: h}, nil
+}
+
+// scenarioTemplateURL returns the template URL of the named scenario.
+func scenarioTemplateURL(ctx context.Context, appAPI applications.API, name string) (string, error) {
+	l := applications.Lister{API: appAPI}
+
+	var u string
+	if err := l.ForEachNamedScenario(ctx, []string{name}, false, func(item *applications.ScenarioItem) error {
+		u = item.Link(api.RelationTemplate)
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	if u == "" {
+		return "", fmt.Errorf("scenario %q does not have a template", name)
+	}
+	return u, nil
+}
+
+// rollbackRevision returns the numbered revision, or the most recent revision which differs from the current template.
+func rollbackRevision(h *snapshot.History, key string, number int, current *applications.Template) (*snapshot.Revision, error) {
+	revs, err := h.Revisions(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if number > 0 {
+		for i := range revs {
+			if revs[i].Number == number {
+				return &revs[i], nil
+			}
+		}
+		return nil, fmt.Errorf("template revision %d not found", number)
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(revs) - 1; i >= 0; i-- {
+		if !jsonEqual(revs[i].Data, data) {
+			return &revs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no previous template revisions were recorded")
+}
+
+// jsonEqual compares two JSON documents ignoring insignificant white space.
+func jsonEqual(a, b []byte) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
@@ -18,11 +18,16 @@ package command
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/analysis"
 	"github.com/thestormforge/optimize-go/pkg/api"
 	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"sigs.k8s.io/yaml"
 )
 
 // NewCreateTrialCommand returns a command for creating a trial.
@@ -30,6 +35,7 @@ func NewCreateTrialCommand(cfg Config, p Printer) *cobra.Command {
 	var (
 		assignments     map[string]string
 		defaultBehavior string
+		baseline        bool
 	)
 
 	cmd := &cobra.Command{
@@ -39,13 +45,10 @@ func NewCreateTrialCommand(cfg Config, p Printer) *cobra.Command {
 
 	cmd.Flags().StringToStringVarP(&assignments, "assign", "A", nil, "assign an explicit `key=value` to a parameter")
 	cmd.Flags().StringVar(&defaultBehavior, "default", "", "select the `behavior` for default values; one of: none|min|max|rand")
+	cmd.Flags().BoolVar(&baseline, "baseline", false, "label the trial as the experiment baseline")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		expAPI := experiments.NewAPI(client)
 
@@ -64,13 +67,176 @@ func NewCreateTrialCommand(cfg Config, p Printer) *cobra.Command {
 			return err
 		}
 
+		if baseline {
+			if err := experiments.ValidateBaseline(&exp, ta.Assignments); err != nil {
+				return fmt.Errorf("invalid baseline:\n%s", indent(err.Error()))
+			}
+			ta.Labels = map[string]string{"baseline": "true"}
+		}
+
 		if _, err := expAPI.CreateTrial(ctx, trialsURL, *ta); err != nil {
 			return err
 		}
 
 		// NOTE: The trial number will not exist until the assignments have been pulled from the queue
 		return p.Fprint(out, NewTrialRow(&experiments.TrialItem{Experiment: &exp, TrialAssignments: *ta}))
+	})
+	return cmd
+}
+
+// NewCreateTrialsCommand returns a command for creating a parameter sweep of trials.
+func NewCreateTrialsCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		grid            []string
+		filename        string
+		defaultBehavior string
+		maxTrials       int
+		dryRun          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "trials EXP_NAME",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: validExperimentArgs(cfg),
 	}
+
+	cmd.Flags().StringArrayVar(&grid, "grid", nil, "sweep a parameter over a `key=value1,value2,...` list of values")
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "`file` containing a list of explicit assignments")
+	cmd.Flags().StringVar(&defaultBehavior, "default", "", "select the `behavior` for default values; one of: none|min|max|rand")
+	cmd.Flags().IntVar(&maxTrials, "max-trials", 0, "create at most this `number` of trials")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the trial assignments without creating them")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		expAPI := experiments.NewAPI(client)
+
+		exp, err := expAPI.GetExperimentByName(ctx, experiments.ExperimentName(args[0]))
+		if err != nil {
+			return err
+		}
+
+		trialsURL := exp.Link(api.RelationTrials)
+		if trialsURL == "" {
+			return fmt.Errorf("malformed response, missing trials link")
+		}
+
+		var tas []experiments.TrialAssignments
+		switch {
+		case len(grid) > 0 && filename != "":
+			return fmt.Errorf("only one of --grid or --filename may be specified")
+
+		case len(grid) > 0:
+			g, err := parseTrialGrid(grid)
+			if err != nil {
+				return err
+			}
+			if tas, err = g.Assignments(&exp, defaultBehavior); err != nil {
+				return err
+			}
+
+		case filename != "":
+			sets, err := readAssignmentSets(cmd.InOrStdin(), filename)
+			if err != nil {
+				return err
+			}
+			if tas, err = experiments.NewTrialAssignmentsList(&exp, sets, defaultBehavior); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("one of --grid or --filename is required")
+		}
+
+		if maxTrials > 0 && len(tas) > maxTrials {
+			tas = tas[:maxTrials]
+		}
+
+		if !dryRun {
+			l := &experiments.TrialLoop{API: expAPI}
+			if _, err := l.Sweep(ctx, trialsURL, tas); err != nil {
+				return err
+			}
+		}
+
+		for i := range tas {
+			if err := p.Fprint(out, NewTrialRow(&experiments.TrialItem{Experiment: &exp, TrialAssignments: tas[i]})); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return cmd
+}
+
+// parseTrialGrid parses `key=value1,value2` parameter sweep values.
+func parseTrialGrid(values []string) (experiments.TrialGrid, error) {
+	g := experiments.TrialGrid{}
+	for _, v := range values {
+		k, vs, ok := strings.Cut(v, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid grid %q, expected key=value1,value2,...", v)
+		}
+		g[k] = append(g[k], strings.Split(vs, ",")...)
+	}
+	return g, nil
+}
+
+// readAssignmentSets reads a list of explicit assignments from a file, "-" is used for stdin.
+func readAssignmentSets(in io.Reader, filename string) ([]map[string]string, error) {
+	data, err := readInput(in, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, this handles both
+	var sets []map[string]string
+	if err := yaml.Unmarshal(data, &sets); err != nil {
+		return nil, fmt.Errorf("failed to read assignments: %w", err)
+	}
+	return sets, nil
+}
+
+// NewImportTrialsCommand returns a command for importing historical observations as trials.
+func NewImportTrialsCommand(cfg Config, p Printer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "trials EXP_NAME FILE",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: validExperimentArgs(cfg),
+	}
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		expAPI := experiments.NewAPI(client)
+
+		exp, err := expAPI.GetExperimentByName(ctx, experiments.ExperimentName(args[0]))
+		if err != nil {
+			return err
+		}
+
+		in := cmd.InOrStdin()
+		if args[1] != "-" {
+			f, err := os.Open(args[1])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+
+		obs, err := experiments.ReadObservations(in)
+		if err != nil {
+			return err
+		}
+
+		n, err := experiments.ImportObservations(ctx, expAPI, &exp, obs)
+		if err != nil {
+			return fmt.Errorf("imported %d of %d observations: %w", n, len(obs), err)
+		}
+
+		return p.Fprint(out, NewExperimentRow(&experiments.ExperimentItem{Experiment: exp}))
+	})
 	return cmd
 }
 
@@ -87,13 +253,10 @@ func NewEditTrialCommand(cfg Config, p Printer) *cobra.Command {
 	}
 
 	cmd.Flags().StringToStringVar(&labels, "set-label", nil, "label `key=value` pairs to assign")
+	_ = cmd.RegisterFlagCompletionFunc("set-label", validLabelArgs(cfg, trialLabelKeys))
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := experiments.Lister{
 			API: experiments.NewAPI(client),
@@ -109,7 +272,7 @@ func NewEditTrialCommand(cfg Config, p Printer) *cobra.Command {
 					return fmt.Errorf("malformed response, missing labels link")
 				}
 
-				err = l.API.LabelTrial(ctx, labelsURL, experiments.TrialLabels{Labels: labels})
+				err := l.API.LabelTrial(ctx, labelsURL, experiments.TrialLabels{Labels: labels})
 				if err != nil {
 					return err
 				}
@@ -117,35 +280,40 @@ func NewEditTrialCommand(cfg Config, p Printer) *cobra.Command {
 
 			return p.Fprint(out, NewTrialRow(item))
 		})
-	}
+	})
 	return cmd
 }
 
 // NewGetTrialsCommand returns a command for getting trials.
 func NewGetTrialsCommand(cfg Config, p Printer) *cobra.Command {
 	var (
-		selector string
-		all      bool
-		sortBy   string
+		selector     string
+		all          bool
+		sortBy       string
+		flagOutliers bool
+		outlierOpts  analysis.OutlierOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:               "trials EXP_NAME | EXP_NAME/TRIAL_NUM ...",
+		Annotations:       map[string]string{annotationOutput: "trial"},
 		Aliases:           []string{"trial"},
 		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: validTrialArgs(cfg),
 	}
 
 	cmd.Flags().StringVarP(&selector, "selector", "l", selector, "selector (label `query`) to filter on")
+	_ = cmd.RegisterFlagCompletionFunc("selector", validLabelArgs(cfg, trialLabelKeys))
 	cmd.Flags().BoolVarP(&all, "all", "A", all, "include all resources")
 	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+	cmd.Flags().BoolVar(&flagOutliers, "flag-outliers", false, "flag trials with metric values inconsistent with their neighbors in parameter space")
+	cmd.Flags().IntVar(&outlierOpts.Neighbors, "outlier-neighbors", 5, "`number` of neighboring trials compared when flagging outliers")
+	cmd.Flags().Float64Var(&outlierOpts.Threshold, "outlier-threshold", 3.5, "robust z-`score` above which a metric value is flagged as an outlier")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := experiments.Lister{
 			API: experiments.NewAPI(client),
@@ -164,12 +332,16 @@ func NewGetTrialsCommand(cfg Config, p Printer) *cobra.Command {
 			return err
 		}
 
+		if flagOutliers {
+			result.FlagOutliers(outlierOpts)
+		}
+
 		if err := result.SortBy(sortBy); err != nil {
 			return err
 		}
 
 		return p.Fprint(out, result)
-	}
+	})
 	return cmd
 }
 
@@ -186,12 +358,10 @@ func NewDeleteTrialsCommand(cfg Config, p Printer) *cobra.Command {
 		ValidArgsFunction: validTrialArgs(cfg),
 	}
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	p = addNameOutputFlags(cmd, p)
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
-		client, err := api.NewClient(cfg.Address(), nil)
-		if err != nil {
-			return err
-		}
 
 		l := experiments.Lister{
 			API: experiments.NewAPI(client),
@@ -205,29 +375,43 @@ func NewDeleteTrialsCommand(cfg Config, p Printer) *cobra.Command {
 				return fmt.Errorf("malformed response, missing self link")
 			}
 
-			err = l.API.AbandonRunningTrial(ctx, selfURL)
+			err := l.API.AbandonRunningTrial(ctx, selfURL)
 			if err != nil {
 				return err
 			}
 
 			return p.Fprint(out, NewTrialRow(item))
 		})
-	}
+	})
 	return cmd
 }
 
 func validTrialArgs(cfg Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
 	return validArgs(cfg, func(l *completionLister, toComplete string) (completions []string, directive cobra.ShellCompDirective) {
 		directive |= cobra.ShellCompDirectiveNoFileComp
-		l.forAllExperiments(func(item *experiments.ExperimentItem) {
-			if strings.HasPrefix(item.Name.String(), toComplete) {
-				completions = append(completions, item.Name.String())
+		for _, name := range l.experimentNames() {
+			switch {
+			case strings.HasPrefix(toComplete, name+"/"):
+				// Once the experiment name is separated, complete the trial numbers
+				for _, num := range l.trialNumbers(experiments.ExperimentName(name)) {
+					completions = append(completions, name+"/"+num)
+				}
+			case strings.HasPrefix(toComplete, name+"-"):
+				for _, num := range l.trialNumbers(experiments.ExperimentName(name)) {
+					n, _ := strconv.ParseInt(num, 10, 64)
+					completions = append(completions, experiments.JoinTrialName(&experiments.Experiment{Name: experiments.ExperimentName(name)}, n))
+				}
+			case strings.HasPrefix(name, toComplete):
+				completions = append(completions, name)
 			}
-		})
+		}
+		completions = filterPrefix(completions, toComplete)
 
 		if len(completions) == 1 && completions[0] == toComplete {
-			completions[0] += "-"
-			directive |= cobra.ShellCompDirectiveNoSpace
+			if _, num := experiments.SplitTrialName(toComplete); num < 0 {
+				completions[0] += "-"
+				directive |= cobra.ShellCompDirectiveNoSpace
+			}
 		}
 
 		return
@@ -22,8 +22,12 @@ import (
 	"fmt"
 	"strings"
 	"text/template"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
 	"golang.org/x/oauth2"
 	"gopkg.in/square/go-jose.v2/jwt"
 	"sigs.k8s.io/yaml"
@@ -35,16 +39,18 @@ func NewWhoAmICommand(cfg Config) *cobra.Command {
 	var (
 		output  string
 		pattern string
+		verify  bool
 	)
 
 	cmd := &cobra.Command{
 		Use: "whoami",
 	}
 
-	cmd.Flags().StringVarP(&output, "output", "o", output, "the output `format` to use; one of: json|yaml|go-template")
+	cmd.Flags().StringVarP(&output, "output", "o", output, "the output `format` to use; one of: json|yaml|text|go-template")
 	cmd.Flags().StringVar(&pattern, "template", pattern, "the template `text` used to render the claims")
+	cmd.Flags().BoolVar(&verify, "verify", false, "verify the token is accepted by the API")
 
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
 		ctx, out := cmd.Context(), cmd.OutOrStdout()
 
 		// Fetch a token so we can inspect the claims
@@ -62,6 +68,10 @@ func NewWhoAmICommand(cfg Config) *cobra.Command {
 		if err := accessToken.UnsafeClaimsWithoutVerification(&claims); err != nil {
 			return err
 		}
+		std := jwt.Claims{}
+		if err := accessToken.UnsafeClaimsWithoutVerification(&std); err != nil {
+			return err
+		}
 
 		// Choose a template
 		switch output {
@@ -69,6 +79,8 @@ func NewWhoAmICommand(cfg Config) *cobra.Command {
 			pattern = "{{ toJson . }}"
 		case "yaml":
 			pattern = "{{ toYaml . }}"
+		case "text":
+			pattern = whoAmITextTemplate
 		case "go-template":
 			if pattern == "" {
 				return fmt.Errorf("missing template")
@@ -91,16 +103,83 @@ func NewWhoAmICommand(cfg Config) *cobra.Command {
 					data, err := yaml.Marshal(v)
 					return string(data), err
 				},
+				"join": strings.Join,
+				"organizations": func() []string {
+					orgs, _ := tokenOrganizations(ctx, cfg)
+					return orgs
+				},
+				"expiry": func() string {
+					return describeExpiry(std.Expiry)
+				},
+				"quota": func() *api.RateLimit {
+					rl, _ := rateLimit(ctx, client)
+					return rl
+				},
+				"describeQuota": describeQuota,
 			}).
 			Parse(pattern)
 		if err != nil {
 			return err
 		}
-		return tmpl.Execute(out, claims)
-	}
+		if err := tmpl.Execute(out, claims); err != nil {
+			return err
+		}
+
+		if !verify {
+			return nil
+		}
+		return verifyToken(ctx, client, &std)
+	})
 	return cmd
 }
 
+// whoAmITextTemplate is used to render a human-readable summary of the claims.
+const whoAmITextTemplate = `Issuer:        {{ .iss }}
+Subject:       {{ .sub }}
+Organizations: {{ join organizations ", " }}
+Expires:       {{ expiry }}
+{{ with quota }}Quota:         {{ describeQuota . }}
+{{ end }}`
+
+// describeExpiry returns a description of when the token expires.
+func describeExpiry(exp *jwt.NumericDate) string {
+	if exp == nil {
+		return "never"
+	}
+	t := exp.Time()
+	return t.Format(time.RFC3339) + " (" + humanize.Time(t) + ")"
+}
+
+// describeQuota returns a description of the remaining API request quota.
+func describeQuota(rl *api.RateLimit) string {
+	desc := fmt.Sprintf("%d of %d requests remaining", rl.Remaining, rl.Limit)
+	if rl.Reset != nil {
+		desc += ", resets " + humanize.Time(*rl.Reset)
+	}
+	return desc
+}
+
+// verifyToken makes a lightweight authenticated request to check the token is
+// accepted, an unauthorized response is explained using the token claims.
+func verifyToken(ctx context.Context, client api.Client, claims *jwt.Claims) error {
+	_, err := applications.NewAPI(client).CheckEndpoint(ctx)
+	if err == nil {
+		return nil
+	}
+	if !api.IsUnauthorized(err) {
+		return err
+	}
+
+	if claims.Expiry != nil && claims.Expiry.Time().Before(time.Now()) {
+		return fmt.Errorf("token rejected by the API: the token expired %s", humanize.Time(claims.Expiry.Time()))
+	}
+	if claims.NotBefore != nil && claims.NotBefore.Time().After(time.Now()) {
+		return fmt.Errorf("token rejected by the API: the token is not valid until %s (check the system clock)", claims.NotBefore.Time().Format(time.RFC3339))
+	}
+	return fmt.Errorf("token rejected by the API: verify the token issuer (%s) and audience (%s) match the configured server",
+		claims.Issuer, strings.Join(claims.Audience, ", "))
+}
+
 // token returns an access token obtained using the supplied configuration.
 func token(ctx context.Context, cfg Config) (*oauth2.Token, error) {
 	// Check that the configuration can produce a token source
@@ -0,0 +1,207 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+// NewGetWorkloadsCommand returns a command for getting the workloads of an application.
+func NewGetWorkloadsCommand(cfg Config, p Printer) *cobra.Command {
+	var (
+		sortBy string
+	)
+
+	cmd := &cobra.Command{
+		Use:               "workloads APP_NAME ...",
+		Annotations:       map[string]string{annotationOutput: "workload"},
+		Aliases:           []string{"workload"},
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: validApplicationArgs(cfg),
+	}
+
+	cmd.Flags().StringVar(&sortBy, "sort-by", sortBy, "sort using `column` name")
+
+	cmd.RunE = withAPIClient(cfg, func(cmd *cobra.Command, args []string, client api.Client) error {
+		ctx, out := cmd.Context(), cmd.OutOrStdout()
+
+		l := applications.Lister{
+			API: applications.NewAPI(client),
+		}
+
+		result := &WorkloadOutput{}
+		if err := l.ForEachNamedApplication(ctx, args, false, func(item *applications.ApplicationItem) error {
+			workloads, err := listWorkloads(ctx, l, &item.Application)
+			if err != nil {
+				return err
+			}
+
+			rec, err := latestRecommendation(ctx, l, &item.Application)
+			if err != nil {
+				return err
+			}
+
+			result.Add(item, workloads, rec)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if err := result.SortBy(sortBy); err != nil {
+			return err
+		}
+
+		return p.Fprint(out, result)
+	})
+	return cmd
+}
+
+// WorkloadRow is a single resource value of a workload container.
+type WorkloadRow struct {
+	Application string   `table:"application" csv:"application" json:"application"`
+	Kind        string   `table:"kind" csv:"kind" json:"kind,omitempty"`
+	Namespace   string   `table:"namespace" csv:"namespace" json:"namespace,omitempty"`
+	Workload    string   `table:"workload" csv:"workload" json:"workload,omitempty"`
+	Container   string   `table:"container" csv:"container" json:"container,omitempty"`
+	Resource    string   `table:"resource" csv:"resource" json:"resource,omitempty"`
+	Current     *float64 `table:"current" csv:"current" json:"current,omitempty"`
+	Recommended *float64 `table:"recommended" csv:"recommended" json:"recommended,omitempty"`
+}
+
+func (r *WorkloadRow) Lookup(key string) (interface{}, bool) {
+	switch SortByKey(key) {
+	case "application", "app":
+		return r.Application, true
+	case "kind":
+		return r.Kind, true
+	case "namespace":
+		return r.Namespace, true
+	case "workload":
+		return r.Workload, true
+	case "container":
+		return r.Container, true
+	case "resource":
+		return r.Resource, true
+	default:
+		return nil, false
+	}
+}
+
+// WorkloadOutput wraps a list of workload resources for output.
+type WorkloadOutput struct {
+	Items []WorkloadRow `json:"items"`
+}
+
+// Add the workloads of an application to the output. The current values come
+// from the discovered workloads and the recommended values come from the
+// (optional) recommendation; workloads only present in the recommendation
+// are also included.
+func (o *WorkloadOutput) Add(item *applications.ApplicationItem, workloads []applications.WorkloadItem, rec *applications.Recommendation) {
+	rows := make(map[string]*WorkloadRow)
+	var keys []string
+	row := func(target *applications.TargetRef, container, resource string) *WorkloadRow {
+		key := strings.Join([]string{target.Kind, target.Namespace, target.Workload, container, resource}, "/")
+		if r, ok := rows[key]; ok {
+			return r
+		}
+		r := &WorkloadRow{
+			Application: item.Name.String(),
+			Kind:        target.Kind,
+			Namespace:   target.Namespace,
+			Workload:    target.Workload,
+			Container:   container,
+			Resource:    resource,
+		}
+		rows[key] = r
+		keys = append(keys, key)
+		return r
+	}
+
+	for i := range workloads {
+		w := &workloads[i].Workload
+		for _, c := range w.Containers {
+			for _, rl := range []struct {
+				prefix string
+				list   *applications.ResourceList
+			}{{"requests", c.Requests}, {"limits", c.Limits}} {
+				for _, name := range []string{"cpu", "memory"} {
+					if v, ok := resourceValue(name, rl.list.Get(name)); ok {
+						row(&w.Target, c.Name, rl.prefix+"."+name).Current = &v
+					}
+				}
+			}
+		}
+	}
+
+	if rec != nil {
+		for i := range rec.Parameters {
+			param := &rec.Parameters[i]
+			for _, cr := range param.ContainerResources {
+				fixCPU(cr)
+				m, _ := cr.(map[string]interface{})
+				values := make(map[string]float64)
+				flattenValues(values, "", m)
+				for _, path := range sortedValueKeys(values) {
+					v := values[path]
+					row(&param.Target, applications.ContainerName(m), path).Recommended = &v
+				}
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		o.Items = append(o.Items, *rows[k])
+	}
+}
+
+// resourceValue returns the numeric value of a resource quantity. Consistent
+// with recommendations, numeric CPU values are in millicores.
+func resourceValue(name string, v *api.NumberOrString) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	if v.IsString {
+		f, err := api.ParseQuantity(v.StrVal)
+		return f, err == nil
+	}
+	f, err := v.Float64()
+	if err != nil {
+		return 0, false
+	}
+	if name == "cpu" {
+		f /= 1000
+	}
+	return f, true
+}
+
+// Len returns the number of items being output.
+func (o *WorkloadOutput) Len() int { return len(o.Items) }
+
+// Swap exchanges the order of the two specified items.
+func (o *WorkloadOutput) Swap(i, j int) { o.Items[i], o.Items[j] = o.Items[j], o.Items[i] }
+
+// Item returns the specified row value.
+func (o *WorkloadOutput) Item(i int) Row { return &o.Items[i] }
+
+// SortBy sorts the output by the named value.
+func (o *WorkloadOutput) SortBy(key string) error { return SortBy(o, key) }
@@ -25,7 +25,9 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/thestormforge/optimize-go/pkg/api"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
@@ -42,6 +44,8 @@ type Config struct {
 	ClientID string `json:"client_id,omitempty" yaml:"client_id,omitempty" env:"STORMFORGE_CLIENT_ID"`
 	// The client secret used to obtain tokens via a client credentials grant.
 	ClientSecret string `json:"client_secret,omitempty" yaml:"client_secret,omitempty" env:"STORMFORGE_CLIENT_SECRET"`
+	// The public client ID used for interactive (device authorization) logins.
+	LoginClientID string `json:"login_client_id,omitempty" yaml:"login_client_id,omitempty" env:"STORMFORGE_LOGIN_CLIENT_ID"`
 	// The list of scopes to request during token exchanges.
 	Scopes []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
 	// Additional parameters to be included with the token request.
@@ -49,6 +53,33 @@ type Config struct {
 	// A hard-coded bearer token for debugging, the token will not be refreshed
 	// so the caller is responsible for providing a valid token.
 	Token string `json:"token,omitempty" yaml:"token,omitempty" env:"STORMFORGE_TOKEN"`
+	// The organization (tenant) used for API requests, only required when the
+	// credentials have access to more than one organization.
+	Organization string `json:"organization,omitempty" yaml:"organization,omitempty" env:"STORMFORGE_ORGANIZATION"`
+	// Flag indicating that only safe (non-mutating) requests should be sent
+	// to the API server, all other requests will fail without being sent.
+	ReadOnly bool `json:"read_only,omitempty" yaml:"read_only,omitempty" env:"STORMFORGE_READ_ONLY"`
+	// The address of a DNS server used to resolve host names instead of the
+	// system resolver (e.g. "10.0.0.2:53").
+	Resolver string `json:"resolver,omitempty" yaml:"resolver,omitempty" env:"STORMFORGE_RESOLVER"`
+	// Restrict connections to a single IP family, one of "ipv4" or "ipv6".
+	IPFamily string `json:"ip_family,omitempty" yaml:"ip_family,omitempty" env:"STORMFORGE_IP_FAMILY"`
+	// The maximum amount of time to wait for a connection to be established.
+	DialTimeout time.Duration `json:"dial_timeout,omitempty" yaml:"dial_timeout,omitempty" env:"STORMFORGE_DIAL_TIMEOUT"`
+	// Additional certificate authorities (PEM data or a file path) trusted when
+	// verifying the API server, e.g. when it is fronted by an internal gateway.
+	CABundle string `json:"ca_bundle,omitempty" yaml:"ca_bundle,omitempty" env:"STORMFORGE_CA_BUNDLE"`
+	// The client certificate (PEM data or a file path) presented to servers
+	// requiring mutual TLS.
+	ClientCertificate string `json:"client_certificate,omitempty" yaml:"client_certificate,omitempty" env:"STORMFORGE_CLIENT_CERTIFICATE"`
+	// The private key (PEM data or a file path) of the client certificate.
+	ClientKey string `json:"client_key,omitempty" yaml:"client_key,omitempty" env:"STORMFORGE_CLIENT_KEY"`
+	// Flag indicating that only FIPS 140 approved TLS versions, cipher suites
+	// and algorithms should be used.
+	FIPS bool `json:"fips,omitempty" yaml:"fips,omitempty" env:"STORMFORGE_FIPS"`
+	// The store of credentials obtained by interactive logins, when set the
+	// stored credential is used if no other credentials are configured.
+	Credentials CredentialStore `json:"-" yaml:"-"`
 	// Hook invoked when an authorized error occurs retrieving a token. May only
 	// be invoked on a sample of errors if they are occurring rapidly.
 	UnauthorizedFunc func(error) `json:"-" yaml:"-"`
@@ -78,14 +109,62 @@ func (cfg *Config) tokenURL() (string, error) {
 	return tokenURL.String(), nil
 }
 
+// DialerOptions returns the network connection options from the configuration.
+func (cfg *Config) DialerOptions() api.DialerOptions {
+	return api.DialerOptions{
+		Resolver: cfg.Resolver,
+		IPFamily: cfg.IPFamily,
+		Timeout:  cfg.DialTimeout,
+	}
+}
+
+// TLSOptions returns the TLS trust and identity options from the configuration.
+func (cfg *Config) TLSOptions() api.TLSOptions {
+	return api.TLSOptions{
+		CABundle:          cfg.CABundle,
+		ClientCertificate: cfg.ClientCertificate,
+		ClientKey:         cfg.ClientKey,
+	}
+}
+
 // Transport wraps the supplied round tripper based on the current state of the configuration.
 func (cfg *Config) Transport(tokenSource oauth2.TokenSource, base http.RoundTripper) http.RoundTripper {
+	if opts := cfg.DialerOptions(); !opts.IsZero() {
+		rt, err := api.DialerTransport(base, opts)
+		if err != nil {
+			return &errorTransport{err: err}
+		}
+		base = rt
+	}
+
+	// NOTE: The TLS options must be applied first so the FIPS configuration preserves them
+	if opts := cfg.TLSOptions(); !opts.IsZero() {
+		rt, err := api.TLSTransport(base, opts)
+		if err != nil {
+			return &errorTransport{err: err}
+		}
+		base = rt
+	}
+
+	if cfg.FIPS {
+		rt, err := api.FIPSTransport(base)
+		if err != nil {
+			return &errorTransport{err: err}
+		}
+		base = rt
+	}
+
+	if cfg.ReadOnly {
+		base = api.ReadOnlyTransport(base)
+	}
+
 	return &transport{
 		Transport: oauth2.Transport{
 			Source: tokenSource,
 			Base:   base,
 		},
-		Audience: cfg.Server,
+		Audience:     cfg.Server,
+		Organization: cfg.Organization,
 	}
 }
 
@@ -125,7 +204,12 @@ func (cfg *Config) TokenSource(ctx context.Context) oauth2.TokenSource {
 		}
 		cc.EndpointParams.Set("audience", cfg.Server)
 
-		result = cc.TokenSource(ctx)
+		result = &RenewingTokenSource{Source: tokenSourceFunc(func() (*oauth2.Token, error) {
+			return cc.Token(ctx)
+		})}
+
+	case cfg.Credentials != nil:
+		result = cfg.loginTokenSource(ctx)
 
 	}
 
@@ -164,18 +248,29 @@ func (ts *unauthorizedHookTokenSource) Token() (*oauth2.Token, error) {
 }
 
 // transport wraps a stock OAuth2 transport with a check that ensures outbound
-// requests only include tokens if they match the configured audience.
+// requests only include tokens (and the organization) if they match the
+// configured audience.
 type transport struct {
 	// The standard OAuth2 transport.
 	oauth2.Transport
 	// The audience used to filter request URLs.
 	Audience string
+	// The organization selected for requests which do not already select one.
+	Organization string
 }
 
 // RoundTrip ensures the audience value matches the request before adding tokens.
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if t.Transport.Source != nil && t.requiresAuthorization(req.URL) {
-		return t.Transport.RoundTrip(req)
+	if t.requiresAuthorization(req.URL) {
+		if t.Organization != "" && req.Header.Get(api.HeaderOrganization) == "" {
+			// The round tripper contract requires we do not modify the request
+			req = req.Clone(req.Context())
+			req.Header.Set(api.HeaderOrganization, t.Organization)
+		}
+
+		if t.Transport.Source != nil {
+			return t.Transport.RoundTrip(req)
+		}
 	}
 
 	if t.Base != nil {
@@ -234,3 +329,17 @@ func (ts *errorTokenSource) Token() (*oauth2.Token, error) {
 	}
 	return nil, ts.err
 }
+
+// errorTransport is a RoundTripper that always returns an error.
+type errorTransport struct {
+	err error
+}
+
+// RoundTrip always returns a non-nil error.
+func (t *errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The round tripper contract requires we close the body
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	return nil, t.err
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	"golang.org/x/oauth2"
+)
+
+func TestConfig_Transport_Organization(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Echo", r.Header.Get(api.HeaderOrganization))
+		w.Header().Set("Echo-Authorization", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	thirdParty := httptest.NewServer(handler)
+	defer thirdParty.Close()
+
+	cases := []struct {
+		desc         string
+		url          string
+		organization string
+		auth         string
+	}{
+		{
+			desc:         "audience",
+			url:          server.URL + "/v1/experiments/",
+			organization: "my-org",
+			auth:         "Bearer t",
+		},
+		{
+			desc: "third party",
+			url:  thirdParty.URL + "/hook",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			cfg := &Config{Server: server.URL + "/", Organization: "my-org"}
+			ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "t"})
+			client := &http.Client{Transport: cfg.Transport(ts, nil)}
+
+			resp, err := client.Get(c.url)
+			if assert.NoError(t, err) {
+				_ = resp.Body.Close()
+				assert.Equal(t, c.organization, resp.Header.Get("Echo"))
+				assert.Equal(t, c.auth, resp.Header.Get("Echo-Authorization"))
+			}
+		})
+	}
+}
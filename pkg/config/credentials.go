@@ -0,0 +1,307 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ErrNoCredential is returned when a credential store does not have a credential for a server.
+var ErrNoCredential = errors.New("no stored credential")
+
+// Credential is the result of an interactive login, stored so subsequent
+// invocations can obtain access tokens without logging in again.
+type Credential struct {
+	// The client ID the refresh token was issued to.
+	ClientID string `json:"client_id"`
+	// The refresh token used to obtain new access tokens.
+	RefreshToken string `json:"refresh_token"`
+}
+
+// CredentialStore persists credentials keyed by the API server address.
+type CredentialStore interface {
+	// Load returns the credential for the server, or ErrNoCredential.
+	Load(server string) (*Credential, error)
+	// Save stores the credential for the server, replacing any existing credential.
+	Save(server string, cred *Credential) error
+	// Delete removes the credential for the server, it is not an error if there is no credential.
+	Delete(server string) error
+}
+
+// DefaultCredentialStore returns a store using the OS keyring if one is
+// available, otherwise a file in the user's configuration directory. The
+// keyring is not checked until the store is first used.
+func DefaultCredentialStore() CredentialStore {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return &fallbackStore{
+		keyring: &KeyringStore{Service: "stormforge"},
+		file:    &FileStore{Path: filepath.Join(dir, "stormforge", "credentials.json")},
+	}
+}
+
+// fallbackStore uses the keyring if it is available, otherwise it falls back
+// to a file. Availability is only checked once, on first use.
+type fallbackStore struct {
+	keyring *KeyringStore
+	file    *FileStore
+
+	once  sync.Once
+	store CredentialStore
+}
+
+// Load returns the credential for the server from the selected store.
+func (s *fallbackStore) Load(server string) (*Credential, error) {
+	return s.selected().Load(server)
+}
+
+// Save stores the credential for the server in the selected store.
+func (s *fallbackStore) Save(server string, cred *Credential) error {
+	return s.selected().Save(server, cred)
+}
+
+// Delete removes the credential for the server from the selected store.
+func (s *fallbackStore) Delete(server string) error {
+	return s.selected().Delete(server)
+}
+
+func (s *fallbackStore) selected() CredentialStore {
+	s.once.Do(func() {
+		s.store = s.file
+		if s.keyring.Available() {
+			s.store = s.keyring
+		}
+	})
+	return s.store
+}
+
+// FileStore is a credential store backed by a JSON file only readable by the current user.
+type FileStore struct {
+	// The path to the credentials file.
+	Path string
+}
+
+// Load returns the credential for the server from the file.
+func (fs *FileStore) Load(server string) (*Credential, error) {
+	creds, err := fs.read()
+	if err != nil {
+		return nil, err
+	}
+	cred, ok := creds[server]
+	if !ok {
+		return nil, ErrNoCredential
+	}
+	return cred, nil
+}
+
+// Save stores the credential for the server in the file.
+func (fs *FileStore) Save(server string, cred *Credential) error {
+	creds, err := fs.read()
+	if err != nil {
+		return err
+	}
+	creds[server] = cred
+	return fs.write(creds)
+}
+
+// Delete removes the credential for the server from the file.
+func (fs *FileStore) Delete(server string) error {
+	creds, err := fs.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := creds[server]; !ok {
+		return nil
+	}
+	delete(creds, server)
+	return fs.write(creds)
+}
+
+func (fs *FileStore) read() (map[string]*Credential, error) {
+	creds := make(map[string]*Credential)
+	data, err := os.ReadFile(fs.Path)
+	if os.IsNotExist(err) {
+		return creds, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", fs.Path, err)
+	}
+	return creds, nil
+}
+
+func (fs *FileStore) write(creds map[string]*Credential) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fs.Path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(fs.Path, data, 0600)
+}
+
+// KeyringStore is a credential store backed by the OS keyring. The keyring is
+// accessed using the platform tools: `security` on macOS and `secret-tool`
+// (libsecret) on Linux; other platforms are not supported.
+type KeyringStore struct {
+	// The service name the credentials are stored under.
+	Service string
+
+	// Overrides the platform tool name and how it is executed, used for testing.
+	name    string
+	command func(name string, arg ...string) *exec.Cmd
+}
+
+// Available checks if the keyring can be used on this system. The tool must
+// be installed and able to reach the keyring: for example, `secret-tool` is
+// often installed on hosts without the D-Bus session it requires.
+func (ks *KeyringStore) Available() bool {
+	var args []string
+	switch ks.tool() {
+	case "security":
+		args = []string{"default-keychain"}
+	case "secret-tool":
+		args = []string{"lookup", "service", ks.Service}
+	default:
+		return false
+	}
+
+	// Looking up a missing item fails quietly, failing to reach the keyring does not
+	_, stderr, err := ks.exec(nil, args...)
+	var exitErr *exec.ExitError
+	return err == nil || errors.As(err, &exitErr) && len(bytes.TrimSpace(stderr)) == 0
+}
+
+// Load returns the credential for the server from the keyring.
+func (ks *KeyringStore) Load(server string) (*Credential, error) {
+	var args []string
+	switch ks.tool() {
+	case "security":
+		args = []string{"find-generic-password", "-s", ks.Service, "-a", server, "-w"}
+	case "secret-tool":
+		args = []string{"lookup", "service", ks.Service, "account", server}
+	default:
+		return nil, ErrNoCredential
+	}
+
+	out, err := ks.run(nil, args...)
+	if err != nil || len(bytes.TrimSpace(out)) == 0 {
+		// Both tools fail when the item does not exist
+		return nil, ErrNoCredential
+	}
+
+	cred := &Credential{}
+	if err := json.Unmarshal(bytes.TrimSpace(out), cred); err != nil {
+		return nil, fmt.Errorf("invalid keyring credential: %w", err)
+	}
+	return cred, nil
+}
+
+// Save stores the credential for the server in the keyring.
+func (ks *KeyringStore) Save(server string, cred *Credential) error {
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+
+	switch ks.tool() {
+	case "security":
+		// Use interactive mode so the secret is read from stdin instead of
+		// appearing in the process arguments, hex encoding avoids any quoting
+		cmd := fmt.Sprintf("add-generic-password -U -s %q -a %q -X %s\n", ks.Service, server, hex.EncodeToString(data))
+		_, err = ks.run([]byte(cmd), "-i")
+	case "secret-tool":
+		_, err = ks.run(data, "store", "--label", ks.Service+" ("+server+")", "service", ks.Service, "account", server)
+	default:
+		err = fmt.Errorf("keyring is not supported on %s", runtime.GOOS)
+	}
+	return err
+}
+
+// Delete removes the credential for the server from the keyring.
+func (ks *KeyringStore) Delete(server string) error {
+	if _, err := ks.Load(server); errors.Is(err, ErrNoCredential) {
+		return nil
+	}
+
+	var err error
+	switch ks.tool() {
+	case "security":
+		_, err = ks.run(nil, "delete-generic-password", "-s", ks.Service, "-a", server)
+	case "secret-tool":
+		_, err = ks.run(nil, "clear", "service", ks.Service, "account", server)
+	}
+	return err
+}
+
+// tool returns the name of the keyring tool for the current platform.
+func (ks *KeyringStore) tool() string {
+	if ks.name != "" {
+		return ks.name
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return "security"
+	case "linux":
+		return "secret-tool"
+	default:
+		return ""
+	}
+}
+
+// run executes the keyring tool with the supplied input.
+func (ks *KeyringStore) run(in []byte, args ...string) ([]byte, error) {
+	out, stderr, err := ks.exec(in, args...)
+	if err != nil {
+		if msg := strings.TrimSpace(string(stderr)); msg != "" {
+			return nil, fmt.Errorf("%s: %s", ks.tool(), msg)
+		}
+		return nil, fmt.Errorf("%s: %w", ks.tool(), err)
+	}
+	return out, nil
+}
+
+// exec executes the keyring tool, returning both the output and error output.
+func (ks *KeyringStore) exec(in []byte, args ...string) ([]byte, []byte, error) {
+	command := ks.command
+	if command == nil {
+		command = exec.Command
+	}
+
+	cmd := command(ks.tool(), args...)
+	if in != nil {
+		cmd.Stdin = bytes.NewReader(in)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	return out, stderr.Bytes(), err
+}
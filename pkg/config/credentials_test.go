@@ -0,0 +1,161 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyringStore(t *testing.T) {
+	for _, name := range []string{"security", "secret-tool"} {
+		t.Run(name, func(t *testing.T) {
+			ks, dir := fakeKeyring(t, name, false)
+			require.True(t, ks.Available())
+
+			_, err := ks.Load("https://example.com/")
+			assert.ErrorIs(t, err, ErrNoCredential)
+
+			cred := &Credential{ClientID: "cli", RefreshToken: "r1"}
+			require.NoError(t, ks.Save("https://example.com/", cred))
+
+			actual, err := ks.Load("https://example.com/")
+			require.NoError(t, err)
+			assert.Equal(t, cred, actual)
+
+			// The secret must never appear in the process arguments
+			args, err := os.ReadFile(filepath.Join(dir, "args"))
+			require.NoError(t, err)
+			assert.NotContains(t, string(args), "r1")
+			assert.NotContains(t, string(args), hex.EncodeToString([]byte(`"r1"`)))
+
+			require.NoError(t, ks.Delete("https://example.com/"))
+			require.NoError(t, ks.Delete("https://example.com/"))
+			_, err = ks.Load("https://example.com/")
+			assert.ErrorIs(t, err, ErrNoCredential)
+		})
+	}
+}
+
+func TestFallbackStore(t *testing.T) {
+	ks, _ := fakeKeyring(t, "secret-tool", true)
+	assert.False(t, ks.Available())
+
+	s := &fallbackStore{keyring: ks, file: &FileStore{Path: filepath.Join(t.TempDir(), "credentials.json")}}
+	cred := &Credential{ClientID: "cli", RefreshToken: "r1"}
+	require.NoError(t, s.Save("https://example.com/", cred))
+
+	actual, err := s.file.Load("https://example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, cred, actual)
+}
+
+// fakeKeyring returns a keyring store whose tool is implemented by TestHelperKeyring.
+func fakeKeyring(t *testing.T, name string, broken bool) (*KeyringStore, string) {
+	dir := t.TempDir()
+	return &KeyringStore{
+		Service: "test",
+		name:    name,
+		command: func(name string, arg ...string) *exec.Cmd {
+			cmd := exec.Command(os.Args[0], append([]string{"-test.run=TestHelperKeyring", "--", name}, arg...)...)
+			cmd.Env = append(os.Environ(), "FAKE_KEYRING_DIR="+dir, fmt.Sprintf("FAKE_KEYRING_BROKEN=%t", broken))
+			return cmd
+		},
+	}, dir
+}
+
+// TestHelperKeyring is not a real test, it emulates just enough of the keyring
+// tools (storing items as files) to exercise the keyring store.
+func TestHelperKeyring(t *testing.T) {
+	dir := os.Getenv("FAKE_KEYRING_DIR")
+	if dir == "" {
+		t.Skip("helper process")
+	}
+	if os.Getenv("FAKE_KEYRING_BROKEN") == "true" {
+		fmt.Fprintln(os.Stderr, "Cannot autolaunch D-Bus without X11 $DISPLAY")
+		os.Exit(1)
+	}
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	args = args[1:]
+
+	f, _ := os.OpenFile(filepath.Join(dir, "args"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	_, _ = fmt.Fprintln(f, strings.Join(args, " "))
+	_ = f.Close()
+
+	// Interactive mode reads the command from stdin
+	if args[0] == "security" && len(args) > 1 && args[1] == "-i" {
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		args = append([]string{"security"}, strings.Fields(strings.ReplaceAll(line, `"`, ""))...)
+	}
+
+	item := func(attr string) string {
+		for i := range args[:len(args)-1] {
+			if args[i] == attr {
+				return filepath.Join(dir, hex.EncodeToString([]byte(args[i+1])))
+			}
+		}
+		return filepath.Join(dir, "missing")
+	}
+
+	switch args[0] + " " + args[1] {
+	case "security default-keychain":
+		fmt.Println(`    "/Users/test/Library/Keychains/login.keychain-db"`)
+	case "secret-tool lookup":
+		if len(args) == 4 {
+			os.Exit(1) // Probe, not found
+		}
+		data, err := os.ReadFile(item("account"))
+		if err != nil {
+			os.Exit(1)
+		}
+		_, _ = os.Stdout.Write(data)
+	case "security find-generic-password":
+		data, err := os.ReadFile(item("-a"))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "security: SecKeychainSearchCopyNext: The specified item could not be found in the keychain.")
+			os.Exit(44)
+		}
+		_, _ = os.Stdout.Write(data)
+	case "security add-generic-password":
+		data, _ := hex.DecodeString(args[len(args)-1])
+		_ = os.WriteFile(item("-a"), data, 0600)
+	case "secret-tool store":
+		data, _ := bufio.NewReader(os.Stdin).ReadString(0)
+		_ = os.WriteFile(item("account"), []byte(data), 0600)
+	case "security delete-generic-password":
+		_ = os.Remove(item("-a"))
+	case "secret-tool clear":
+		_ = os.Remove(item("account"))
+	default:
+		fmt.Fprintln(os.Stderr, "unexpected arguments:", args)
+		os.Exit(2)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// offlineAccessScope is requested during logins to obtain a refresh token.
+const offlineAccessScope = "offline_access"
+
+// DeviceCode is the response to a device authorization request (RFC 8628).
+type DeviceCode struct {
+	// The code used to poll for the token.
+	DeviceCode string `json:"device_code"`
+	// The code the user enters on the verification page.
+	UserCode string `json:"user_code"`
+	// The URL of the verification page.
+	VerificationURI string `json:"verification_uri"`
+	// The URL of the verification page including the user code, if supported.
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	// The number of seconds until the codes expire.
+	ExpiresIn int `json:"expires_in"`
+	// The minimum number of seconds between polling requests.
+	Interval int `json:"interval,omitempty"`
+}
+
+// Login performs an interactive device authorization using the login client
+// ID. The prompt is invoked with the code the user must enter, the resulting
+// refresh token is saved to the credential store.
+func (cfg *Config) Login(ctx context.Context, prompt func(*DeviceCode)) error {
+	if cfg.LoginClientID == "" {
+		return fmt.Errorf("a login client ID is required (set STORMFORGE_LOGIN_CLIENT_ID)")
+	}
+
+	tokenURL, err := cfg.tokenURL()
+	if err != nil {
+		return err
+	}
+	deviceURL := strings.TrimSuffix(tokenURL, "token") + "device/code"
+
+	scopes := append([]string{offlineAccessScope}, cfg.Scopes...)
+	dc := &DeviceCode{}
+	if err := postForm(ctx, deviceURL, url.Values{
+		"client_id": {cfg.LoginClientID},
+		"scope":     {strings.Join(scopes, " ")},
+		"audience":  {cfg.Server},
+	}, dc); err != nil {
+		return fmt.Errorf("device authorization failed: %w", err)
+	}
+
+	prompt(dc)
+
+	tok, err := pollDeviceToken(ctx, tokenURL, cfg.LoginClientID, dc)
+	if err != nil {
+		return err
+	}
+	if tok.RefreshToken == "" {
+		return fmt.Errorf("login did not return a refresh token")
+	}
+
+	return cfg.credentialStore().Save(cfg.Server, &Credential{
+		ClientID:     cfg.LoginClientID,
+		RefreshToken: tok.RefreshToken,
+	})
+}
+
+// Logout removes the stored credential for the configured server.
+func (cfg *Config) Logout() error {
+	return cfg.credentialStore().Delete(cfg.Server)
+}
+
+// credentialStore returns the configured credential store or the default store.
+func (cfg *Config) credentialStore() CredentialStore {
+	if cfg.Credentials != nil {
+		return cfg.Credentials
+	}
+	return DefaultCredentialStore()
+}
+
+// loginTokenSource returns a token source using the stored credential. The
+// credential is not loaded until a token is first requested, so invocations
+// that never need a token do not access the credential store.
+func (cfg *Config) loginTokenSource(ctx context.Context) oauth2.TokenSource {
+	return &storedTokenSource{load: func() oauth2.TokenSource {
+		store := cfg.Credentials
+		cred, err := store.Load(cfg.Server)
+		if errors.Is(err, ErrNoCredential) {
+			return &errorTokenSource{err: fmt.Errorf("not logged in: %w", err)}
+		} else if err != nil {
+			return &errorTokenSource{err: err}
+		}
+
+		tokenURL, err := cfg.tokenURL()
+		if err != nil {
+			return &errorTokenSource{err: err}
+		}
+
+		oc := &oauth2.Config{
+			ClientID: cred.ClientID,
+			Endpoint: oauth2.Endpoint{TokenURL: tokenURL, AuthStyle: oauth2.AuthStyleInParams},
+		}
+		return &RenewingTokenSource{Source: &refreshTokenSource{
+			ctx:    ctx,
+			config: oc,
+			store:  store,
+			server: cfg.Server,
+			cred:   *cred,
+		}}
+	}}
+}
+
+// storedTokenSource defers loading a token source until the first token is requested.
+type storedTokenSource struct {
+	load func() oauth2.TokenSource
+
+	once sync.Once
+	src  oauth2.TokenSource
+}
+
+// Token loads the token source, if necessary, and returns a token from it.
+func (ts *storedTokenSource) Token() (*oauth2.Token, error) {
+	ts.once.Do(func() { ts.src = ts.load() })
+	return ts.src.Token()
+}
+
+// refreshTokenSource obtains new tokens using the stored refresh token, rotated
+// refresh tokens are saved back to the credential store.
+type refreshTokenSource struct {
+	ctx    context.Context
+	config *oauth2.Config
+	store  CredentialStore
+	server string
+
+	mu   sync.Mutex
+	cred Credential
+}
+
+// Token refreshes the access token, saving the refresh token if it changed.
+func (ts *refreshTokenSource) Token() (*oauth2.Token, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	// Without an access token, the OAuth2 library always refreshes
+	t, err := ts.config.TokenSource(ts.ctx, &oauth2.Token{RefreshToken: ts.cred.RefreshToken}).Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.RefreshToken != "" && t.RefreshToken != ts.cred.RefreshToken {
+		ts.cred.RefreshToken = t.RefreshToken
+		// A failure to save only means the next invocation has to log in again
+		_ = ts.store.Save(ts.server, &ts.cred)
+	}
+	return t, nil
+}
+
+// pollDeviceToken polls the token endpoint until the user completes the device authorization.
+func pollDeviceToken(ctx context.Context, tokenURL, clientID string, dc *DeviceCode) (*oauth2.Token, error) {
+	interval := time.Duration(dc.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expires := time.Now().Add(time.Duration(dc.ExpiresIn) * time.Second)
+
+	for {
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+
+		var tok struct {
+			AccessToken  string `json:"access_token"`
+			TokenType    string `json:"token_type"`
+			RefreshToken string `json:"refresh_token"`
+			ExpiresIn    int    `json:"expires_in"`
+		}
+		err := postForm(ctx, tokenURL, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {dc.DeviceCode},
+			"client_id":   {clientID},
+		}, &tok)
+
+		var oauthErr *oauthError
+		switch {
+		case err == nil:
+			return &oauth2.Token{
+				AccessToken:  tok.AccessToken,
+				TokenType:    tok.TokenType,
+				RefreshToken: tok.RefreshToken,
+				Expiry:       time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second),
+			}, nil
+		case !errors.As(err, &oauthErr):
+			return nil, err
+		case oauthErr.Code == "authorization_pending":
+		case oauthErr.Code == "slow_down":
+			interval += 5 * time.Second
+		case oauthErr.Code == "expired_token":
+			return nil, fmt.Errorf("login expired, the code was not entered in time")
+		case oauthErr.Code == "access_denied":
+			return nil, fmt.Errorf("login was denied")
+		default:
+			return nil, err
+		}
+
+		if dc.ExpiresIn > 0 && time.Now().After(expires) {
+			return nil, fmt.Errorf("login expired, the code was not entered in time")
+		}
+	}
+}
+
+// oauthError is an error response from the authorization server.
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *oauthError) Error() string {
+	if e.Description != "" {
+		return e.Code + ": " + e.Description
+	}
+	return e.Code
+}
+
+// postForm sends a form to the authorization server and decodes the JSON response.
+func postForm(ctx context.Context, u string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	// Use the same (unauthorized) client the OAuth2 library would use
+	client := http.DefaultClient
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		client = c
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		oauthErr := &oauthError{}
+		if json.Unmarshal(data, oauthErr) == nil && oauthErr.Code != "" {
+			return oauthErr
+		}
+		return fmt.Errorf("unexpected response from authorization server: %s", resp.Status)
+	}
+	return json.Unmarshal(data, out)
+}
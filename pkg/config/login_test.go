@@ -0,0 +1,109 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestFileStore(t *testing.T) {
+	fs := &FileStore{Path: filepath.Join(t.TempDir(), "credentials.json")}
+
+	_, err := fs.Load("https://example.com/")
+	assert.ErrorIs(t, err, ErrNoCredential)
+
+	cred := &Credential{ClientID: "cli", RefreshToken: "r1"}
+	require.NoError(t, fs.Save("https://example.com/", cred))
+
+	actual, err := fs.Load("https://example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, cred, actual)
+
+	require.NoError(t, fs.Delete("https://example.com/"))
+	require.NoError(t, fs.Delete("https://example.com/"))
+	_, err = fs.Load("https://example.com/")
+	assert.ErrorIs(t, err, ErrNoCredential)
+}
+
+func TestConfig_Login(t *testing.T) {
+	var grants []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/device/code":
+			assert.Equal(t, "cli", r.Form.Get("client_id"))
+			assert.Equal(t, "offline_access", r.Form.Get("scope"))
+			_ = json.NewEncoder(w).Encode(&DeviceCode{DeviceCode: "dev", UserCode: "ABCD-EFGH", VerificationURI: "https://example.com/activate", Interval: 1})
+		case "/oauth/token":
+			grants = append(grants, r.Form.Get("grant_type"))
+			refreshToken := "r1"
+			if r.Form.Get("grant_type") == "refresh_token" {
+				assert.Equal(t, "r1", r.Form.Get("refresh_token"))
+				refreshToken = "r2"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "access",
+				"token_type":    "Bearer",
+				"refresh_token": refreshToken,
+				"expires_in":    3600,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, srv.Client())
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "credentials.json")}
+	cfg := &Config{
+		Server:        "https://api.example.com/",
+		Issuer:        srv.URL + "/",
+		LoginClientID: "cli",
+		Credentials:   store,
+	}
+
+	var userCode string
+	require.NoError(t, cfg.Login(ctx, func(dc *DeviceCode) { userCode = dc.UserCode }))
+	assert.Equal(t, "ABCD-EFGH", userCode)
+
+	cred, err := store.Load(cfg.Server)
+	require.NoError(t, err)
+	assert.Equal(t, &Credential{ClientID: "cli", RefreshToken: "r1"}, cred)
+
+	// The stored credential is used (and rotated) by the token source
+	tok, err := cfg.TokenSource(ctx).Token()
+	require.NoError(t, err)
+	assert.Equal(t, "access", tok.AccessToken)
+	cred, err = store.Load(cfg.Server)
+	require.NoError(t, err)
+	assert.Equal(t, "r2", cred.RefreshToken)
+	assert.Equal(t, []string{"urn:ietf:params:oauth:grant-type:device_code", "refresh_token"}, grants)
+
+	require.NoError(t, cfg.Logout())
+	_, err = cfg.TokenSource(ctx).Token()
+	assert.ErrorIs(t, err, ErrNoCredential)
+}
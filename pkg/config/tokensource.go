@@ -0,0 +1,146 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// DefaultRenewBefore is how long before expiry tokens are renewed in the background.
+	DefaultRenewBefore = time.Minute
+	// DefaultClockSkew is how long before expiry tokens are considered expired.
+	DefaultClockSkew = 10 * time.Second
+
+	// renewRetryInterval is the minimum time between failed background renewals.
+	renewRetryInterval = 5 * time.Second
+)
+
+// RenewingTokenSource caches the tokens of another source. Concurrent callers
+// share a single in-flight request for a new token (instead of each
+// requesting one) and tokens are renewed in the background shortly before
+// they expire so callers are not blocked waiting for a refresh.
+type RenewingTokenSource struct {
+	// The source of new tokens, it is never invoked concurrently.
+	Source oauth2.TokenSource
+	// Renew tokens in the background this long before they expire, defaults to DefaultRenewBefore.
+	RenewBefore time.Duration
+	// Consider tokens expired this long before their expiry to tolerate
+	// differences between the local and server clocks, defaults to DefaultClockSkew.
+	ClockSkew time.Duration
+
+	mu     sync.Mutex
+	tok    *oauth2.Token
+	call   *tokenCall
+	failed time.Time
+	now    func() time.Time
+}
+
+// tokenCall is a request for a new token that may be shared by multiple callers.
+type tokenCall struct {
+	done chan struct{}
+	tok  *oauth2.Token
+	err  error
+}
+
+// Token returns the cached token if it is valid, otherwise it waits for a new token.
+func (ts *RenewingTokenSource) Token() (*oauth2.Token, error) {
+	ts.mu.Lock()
+	now := ts.clock()
+	if ts.tok != nil && !ts.expired(ts.tok, now, ts.clockSkew()) {
+		// Start renewing a token that is about to expire, but keep using it for now
+		if ts.expired(ts.tok, now, ts.clockSkew()+ts.renewBefore()) && now.Sub(ts.failed) >= renewRetryInterval {
+			ts.renew()
+		}
+		tok := ts.tok
+		ts.mu.Unlock()
+		return tok, nil
+	}
+
+	c := ts.renew()
+	ts.mu.Unlock()
+
+	<-c.done
+	return c.tok, c.err
+}
+
+// renew returns the in-flight request for a new token, starting one if
+// necessary. Must be called while holding the lock.
+func (ts *RenewingTokenSource) renew() *tokenCall {
+	if ts.call != nil {
+		return ts.call
+	}
+
+	c := &tokenCall{done: make(chan struct{})}
+	ts.call = c
+	go func() {
+		c.tok, c.err = ts.Source.Token()
+
+		ts.mu.Lock()
+		if c.err == nil {
+			ts.tok = c.tok
+		} else {
+			ts.failed = ts.clock()
+		}
+		ts.call = nil
+		ts.mu.Unlock()
+
+		close(c.done)
+	}()
+	return c
+}
+
+// expired checks if the token expires within the supplied window.
+func (ts *RenewingTokenSource) expired(tok *oauth2.Token, now time.Time, window time.Duration) bool {
+	if tok.AccessToken == "" {
+		return true
+	}
+	if tok.Expiry.IsZero() {
+		return false
+	}
+	return !now.Add(window).Before(tok.Expiry)
+}
+
+func (ts *RenewingTokenSource) clock() time.Time {
+	if ts.now != nil {
+		return ts.now()
+	}
+	return time.Now()
+}
+
+func (ts *RenewingTokenSource) renewBefore() time.Duration {
+	if ts.RenewBefore > 0 {
+		return ts.RenewBefore
+	}
+	return DefaultRenewBefore
+}
+
+func (ts *RenewingTokenSource) clockSkew() time.Duration {
+	if ts.ClockSkew > 0 {
+		return ts.ClockSkew
+	}
+	return DefaultClockSkew
+}
+
+// tokenSourceFunc adapts a function to the token source interface.
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+// Token returns the result of invoking the function.
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) { return f() }
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// countingTokenSource issues numbered tokens which expire after a fixed lifetime.
+type countingTokenSource struct {
+	calls    int32
+	lifetime time.Duration
+	delay    time.Duration
+	err      error
+	now      func() time.Time
+}
+
+func (s *countingTokenSource) Token() (*oauth2.Token, error) {
+	n := atomic.AddInt32(&s.calls, 1)
+	time.Sleep(s.delay)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &oauth2.Token{AccessToken: strconv.Itoa(int(n)), Expiry: s.now().Add(s.lifetime)}, nil
+}
+
+func TestRenewingTokenSource_Concurrent(t *testing.T) {
+	src := &countingTokenSource{lifetime: time.Hour, delay: 20 * time.Millisecond, now: time.Now}
+	ts := &RenewingTokenSource{Source: src}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tok, err := ts.Token()
+			if assert.NoError(t, err) {
+				assert.Equal(t, "1", tok.AccessToken)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&src.calls))
+}
+
+func TestRenewingTokenSource_Renewal(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	src := &countingTokenSource{lifetime: 5 * time.Minute, now: clock}
+	ts := &RenewingTokenSource{Source: src, RenewBefore: time.Minute, ClockSkew: 10 * time.Second, now: clock}
+
+	tok, err := ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "1", tok.AccessToken)
+
+	// Inside the renewal window the current token is returned while a new one is fetched
+	advance(4*time.Minute + 30*time.Second)
+	tok, err = ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "1", tok.AccessToken)
+	assert.Eventually(t, func() bool {
+		tok, _ := ts.Token()
+		return tok.AccessToken == "2"
+	}, time.Second, time.Millisecond)
+
+	// Inside the clock skew tolerance callers wait for a new token
+	advance(4*time.Minute + 55*time.Second)
+	tok, err = ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "3", tok.AccessToken)
+}
+
+func TestRenewingTokenSource_Error(t *testing.T) {
+	src := &countingTokenSource{err: errors.New("boom"), now: time.Now}
+	ts := &RenewingTokenSource{Source: src}
+
+	_, err := ts.Token()
+	assert.EqualError(t, err, "boom")
+
+	// Errors are not cached
+	_, err = ts.Token()
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&src.calls))
+}
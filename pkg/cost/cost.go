@@ -0,0 +1,125 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cost estimates the monthly cost of the compute resources requested
+// by workloads so recommendations can be compared in dollar terms.
+package cost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// DefaultProvider is the cloud provider whose prices are used when none is specified.
+const DefaultProvider = "aws"
+
+// gibibyte is the number of bytes memory prices are quoted for.
+const gibibyte = 1 << 30
+
+// Pricing is the monthly price of each compute resource.
+type Pricing struct {
+	// The monthly price of one CPU core.
+	CPU float64 `json:"cpu"`
+	// The monthly price of one GiB of memory.
+	Memory float64 `json:"memory"`
+}
+
+// Providers are the default on-demand prices of general purpose instances on
+// the major cloud providers. These are estimates, actual prices vary by
+// region, instance family and discounts.
+var Providers = map[string]Pricing{
+	"aws":   {CPU: 24.24, Memory: 3.25},
+	"azure": {CPU: 25.55, Memory: 3.36},
+	"gcp":   {CPU: 23.08, Memory: 3.09},
+}
+
+// NewPricing returns the prices of the named provider, overridden by the
+// supplied `resource=price` values. Prices may include a unit (e.g.
+// "$0.03/hour"), otherwise they are monthly prices.
+func NewPricing(provider string, prices map[string]string) (Pricing, error) {
+	if provider == "" {
+		provider = DefaultProvider
+	}
+	p, ok := Providers[strings.ToLower(provider)]
+	if !ok {
+		names := make([]string, 0, len(Providers))
+		for name := range Providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return Pricing{}, fmt.Errorf("unknown pricing provider %q, must be one of: %s", provider, strings.Join(names, "|"))
+	}
+
+	for k, v := range prices {
+		price, unit, err := api.ParseValueWithUnit(v)
+		if err == nil && unit != "" {
+			price, err = unit.Convert(price, api.UnitDollarsPerMonth)
+		}
+		if err != nil || price < 0 {
+			return Pricing{}, fmt.Errorf("invalid price for %q: %s", k, v)
+		}
+
+		switch strings.ToLower(k) {
+		case "cpu":
+			p.CPU = price
+		case "memory", "mem":
+			p.Memory = price
+		default:
+			return Pricing{}, fmt.Errorf("unknown resource %q, must be one of: cpu|memory", k)
+		}
+	}
+	return p, nil
+}
+
+// Resources is an amount of compute resources.
+type Resources struct {
+	// The number of CPU cores.
+	CPU float64
+	// The number of bytes of memory.
+	Memory float64
+}
+
+// Monthly returns the monthly cost of the supplied resources.
+func (p Pricing) Monthly(r Resources) float64 {
+	return r.CPU*p.CPU + r.Memory/gibibyte*p.Memory
+}
+
+// Estimate is the monthly cost of the current and recommended resources.
+type Estimate struct {
+	// The monthly cost of the current resources.
+	Current float64 `json:"current"`
+	// The monthly cost of the recommended resources.
+	Recommended float64 `json:"recommended"`
+}
+
+// Savings returns the monthly amount saved by applying the recommendation,
+// a negative value indicates the recommendation increases cost.
+func (e Estimate) Savings() float64 {
+	return e.Current - e.Recommended
+}
+
+// String returns the formatted monthly savings.
+func (e Estimate) String() string {
+	s := e.Savings()
+	sign := ""
+	if s < 0 {
+		sign, s = "-", -s
+	}
+	return fmt.Sprintf("%s$%.2f/month", sign, s)
+}
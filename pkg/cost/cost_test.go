@@ -0,0 +1,119 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+func TestNewPricing(t *testing.T) {
+	cases := []struct {
+		desc     string
+		provider string
+		prices   map[string]string
+		expected Pricing
+		invalid  bool
+	}{
+		{
+			desc:     "default",
+			expected: Providers[DefaultProvider],
+		},
+		{
+			desc:     "provider",
+			provider: "GCP",
+			expected: Providers["gcp"],
+		},
+		{
+			desc:     "overrides",
+			provider: "aws",
+			prices:   map[string]string{"cpu": "20", "memory": "$0.005/hour"},
+			expected: Pricing{CPU: 20, Memory: 3.65},
+		},
+		{
+			desc:     "unknown provider",
+			provider: "on-prem",
+			invalid:  true,
+		},
+		{
+			desc:    "unknown resource",
+			prices:  map[string]string{"gpu": "100"},
+			invalid: true,
+		},
+		{
+			desc:    "incompatible unit",
+			prices:  map[string]string{"cpu": "20ms"},
+			invalid: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			p, err := NewPricing(c.provider, c.prices)
+			if c.invalid {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.InDelta(t, c.expected.CPU, p.CPU, 1e-9)
+				assert.InDelta(t, c.expected.Memory, p.Memory, 1e-9)
+			}
+		})
+	}
+}
+
+func TestPricing_EstimateRecommendation(t *testing.T) {
+	cpu, mem := api.FromString("1"), api.FromString("2Gi")
+	workloads := []applications.WorkloadItem{{Workload: applications.Workload{
+		Target: applications.TargetRef{Kind: "Deployment", Namespace: "default", Workload: "app"},
+		Containers: []applications.WorkloadContainer{
+			{Name: "main", Requests: &applications.ResourceList{CPU: &cpu, Memory: &mem}},
+			{Name: "sidecar"},
+		},
+	}}}
+
+	rec := &applications.Recommendation{Parameters: []applications.Parameter{{
+		Target: applications.TargetRef{Kind: "Deployment", Namespace: "default", Workload: "app"},
+		ContainerResources: []interface{}{
+			map[string]interface{}{
+				"containerName": "main",
+				"requests":      map[string]interface{}{"cpu": 500.0, "memory": "1Gi"},
+			},
+			map[string]interface{}{
+				"containerName": "sidecar",
+				"requests":      map[string]interface{}{"cpu": 100.0},
+			},
+		},
+	}}}
+
+	p := Pricing{CPU: 20, Memory: 4}
+
+	e, ok := p.EstimateRecommendation(workloads, rec)
+	require.True(t, ok)
+	assert.InDelta(t, 28, e.Current, 1e-9)
+	assert.InDelta(t, 14, e.Recommended, 1e-9)
+	assert.InDelta(t, 14, e.Savings(), 1e-9)
+	assert.Equal(t, "$14.00/month", e.String())
+
+	_, ok = p.EstimateRecommendation(nil, rec)
+	assert.False(t, ok)
+
+	assert.Equal(t, "-$2.50/month", Estimate{Current: 1, Recommended: 3.5}.String())
+}
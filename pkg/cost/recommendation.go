@@ -0,0 +1,114 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"strings"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+// EstimateRecommendation computes the monthly cost of the container resource
+// requests of the current workloads and of the recommendation. Only
+// containers with both current and recommended requests are included, an
+// unknown current value would make any difference meaningless. The second
+// return value indicates if any containers could be compared.
+func (p Pricing) EstimateRecommendation(workloads []applications.WorkloadItem, rec *applications.Recommendation) (Estimate, bool) {
+	current := make(map[string]Resources)
+	for i := range workloads {
+		w := &workloads[i]
+		for _, c := range w.Containers {
+			if c.Requests == nil {
+				continue
+			}
+			current[containerKey(&w.Target, c.Name)] = Resources{
+				CPU:    resourceValue("cpu", c.Requests.CPU),
+				Memory: resourceValue("memory", c.Requests.Memory),
+			}
+		}
+	}
+
+	var e Estimate
+	var found bool
+	if rec == nil {
+		return e, found
+	}
+	for i := range rec.Parameters {
+		param := &rec.Parameters[i]
+		for _, cr := range param.ContainerResources {
+			m, _ := cr.(map[string]interface{})
+			cur, ok := current[containerKey(&param.Target, applications.ContainerName(m))]
+			if !ok {
+				continue
+			}
+
+			requests, _ := m["requests"].(map[string]interface{})
+			if requests == nil {
+				continue
+			}
+
+			recommended := cur
+			if v, ok := looseValue("cpu", requests["cpu"]); ok {
+				recommended.CPU = v
+			}
+			if v, ok := looseValue("memory", requests["memory"]); ok {
+				recommended.Memory = v
+			}
+
+			e.Current += p.Monthly(cur)
+			e.Recommended += p.Monthly(recommended)
+			found = true
+		}
+	}
+	return e, found
+}
+
+// containerKey returns a key which identifies a container of a workload.
+func containerKey(target *applications.TargetRef, container string) string {
+	return strings.Join([]string{target.Kind, target.Namespace, target.Workload, container}, "/")
+}
+
+// resourceValue returns the amount of a resource in cores or bytes.
+func resourceValue(name string, v *api.NumberOrString) float64 {
+	if v == nil {
+		return 0
+	}
+	if v.IsString {
+		f, _ := looseValue(name, v.StrVal)
+		return f
+	}
+	f, _ := looseValue(name, v.Float64Value())
+	return f
+}
+
+// looseValue returns the amount of a loosely typed resource value in cores or
+// bytes. Consistent with the API, numeric CPU values are in millicores.
+func looseValue(name string, v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		if name == "cpu" {
+			v /= 1000
+		}
+		return v, true
+	case string:
+		f, err := api.ParseQuantity(v)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
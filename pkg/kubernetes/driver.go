@@ -0,0 +1,253 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/metrics"
+	"github.com/thestormforge/optimize-go/pkg/patch"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FailureReasonPatch is the failure reason reported when the assignments cannot be applied to a workload.
+	FailureReasonPatch = "PatchFailed"
+	// FailureReasonRollout is the failure reason reported when a patched workload does not become ready.
+	FailureReasonRollout = "RolloutFailed"
+	// FailureReasonLoadTest is the failure reason reported when the load test job fails.
+	FailureReasonLoadTest = "LoadTestFailed"
+)
+
+// Config is the configuration of the trial driver, typically loaded from a YAML file.
+type Config struct {
+	// The kubeconfig context to use, defaults to the current context.
+	Context string `json:"context,omitempty"`
+	// The default namespace of the targets and the load test job.
+	Namespace string `json:"namespace,omitempty"`
+	// The workloads patched with the assignments of each trial.
+	Targets []Target `json:"targets"`
+	// The load test job manifest, rendered using the trial assignments (see `patch.Template`).
+	LoadTest string `json:"loadTest,omitempty"`
+	// The amount of time to measure after the rollout when there is no load test.
+	Duration api.Duration `json:"duration,omitempty"`
+	// The maximum amount of time to wait for each workload rollout, defaults to 5 minutes.
+	RolloutTimeout api.Duration `json:"rolloutTimeout,omitempty"`
+	// The maximum amount of time to wait for the load test job, defaults to 1 hour.
+	LoadTestTimeout api.Duration `json:"loadTestTimeout,omitempty"`
+	// The base URL of the Prometheus server used to collect metric values, a
+	// shorthand for a "prometheus" metric source.
+	PrometheusURL string `json:"prometheusURL,omitempty"`
+	// The metric source used to collect metric values (see `metrics.SourceConfig`).
+	Source *metrics.SourceConfig `json:"source,omitempty"`
+	// The queries used to collect the metric values (see `metrics.Query`).
+	Metrics []metrics.Query `json:"metrics"`
+}
+
+// Target is a workload patched with the trial assignments.
+type Target struct {
+	// The workload reference, e.g. "deployment/my-app".
+	Workload string `json:"workload"`
+	// The namespace of the workload, defaults to the configured namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// The type of patch: "strategic" (the default), "merge" or "json".
+	PatchType string `json:"patchType,omitempty"`
+	// The patch, rendered using the trial assignments (see `patch.Template`).
+	Patch string `json:"patch"`
+}
+
+// LoadConfig reads the driver configuration from a YAML or JSON file.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid driver configuration %q: %w", filename, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid driver configuration %q: %w", filename, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration for problems.
+func (cfg *Config) Validate() error {
+	var errs api.FieldErrorList
+	if len(cfg.Targets) == 0 {
+		errs.Add("targets", "at least one target is required")
+	}
+	for i, t := range cfg.Targets {
+		if t.Workload == "" {
+			errs.Add(fmt.Sprintf("targets[%d].workload", i), "workload is required")
+		}
+		if t.Patch == "" {
+			errs.Add(fmt.Sprintf("targets[%d].patch", i), "patch is required")
+		}
+		switch t.PatchType {
+		case "", "strategic", "merge", "json":
+		default:
+			errs.Add(fmt.Sprintf("targets[%d].patchType", i), "unknown patch type %q", t.PatchType)
+		}
+	}
+	if cfg.LoadTest == "" && cfg.Duration <= 0 {
+		errs.Add("duration", "duration is required when there is no load test")
+	}
+	switch {
+	case cfg.PrometheusURL == "" && cfg.Source == nil:
+		errs.Add("source", "either a metric source or a Prometheus URL is required")
+	case cfg.PrometheusURL != "" && cfg.Source != nil:
+		errs.Add("source", "only one of metric source or Prometheus URL may be specified")
+	case cfg.Source != nil && cfg.Source.Name == "":
+		errs.Add("source.name", "metric source name is required")
+	}
+	if len(cfg.Metrics) == 0 {
+		errs.Add("metrics", "at least one metric query is required")
+	}
+	for i := range cfg.Metrics {
+		if err := cfg.Metrics[i].Validate(); err != nil {
+			errs.Add(fmt.Sprintf("metrics[%d]", i), "%s", err.Error())
+		}
+	}
+	return errs.Err()
+}
+
+// Driver executes trials in a Kubernetes cluster. Since every trial patches
+// the same workloads, trials are executed one at a time regardless of the
+// parallelism of the trial loop.
+type Driver struct {
+	// The driver configuration.
+	Config Config
+	// The cluster the trials are executed in.
+	Cluster Cluster
+	// The source of the metric values.
+	Source metrics.MetricSource
+
+	mu sync.Mutex
+}
+
+// NewDriver returns a driver which uses kubectl and the configured metric source.
+func NewDriver(cfg *Config) (*Driver, error) {
+	sourceConfig := cfg.Source
+	if sourceConfig == nil {
+		sourceConfig = &metrics.SourceConfig{Name: metrics.SourcePrometheus, URL: cfg.PrometheusURL}
+	}
+
+	source, err := metrics.NewSource(sourceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Driver{
+		Config:  *cfg,
+		Cluster: &Kubectl{Context: cfg.Context},
+		Source:  source,
+	}, nil
+}
+
+// RunTrial executes a single trial, it can be used as the `experiments.TrialFunc` of a trial loop.
+func (d *Driver) RunTrial(ctx context.Context, ta *experiments.TrialAssignments) (experiments.TrialValues, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Patch the workloads and wait for them to become ready
+	for _, t := range d.Config.Targets {
+		data, err := (&patch.Template{Manifest: []byte(t.Patch)}).Render(ta)
+		if err != nil {
+			return experiments.TrialValues{}, err
+		}
+		data, err = yaml.YAMLToJSON(data)
+		if err != nil {
+			return experiments.TrialValues{}, fmt.Errorf("invalid patch for %s: %w", t.Workload, err)
+		}
+
+		if err := d.Cluster.Patch(ctx, d.namespace(t), t.Workload, t.PatchType, data); err != nil {
+			return failed(ctx, FailureReasonPatch, err)
+		}
+	}
+	for _, t := range d.Config.Targets {
+		if err := d.Cluster.WaitForRollout(ctx, d.namespace(t), t.Workload, durationOrDefault(d.Config.RolloutTimeout, 5*time.Minute)); err != nil {
+			return failed(ctx, FailureReasonRollout, err)
+		}
+	}
+
+	// Generate load (or just wait) to define the measurement window
+	start := time.Now()
+	if d.Config.LoadTest != "" {
+		manifest, err := (&patch.Template{Manifest: []byte(d.Config.LoadTest)}).Render(ta)
+		if err != nil {
+			return experiments.TrialValues{}, err
+		}
+
+		if err := d.Cluster.RunJob(ctx, d.Config.Namespace, manifest, durationOrDefault(d.Config.LoadTestTimeout, time.Hour)); err != nil {
+			return failed(ctx, FailureReasonLoadTest, err)
+		}
+	} else {
+		select {
+		case <-ctx.Done():
+			return experiments.TrialValues{}, ctx.Err()
+		case <-time.After(time.Duration(d.Config.Duration)):
+		}
+	}
+	end := time.Now()
+
+	values, err := d.Source.Query(ctx, d.Config.Metrics, metrics.Window{Start: start, End: end})
+	if err != nil {
+		return experiments.TrialValues{}, err
+	}
+
+	return experiments.TrialValues{
+		Values:         values,
+		StartTime:      &start,
+		CompletionTime: &end,
+	}, nil
+}
+
+// namespace returns the namespace of the target.
+func (d *Driver) namespace(t Target) string {
+	if t.Namespace != "" {
+		return t.Namespace
+	}
+	return d.Config.Namespace
+}
+
+// failed returns the values of a failed trial, unless the failure was caused by cancellation.
+func failed(ctx context.Context, reason string, err error) (experiments.TrialValues, error) {
+	if ctx.Err() != nil {
+		return experiments.TrialValues{}, ctx.Err()
+	}
+	return experiments.TrialValues{
+		Failed:         true,
+		FailureReason:  reason,
+		FailureMessage: err.Error(),
+	}, nil
+}
+
+func durationOrDefault(d api.Duration, def time.Duration) time.Duration {
+	if d > 0 {
+		return time.Duration(d)
+	}
+	return def
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"github.com/thestormforge/optimize-go/pkg/metrics"
+)
+
+type fakeCluster struct {
+	patches    []string
+	rollouts   []string
+	jobs       []string
+	rolloutErr error
+	jobErr     error
+}
+
+func (c *fakeCluster) Patch(_ context.Context, namespace, workload, patchType string, patch []byte) error {
+	c.patches = append(c.patches, fmt.Sprintf("%s %s %s %s", namespace, workload, patchType, patch))
+	return nil
+}
+
+func (c *fakeCluster) WaitForRollout(_ context.Context, namespace, workload string, _ time.Duration) error {
+	c.rollouts = append(c.rollouts, namespace+" "+workload)
+	return c.rolloutErr
+}
+
+func (c *fakeCluster) RunJob(_ context.Context, namespace string, manifest []byte, _ time.Duration) error {
+	c.jobs = append(c.jobs, namespace+" "+string(manifest))
+	return c.jobErr
+}
+
+type fakeSource map[string]float64
+
+func (c fakeSource) Query(_ context.Context, queries []metrics.Query, _ metrics.Window) ([]experiments.Value, error) {
+	var values []experiments.Value
+	for _, q := range queries {
+		v, ok := c[q.Query]
+		if !ok {
+			return nil, fmt.Errorf("unexpected query %q", q.Query)
+		}
+		values = append(values, experiments.Value{MetricName: q.MetricName, Value: v})
+	}
+	return values, nil
+}
+
+func TestDriver_RunTrial(t *testing.T) {
+	cfg := Config{
+		Namespace: "default",
+		Targets: []Target{
+			{Workload: "deployment/app", Patch: "spec:\n  replicas: {{ .Values.replicas }}\n"},
+			{Workload: "deployment/db", Namespace: "data", PatchType: "merge", Patch: `{"metadata":{"labels":{"tier":"{{ .Values.tier }}"}}}`},
+		},
+		LoadTest: "kind: Job\nmetadata:\n  name: load-{{ .Values.replicas }}\n",
+		Metrics: []metrics.Query{
+			{MetricName: "cost", Query: "sum(cost)"},
+			{MetricName: "latency", Query: "latency", Aggregation: metrics.AggregateAverage},
+		},
+	}
+	ta := &experiments.TrialAssignments{
+		Assignments: []experiments.Assignment{
+			{ParameterName: "replicas", Value: api.FromInt64(3)},
+			{ParameterName: "tier", Value: api.FromString("gold")},
+		},
+	}
+
+	cases := []struct {
+		desc     string
+		cluster  fakeCluster
+		expected experiments.TrialValues
+	}{
+		{
+			desc: "completed",
+			expected: experiments.TrialValues{
+				Values: []experiments.Value{
+					{MetricName: "cost", Value: 10},
+					{MetricName: "latency", Value: 0.25},
+				},
+			},
+		},
+		{
+			desc:    "rollout failed",
+			cluster: fakeCluster{rolloutErr: fmt.Errorf("progress deadline exceeded")},
+			expected: experiments.TrialValues{
+				Failed:         true,
+				FailureReason:  FailureReasonRollout,
+				FailureMessage: "progress deadline exceeded",
+			},
+		},
+		{
+			desc:    "load test failed",
+			cluster: fakeCluster{jobErr: fmt.Errorf("job failed")},
+			expected: experiments.TrialValues{
+				Failed:         true,
+				FailureReason:  FailureReasonLoadTest,
+				FailureMessage: "job failed",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			d := &Driver{
+				Config:  cfg,
+				Cluster: &c.cluster,
+				Source:  fakeSource{"sum(cost)": 10, "latency": 0.25},
+			}
+
+			vls, err := d.RunTrial(context.Background(), ta)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if !c.expected.Failed {
+				assert.NotNil(t, vls.StartTime)
+				assert.NotNil(t, vls.CompletionTime)
+				vls.StartTime, vls.CompletionTime = nil, nil
+			}
+			assert.Equal(t, c.expected, vls)
+
+			assert.Equal(t, []string{
+				`default deployment/app  {"spec":{"replicas":3}}`,
+				`data deployment/db merge {"metadata":{"labels":{"tier":"gold"}}}`,
+			}, c.cluster.patches)
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cases := []struct {
+		desc string
+		cfg  Config
+		err  bool
+	}{
+		{
+			desc: "valid",
+			cfg: Config{
+				Targets:       []Target{{Workload: "deployment/app", Patch: "{}"}},
+				Duration:      api.Duration(time.Minute),
+				PrometheusURL: "http://prometheus:9090",
+				Metrics:       []metrics.Query{{MetricName: "cost", Query: "sum(cost)"}},
+			},
+		},
+		{
+			desc: "datadog",
+			cfg: Config{
+				Targets:  []Target{{Workload: "deployment/app", Patch: "{}"}},
+				Duration: api.Duration(time.Minute),
+				Source:   &metrics.SourceConfig{Name: metrics.SourceDatadog},
+				Metrics:  []metrics.Query{{MetricName: "cost", Query: "sum:cost{*}"}},
+			},
+		},
+		{
+			desc: "unnamed source",
+			cfg: Config{
+				Targets:  []Target{{Workload: "deployment/app", Patch: "{}"}},
+				Duration: api.Duration(time.Minute),
+				Source:   &metrics.SourceConfig{URL: "http://influxdb:8086"},
+				Metrics:  []metrics.Query{{MetricName: "cost", Query: "sum(cost)"}},
+			},
+			err: true,
+		},
+		{
+			desc: "prometheus URL and source",
+			cfg: Config{
+				Targets:       []Target{{Workload: "deployment/app", Patch: "{}"}},
+				Duration:      api.Duration(time.Minute),
+				PrometheusURL: "http://prometheus:9090",
+				Source:        &metrics.SourceConfig{Name: metrics.SourceDatadog},
+				Metrics:       []metrics.Query{{MetricName: "cost", Query: "sum(cost)"}},
+			},
+			err: true,
+		},
+		{
+			desc: "empty",
+			err:  true,
+		},
+		{
+			desc: "no load test or duration",
+			cfg: Config{
+				Targets:       []Target{{Workload: "deployment/app", Patch: "{}"}},
+				PrometheusURL: "http://prometheus:9090",
+				Metrics:       []metrics.Query{{MetricName: "cost", Query: "sum(cost)"}},
+			},
+			err: true,
+		},
+		{
+			desc: "unknown patch type",
+			cfg: Config{
+				Targets:       []Target{{Workload: "deployment/app", Patch: "{}", PatchType: "apply"}},
+				LoadTest:      "kind: Job",
+				PrometheusURL: "http://prometheus:9090",
+				Metrics:       []metrics.Query{{MetricName: "cost", Query: "sum(cost)"}},
+			},
+			err: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := c.cfg.Validate()
+			if c.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubernetes runs experiment trials in a Kubernetes cluster without
+// the optimize controller: workloads are patched with the trial assignments,
+// an optional load test job is run and the metric values are collected.
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Cluster is the set of cluster operations used by the trial driver.
+type Cluster interface {
+	// Patch patches a workload (e.g. "deployment/my-app") using the supplied
+	// patch type ("strategic", "merge" or "json").
+	Patch(ctx context.Context, namespace, workload, patchType string, patch []byte) error
+	// WaitForRollout blocks until the rollout of the workload is complete.
+	WaitForRollout(ctx context.Context, namespace, workload string, timeout time.Duration) error
+	// RunJob creates the job described by the manifest and blocks until it
+	// completes; an error is returned if the job fails. The job is deleted
+	// before returning.
+	RunJob(ctx context.Context, namespace string, manifest []byte, timeout time.Duration) error
+}
+
+// Kubectl is a cluster which is accessed by running `kubectl`.
+type Kubectl struct {
+	// The kubectl executable, defaults to "kubectl".
+	Bin string
+	// Optional kubeconfig file.
+	Kubeconfig string
+	// Optional kubeconfig context.
+	Context string
+	// The interval at which job status is polled, defaults to 5 seconds.
+	PollInterval time.Duration
+	// Optional destination for the standard error of kubectl.
+	Stderr io.Writer
+}
+
+var _ Cluster = &Kubectl{}
+
+// Patch invokes `kubectl patch`.
+func (k *Kubectl) Patch(ctx context.Context, namespace, workload, patchType string, patch []byte) error {
+	if patchType == "" {
+		patchType = "strategic"
+	}
+	_, err := k.run(ctx, nil, withNamespace(namespace, "patch", workload, "--type", patchType, "--patch", string(patch))...)
+	return err
+}
+
+// WaitForRollout invokes `kubectl rollout status`.
+func (k *Kubectl) WaitForRollout(ctx context.Context, namespace, workload string, timeout time.Duration) error {
+	args := withNamespace(namespace, "rollout", "status", workload, "--watch")
+	if timeout > 0 {
+		args = append(args, "--timeout", timeout.String())
+	}
+	_, err := k.run(ctx, nil, args...)
+	return err
+}
+
+// RunJob creates the job and polls its status until it finishes.
+func (k *Kubectl) RunJob(ctx context.Context, namespace string, manifest []byte, timeout time.Duration) error {
+	out, err := k.run(ctx, manifest, withNamespace(namespace, "create", "--filename", "-", "--output", "name")...)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSpace(string(out))
+
+	// Always try to clean up the job, even if we were cancelled
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		_, _ = k.run(cleanupCtx, nil, withNamespace(namespace, "delete", name, "--ignore-not-found", "--cascade", "background", "--wait=false")...)
+	}()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	interval := k.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		finished, err := k.jobFinished(ctx, namespace, name)
+		if finished || err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s did not finish: %w", name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// jobFinished checks the job conditions, a failed job is reported as an error.
+func (k *Kubectl) jobFinished(ctx context.Context, namespace, name string) (bool, error) {
+	out, err := k.run(ctx, nil, withNamespace(namespace, "get", name, "--output", "json")...)
+	if err != nil {
+		return false, err
+	}
+
+	job := struct {
+		Status struct {
+			Conditions []struct {
+				Type    string `json:"type"`
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"conditions"`
+		} `json:"status"`
+	}{}
+	if err := json.Unmarshal(out, &job); err != nil {
+		return false, err
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != "True" {
+			continue
+		}
+		switch c.Type {
+		case "Complete":
+			return true, nil
+		case "Failed":
+			return true, fmt.Errorf("%s failed: %s", name, c.Message)
+		}
+	}
+	return false, nil
+}
+
+// run executes kubectl with the supplied arguments, returning the standard output.
+func (k *Kubectl) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	bin := k.Bin
+	if bin == "" {
+		bin = "kubectl"
+	}
+	verb := args[0]
+	if k.Kubeconfig != "" {
+		args = append([]string{"--kubeconfig", k.Kubeconfig}, args...)
+	}
+	if k.Context != "" {
+		args = append([]string{"--context", k.Context}, args...)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stderr = &stderr
+	if k.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, k.Stderr)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("kubectl %s: %s", verb, msg)
+		}
+		return nil, fmt.Errorf("kubectl %s: %w", verb, err)
+	}
+	return out, nil
+}
+
+// withNamespace adds the namespace to the kubectl arguments, if it is not empty.
+func withNamespace(namespace string, args ...string) []string {
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	return args
+}
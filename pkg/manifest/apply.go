@@ -0,0 +1,116 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// Applier makes the changes of a plan.
+type Applier struct {
+	// The API used to change applications and scenarios.
+	ApplicationsAPI applications.API
+	// The API used to change experiments.
+	ExperimentsAPI experiments.API
+	// The scheme used to decode documents. Defaults to `DefaultScheme`.
+	Scheme *Scheme
+	// Optional callback invoked after each change is made, it is also invoked
+	// for the unchanged resources so every declared resource is reported.
+	OnChange func(c *Change)
+}
+
+// Apply makes each change of the plan in order, stopping at the first failure.
+func (a *Applier) Apply(ctx context.Context, p *Plan) error {
+	for i := range p.Changes {
+		c := &p.Changes[i]
+		if c.Action != ActionNone {
+			if err := a.apply(ctx, c); err != nil {
+				return fmt.Errorf("failed to %s %s %q: %w", c.Action, strings.ToLower(string(c.Kind)), c.Name, err)
+			}
+		}
+
+		if a.OnChange != nil {
+			a.OnChange(c)
+		}
+	}
+	return nil
+}
+
+// apply makes a single change.
+func (a *Applier) apply(ctx context.Context, c *Change) error {
+	if c.Action == ActionDelete {
+		switch c.Kind {
+		case KindApplication:
+			return a.ApplicationsAPI.DeleteApplication(ctx, c.Current.URL)
+		case KindScenario:
+			return a.ApplicationsAPI.DeleteScenario(ctx, c.Current.URL)
+		case KindExperiment:
+			return a.ExperimentsAPI.DeleteExperiment(ctx, c.Current.URL)
+		}
+		return fmt.Errorf("unknown kind %q", c.Kind)
+	}
+
+	scheme := a.Scheme
+	if scheme == nil {
+		scheme = DefaultScheme
+	}
+
+	d := c.Document
+	obj, err := scheme.Decode(d)
+	if err != nil {
+		return err
+	}
+
+	switch obj := obj.(type) {
+	case *applications.Application:
+		if obj.Extensions, err = markManaged(obj.Extensions); err != nil {
+			return err
+		}
+		_, err := a.ApplicationsAPI.UpdateApplicationByName(ctx, applications.ApplicationName(d.Metadata.Name), *obj)
+		return err
+
+	case *applications.Scenario:
+		if obj.Extensions, err = markManaged(obj.Extensions); err != nil {
+			return err
+		}
+		app, err := a.ApplicationsAPI.GetApplicationByName(ctx, applications.ApplicationName(d.Metadata.Application))
+		if err != nil {
+			return err
+		}
+		u := app.Link(api.RelationScenarios)
+		if u == "" {
+			return fmt.Errorf("application %q does not support scenarios", d.Metadata.Application)
+		}
+		_, err = a.ApplicationsAPI.UpdateScenarioByName(ctx, u, applications.ScenarioName(d.Metadata.Name), *obj)
+		return err
+
+	case *experiments.Experiment:
+		if obj.Labels == nil {
+			obj.Labels = make(map[string]string, 1)
+		}
+		obj.Labels[ManagedLabel] = managedBy
+		_, err := a.ExperimentsAPI.CreateExperimentByName(ctx, experiments.ExperimentName(d.Metadata.Name), *obj)
+		return err
+	}
+	return fmt.Errorf("applying %s is not supported", c.Kind)
+}
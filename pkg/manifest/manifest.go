@@ -0,0 +1,257 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package manifest manages API resources declaratively: a directory of YAML
+// documents describing the desired applications, scenarios and experiments
+// is compared to the current state to produce a plan, which can then be applied.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	"sigs.k8s.io/yaml"
+)
+
+// Kind identifies the type of resource declared by a document.
+type Kind string
+
+const (
+	KindApplication Kind = "Application"
+	KindScenario    Kind = "Scenario"
+	KindExperiment  Kind = "Experiment"
+)
+
+// kindOrder is the order in which resources are created, they are deleted in the reverse order.
+var kindOrder = []Kind{KindApplication, KindScenario, KindExperiment}
+
+// ObjectMeta identifies the declared resource.
+type ObjectMeta struct {
+	// The name of the resource.
+	Name string `json:"name"`
+	// The name of the application a scenario belongs to.
+	Application string `json:"application,omitempty"`
+}
+
+// Document is a single declared resource.
+type Document struct {
+	TypeMeta
+	// The resource identity.
+	Metadata ObjectMeta `json:"metadata"`
+	// The desired state of the resource, the same representation used by the API.
+	Spec json.RawMessage `json:"spec,omitempty"`
+	// The file the document was loaded from.
+	Source string `json:"-"`
+}
+
+// Name returns the qualified name of the resource, scenarios are qualified by their application.
+func (d *Document) Name() string {
+	if d.Kind == KindScenario {
+		return d.Metadata.Application + "/" + d.Metadata.Name
+	}
+	return d.Metadata.Name
+}
+
+// Key returns the identifier of the resource, unique across all kinds.
+func (d *Document) Key() string {
+	return key(d.Kind, d.Name())
+}
+
+// key returns the identifier of a resource.
+func key(kind Kind, name string) string {
+	return string(kind) + "/" + name
+}
+
+// Load reads the documents from a file or from every YAML or JSON file in a
+// directory (including subdirectories); each file may contain multiple YAML
+// documents separated by "---". The documents are validated and returned in a
+// deterministic order.
+func Load(path string) ([]Document, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	if info.IsDir() {
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".yaml", ".yml", ".json":
+				if !d.IsDir() {
+					files = append(files, p)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	} else {
+		files = append(files, path)
+	}
+
+	var docs []Document
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		fileDocs, err := DecodeAll(data, f)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, fileDocs...)
+	}
+
+	if err := Validate(docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// Read reads and validates the documents from a stream, e.g. stdin.
+func Read(r io.Reader, source string) ([]Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := DecodeAll(data, source)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Validate(docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// DecodeAll reads a multi-document YAML (or a single JSON document). Empty
+// documents are skipped; the source of each document is the supplied source,
+// qualified by the document index if there is more than one document.
+func DecodeAll(data []byte, source string) ([]Document, error) {
+	parts := splitDocuments(data)
+
+	var docs []Document
+	for i, part := range parts {
+		if len(bytes.TrimSpace(part)) == 0 {
+			continue
+		}
+
+		src := source
+		if len(parts) > 1 {
+			src = fmt.Sprintf("%s[%d]", source, i)
+		}
+
+		d, err := Decode(part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", src, err)
+		}
+
+		// Documents containing only comments decode as empty
+		if d.Kind == "" && d.Metadata == (ObjectMeta{}) && len(d.Spec) == 0 {
+			continue
+		}
+
+		d.Source = src
+		docs = append(docs, d)
+	}
+	return docs, nil
+}
+
+// splitDocuments splits a multi-document YAML stream on the "---" separator lines.
+func splitDocuments(data []byte) [][]byte {
+	var parts [][]byte
+	var current []byte
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if sep := bytes.TrimRight(line, " \t\r\n"); bytes.Equal(sep, []byte("---")) || bytes.HasPrefix(sep, []byte("--- ")) {
+			parts = append(parts, current)
+			current = nil
+			continue
+		}
+		current = append(current, line...)
+	}
+	return append(parts, current)
+}
+
+// Decode reads a single JSON or YAML document.
+func Decode(data []byte) (Document, error) {
+	d := Document{}
+	// YAML is a superset of JSON, this handles both
+	if err := yaml.Unmarshal(data, &d); err != nil {
+		return d, err
+	}
+	return d, nil
+}
+
+// Validate checks that every document declares a kind of resource registered
+// with the default scheme and that no resource is declared more than once.
+func Validate(docs []Document) error {
+	return DefaultScheme.Validate(docs)
+}
+
+// Validate checks that every document declares a kind of resource registered
+// with the scheme and that no resource is declared more than once.
+func (s *Scheme) Validate(docs []Document) error {
+	var errs api.FieldErrorList
+
+	seen := make(map[string]string, len(docs))
+	for i := range docs {
+		d := &docs[i]
+		field := d.Source
+		if field == "" {
+			field = fmt.Sprintf("documents[%d]", i)
+		}
+
+		if d.Kind == "" {
+			errs.Add(field+": kind", "kind is required")
+			continue
+		} else if _, err := s.resolve(d.TypeMeta); err != nil {
+			errs.Add(field+": kind", "%s", err.Error())
+			continue
+		}
+
+		if d.Kind == KindScenario && d.Metadata.Application == "" {
+			errs.Add(field+": metadata.application", "application is required for scenarios")
+		}
+
+		if d.Metadata.Name == "" {
+			errs.Add(field+": metadata.name", "name is required")
+			continue
+		}
+
+		if prev, ok := seen[d.Key()]; ok {
+			errs.Add(field, "%s %q is already declared in %s", strings.ToLower(string(d.Kind)), d.Name(), prev)
+		}
+		seen[d.Key()] = field
+	}
+
+	return errs.Err()
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+func TestLoad(t *testing.T) {
+	cases := []struct {
+		desc     string
+		files    map[string]string
+		expected []string
+		errors   bool
+	}{
+		{
+			desc: "directory",
+			files: map[string]string{
+				"app.yaml":         "kind: Application\nmetadata:\n  name: app\nspec:\n  title: My App\n",
+				"scenarios/s.yml":  "kind: Scenario\nmetadata:\n  name: scn\n  application: app\n",
+				"experiments.json": `{"kind":"Experiment","metadata":{"name":"exp"}}`,
+				"README.md":        "ignored",
+			},
+			expected: []string{"Application/app", "Experiment/exp", "Scenario/app/scn"},
+		},
+		{
+			desc: "multiple documents",
+			files: map[string]string{
+				"all.yaml": "# Comment\n---\nkind: Application\nmetadata:\n  name: app\n---\n# Empty\n--- \nkind: Experiment\nmetadata:\n  name: exp\n---\n",
+			},
+			expected: []string{"Application/app", "Experiment/exp"},
+		},
+		{
+			desc: "invalid",
+			files: map[string]string{
+				"a.yaml": "kind: Application\nmetadata:\n  name: app\n",
+				"b.yaml": "kind: Application\nmetadata:\n  name: app\n",
+				"c.yaml": "kind: Scenario\nmetadata:\n  name: scn\n",
+				"d.yaml": "kind: Cluster\nmetadata:\n  name: c\n",
+			},
+			errors: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range c.files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+			}
+
+			docs, err := Load(dir)
+			if c.errors {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				var keys []string
+				for _, d := range docs {
+					keys = append(keys, d.Key())
+				}
+				assert.Equal(t, c.expected, keys)
+			}
+		})
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	docs := []Document{
+		{TypeMeta: TypeMeta{Kind: KindApplication}, Metadata: ObjectMeta{Name: "a"}, Spec: json.RawMessage(`{"title":"A"}`)},
+		{TypeMeta: TypeMeta{Kind: KindApplication}, Metadata: ObjectMeta{Name: "b"}, Spec: json.RawMessage(`{"title":"B","resources":[]}`)},
+		{TypeMeta: TypeMeta{Kind: KindScenario}, Metadata: ObjectMeta{Name: "s", Application: "a"}, Spec: json.RawMessage(`{"clusters":["c"]}`)},
+		{TypeMeta: TypeMeta{Kind: KindExperiment}, Metadata: ObjectMeta{Name: "e"}},
+	}
+	current := State{
+		"Application/b":   {Kind: KindApplication, Name: "b", Data: json.RawMessage(`{"name":"b","title":"B"}`)},
+		"Application/c":   {Kind: KindApplication, Name: "c", Managed: true, Data: json.RawMessage(`{"name":"c"}`)},
+		"Application/d":   {Kind: KindApplication, Name: "d", Data: json.RawMessage(`{"name":"d"}`)},
+		"Scenario/a/s":    {Kind: KindScenario, Name: "a/s", Data: json.RawMessage(`{"clusters":["d"]}`)},
+		"Scenario/a/t":    {Kind: KindScenario, Name: "a/t", Managed: true, Data: json.RawMessage(`{}`)},
+		"Scenario/a/v":    {Kind: KindScenario, Name: "a/v", Data: json.RawMessage(`{}`)},
+		"Scenario/c/u":    {Kind: KindScenario, Name: "c/u", Managed: true, Data: json.RawMessage(`{}`)},
+		"Experiment/old":  {Kind: KindExperiment, Name: "old", Managed: true, Data: json.RawMessage(`{}`)},
+		"Experiment/keep": {Kind: KindExperiment, Name: "keep", Data: json.RawMessage(`{}`)},
+	}
+
+	summary := func(p *Plan) []string {
+		var result []string
+		for _, c := range p.Changes {
+			result = append(result, string(c.Action)+" "+string(c.Kind)+" "+c.Name)
+		}
+		return result
+	}
+
+	p, err := NewPlan(docs, current, false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{
+			"create Application a",
+			"unchanged Application b",
+			"update Scenario a/s",
+			"create Experiment e",
+		}, summary(p))
+	}
+
+	p, err = NewPlan(docs, current, true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{
+			"create Application a",
+			"unchanged Application b",
+			"update Scenario a/s",
+			"create Experiment e",
+			"delete Experiment old",
+			"delete Scenario a/t",
+			"delete Application c",
+		}, summary(p))
+		assert.Equal(t, 2, p.Count(ActionCreate))
+		assert.Equal(t, 3, p.Count(ActionDelete))
+	}
+
+	// Unmanaged resources are never pruned
+	for _, c := range p.Changes {
+		assert.NotContains(t, []string{"d", "a/v", "keep"}, c.Name)
+	}
+
+	_, err = NewPlan(nil, current, true)
+	assert.Error(t, err)
+}
+
+func TestManaged(t *testing.T) {
+	assert.False(t, isManaged(nil))
+
+	ext, err := markManaged(api.Extensions{"labels": json.RawMessage(`{"team":"a"}`), "other": json.RawMessage(`1`)})
+	if assert.NoError(t, err) {
+		assert.True(t, isManaged(ext))
+		assert.JSONEq(t, `{"team":"a","stormforge.io/managed-by":"manifest"}`, string(ext["labels"]))
+		assert.JSONEq(t, `1`, string(ext["other"]))
+	}
+}
+
+func TestScheme(t *testing.T) {
+	type widget struct {
+		Size int `json:"size"`
+	}
+
+	s := NewScheme()
+	s.AddKnownType("example.com/v1", "Widget", &widget{})
+	s.AddKnownType("example.com/v2", "Widget", &widget{})
+
+	obj, err := s.Decode(&Document{TypeMeta: TypeMeta{Kind: "Widget"}, Spec: json.RawMessage(`{"size":3}`)})
+	if assert.NoError(t, err) {
+		assert.Equal(t, &widget{Size: 3}, obj)
+	}
+
+	assert.True(t, s.Recognizes(TypeMeta{APIVersion: "example.com/v2", Kind: "Widget"}))
+	assert.False(t, s.Recognizes(TypeMeta{APIVersion: "example.com/v3", Kind: "Widget"}))
+	assert.False(t, s.Recognizes(TypeMeta{Kind: "Gadget"}))
+
+	d, err := s.Encode(ObjectMeta{Name: "w"}, &widget{Size: 5})
+	if assert.NoError(t, err) {
+		assert.Equal(t, TypeMeta{APIVersion: "example.com/v1", Kind: "Widget"}, d.TypeMeta)
+		assert.JSONEq(t, `{"size":5}`, string(d.Spec))
+	}
+
+	_, err = s.Encode(ObjectMeta{Name: "w"}, &struct{}{})
+	assert.Error(t, err)
+
+	assert.Error(t, s.Validate([]Document{{TypeMeta: TypeMeta{Kind: KindApplication}, Metadata: ObjectMeta{Name: "app"}}}))
+	assert.NoError(t, DefaultScheme.Validate([]Document{{TypeMeta: TypeMeta{APIVersion: APIVersionApplications, Kind: KindApplication}, Metadata: ObjectMeta{Name: "app"}}}))
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Action is the change needed to make the current state match a document.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionNone   Action = "unchanged"
+)
+
+// Change is a single planned change.
+type Change struct {
+	// The change to make.
+	Action Action
+	// The type of resource.
+	Kind Kind
+	// The qualified name of the resource.
+	Name string
+	// The desired state, nil for deletions.
+	Document *Document
+	// The current state, nil for creations.
+	Current *Object
+}
+
+// Plan is the ordered list of changes needed to make the current state match the documents.
+type Plan struct {
+	Changes []Change
+}
+
+// NewPlan compares the documents to the current state. When pruning, the plan
+// also deletes resources which were previously created from a manifest but are
+// no longer declared: applications, scenarios of declared applications and
+// experiments. Pruning without any documents is an error.
+func NewPlan(docs []Document, current State, prune bool) (*Plan, error) {
+	if prune && len(docs) == 0 {
+		return nil, fmt.Errorf("refusing to prune without any documents")
+	}
+
+	p := &Plan{}
+
+	declared := make(map[string]bool, len(docs))
+	apps := make(map[string]bool)
+	for i := range docs {
+		d := &docs[i]
+		declared[d.Key()] = true
+		switch d.Kind {
+		case KindApplication:
+			apps[d.Metadata.Name] = true
+		case KindScenario:
+			apps[d.Metadata.Application] = true
+		}
+
+		c := Change{Kind: d.Kind, Name: d.Name(), Document: d, Current: current[d.Key()]}
+		if c.Current == nil {
+			c.Action = ActionCreate
+		} else if ok, err := contains(c.Current.Data, d.Spec); err != nil {
+			return nil, err
+		} else if ok {
+			c.Action = ActionNone
+		} else {
+			c.Action = ActionUpdate
+		}
+		p.Changes = append(p.Changes, c)
+	}
+
+	if prune {
+		for k, obj := range current {
+			if declared[k] {
+				continue
+			}
+
+			if !obj.Managed {
+				continue
+			}
+			if obj.Kind == KindScenario {
+				if app, _, _ := strings.Cut(obj.Name, "/"); !apps[app] {
+					continue
+				}
+			}
+
+			p.Changes = append(p.Changes, Change{Action: ActionDelete, Kind: obj.Kind, Name: obj.Name, Current: obj})
+		}
+	}
+
+	sort.SliceStable(p.Changes, func(i, j int) bool { return p.Changes[i].less(&p.Changes[j]) })
+	return p, nil
+}
+
+// Count returns the number of changes with the specified action.
+func (p *Plan) Count(action Action) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// less orders creates and updates by kind, followed by deletes in reverse order of kind.
+func (c *Change) less(o *Change) bool {
+	cd, od := c.Action == ActionDelete, o.Action == ActionDelete
+	if cd != od {
+		return od
+	}
+
+	ck, ok := kindIndex(c.Kind), kindIndex(o.Kind)
+	if ck != ok {
+		return ck < ok != cd
+	}
+	return c.Name < o.Name
+}
+
+// kindIndex returns the creation order of a kind.
+func kindIndex(k Kind) int {
+	for i := range kindOrder {
+		if kindOrder[i] == k {
+			return i
+		}
+	}
+	return len(kindOrder)
+}
+
+// contains checks if every value in the desired JSON document matches the
+// current JSON document, values which are only in the current document (e.g.
+// generated by the server) are ignored.
+func contains(current, desired json.RawMessage) (bool, error) {
+	if len(desired) == 0 {
+		return true, nil
+	}
+
+	var c, d interface{}
+	if err := json.Unmarshal(current, &c); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(desired, &d); err != nil {
+		return false, err
+	}
+	return containsValue(c, d), nil
+}
+
+// containsValue checks if the desired value is a subset of the current value.
+func containsValue(current, desired interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			return current == nil && len(d) == 0
+		}
+		for k, v := range d {
+			if !containsValue(c[k], v) {
+				return false
+			}
+		}
+		return true
+
+	case []interface{}:
+		c, _ := current.([]interface{})
+		if len(c) != len(d) || (c == nil && current != nil) {
+			return false
+		}
+		for i := range d {
+			if !containsValue(c[i], d[i]) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		// The server omits empty values
+		if current == nil {
+			return desired == nil || reflect.ValueOf(desired).IsZero()
+		}
+		return reflect.DeepEqual(current, desired)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+const (
+	// APIVersionApplications is the version of the application and scenario documents.
+	APIVersionApplications = "optimize.stormforge.io/v2"
+	// APIVersionExperiments is the version of the experiment documents.
+	APIVersionExperiments = "optimize.stormforge.io/v1alpha1"
+)
+
+// TypeMeta identifies the type of object a document declares.
+type TypeMeta struct {
+	// The version of the document schema, optional if only one version of the kind is registered.
+	APIVersion string `json:"apiVersion,omitempty"`
+	// The type of resource.
+	Kind Kind `json:"kind"`
+}
+
+// DefaultScheme is the scheme containing the built-in kinds, other packages
+// may register additional kinds.
+var DefaultScheme = NewScheme()
+
+func init() {
+	DefaultScheme.AddKnownType(APIVersionApplications, KindApplication, &applications.Application{})
+	DefaultScheme.AddKnownType(APIVersionApplications, KindScenario, &applications.Scenario{})
+	DefaultScheme.AddKnownType(APIVersionExperiments, KindExperiment, &experiments.Experiment{})
+}
+
+// Scheme maps the version and kind of a document to the Go type used to
+// decode its specification.
+type Scheme struct {
+	types    map[TypeMeta]reflect.Type
+	kinds    map[reflect.Type]TypeMeta
+	versions map[Kind][]string
+}
+
+// NewScheme returns an empty scheme.
+func NewScheme() *Scheme {
+	return &Scheme{
+		types:    make(map[TypeMeta]reflect.Type),
+		kinds:    make(map[reflect.Type]TypeMeta),
+		versions: make(map[Kind][]string),
+	}
+}
+
+// AddKnownType registers the type of the supplied object (which must be a
+// pointer to a struct) for the version and kind. The first version registered
+// for a kind is used for documents which do not specify a version.
+func (s *Scheme) AddKnownType(apiVersion string, kind Kind, obj interface{}) {
+	t := reflect.TypeOf(obj)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("%s %s must be registered using a pointer to a struct, got %s", apiVersion, kind, t))
+	}
+
+	tm := TypeMeta{APIVersion: apiVersion, Kind: kind}
+	if _, ok := s.types[tm]; !ok {
+		s.versions[kind] = append(s.versions[kind], apiVersion)
+	}
+	s.types[tm] = t.Elem()
+	if _, ok := s.kinds[t.Elem()]; !ok {
+		s.kinds[t.Elem()] = tm
+	}
+}
+
+// Recognizes checks if the version and kind are registered.
+func (s *Scheme) Recognizes(tm TypeMeta) bool {
+	_, err := s.resolve(tm)
+	return err == nil
+}
+
+// New returns a pointer to a new object of the registered type.
+func (s *Scheme) New(tm TypeMeta) (interface{}, error) {
+	tm, err := s.resolve(tm)
+	if err != nil {
+		return nil, err
+	}
+	return reflect.New(s.types[tm]).Interface(), nil
+}
+
+// Decode returns a new object of the registered type populated from the document specification.
+func (s *Scheme) Decode(d *Document) (interface{}, error) {
+	obj, err := s.New(d.TypeMeta)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Spec) > 0 {
+		if err := json.Unmarshal(d.Spec, obj); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// Encode returns a document declaring the supplied object.
+func (s *Scheme) Encode(meta ObjectMeta, obj interface{}) (Document, error) {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	tm, ok := s.kinds[t]
+	if !ok {
+		return Document{}, fmt.Errorf("no kind is registered for %s", t)
+	}
+
+	spec, err := json.Marshal(obj)
+	if err != nil {
+		return Document{}, err
+	}
+	return Document{TypeMeta: tm, Metadata: meta, Spec: spec}, nil
+}
+
+// resolve fills in the default version of the kind.
+func (s *Scheme) resolve(tm TypeMeta) (TypeMeta, error) {
+	versions, ok := s.versions[tm.Kind]
+	if !ok {
+		return tm, fmt.Errorf("unknown kind %q", tm.Kind)
+	}
+
+	if tm.APIVersion == "" {
+		tm.APIVersion = versions[0]
+	} else if _, ok := s.types[tm]; !ok {
+		return tm, fmt.Errorf("unknown version %q of kind %q", tm.APIVersion, tm.Kind)
+	}
+	return tm, nil
+}
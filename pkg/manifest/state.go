@@ -0,0 +1,200 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// ManagedLabel is the label identifying resources created from a manifest; only
+// resources with this label are pruned.
+const ManagedLabel = "stormforge.io/managed-by"
+
+// managedBy is the value of the managed label.
+const managedBy = "manifest"
+
+// labelsExtension is the field holding the labels of applications and scenarios,
+// which are not modeled by their API types.
+const labelsExtension = "labels"
+
+// isManaged checks the extension labels of an application or scenario for the managed label.
+func isManaged(ext api.Extensions) bool {
+	var labels map[string]string
+	if raw, ok := ext[labelsExtension]; ok {
+		_ = json.Unmarshal(raw, &labels)
+	}
+	return labels[ManagedLabel] == managedBy
+}
+
+// markManaged adds the managed label to the extension labels of an application or scenario.
+func markManaged(ext api.Extensions) (api.Extensions, error) {
+	labels := make(map[string]string, 1)
+	if raw, ok := ext[labelsExtension]; ok {
+		if err := json.Unmarshal(raw, &labels); err != nil {
+			return nil, err
+		}
+	}
+	labels[ManagedLabel] = managedBy
+
+	raw, err := json.Marshal(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(api.Extensions, len(ext)+1)
+	for k, v := range ext {
+		result[k] = v
+	}
+	result[labelsExtension] = raw
+	return result, nil
+}
+
+// Object is the current state of a resource.
+type Object struct {
+	// The type of resource.
+	Kind Kind
+	// The qualified name of the resource.
+	Name string
+	// The URL of the resource.
+	URL string
+	// The flag indicating the resource was created from a manifest.
+	Managed bool
+	// The current representation of the resource.
+	Data json.RawMessage
+}
+
+// State is the current state of the resources, indexed by key.
+type State map[string]*Object
+
+// add records the current state of a resource.
+func (s State) add(kind Kind, name, u string, managed bool, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	s[key(kind, name)] = &Object{Kind: kind, Name: name, URL: u, Managed: managed, Data: data}
+	return nil
+}
+
+// Fetch returns the current state of all the applications (including their
+// scenarios) and experiments. Either API may be nil to skip those resources.
+func Fetch(ctx context.Context, appAPI applications.API, expAPI experiments.API) (State, error) {
+	s := State{}
+
+	if appAPI != nil {
+		l := applications.Lister{API: appAPI}
+		if err := l.ForEachApplication(ctx, applications.ApplicationListQuery{}, func(item *applications.ApplicationItem) error {
+			appName := item.Name.String()
+			if err := s.add(KindApplication, appName, item.Link(api.RelationSelf), isManaged(item.Extensions), &item.Application); err != nil {
+				return err
+			}
+
+			return l.ForEachScenario(ctx, &item.Application, applications.ScenarioListQuery{}, func(scn *applications.ScenarioItem) error {
+				return s.add(KindScenario, appName+"/"+scn.Name.String(), scn.Link(api.RelationSelf), isManaged(scn.Extensions), &scn.Scenario)
+			})
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if expAPI != nil {
+		l := experiments.Lister{API: expAPI}
+		if err := l.ForEachExperiment(ctx, experiments.ExperimentListQuery{}, func(item *experiments.ExperimentItem) error {
+			managed := item.Labels[ManagedLabel] == managedBy
+			return s.add(KindExperiment, item.Name.String(), item.Link(api.RelationSelf), managed, &item.Experiment)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// FetchDocuments returns the current state of only the resources declared by
+// the documents, it is cheaper than `Fetch` but cannot be used for pruning.
+func FetchDocuments(ctx context.Context, appAPI applications.API, expAPI experiments.API, docs []Document) (State, error) {
+	s := State{}
+
+	// Scenarios are fetched through their application
+	apps := make(map[string]*applications.Application)
+	getApp := func(name string) (*applications.Application, error) {
+		if app, ok := apps[name]; ok {
+			return app, nil
+		}
+		app, err := appAPI.GetApplicationByName(ctx, applications.ApplicationName(name))
+		if api.IsNotFound(err) {
+			apps[name] = nil
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		apps[name] = &app
+		return &app, nil
+	}
+
+	for i := range docs {
+		d := &docs[i]
+		switch d.Kind {
+		case KindApplication:
+			app, err := getApp(d.Metadata.Name)
+			if err != nil {
+				return nil, err
+			} else if app != nil {
+				if err := s.add(KindApplication, d.Name(), app.Link(api.RelationSelf), isManaged(app.Extensions), app); err != nil {
+					return nil, err
+				}
+			}
+
+		case KindScenario:
+			app, err := getApp(d.Metadata.Application)
+			if err != nil {
+				return nil, err
+			} else if app == nil {
+				// The application is created by the same plan
+				continue
+			}
+			scn, err := appAPI.GetScenarioByName(ctx, app.Link(api.RelationScenarios), applications.ScenarioName(d.Metadata.Name))
+			if api.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			if err := s.add(KindScenario, d.Name(), scn.Link(api.RelationSelf), isManaged(scn.Extensions), &scn); err != nil {
+				return nil, err
+			}
+
+		case KindExperiment:
+			exp, err := expAPI.GetExperimentByName(ctx, experiments.ExperimentName(d.Metadata.Name))
+			if api.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			managed := exp.Labels[ManagedLabel] == managedBy
+			if err := s.add(KindExperiment, d.Name(), exp.Link(api.RelationSelf), managed, &exp); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s, nil
+}
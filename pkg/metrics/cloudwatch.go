@@ -0,0 +1,250 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// CloudWatch is a metric source which executes `GetMetricData` queries using the AWS CloudWatch API.
+//
+// A query with a statistic references a single metric as `NAMESPACE METRIC_NAME [NAME=VALUE ...]`,
+// for example `AWS/EC2 CPUUtilization InstanceId=i-0123` with the "Average" statistic. A query
+// without a statistic is a metric math or Metrics Insights expression. The step of the query is
+// used as the period, defaulting to a 30th of the window rounded up to the nearest minute.
+type CloudWatch struct {
+	// The AWS region, e.g. "us-east-1".
+	Region string
+	// The base URL of the CloudWatch API, defaults to the regional endpoint.
+	URL string
+	// The access key ID used to sign requests.
+	AccessKeyID string
+	// The secret access key used to sign requests.
+	SecretAccessKey string
+	// The optional session token of temporary credentials.
+	SessionToken string
+	// The statistic used for queries which do not specify one.
+	Stat string
+	// The HTTP client used to execute queries. Defaults to `http.DefaultClient`.
+	Client *http.Client
+}
+
+// NewCloudWatch returns a CloudWatch metric source using the credentials from
+// the standard AWS environment variables. If the region is empty, it is also
+// read from the environment.
+func NewCloudWatch(region string) *CloudWatch {
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	return &CloudWatch{
+		Region:          region,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// cloudWatchResponse is the response of the `GetMetricData` action.
+type cloudWatchResponse struct {
+	Results []struct {
+		ID         string   `xml:"Id"`
+		StatusCode string   `xml:"StatusCode"`
+		Values     []string `xml:"Values>member"`
+	} `xml:"GetMetricDataResult>MetricDataResults>member"`
+	NextToken string `xml:"GetMetricDataResult>NextToken"`
+}
+
+// cloudWatchError is the error response of the CloudWatch API.
+type cloudWatchError struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+var _ MetricSource = &CloudWatch{}
+
+// Query evaluates the queries over the measurement window. The data points of
+// each query are aggregated using the average if the query does not specify
+// an aggregation.
+func (c *CloudWatch) Query(ctx context.Context, queries []Query, w Window) ([]experiments.Value, error) {
+	return evaluateAll(queries, w, func(q *Query, query string) (float64, float64, error) {
+		period := time.Duration(q.Step)
+		if period <= 0 {
+			period = (q.step(w) + time.Minute - 1) / time.Minute * time.Minute
+		}
+
+		stat := q.Stat
+		if stat == "" {
+			stat = c.Stat
+		}
+
+		points, err := c.GetMetricData(ctx, query, stat, period, w.Start, w.End)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		// Check the number of data points against the error budget
+		expected := int(w.Duration() / period)
+		if expected < 1 {
+			expected = 1
+		}
+		if missing := expected - len(points); len(points) == 0 || float64(missing) > q.errorBudget()*float64(expected) {
+			return 0, 0, fmt.Errorf("query returned %d of %d expected data points", len(points), expected)
+		}
+
+		aggregation := q.Aggregation
+		if aggregation == "" {
+			aggregation = AggregateAverage
+		}
+		v, stddev := aggregate(points, aggregation)
+		return v, stddev, nil
+	})
+}
+
+// GetMetricData returns the data points of a single metric (if the statistic is
+// not empty) or expression, in chronological order.
+func (c *CloudWatch) GetMetricData(ctx context.Context, query, stat string, period time.Duration, start, end time.Time) ([]float64, error) {
+	form := url.Values{}
+	form.Set("Action", "GetMetricData")
+	form.Set("Version", "2010-08-01")
+	form.Set("StartTime", start.UTC().Format(time.RFC3339))
+	form.Set("EndTime", end.UTC().Format(time.RFC3339))
+	form.Set("ScanBy", "TimestampAscending")
+
+	const prefix = "MetricDataQueries.member.1."
+	form.Set(prefix+"Id", "m1")
+	form.Set(prefix+"ReturnData", "true")
+	seconds := strconv.FormatInt(int64(period/time.Second), 10)
+	if stat == "" {
+		form.Set(prefix+"Expression", query)
+		form.Set(prefix+"Period", seconds)
+	} else {
+		fields := strings.Fields(query)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid metric %q, expected NAMESPACE METRIC_NAME [NAME=VALUE ...]", query)
+		}
+		form.Set(prefix+"MetricStat.Metric.Namespace", fields[0])
+		form.Set(prefix+"MetricStat.Metric.MetricName", fields[1])
+		for i, d := range fields[2:] {
+			name, value, ok := strings.Cut(d, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid dimension %q, expected NAME=VALUE", d)
+			}
+			form.Set(fmt.Sprintf("%sMetricStat.Metric.Dimensions.member.%d.Name", prefix, i+1), name)
+			form.Set(fmt.Sprintf("%sMetricStat.Metric.Dimensions.member.%d.Value", prefix, i+1), value)
+		}
+		form.Set(prefix+"MetricStat.Period", seconds)
+		form.Set(prefix+"MetricStat.Stat", stat)
+	}
+
+	var points []float64
+	for {
+		result, err := c.do(ctx, form)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range result.Results {
+			if r.StatusCode == "Forbidden" || r.StatusCode == "InternalError" {
+				return nil, fmt.Errorf("query %q failed: %s", query, r.StatusCode)
+			}
+			for _, s := range r.Values {
+				v, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid data point value: %s", s)
+				}
+				points = append(points, v)
+			}
+		}
+
+		if result.NextToken == "" {
+			return points, nil
+		}
+		form.Set("NextToken", result.NextToken)
+	}
+}
+
+// do executes a signed request against the CloudWatch API and returns the response.
+func (c *CloudWatch) do(ctx context.Context, form url.Values) (*cloudWatchResponse, error) {
+	if c.Region == "" {
+		return nil, fmt.Errorf("AWS region is required")
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS credentials are required")
+	}
+
+	u := c.URL
+	if u == "" {
+		u = "https://monitoring." + c.Region + ".amazonaws.com/"
+	}
+
+	body := []byte(form.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signV4(req, body, awsCredentials{
+		AccessKeyID:     c.AccessKeyID,
+		SecretAccessKey: c.SecretAccessKey,
+		SessionToken:    c.SessionToken,
+	}, c.Region, "monitoring", time.Now())
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		e := &cloudWatchError{}
+		if err := xml.Unmarshal(data, e); err != nil || e.Code == "" {
+			return nil, fmt.Errorf("unexpected CloudWatch response (%s)", resp.Status)
+		}
+		return nil, fmt.Errorf("cloudwatch query failed (%s): %s", e.Code, e.Message)
+	}
+
+	result := &cloudWatchResponse{}
+	if err := xml.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("unexpected CloudWatch response (%s)", resp.Status)
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestCloudWatch_Query(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	end := start.Add(10 * time.Minute)
+
+	cases := []struct {
+		desc     string
+		query    Query
+		status   int
+		response string
+		expected experiments.Value
+		form     map[string]string
+		err      bool
+	}{
+		{
+			desc:     "metric stat",
+			query:    Query{MetricName: "cpu", Query: "AWS/EC2 CPUUtilization InstanceId=i-0123", Stat: "Average"},
+			response: cloudWatchResult("", 1, 2, 3, 4, 5, 6, 7, 8, 9, 10),
+			expected: experiments.Value{MetricName: "cpu", Value: 5.5, Error: 2.8722813232690143},
+			form: map[string]string{
+				"Action": "GetMetricData",
+				"MetricDataQueries.member.1.MetricStat.Metric.Namespace":                "AWS/EC2",
+				"MetricDataQueries.member.1.MetricStat.Metric.MetricName":               "CPUUtilization",
+				"MetricDataQueries.member.1.MetricStat.Metric.Dimensions.member.1.Name": "InstanceId",
+				"MetricDataQueries.member.1.MetricStat.Period":                          "60",
+				"MetricDataQueries.member.1.MetricStat.Stat":                            "Average",
+			},
+		},
+		{
+			desc:     "expression",
+			query:    Query{MetricName: "cpu", Query: "SELECT MAX(CPUUtilization) FROM SCHEMA(\"AWS/EC2\")", Aggregation: AggregateMaximum},
+			response: cloudWatchResult("", 1, 2, 3, 4, 5, 6, 7, 8, 9, 10),
+			expected: experiments.Value{MetricName: "cpu", Value: 10, Error: 2.8722813232690143},
+			form: map[string]string{
+				"MetricDataQueries.member.1.Expression": "SELECT MAX(CPUUtilization) FROM SCHEMA(\"AWS/EC2\")",
+				"MetricDataQueries.member.1.Period":     "60",
+			},
+		},
+		{
+			desc:     "exceeds error budget",
+			query:    Query{MetricName: "cpu", Query: "AWS/EC2 CPUUtilization", Stat: "Average"},
+			response: cloudWatchResult("", 1, 2, 3),
+			err:      true,
+		},
+		{
+			desc:     "invalid metric",
+			query:    Query{MetricName: "cpu", Query: "CPUUtilization", Stat: "Average"},
+			response: cloudWatchResult(""),
+			err:      true,
+		},
+		{
+			desc:     "error",
+			query:    Query{MetricName: "cpu", Query: "AWS/EC2 CPUUtilization", Stat: "Average"},
+			status:   http.StatusBadRequest,
+			response: `<ErrorResponse><Error><Type>Sender</Type><Code>InvalidParameterValue</Code><Message>bad</Message></Error></ErrorResponse>`,
+			err:      true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Contains(t, r.Header.Get("Authorization"), "Credential=AKID/")
+				assert.NoError(t, r.ParseForm())
+				for k, v := range c.form {
+					assert.Equal(t, v, r.PostForm.Get(k), k)
+				}
+				if c.status != 0 {
+					w.WriteHeader(c.status)
+				}
+				_, _ = w.Write([]byte(c.response))
+			}))
+			defer srv.Close()
+
+			cw := &CloudWatch{URL: srv.URL, Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret"}
+			actual, err := cw.Query(context.Background(), []Query{c.query}, Window{Start: start, End: end})
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) && assert.Len(t, actual, 1) {
+				assert.Equal(t, c.expected.MetricName, actual[0].MetricName)
+				assert.InDelta(t, c.expected.Value, actual[0].Value, 1e-9)
+				assert.InDelta(t, c.expected.Error, actual[0].Error, 1e-9)
+			}
+		})
+	}
+}
+
+func TestCloudWatch_GetMetricData(t *testing.T) {
+	// The results are split across two pages
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		if r.PostForm.Get("NextToken") == "" {
+			_, _ = w.Write([]byte(cloudWatchResult("next", 1, 2)))
+			return
+		}
+		assert.Equal(t, "next", r.PostForm.Get("NextToken"))
+		_, _ = w.Write([]byte(cloudWatchResult("", 3)))
+	}))
+	defer srv.Close()
+
+	cw := &CloudWatch{URL: srv.URL, Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret"}
+	points, err := cw.GetMetricData(context.Background(), "AWS/EC2 CPUUtilization", "Average", time.Minute, time.Unix(0, 0), time.Unix(180, 0))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []float64{1, 2, 3}, points)
+	}
+}
+
+// cloudWatchResult returns a GetMetricData response containing the supplied values.
+func cloudWatchResult(nextToken string, values ...float64) string {
+	var members []string
+	for _, v := range values {
+		members = append(members, fmt.Sprintf("<member>%g</member>", v))
+	}
+	return `<GetMetricDataResponse xmlns="http://monitoring.amazonaws.com/doc/2010-08-01/"><GetMetricDataResult>` +
+		`<MetricDataResults><member><Id>m1</Id><StatusCode>Complete</StatusCode><Values>` + strings.Join(members, "") +
+		`</Values></member></MetricDataResults><NextToken>` + nextToken + `</NextToken></GetMetricDataResult></GetMetricDataResponse>`
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// DefaultDatadogURL is the base URL of the Datadog API in the default (US1) site.
+const DefaultDatadogURL = "https://api.datadoghq.com"
+
+// Datadog is a metric source which executes metric queries using the Datadog API.
+type Datadog struct {
+	// The base URL of the Datadog API, defaults to "https://api.datadoghq.com".
+	URL string
+	// The API key used to authenticate requests.
+	APIKey string
+	// The application key used to authorize queries.
+	ApplicationKey string
+	// The HTTP client used to execute queries. Defaults to `http.DefaultClient`.
+	Client *http.Client
+}
+
+// DatadogSiteURL returns the base API URL for a Datadog site, e.g. "datadoghq.eu".
+func DatadogSiteURL(site string) string {
+	if site == "" {
+		return DefaultDatadogURL
+	}
+	return "https://api." + strings.TrimPrefix(site, "app.")
+}
+
+// datadogResponse is the response of the Datadog timeseries query API.
+type datadogResponse struct {
+	Status string          `json:"status"`
+	Error  string          `json:"error,omitempty"`
+	Errors []string        `json:"errors,omitempty"`
+	Series []datadogSeries `json:"series"`
+}
+
+// datadogSeries is a single series of a Datadog query result.
+type datadogSeries struct {
+	Metric    string        `json:"metric"`
+	Scope     string        `json:"scope"`
+	PointList [][2]*float64 `json:"pointlist"`
+}
+
+var _ MetricSource = &Datadog{}
+
+// Query evaluates the queries over the measurement window. Datadog decides the
+// resolution of the returned points so every query is aggregated, using the
+// average if the query does not specify an aggregation; the step and error
+// budget of the queries are ignored.
+func (d *Datadog) Query(ctx context.Context, queries []Query, w Window) ([]experiments.Value, error) {
+	return evaluateAll(queries, w, func(q *Query, query string) (float64, float64, error) {
+		points, err := d.QueryTimeseries(ctx, query, w.Start, w.End)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(points) == 0 {
+			return 0, 0, fmt.Errorf("query %q returned no points", query)
+		}
+
+		aggregation := q.Aggregation
+		if aggregation == "" {
+			aggregation = AggregateAverage
+		}
+		v, stddev := aggregate(points, aggregation)
+		return v, stddev, nil
+	})
+}
+
+// QueryTimeseries executes a timeseries query and returns the point values.
+// The query must produce a single series; points without a value are omitted.
+func (d *Datadog) QueryTimeseries(ctx context.Context, query string, start, end time.Time) ([]float64, error) {
+	q := url.Values{}
+	q.Set("query", query)
+	q.Set("from", strconv.FormatInt(start.Unix(), 10))
+	q.Set("to", strconv.FormatInt(end.Unix(), 10))
+
+	result, err := d.do(ctx, "/api/v1/query", q)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(result.Series) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("query %q returned %d series, expected 1", query, len(result.Series))
+	}
+
+	points := make([]float64, 0, len(result.Series[0].PointList))
+	for _, p := range result.Series[0].PointList {
+		if p[1] != nil {
+			points = append(points, *p[1])
+		}
+	}
+	return points, nil
+}
+
+// do executes a request against the Datadog API and returns the response.
+func (d *Datadog) do(ctx context.Context, path string, q url.Values) (*datadogResponse, error) {
+	base := d.URL
+	if base == "" {
+		base = DefaultDatadogURL
+	}
+
+	u := strings.TrimRight(base, "/") + path + "?" + q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("DD-API-KEY", d.APIKey)
+	req.Header.Set("DD-APPLICATION-KEY", d.ApplicationKey)
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &datadogResponse{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, fmt.Errorf("unexpected Datadog response (%s)", resp.Status)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("datadog query failed (%s): %s", resp.Status, strings.Join(result.Errors, ", "))
+	}
+	if resp.StatusCode != http.StatusOK || result.Status == "error" {
+		return nil, fmt.Errorf("datadog query failed (%s): %s", resp.Status, result.Error)
+	}
+	return result, nil
+}
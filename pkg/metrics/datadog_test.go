@@ -0,0 +1,116 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestDatadog_Query(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	end := start.Add(5 * time.Minute)
+
+	cases := []struct {
+		desc     string
+		query    Query
+		status   int
+		response string
+		expected experiments.Value
+		err      bool
+	}{
+		{
+			desc:     "average",
+			query:    Query{MetricName: "m", Query: "avg:x{*}", Unit: api.UnitMilliseconds},
+			response: `{"status":"ok","series":[{"pointlist":[[1700000000000,1],[1700000060000,null],[1700000120000,3]]}]}`,
+			expected: experiments.Value{MetricName: "m", Value: 2, Error: 1, Unit: api.UnitMilliseconds},
+		},
+		{
+			desc:     "maximum",
+			query:    Query{MetricName: "m", Query: "avg:x{*}", Aggregation: AggregateMaximum},
+			response: `{"status":"ok","series":[{"pointlist":[[1700000000000,1],[1700000060000,3]]}]}`,
+			expected: experiments.Value{MetricName: "m", Value: 3, Error: 1},
+		},
+		{
+			desc:     "no series",
+			query:    Query{MetricName: "m", Query: "avg:x{*}"},
+			response: `{"status":"ok","series":[]}`,
+			err:      true,
+		},
+		{
+			desc:     "multiple series",
+			query:    Query{MetricName: "m", Query: "avg:x{*}"},
+			response: `{"status":"ok","series":[{"pointlist":[[0,1]]},{"pointlist":[[0,2]]}]}`,
+			err:      true,
+		},
+		{
+			desc:     "query error",
+			query:    Query{MetricName: "m", Query: "avg:x{*}"},
+			response: `{"status":"error","error":"Error parsing query"}`,
+			err:      true,
+		},
+		{
+			desc:     "forbidden",
+			query:    Query{MetricName: "m", Query: "avg:x{*}"},
+			status:   http.StatusForbidden,
+			response: `{"errors":["Forbidden"]}`,
+			err:      true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v1/query", r.URL.Path)
+				assert.Equal(t, "api-key", r.Header.Get("DD-API-KEY"))
+				assert.Equal(t, "app-key", r.Header.Get("DD-APPLICATION-KEY"))
+				assert.Equal(t, "1700000000", r.URL.Query().Get("from"))
+				assert.Equal(t, "1700000300", r.URL.Query().Get("to"))
+				if c.status != 0 {
+					w.WriteHeader(c.status)
+				}
+				_, _ = w.Write([]byte(c.response))
+			}))
+			defer srv.Close()
+
+			d := &Datadog{URL: srv.URL, APIKey: "api-key", ApplicationKey: "app-key"}
+			actual, err := d.Query(context.Background(), []Query{c.query}, Window{Start: start, End: end})
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) && assert.Len(t, actual, 1) {
+				assert.Equal(t, c.expected.MetricName, actual[0].MetricName)
+				assert.Equal(t, c.expected.Unit, actual[0].Unit)
+				assert.InDelta(t, c.expected.Value, actual[0].Value, 1e-9)
+				assert.InDelta(t, c.expected.Error, actual[0].Error, 1e-9)
+			}
+		})
+	}
+}
+
+func TestDatadogSiteURL(t *testing.T) {
+	assert.Equal(t, "https://api.datadoghq.com", DatadogSiteURL(""))
+	assert.Equal(t, "https://api.datadoghq.eu", DatadogSiteURL("datadoghq.eu"))
+	assert.Equal(t, "https://api.us3.datadoghq.com", DatadogSiteURL("us3.datadoghq.com"))
+}
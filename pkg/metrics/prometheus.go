@@ -0,0 +1,222 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics collects the metric values of trials from monitoring systems.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// Prometheus is a metric source which executes PromQL queries using the Prometheus HTTP API.
+type Prometheus struct {
+	// The base URL of the Prometheus server, e.g. "http://prometheus:9090".
+	URL string
+	// The HTTP client used to execute queries. Defaults to `http.DefaultClient`.
+	Client *http.Client
+}
+
+// prometheusResponse is the response envelope of the Prometheus HTTP API.
+type prometheusResponse struct {
+	Status    string         `json:"status"`
+	ErrorType string         `json:"errorType,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Data      prometheusData `json:"data"`
+}
+
+// prometheusData is the query result of the Prometheus HTTP API.
+type prometheusData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+var _ MetricSource = &Prometheus{}
+
+// Query evaluates the queries over the measurement window. Queries without an
+// aggregation are instant queries evaluated at the end of the window.
+func (p *Prometheus) Query(ctx context.Context, queries []Query, w Window) ([]experiments.Value, error) {
+	return evaluateAll(queries, w, func(q *Query, query string) (float64, float64, error) {
+		if q.Aggregation == "" {
+			v, err := p.QueryInstant(ctx, query, w.End)
+			return v, 0, err
+		}
+
+		step := q.step(w)
+		samples, err := p.QueryRange(ctx, query, w.Start, w.End, step)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		// Check the number of samples against the error budget
+		expected := int(w.Duration()/step) + 1
+		if missing := expected - len(samples); len(samples) == 0 || float64(missing) > q.errorBudget()*float64(expected) {
+			return 0, 0, fmt.Errorf("query returned %d of %d expected samples", len(samples), expected)
+		}
+
+		v, stddev := aggregate(samples, q.Aggregation)
+		return v, stddev, nil
+	})
+}
+
+// QueryInstant executes an instant query evaluated at the supplied time. The
+// query must produce a scalar or a vector with a single sample.
+func (p *Prometheus) QueryInstant(ctx context.Context, query string, at time.Time) (float64, error) {
+	q := url.Values{}
+	q.Set("query", query)
+	if !at.IsZero() {
+		q.Set("time", formatPrometheusTime(at))
+	}
+
+	data, err := p.do(ctx, "/api/v1/query", q)
+	if err != nil {
+		return 0, err
+	}
+
+	switch data.ResultType {
+	case "scalar":
+		var sample [2]interface{}
+		if err := json.Unmarshal(data.Result, &sample); err != nil {
+			return 0, err
+		}
+		return sampleValue(sample)
+
+	case "vector":
+		var vector []struct {
+			Value [2]interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(data.Result, &vector); err != nil {
+			return 0, err
+		}
+		if len(vector) != 1 {
+			return 0, fmt.Errorf("query %q returned %d samples, expected 1", query, len(vector))
+		}
+		return sampleValue(vector[0].Value)
+
+	default:
+		return 0, fmt.Errorf("query %q returned an unsupported %s result", query, data.ResultType)
+	}
+}
+
+// QueryRange executes a range query and returns the sample values. The query
+// must produce a scalar or a single series; steps without a sample are omitted.
+func (p *Prometheus) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]float64, error) {
+	q := url.Values{}
+	q.Set("query", query)
+	q.Set("start", formatPrometheusTime(start))
+	q.Set("end", formatPrometheusTime(end))
+	q.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	data, err := p.do(ctx, "/api/v1/query_range", q)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.ResultType != "matrix" {
+		return nil, fmt.Errorf("query %q returned an unsupported %s result", query, data.ResultType)
+	}
+
+	var matrix []struct {
+		Values [][2]interface{} `json:"values"`
+	}
+	if err := json.Unmarshal(data.Result, &matrix); err != nil {
+		return nil, err
+	}
+	switch len(matrix) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("query %q returned %d series, expected 1", query, len(matrix))
+	}
+
+	samples := make([]float64, 0, len(matrix[0].Values))
+	for _, s := range matrix[0].Values {
+		v, err := sampleValue(s)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, v)
+	}
+	return samples, nil
+}
+
+// do executes a request against the Prometheus HTTP API and returns the response data.
+func (p *Prometheus) do(ctx context.Context, path string, q url.Values) (*prometheusData, error) {
+	u := strings.TrimRight(p.URL, "/") + path + "?" + q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &prometheusResponse{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, fmt.Errorf("unexpected Prometheus response (%s)", resp.Status)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed (%s): %s", result.ErrorType, result.Error)
+	}
+	return &result.Data, nil
+}
+
+// sampleValue returns the value of a `[ <unix_time>, "<value>" ]` sample.
+func sampleValue(sample [2]interface{}) (float64, error) {
+	s, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid sample value: %v", sample[1])
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// formatPrometheusTime formats a time as fractional Unix seconds.
+func formatPrometheusTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
+
+// FormatRange returns the duration in whole seconds for use in a PromQL range
+// selector, e.g. "300s" for use as `rate(x[300s])`.
+func FormatRange(d time.Duration) string {
+	s := int64(d.Round(time.Second) / time.Second)
+	if s < 1 {
+		s = 1
+	}
+	return strconv.FormatInt(s, 10) + "s"
+}
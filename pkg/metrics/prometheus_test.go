@@ -0,0 +1,89 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheus_QueryInstant(t *testing.T) {
+	cases := []struct {
+		desc     string
+		response string
+		expected float64
+		err      bool
+	}{
+		{
+			desc:     "scalar",
+			response: `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1.5"]}}`,
+			expected: 1.5,
+		},
+		{
+			desc:     "vector",
+			response: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"42"]}]}}`,
+			expected: 42,
+		},
+		{
+			desc:     "empty vector",
+			response: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			err:      true,
+		},
+		{
+			desc:     "matrix",
+			response: `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			err:      true,
+		},
+		{
+			desc:     "error",
+			response: `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			err:      true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v1/query", r.URL.Path)
+				assert.Equal(t, "up", r.URL.Query().Get("query"))
+				assert.Equal(t, "1700000000", r.URL.Query().Get("time"))
+				_, _ = w.Write([]byte(c.response))
+			}))
+			defer srv.Close()
+
+			p := &Prometheus{URL: srv.URL}
+			actual, err := p.QueryInstant(context.Background(), "up", time.Unix(1700000000, 0))
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFormatRange(t *testing.T) {
+	assert.Equal(t, "300s", FormatRange(5*time.Minute))
+	assert.Equal(t, "1s", FormatRange(0))
+	assert.Equal(t, "2s", FormatRange(1500*time.Millisecond))
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"text/template"
+	"time"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// Aggregations of range query samples.
+const (
+	AggregateAverage = "avg"
+	AggregateMinimum = "min"
+	AggregateMaximum = "max"
+	AggregateLast    = "last"
+)
+
+// Window is the time range over which the metric values of a trial are measured.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the window.
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// MetricSource collects metric values from a monitoring system.
+type MetricSource interface {
+	// Query evaluates the queries over the measurement window and returns
+	// the metric values in the same order as the queries.
+	Query(ctx context.Context, queries []Query, w Window) ([]experiments.Value, error)
+}
+
+// defaultErrorBudget is the fraction of range query samples which may be missing by default.
+const defaultErrorBudget = 0.1
+
+// Query describes how the value of a single metric is collected.
+type Query struct {
+	// The name of the metric the value is reported for.
+	MetricName string `json:"metric"`
+	// The query, a template with the measurement window available as `{{ .Range }}`
+	// (the duration in seconds, e.g. "300s"), `{{ .Start }}` and `{{ .End }}`.
+	Query string `json:"query"`
+	// The aggregation of a range query: one of "avg", "min", "max" or "last". If
+	// empty, the query is an instant query evaluated at the end of the window.
+	Aggregation string `json:"aggregation,omitempty"`
+	// The resolution of a range query, defaults to a 30th of the window.
+	Step api.Duration `json:"step,omitempty"`
+	// The unit of the query results (e.g. "B" or "s"), values are converted to
+	// the unit of the experiment metric when they are normalized.
+	Unit api.Unit `json:"unit,omitempty"`
+	// The statistic of a CloudWatch metric (e.g. "Average" or "p99"), when set
+	// the query is a metric reference instead of an expression (see `CloudWatch`).
+	Stat string `json:"stat,omitempty"`
+	// The fraction of the expected range query samples that may be missing
+	// (e.g. due to failed scrapes) before the value is rejected, defaults to 0.1.
+	ErrorBudget *float64 `json:"errorBudget,omitempty"`
+}
+
+// Validate checks the query for problems.
+func (q *Query) Validate() error {
+	if q.MetricName == "" {
+		return fmt.Errorf("metric name is required")
+	}
+	if q.Query == "" {
+		return fmt.Errorf("query for metric %q is required", q.MetricName)
+	}
+	switch q.Aggregation {
+	case "", AggregateAverage, AggregateMinimum, AggregateMaximum, AggregateLast:
+	default:
+		return fmt.Errorf("unknown aggregation for metric %q: %s", q.MetricName, q.Aggregation)
+	}
+	if _, err := api.ParseUnit(string(q.Unit)); err != nil {
+		return fmt.Errorf("invalid unit for metric %q: %w", q.MetricName, err)
+	}
+	if q.ErrorBudget != nil && (*q.ErrorBudget < 0 || *q.ErrorBudget > 1) {
+		return fmt.Errorf("error budget for metric %q must be between 0 and 1", q.MetricName)
+	}
+	return nil
+}
+
+// render returns the query text for the supplied measurement window.
+func (q *Query) render(w Window) (string, error) {
+	tmpl, err := template.New(q.MetricName).Option("missingkey=error").Parse(q.Query)
+	if err != nil {
+		return "", fmt.Errorf("invalid query for metric %q: %w", q.MetricName, err)
+	}
+
+	data := struct {
+		Range string
+		Start time.Time
+		End   time.Time
+	}{
+		Range: FormatRange(w.Duration()),
+		Start: w.Start,
+		End:   w.End,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, &data); err != nil {
+		return "", fmt.Errorf("invalid query for metric %q: %w", q.MetricName, err)
+	}
+	return buf.String(), nil
+}
+
+// step returns the range query resolution for the supplied measurement window.
+func (q *Query) step(w Window) time.Duration {
+	if q.Step > 0 {
+		return time.Duration(q.Step)
+	}
+	step := w.Duration() / 30
+	if step < time.Second {
+		step = time.Second
+	}
+	return step
+}
+
+// errorBudget returns the fraction of samples which may be missing.
+func (q *Query) errorBudget() float64 {
+	if q.ErrorBudget != nil {
+		return *q.ErrorBudget
+	}
+	return defaultErrorBudget
+}
+
+// evaluateFunc returns the value and error of a single rendered query.
+type evaluateFunc func(q *Query, query string) (float64, float64, error)
+
+// evaluateAll renders and evaluates each of the queries.
+func evaluateAll(queries []Query, w Window, eval evaluateFunc) ([]experiments.Value, error) {
+	values := make([]experiments.Value, 0, len(queries))
+	for i := range queries {
+		q := &queries[i]
+		if err := q.Validate(); err != nil {
+			return nil, err
+		}
+
+		query, err := q.render(w)
+		if err != nil {
+			return nil, err
+		}
+
+		v := experiments.Value{MetricName: q.MetricName, Unit: q.Unit}
+		v.Value, v.Error, err = eval(q, query)
+		if err != nil {
+			return nil, fmt.Errorf("unable to collect metric %q: %w", q.MetricName, err)
+		}
+		if math.IsNaN(v.Value) || math.IsInf(v.Value, 0) {
+			return nil, fmt.Errorf("unable to collect metric %q: query returned %v", q.MetricName, v.Value)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// aggregate returns the aggregated value of the samples along with their
+// standard deviation. There must be at least one sample.
+func aggregate(samples []float64, aggregation string) (float64, float64) {
+	var value, sum, sumSq float64
+	for i, s := range samples {
+		sum += s
+		sumSq += s * s
+		switch {
+		case i == 0, aggregation == AggregateLast:
+			value = s
+		case aggregation == AggregateMinimum:
+			value = math.Min(value, s)
+		case aggregation == AggregateMaximum:
+			value = math.Max(value, s)
+		}
+	}
+
+	n := float64(len(samples))
+	mean := sum / n
+	if aggregation == AggregateAverage {
+		value = mean
+	}
+	return value, math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestPrometheus_Query(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	end := start.Add(10 * time.Second)
+	zero := 0.0
+
+	// The server returns a sample for every step of a range query, except the "sparse" query which is missing one
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("query")
+		switch r.URL.Path {
+		case "/api/v1/query":
+			_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"value":[0,"%d"]}]}}`, len(q))
+		case "/api/v1/query_range":
+			assert.Equal(t, "1", r.URL.Query().Get("step"))
+			var values []string
+			for i := 0; i <= 10; i++ {
+				if q == "sparse" && i == 5 {
+					continue
+				}
+				values = append(values, fmt.Sprintf(`[%d,"%d"]`, i, i))
+			}
+			_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"values":[%s]}]}}`, strings.Join(values, ","))
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		desc     string
+		query    Query
+		expected experiments.Value
+		err      bool
+	}{
+		{
+			desc:     "instant",
+			query:    Query{MetricName: "m", Query: "rate(x[{{ .Range }}])", Unit: api.UnitMilliseconds},
+			expected: experiments.Value{MetricName: "m", Value: float64(len("rate(x[10s])")), Unit: api.UnitMilliseconds},
+		},
+		{
+			desc:     "average",
+			query:    Query{MetricName: "m", Query: "x", Aggregation: AggregateAverage},
+			expected: experiments.Value{MetricName: "m", Value: 5, Error: 3.1622776601683795},
+		},
+		{
+			desc:     "maximum",
+			query:    Query{MetricName: "m", Query: "x", Aggregation: AggregateMaximum},
+			expected: experiments.Value{MetricName: "m", Value: 10, Error: 3.1622776601683795},
+		},
+		{
+			desc:     "within error budget",
+			query:    Query{MetricName: "m", Query: "sparse", Aggregation: AggregateLast},
+			expected: experiments.Value{MetricName: "m", Value: 10, Error: 3.3166247903554},
+		},
+		{
+			desc:  "exceeds error budget",
+			query: Query{MetricName: "m", Query: "sparse", Aggregation: AggregateLast, ErrorBudget: &zero},
+			err:   true,
+		},
+		{
+			desc:  "invalid aggregation",
+			query: Query{MetricName: "m", Query: "x", Aggregation: "median"},
+			err:   true,
+		},
+		{
+			desc:  "invalid template",
+			query: Query{MetricName: "m", Query: "{{ .Other }}"},
+			err:   true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			p := &Prometheus{URL: srv.URL}
+			actual, err := p.Query(context.Background(), []Query{c.query}, Window{Start: start, End: end})
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) && assert.Len(t, actual, 1) {
+				assert.Equal(t, c.expected.MetricName, actual[0].MetricName)
+				assert.Equal(t, c.expected.Unit, actual[0].Unit)
+				assert.InDelta(t, c.expected.Value, actual[0].Value, 1e-9)
+				assert.InDelta(t, c.expected.Error, actual[0].Error, 1e-9)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Names of the built-in metric sources.
+const (
+	SourcePrometheus = "prometheus"
+	SourceDatadog    = "datadog"
+	SourceCloudWatch = "cloudwatch"
+)
+
+// SourceConfig is the configuration of a metric source, the source is
+// discovered by name using a registry.
+type SourceConfig struct {
+	// The registered name of the metric source, e.g. "prometheus".
+	Name string `json:"name"`
+	// The base URL of the monitoring system, the meaning depends on the source.
+	URL string `json:"url,omitempty"`
+	// Additional source specific options.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// Option returns the named option, falling back to the environment variable
+// (if not empty) when the option is not set.
+func (cfg *SourceConfig) Option(name, env string) string {
+	if v := cfg.Options[name]; v != "" {
+		return v
+	}
+	if env != "" {
+		return os.Getenv(env)
+	}
+	return ""
+}
+
+// SourceFactory returns a new metric source for the supplied configuration.
+type SourceFactory func(cfg *SourceConfig) (MetricSource, error)
+
+// DefaultRegistry is the registry containing the built-in metric sources, other
+// packages may register additional sources.
+var DefaultRegistry = NewRegistry()
+
+func init() {
+	DefaultRegistry.Register(SourcePrometheus, newPrometheusSource)
+	DefaultRegistry.Register(SourceDatadog, newDatadogSource)
+	DefaultRegistry.Register(SourceCloudWatch, newCloudWatchSource)
+}
+
+// Register adds a metric source to the default registry.
+func Register(name string, factory SourceFactory) {
+	DefaultRegistry.Register(name, factory)
+}
+
+// NewSource returns a metric source from the default registry.
+func NewSource(cfg *SourceConfig) (MetricSource, error) {
+	return DefaultRegistry.New(cfg)
+}
+
+// Registry maps metric source names to the factories used to create them.
+type Registry struct {
+	factories map[string]SourceFactory
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]SourceFactory)}
+}
+
+// Register adds a metric source factory. Names are case-insensitive and may
+// only be registered once.
+func (r *Registry) Register(name string, factory SourceFactory) {
+	key := strings.ToLower(name)
+	if key == "" || factory == nil {
+		panic("metric sources must be registered with a name and a factory")
+	}
+	if _, ok := r.factories[key]; ok {
+		panic(fmt.Sprintf("metric source %q is already registered", name))
+	}
+	r.factories[key] = factory
+}
+
+// Names returns the sorted names of the registered metric sources.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for k := range r.factories {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns a new metric source for the supplied configuration.
+func (r *Registry) New(cfg *SourceConfig) (MetricSource, error) {
+	factory, ok := r.factories[strings.ToLower(cfg.Name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric source %q, expected one of: %s", cfg.Name, strings.Join(r.Names(), ", "))
+	}
+	return factory(cfg)
+}
+
+// newPrometheusSource returns a Prometheus metric source, the URL is required.
+func newPrometheusSource(cfg *SourceConfig) (MetricSource, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("the Prometheus URL is required")
+	}
+	return &Prometheus{URL: cfg.URL}, nil
+}
+
+// newDatadogSource returns a Datadog metric source. The URL defaults to the API
+// of the "site" option, the "apiKey" and "appKey" options default to the
+// `DD_API_KEY` and `DD_APP_KEY` environment variables.
+func newDatadogSource(cfg *SourceConfig) (MetricSource, error) {
+	d := &Datadog{
+		URL:            cfg.URL,
+		APIKey:         cfg.Option("apiKey", "DD_API_KEY"),
+		ApplicationKey: cfg.Option("appKey", "DD_APP_KEY"),
+	}
+	if d.URL == "" {
+		d.URL = DatadogSiteURL(cfg.Option("site", "DD_SITE"))
+	}
+	if d.APIKey == "" || d.ApplicationKey == "" {
+		return nil, fmt.Errorf("the Datadog API and application keys are required")
+	}
+	return d, nil
+}
+
+// newCloudWatchSource returns a CloudWatch metric source. The "region" option
+// and the credentials default to the standard AWS environment variables, the
+// "stat" option is the default statistic of the queries.
+func newCloudWatchSource(cfg *SourceConfig) (MetricSource, error) {
+	cw := NewCloudWatch(cfg.Options["region"])
+	cw.URL = cfg.URL
+	cw.Stat = cfg.Options["stat"]
+	if cw.Region == "" {
+		return nil, fmt.Errorf("the AWS region is required")
+	}
+	return cw, nil
+}
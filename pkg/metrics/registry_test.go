@@ -0,0 +1,98 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+type staticSource float64
+
+func (s staticSource) Query(_ context.Context, queries []Query, _ Window) ([]experiments.Value, error) {
+	values := make([]experiments.Value, 0, len(queries))
+	for _, q := range queries {
+		values = append(values, experiments.Value{MetricName: q.MetricName, Value: float64(s)})
+	}
+	return values, nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register("Static", func(cfg *SourceConfig) (MetricSource, error) {
+		return staticSource(1), nil
+	})
+
+	assert.Equal(t, []string{"static"}, r.Names())
+	assert.Panics(t, func() { r.Register("static", func(cfg *SourceConfig) (MetricSource, error) { return nil, nil }) })
+
+	s, err := r.New(&SourceConfig{Name: "STATIC"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, staticSource(1), s)
+	}
+
+	_, err = r.New(&SourceConfig{Name: "influxdb"})
+	assert.EqualError(t, err, `unknown metric source "influxdb", expected one of: static`)
+}
+
+func TestNewSource(t *testing.T) {
+	cases := []struct {
+		desc     string
+		cfg      SourceConfig
+		expected MetricSource
+		err      bool
+	}{
+		{
+			desc:     "prometheus",
+			cfg:      SourceConfig{Name: SourcePrometheus, URL: "http://prometheus:9090"},
+			expected: &Prometheus{URL: "http://prometheus:9090"},
+		},
+		{
+			desc: "prometheus without URL",
+			cfg:  SourceConfig{Name: SourcePrometheus},
+			err:  true,
+		},
+		{
+			desc:     "datadog",
+			cfg:      SourceConfig{Name: SourceDatadog, Options: map[string]string{"site": "datadoghq.eu", "apiKey": "a", "appKey": "b"}},
+			expected: &Datadog{URL: "https://api.datadoghq.eu", APIKey: "a", ApplicationKey: "b"},
+		},
+		{
+			desc:     "cloudwatch",
+			cfg:      SourceConfig{Name: SourceCloudWatch, Options: map[string]string{"region": "us-west-2", "stat": "p99"}},
+			expected: &CloudWatch{Region: "us-west-2", Stat: "p99"},
+		},
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := NewSource(&c.cfg)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, actual)
+			}
+		})
+	}
+}
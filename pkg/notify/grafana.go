@@ -0,0 +1,199 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// GrafanaAnnotator creates Grafana annotations spanning the execution of each
+// trial so dashboards can be correlated with optimization activity.
+type GrafanaAnnotator struct {
+	// The base URL of the Grafana server, e.g. "https://grafana.example.com".
+	URL string
+	// The service account token (or API key) used to authorize requests.
+	Token string
+	// Optional dashboard to annotate, annotations are global (organization wide) if empty.
+	DashboardUID string
+	// Additional tags added to every annotation.
+	Tags []string
+	// The HTTP client used to create annotations. Defaults to `http.DefaultClient`.
+	Client *http.Client
+	// Optional callback invoked with errors creating annotations.
+	OnError func(error)
+
+	mu  sync.Mutex
+	ids map[string]int64
+}
+
+// grafanaAnnotation is the annotation payload of the Grafana HTTP API.
+type grafanaAnnotation struct {
+	ID           int64    `json:"id,omitempty"`
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	Time         int64    `json:"time,omitempty"`
+	TimeEnd      int64    `json:"timeEnd,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Text         string   `json:"text,omitempty"`
+}
+
+// AttachTrialLoop adds hooks to the trial loop which annotate the start and
+// finish of each trial of the named experiment. Any existing hooks are preserved.
+func (g *GrafanaAnnotator) AttachTrialLoop(ctx context.Context, l *experiments.TrialLoop, name string) {
+	tags := append([]string{"stormforge", "experiment:" + name}, g.Tags...)
+
+	nextStart := l.OnTrialStart
+	l.OnTrialStart = func(ta *experiments.TrialAssignments) {
+		if nextStart != nil {
+			nextStart(ta)
+		}
+
+		id, err := g.create(ctx, &grafanaAnnotation{
+			DashboardUID: g.DashboardUID,
+			Time:         time.Now().UnixMilli(),
+			Tags:         tags,
+			Text:         fmt.Sprintf("Trial started (%s): %s", name, formatAssignments(ta.Assignments)),
+		})
+		if err != nil {
+			g.reportError(err)
+			return
+		}
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.ids == nil {
+			g.ids = make(map[string]int64)
+		}
+		g.ids[ta.Location()] = id
+	}
+
+	nextFinish := l.OnTrialFinish
+	l.OnTrialFinish = func(ta *experiments.TrialAssignments, vls *experiments.TrialValues) {
+		if nextFinish != nil {
+			nextFinish(ta, vls)
+		}
+
+		g.mu.Lock()
+		id, ok := g.ids[ta.Location()]
+		delete(g.ids, ta.Location())
+		g.mu.Unlock()
+		if !ok {
+			return
+		}
+
+		if err := g.update(ctx, id, &grafanaAnnotation{
+			TimeEnd: time.Now().UnixMilli(),
+			Text:    fmt.Sprintf("%s (%s): %s", formatValues(vls), name, formatAssignments(ta.Assignments)),
+		}); err != nil {
+			g.reportError(err)
+		}
+	}
+}
+
+// create adds a new annotation and returns its identifier.
+func (g *GrafanaAnnotator) create(ctx context.Context, a *grafanaAnnotation) (int64, error) {
+	result := grafanaAnnotation{}
+	if err := g.do(ctx, http.MethodPost, "/api/annotations", a, &result); err != nil {
+		return 0, err
+	}
+	return result.ID, nil
+}
+
+// update patches an existing annotation.
+func (g *GrafanaAnnotator) update(ctx context.Context, id int64, a *grafanaAnnotation) error {
+	return g.do(ctx, http.MethodPatch, fmt.Sprintf("/api/annotations/%d", id), a, nil)
+}
+
+// do sends a request to the Grafana HTTP API.
+func (g *GrafanaAnnotator) do(ctx context.Context, method, path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(g.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return api.NewUnexpectedError(resp, respBody)
+	}
+	if out != nil {
+		return json.Unmarshal(respBody, out)
+	}
+	return nil
+}
+
+// reportError invokes the error callback.
+func (g *GrafanaAnnotator) reportError(err error) {
+	if g.OnError != nil {
+		g.OnError(fmt.Errorf("failed to annotate Grafana: %w", err))
+	}
+}
+
+// formatAssignments returns a compact representation of the trial assignments.
+func formatAssignments(assignments []experiments.Assignment) string {
+	parts := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		parts = append(parts, a.ParameterName+"="+a.Value.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatValues returns a compact description of the trial outcome.
+func formatValues(vls *experiments.TrialValues) string {
+	if vls.Failed {
+		return fmt.Sprintf("Trial failed [%s: %s]", vls.FailureReason, vls.FailureMessage)
+	}
+
+	parts := make([]string, 0, len(vls.Values))
+	for _, v := range vls.Values {
+		parts = append(parts, fmt.Sprintf("%s=%g", v.MetricName, v.Value))
+	}
+	return "Trial completed [" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestGrafanaAnnotator_AttachTrialLoop(t *testing.T) {
+	var requests []string
+	var annotations []grafanaAnnotation
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		a := grafanaAnnotation{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&a))
+		annotations = append(annotations, a)
+
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"id":42,"message":"Annotation added"}`))
+		}
+	}))
+	defer srv.Close()
+
+	g := &GrafanaAnnotator{URL: srv.URL, Token: "token", DashboardUID: "abc", OnError: func(err error) { assert.NoError(t, err) }}
+	l := &experiments.TrialLoop{}
+	g.AttachTrialLoop(context.Background(), l, "test")
+
+	ta := &experiments.TrialAssignments{
+		Metadata:    api.Metadata{"Location": []string{"trial"}},
+		Assignments: []experiments.Assignment{{ParameterName: "cpu", Value: api.FromInt64(500)}},
+	}
+	l.OnTrialStart(ta)
+	l.OnTrialFinish(ta, &experiments.TrialValues{Values: []experiments.Value{{MetricName: "cost", Value: 1.5}}})
+
+	assert.Equal(t, []string{"POST /api/annotations", "PATCH /api/annotations/42"}, requests)
+	if assert.Len(t, annotations, 2) {
+		assert.Equal(t, "abc", annotations[0].DashboardUID)
+		assert.Equal(t, []string{"stormforge", "experiment:test"}, annotations[0].Tags)
+		assert.Equal(t, "Trial started (test): cpu=500", annotations[0].Text)
+		assert.Equal(t, "Trial completed [cost=1.5] (test): cpu=500", annotations[1].Text)
+		assert.NotZero(t, annotations[1].TimeEnd)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// AttachTrialLoop adds a hook to the trial loop which sends a notification
+// when the supplied number of consecutive trials of the named experiment have
+// failed. Only one notification is sent until a trial succeeds again. Any
+// existing hook is preserved.
+func (n *Notifier) AttachTrialLoop(ctx context.Context, l *experiments.TrialLoop, name string, threshold int) {
+	if n == nil || threshold <= 0 {
+		return
+	}
+
+	var (
+		mu       sync.Mutex
+		failures int
+	)
+
+	next := l.OnTrialFinish
+	l.OnTrialFinish = func(ta *experiments.TrialAssignments, vls *experiments.TrialValues) {
+		if next != nil {
+			next(ta, vls)
+		}
+
+		mu.Lock()
+		if vls.Failed {
+			failures++
+		} else {
+			failures = 0
+		}
+		count := failures
+		mu.Unlock()
+
+		if count == threshold {
+			n.Notify(ctx, Event{
+				Type:    EventTrialFailures,
+				Subject: name,
+				Message: fmt.Sprintf("%d consecutive trials of experiment %q failed, last failure (%s): %s", count, name, vls.FailureReason, vls.FailureMessage),
+			})
+		}
+	}
+}
+
+// ExperimentStopped returns the event sent when the named experiment stops.
+func ExperimentStopped(name string, exp *experiments.Experiment) Event {
+	e := Event{
+		Type:    EventExperimentStopped,
+		Subject: name,
+		Message: fmt.Sprintf("experiment %q stopped", name),
+	}
+	if exp != nil {
+		e.URL = exp.Link(api.RelationSelf)
+		if exp.Observations > 0 {
+			e.Message = fmt.Sprintf("experiment %q stopped after %d observations", name, exp.Observations)
+		}
+	}
+	return e
+}
+
+// ActivityEvent returns the event for an activity item, the returned flag is
+// false if the item does not warrant a notification.
+func ActivityEvent(item *applications.ActivityItem) (Event, bool) {
+	// Approval is requested when a recommendation is generated
+	if !item.HasTag(applications.TagApprove) {
+		return Event{}, false
+	}
+
+	msg := item.Title
+	if msg == "" {
+		msg = "recommendation generated"
+	}
+
+	return Event{
+		Type:    EventRecommendation,
+		Subject: item.ID,
+		Message: msg,
+		URL:     item.ExternalURL,
+		Time:    item.DatePublished,
+	}, true
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notify sends messages about optimization activity (e.g. an
+// experiment stopping) to external systems such as Slack.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// EventType identifies the kind of event a notification is sent for.
+type EventType string
+
+const (
+	// EventExperimentStopped is sent when an experiment stops producing trials.
+	EventExperimentStopped EventType = "experiment-stopped"
+	// EventTrialFailures is sent when consecutive trials of an experiment fail.
+	EventTrialFailures EventType = "trial-failures"
+	// EventRecommendation is sent when a recommendation is generated.
+	EventRecommendation EventType = "recommendation"
+)
+
+// Event describes something worth notifying someone about.
+type Event struct {
+	// The type of event.
+	Type EventType `json:"type"`
+	// The name of the resource the event is for.
+	Subject string `json:"subject"`
+	// A human readable description of the event.
+	Message string `json:"message"`
+	// An optional link to more information.
+	URL string `json:"url,omitempty"`
+	// The time the event occurred.
+	Time time.Time `json:"time"`
+}
+
+// String returns a single line summary of the event.
+func (e *Event) String() string {
+	if e.URL == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, e.URL)
+}
+
+// Sink delivers events to an external system.
+type Sink interface {
+	// Send delivers a single event.
+	Send(ctx context.Context, e *Event) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(ctx context.Context, e *Event) error
+
+// Send invokes the function.
+func (f SinkFunc) Send(ctx context.Context, e *Event) error {
+	return f(ctx, e)
+}
+
+// Notifier sends events to a collection of sinks. The zero value discards all events.
+type Notifier struct {
+	// The sinks each event is sent to.
+	Sinks []Sink
+	// Only send events of these types, send all events if empty.
+	Types []EventType
+	// Optional callback invoked with errors from individual sinks.
+	OnError func(error)
+}
+
+// Notify sends the event to every sink. Sink failures do not prevent delivery
+// to the remaining sinks, they are reported to the error callback instead.
+func (n *Notifier) Notify(ctx context.Context, e Event) {
+	if n == nil || !n.accepts(e.Type) {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	for _, s := range n.Sinks {
+		if err := s.Send(ctx, &e); err != nil && n.OnError != nil {
+			n.OnError(fmt.Errorf("failed to send %s notification: %w", e.Type, err))
+		}
+	}
+}
+
+// accepts checks if the notifier is configured for the event type.
+func (n *Notifier) accepts(t EventType) bool {
+	if len(n.Types) == 0 {
+		return true
+	}
+	for _, tt := range n.Types {
+		if tt == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// recorder is a sink that records the events it receives.
+type recorder []Event
+
+func (r *recorder) Send(_ context.Context, e *Event) error {
+	*r = append(*r, *e)
+	return nil
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	var errs []error
+	rec := &recorder{}
+	n := &Notifier{
+		Sinks: []Sink{
+			SinkFunc(func(context.Context, *Event) error { return errors.New("unavailable") }),
+			rec,
+		},
+		Types:   []EventType{EventExperimentStopped},
+		OnError: func(err error) { errs = append(errs, err) },
+	}
+
+	n.Notify(context.Background(), Event{Type: EventExperimentStopped, Message: "stopped"})
+	n.Notify(context.Background(), Event{Type: EventRecommendation, Message: "ignored"})
+
+	if assert.Len(t, *rec, 1) {
+		assert.Equal(t, "stopped", (*rec)[0].Message)
+		assert.False(t, (*rec)[0].Time.IsZero())
+	}
+	assert.Len(t, errs, 1)
+
+	// A nil notifier discards everything
+	var nilNotifier *Notifier
+	nilNotifier.Notify(context.Background(), Event{Type: EventExperimentStopped})
+}
+
+func TestNotifier_AttachTrialLoop(t *testing.T) {
+	cases := []struct {
+		desc     string
+		failed   []bool
+		expected int
+	}{
+		{
+			desc:   "no failures",
+			failed: []bool{false, false, false},
+		},
+		{
+			desc:     "consecutive failures",
+			failed:   []bool{true, true, true, true},
+			expected: 1,
+		},
+		{
+			desc:     "reset",
+			failed:   []bool{true, true, false, true, true, true, false, true, true, true},
+			expected: 2,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rec := &recorder{}
+			n := &Notifier{Sinks: []Sink{rec}}
+
+			finished := 0
+			l := &experiments.TrialLoop{
+				OnTrialFinish: func(*experiments.TrialAssignments, *experiments.TrialValues) { finished++ },
+			}
+			n.AttachTrialLoop(context.Background(), l, "test", 3)
+
+			for _, failed := range c.failed {
+				l.OnTrialFinish(&experiments.TrialAssignments{}, &experiments.TrialValues{Failed: failed})
+			}
+
+			assert.Equal(t, len(c.failed), finished)
+			assert.Len(t, *rec, c.expected)
+		})
+	}
+}
+
+func TestActivityEvent(t *testing.T) {
+	_, ok := ActivityEvent(&applications.ActivityItem{Tags: []string{applications.TagScan}})
+	assert.False(t, ok)
+
+	e, ok := ActivityEvent(&applications.ActivityItem{ID: "1", Tags: []string{applications.TagApprove}, ExternalURL: "https://example.com/r/1"})
+	if assert.True(t, ok) {
+		assert.Equal(t, EventRecommendation, e.Type)
+		assert.Equal(t, "https://example.com/r/1", e.URL)
+	}
+}
+
+func TestSlackSink_Send(t *testing.T) {
+	var msg map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+	}))
+	defer srv.Close()
+
+	s := &SlackSink{WebhookURL: srv.URL, Channel: "#optimize"}
+	assert.NoError(t, s.Send(context.Background(), &Event{Message: "stopped", URL: "https://example.com"}))
+	assert.Equal(t, map[string]interface{}{"text": "<https://example.com|stopped>", "channel": "#optimize"}, msg)
+
+	s.WebhookURL = srv.URL + "/missing"
+	srv.Config.Handler = http.NotFoundHandler()
+	assert.Error(t, s.Send(context.Background(), &Event{Message: "stopped"}))
+}
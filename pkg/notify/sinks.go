@@ -0,0 +1,104 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+)
+
+// WebhookSink posts each event as JSON to a URL.
+type WebhookSink struct {
+	// The URL events are posted to.
+	URL string
+	// The HTTP client used to send events. Defaults to `http.DefaultClient`.
+	Client *http.Client
+}
+
+// Send posts the event to the webhook.
+func (s *WebhookSink) Send(ctx context.Context, e *Event) error {
+	return postJSON(ctx, s.Client, s.URL, e)
+}
+
+// SlackSink posts each event as a message to a Slack incoming webhook.
+type SlackSink struct {
+	// The incoming webhook URL.
+	WebhookURL string
+	// Optional channel override, e.g. "#optimize".
+	Channel string
+	// Optional user name override.
+	Username string
+	// The HTTP client used to send events. Defaults to `http.DefaultClient`.
+	Client *http.Client
+}
+
+// slackMessage is the payload accepted by Slack incoming webhooks.
+type slackMessage struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// Send posts the event as a Slack message.
+func (s *SlackSink) Send(ctx context.Context, e *Event) error {
+	text := e.Message
+	if e.URL != "" {
+		// Slack link syntax: <url|text>
+		text = "<" + e.URL + "|" + e.Message + ">"
+	}
+
+	return postJSON(ctx, s.Client, s.WebhookURL, &slackMessage{
+		Text:     text,
+		Channel:  s.Channel,
+		Username: s.Username,
+	})
+}
+
+// postJSON posts a JSON body and checks for a successful response.
+func postJSON(ctx context.Context, client *http.Client, u string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return api.NewUnexpectedError(resp, respBody)
+	}
+	return nil
+}
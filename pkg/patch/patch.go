@@ -0,0 +1,145 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package patch renders the concrete manifests applied for a trial by
+// interpolating the trial assignments into a template manifest.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// Template is a manifest which is rendered for each trial.
+//
+// The manifest and the patch are Go templates: the parameter assignments are
+// available as `{{ .Values.paramName }}` (use `{{ index .Values "param-name" }}`
+// for names which are not valid identifiers) and the trial labels are available
+// as `{{ .Labels }}`. Integer and double parameters render as numbers,
+// categorical parameters render as (unquoted) strings. Referencing a parameter
+// without an assignment is an error.
+type Template struct {
+	// The template manifest, in YAML or JSON.
+	Manifest []byte
+	// Optional RFC 6902 JSON patch operations (in YAML or JSON) which are
+	// applied to the rendered manifest. The manifest must contain a single
+	// document when a patch is used.
+	Patch []byte
+}
+
+// Render returns the manifest to apply for the supplied trial assignments.
+func (t *Template) Render(ta *experiments.TrialAssignments) ([]byte, error) {
+	data := templateData{Values: Values(ta), Labels: ta.Labels}
+
+	manifest, err := execute("manifest", t.Manifest, &data)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(t.Patch)) == 0 {
+		return manifest, nil
+	}
+
+	patch, err := execute("patch", t.Patch, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyPatch(manifest, patch)
+}
+
+// Values returns the template values for the supplied trial assignments.
+func Values(ta *experiments.TrialAssignments) map[string]interface{} {
+	values := make(map[string]interface{}, len(ta.Assignments))
+	for _, a := range ta.Assignments {
+		v := a.Value
+		switch {
+		case v.IsString:
+			values[a.ParameterName] = v.StrVal
+		default:
+			if i, err := v.NumVal.Int64(); err == nil {
+				values[a.ParameterName] = i
+			} else {
+				values[a.ParameterName] = v.Float64Value()
+			}
+		}
+	}
+	return values
+}
+
+// templateData is the data exposed to the template.
+type templateData struct {
+	Values map[string]interface{}
+	Labels map[string]string
+}
+
+// execute renders a single template.
+func execute(name string, text []byte, data *templateData) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("unable to render %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// applyPatch applies the JSON patch operations to the manifest, the result
+// uses the same format (YAML or JSON) as the manifest.
+func applyPatch(manifest, patch []byte) ([]byte, error) {
+	if isMultiDocument(manifest) {
+		return nil, fmt.Errorf("a patch can only be applied to a manifest containing a single document")
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(manifest, &doc); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	var ops []Operation
+	if err := yaml.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	doc, err := Apply(doc, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(manifest); len(trimmed) > 0 && trimmed[0] == '{' {
+		return json.MarshalIndent(doc, "", "  ")
+	}
+	return yaml.Marshal(doc)
+}
+
+// isMultiDocument checks for YAML document separators after the first document.
+func isMultiDocument(manifest []byte) bool {
+	docs := 0
+	for _, doc := range strings.Split("\n"+string(manifest), "\n---") {
+		if strings.TrimSpace(doc) != "" {
+			docs++
+		}
+	}
+	return docs > 1
+}
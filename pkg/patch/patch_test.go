@@ -0,0 +1,208 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	ta := &experiments.TrialAssignments{
+		Assignments: []experiments.Assignment{
+			{ParameterName: "replicas", Value: api.FromInt64(3)},
+			{ParameterName: "cpu-ratio", Value: api.FromFloat64(0.5)},
+			{ParameterName: "tier", Value: api.FromString("gold")},
+		},
+		Labels: map[string]string{"baseline": "true"},
+	}
+
+	cases := []struct {
+		desc     string
+		template Template
+		expected string
+		err      bool
+	}{
+		{
+			desc: "values",
+			template: Template{Manifest: []byte(`
+spec:
+  replicas: {{ .Values.replicas }}
+  ratio: {{ index .Values "cpu-ratio" }}
+  tier: {{ .Values.tier }}
+  baseline: "{{ .Labels.baseline }}"
+`)},
+			expected: `
+spec:
+  replicas: 3
+  ratio: 0.5
+  tier: gold
+  baseline: "true"
+`,
+		},
+		{
+			desc:     "missing value",
+			template: Template{Manifest: []byte(`replicas: {{ .Values.other }}`)},
+			err:      true,
+		},
+		{
+			desc: "yaml patch",
+			template: Template{
+				Manifest: []byte("spec:\n  replicas: 1\n  template: {}\n"),
+				Patch: []byte(`
+- op: replace
+  path: /spec/replicas
+  value: {{ .Values.replicas }}
+- op: add
+  path: /spec/tier
+  value: {{ .Values.tier }}
+`),
+			},
+			expected: "spec:\n  replicas: 3\n  template: {}\n  tier: gold\n",
+		},
+		{
+			desc: "json patch",
+			template: Template{
+				Manifest: []byte(`{"spec":{"replicas":1}}`),
+				Patch:    []byte(`[{"op":"replace","path":"/spec/replicas","value":{{ .Values.replicas }}}]`),
+			},
+			expected: "{\n  \"spec\": {\n    \"replicas\": 3\n  }\n}",
+		},
+		{
+			desc: "patch multiple documents",
+			template: Template{
+				Manifest: []byte("a: 1\n---\nb: 2\n"),
+				Patch:    []byte(`[{"op":"remove","path":"/a"}]`),
+			},
+			err: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			actual, err := c.template.Render(ta)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, c.expected, string(actual))
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		desc     string
+		doc      string
+		ops      string
+		expected string
+		err      bool
+	}{
+		{
+			desc:     "add to object",
+			doc:      `{"a":1}`,
+			ops:      `[{"op":"add","path":"/b","value":[1]}]`,
+			expected: `{"a":1,"b":[1]}`,
+		},
+		{
+			desc:     "insert into nested array",
+			doc:      `{"a":[[1,3]]}`,
+			ops:      `[{"op":"add","path":"/a/0/1","value":2},{"op":"add","path":"/a/0/-","value":4}]`,
+			expected: `{"a":[[1,2,3,4]]}`,
+		},
+		{
+			desc:     "remove from array",
+			doc:      `{"a":[1,2,3]}`,
+			ops:      `[{"op":"remove","path":"/a/1"}]`,
+			expected: `{"a":[1,3]}`,
+		},
+		{
+			desc:     "replace escaped key",
+			doc:      `{"a/b":{"c~d":1}}`,
+			ops:      `[{"op":"replace","path":"/a~1b/c~0d","value":2}]`,
+			expected: `{"a/b":{"c~d":2}}`,
+		},
+		{
+			desc: "replace missing",
+			doc:  `{"a":1}`,
+			ops:  `[{"op":"replace","path":"/b","value":2}]`,
+			err:  true,
+		},
+		{
+			desc:     "move and copy",
+			doc:      `{"a":{"x":1},"b":{}}`,
+			ops:      `[{"op":"copy","from":"/a/x","path":"/b/y"},{"op":"move","from":"/a","path":"/c"}]`,
+			expected: `{"b":{"y":1},"c":{"x":1}}`,
+		},
+		{
+			desc: "move into child",
+			doc:  `{"a":{"b":{}}}`,
+			ops:  `[{"op":"move","from":"/a","path":"/a/b/c"}]`,
+			err:  true,
+		},
+		{
+			desc:     "test",
+			doc:      `{"a":[1,"x"]}`,
+			ops:      `[{"op":"test","path":"/a","value":[1,"x"]}]`,
+			expected: `{"a":[1,"x"]}`,
+		},
+		{
+			desc: "test failed",
+			doc:  `{"a":1}`,
+			ops:  `[{"op":"test","path":"/a","value":2}]`,
+			err:  true,
+		},
+		{
+			desc: "invalid index",
+			doc:  `{"a":[1]}`,
+			ops:  `[{"op":"add","path":"/a/01","value":2}]`,
+			err:  true,
+		},
+		{
+			desc: "unknown operation",
+			doc:  `{}`,
+			ops:  `[{"op":"merge","path":"/a"}]`,
+			err:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var doc interface{}
+			var ops []Operation
+			if !assert.NoError(t, json.Unmarshal([]byte(c.doc), &doc)) || !assert.NoError(t, json.Unmarshal([]byte(c.ops), &ops)) {
+				return
+			}
+
+			actual, err := Apply(doc, ops)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				data, err := json.Marshal(actual)
+				if assert.NoError(t, err) {
+					assert.JSONEq(t, c.expected, string(data))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preset provides canned experiment definitions for common tuning problems.
+package preset
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// Workload describes the workload an experiment is generated for. The current
+// values are used to center the parameter ranges; zero values are replaced by
+// reasonable defaults.
+type Workload struct {
+	// The name of the workload, used in the experiment title.
+	Name string
+	// The current CPU request in cores.
+	CPU float64
+	// The current memory request in bytes.
+	Memory float64
+	// The maximum number of replicas an autoscaler may create.
+	MaxReplicas int64
+}
+
+// Default workload values used when the workload does not specify them.
+const (
+	defaultCPU         = 1.0
+	defaultMemory      = 1 << 30
+	defaultMaxReplicas = 10
+)
+
+// Preset is a canned experiment definition.
+type Preset struct {
+	// The name used to select the preset, e.g. "jvm".
+	Name string
+	// A short description of what the preset tunes.
+	Description string
+
+	parameters func(w *Workload) ([]experiments.TypedParameter, error)
+	constraint func(params []experiments.Parameter) (*experiments.Constraint, error)
+}
+
+// presets are the built-in presets, keyed by name.
+var presets = map[string]*Preset{
+	"jvm": {
+		Name:        "jvm",
+		Description: "JVM heap and garbage collector tuning along with the container resources",
+		parameters: func(w *Workload) ([]experiments.TypedParameter, error) {
+			return typedParameters(
+				cpuParameter(w),
+				memoryParameter(w),
+				intParameter("max_ram_percentage", 25, 90),
+				categoricalParameter("gc", "G1GC", "ParallelGC", "SerialGC", "ZGC"),
+			)
+		},
+	},
+	"go": {
+		Name:        "go",
+		Description: "Go garbage collector tuning (GOGC and GOMEMLIMIT) along with the container resources",
+		parameters: func(w *Workload) ([]experiments.TypedParameter, error) {
+			return typedParameters(
+				cpuParameter(w),
+				memoryParameter(w),
+				intParameter("gogc", 25, 400),
+				intParameter("gomemlimit_percentage", 50, 95),
+			)
+		},
+	},
+	"hpa": {
+		Name:        "hpa",
+		Description: "Horizontal Pod Autoscaler target utilization and replica bounds along with the container resources",
+		parameters: func(w *Workload) ([]experiments.TypedParameter, error) {
+			maxReplicas := w.MaxReplicas
+			if maxReplicas < 2 {
+				return nil, fmt.Errorf("the HPA preset requires at least 2 replicas, got %d", maxReplicas)
+			}
+			return typedParameters(
+				cpuParameter(w),
+				memoryParameter(w),
+				intParameter("target_utilization", 30, 90),
+				intParameter("min_replicas", 1, maxReplicas-1),
+				intParameter("max_replicas", 2, maxReplicas),
+			)
+		},
+		constraint: func(params []experiments.Parameter) (*experiments.Constraint, error) {
+			c, err := experiments.NewOrderConstraint("min_replicas", "max_replicas").Named("replicas").Build(params)
+			return &c, err
+		},
+	},
+	"rightsizing": {
+		Name:        "rightsizing",
+		Description: "Generic CPU and memory right-sizing of the container resources",
+		parameters: func(w *Workload) ([]experiments.TypedParameter, error) {
+			return typedParameters(
+				cpuParameter(w),
+				memoryParameter(w),
+			)
+		},
+	},
+}
+
+// Names returns the sorted names of the presets.
+func Names() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named preset.
+func Get(name string) (*Preset, error) {
+	p, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q, expected one of: %s", name, strings.Join(Names(), ", "))
+	}
+	return p, nil
+}
+
+// Experiment returns a new experiment definition for the supplied workload. The
+// experiment optimizes cost and latency, the "cpu" parameter is in millicores
+// and the "memory" parameter is in mebibytes.
+func (p *Preset) Experiment(w Workload) (experiments.Experiment, error) {
+	if w.CPU <= 0 {
+		w.CPU = defaultCPU
+	}
+	if w.Memory <= 0 {
+		w.Memory = defaultMemory
+	}
+	if w.MaxReplicas <= 0 {
+		w.MaxReplicas = defaultMaxReplicas
+	}
+
+	typed, err := p.parameters(&w)
+	if err != nil {
+		return experiments.Experiment{}, err
+	}
+
+	exp := experiments.Experiment{
+		DisplayName: p.Description,
+		Metrics: []experiments.Metric{
+			{Name: "cost", Minimize: true, Unit: api.UnitDollarsPerMonth},
+			{Name: "latency", Minimize: true, Unit: api.UnitMilliseconds},
+		},
+		Labels: map[string]string{"preset": p.Name},
+	}
+	if w.Name != "" {
+		exp.DisplayName = fmt.Sprintf("%s: %s", w.Name, exp.DisplayName)
+	}
+	for _, tp := range typed {
+		exp.Parameters = append(exp.Parameters, tp.Parameter())
+	}
+
+	if p.constraint != nil {
+		c, err := p.constraint(exp.Parameters)
+		if err != nil {
+			return experiments.Experiment{}, err
+		}
+		exp.Constraints = append(exp.Constraints, *c)
+	}
+
+	return exp, experiments.Validate(&exp)
+}
+
+// parameterFunc returns a single typed parameter.
+type parameterFunc func() (experiments.TypedParameter, error)
+
+// typedParameters returns the parameters, stopping at the first error.
+func typedParameters(fns ...parameterFunc) ([]experiments.TypedParameter, error) {
+	params := make([]experiments.TypedParameter, 0, len(fns))
+	for _, fn := range fns {
+		p, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+func intParameter(name string, min, max int64) parameterFunc {
+	return func() (experiments.TypedParameter, error) {
+		return experiments.NewIntParameter(name, min, max)
+	}
+}
+
+func categoricalParameter(name string, values ...string) parameterFunc {
+	return func() (experiments.TypedParameter, error) {
+		return experiments.NewCategoricalParameter(name, values...)
+	}
+}
+
+// cpuParameter returns the CPU request in millicores, ranging from a quarter
+// to twice the current request.
+func cpuParameter(w *Workload) parameterFunc {
+	min, max := scaledRange(w.CPU*1000, 10)
+	return intParameter("cpu", min, max)
+}
+
+// memoryParameter returns the memory request in mebibytes, ranging from a
+// quarter to twice the current request.
+func memoryParameter(w *Workload) parameterFunc {
+	min, max := scaledRange(w.Memory/(1<<20), 32)
+	return intParameter("memory", min, max)
+}
+
+// scaledRange returns the range from a quarter to twice the current value,
+// never going below the supplied floor.
+func scaledRange(current, floor float64) (int64, int64) {
+	min := math.Max(math.Floor(current/4), floor)
+	max := math.Max(math.Ceil(current*2), min+1)
+	return int64(min), int64(max)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+func TestPreset_Experiment(t *testing.T) {
+	cases := []struct {
+		desc        string
+		preset      string
+		workload    Workload
+		parameters  []string
+		bounds      map[string][2]string
+		constraints int
+		err         bool
+	}{
+		{
+			desc:       "jvm",
+			preset:     "jvm",
+			workload:   Workload{Name: "app", CPU: 0.5, Memory: 2 << 30},
+			parameters: []string{"cpu", "memory", "max_ram_percentage", "gc"},
+			bounds: map[string][2]string{
+				"cpu":    {"125", "1000"},
+				"memory": {"512", "4096"},
+			},
+		},
+		{
+			desc:       "go defaults",
+			preset:     "GO",
+			parameters: []string{"cpu", "memory", "gogc", "gomemlimit_percentage"},
+			bounds: map[string][2]string{
+				"cpu":    {"250", "2000"},
+				"memory": {"256", "2048"},
+			},
+		},
+		{
+			desc:        "hpa",
+			preset:      "hpa",
+			workload:    Workload{MaxReplicas: 5},
+			parameters:  []string{"cpu", "memory", "target_utilization", "min_replicas", "max_replicas"},
+			bounds:      map[string][2]string{"min_replicas": {"1", "4"}, "max_replicas": {"2", "5"}},
+			constraints: 1,
+		},
+		{
+			desc:     "hpa single replica",
+			preset:   "hpa",
+			workload: Workload{MaxReplicas: 1},
+			err:      true,
+		},
+		{
+			desc:       "small rightsizing",
+			preset:     "rightsizing",
+			workload:   Workload{CPU: 0.001, Memory: 1 << 20},
+			parameters: []string{"cpu", "memory"},
+			bounds: map[string][2]string{
+				"cpu":    {"10", "11"},
+				"memory": {"32", "33"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			p, err := Get(c.preset)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			exp, err := p.Experiment(c.workload)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var names []string
+			for _, param := range exp.Parameters {
+				names = append(names, param.Name)
+				if b, ok := c.bounds[param.Name]; ok && assert.NotNil(t, param.Bounds) {
+					assert.Equal(t, b[0], param.Bounds.Min.String(), param.Name)
+					assert.Equal(t, b[1], param.Bounds.Max.String(), param.Name)
+				}
+			}
+			assert.Equal(t, c.parameters, names)
+			assert.Len(t, exp.Constraints, c.constraints)
+			assert.Equal(t, p.Name, exp.Labels["preset"])
+			assert.NoError(t, experiments.Validate(&exp))
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	assert.Equal(t, []string{"go", "hpa", "jvm", "rightsizing"}, Names())
+
+	_, err := Get("python")
+	assert.EqualError(t, err, `unknown preset "python", expected one of: go, hpa, jvm, rightsizing`)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// History persists numbered revisions of individual resources as JSON files in a directory.
+type History struct {
+	// The directory containing the history files.
+	Dir string
+}
+
+// Revision is a recorded state of a resource.
+type Revision struct {
+	// The revision number, starting at 1.
+	Number int `json:"revision"`
+	// The time the revision was recorded.
+	Timestamp time.Time `json:"timestamp"`
+	// The recorded resource.
+	Data json.RawMessage `json:"data"`
+}
+
+// Record adds a new revision of the resource identified by the key. If the
+// resource is unchanged from the latest revision, the latest revision is returned.
+func (h *History) Record(key string, obj interface{}) (*Revision, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	revs, err := h.Revisions(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if n := len(revs); n > 0 && bytes.Equal(compact(revs[n-1].Data), compact(data)) {
+		return &revs[n-1], nil
+	}
+
+	rev := Revision{Number: len(revs) + 1, Timestamp: time.Now().UTC(), Data: data}
+	revs = append(revs, rev)
+
+	if err := os.MkdirAll(h.Dir, 0700); err != nil {
+		return nil, err
+	}
+
+	out, err := json.Marshal(revs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(h.filename(key), out, 0600); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// Revisions returns all the recorded revisions of the resource identified by the key, oldest first.
+func (h *History) Revisions(key string) ([]Revision, error) {
+	data, err := os.ReadFile(h.filename(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var revs []Revision
+	if err := json.Unmarshal(data, &revs); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// Get decodes the numbered revision of the resource identified by the key into the supplied object.
+func (h *History) Get(key string, number int, obj interface{}) error {
+	revs, err := h.Revisions(key)
+	if err != nil {
+		return err
+	}
+
+	for _, rev := range revs {
+		if rev.Number == number {
+			return json.Unmarshal(rev.Data, obj)
+		}
+	}
+	return fmt.Errorf("revision %d of %s not found", number, key)
+}
+
+// filename returns the name of the file containing the revisions of a resource.
+func (h *History) filename(key string) string {
+	return filepath.Join(h.Dir, url.PathEscape(key)+".json")
+}
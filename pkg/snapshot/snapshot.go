@@ -0,0 +1,190 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot records the state of the API resources at a point in time
+// so changes can be reported between any two recorded points.
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+	experiments "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
+)
+
+// Kind identifies the type of resource recorded in a snapshot.
+type Kind string
+
+const (
+	KindApplication    Kind = "application"
+	KindExperiment     Kind = "experiment"
+	KindRecommendation Kind = "recommendation"
+	KindScenario       Kind = "scenario"
+)
+
+// Snapshot is the recorded state of the resources at a specific time.
+type Snapshot struct {
+	// The time the snapshot was taken.
+	Timestamp time.Time `json:"timestamp"`
+	// The resources, indexed by kind and name.
+	Resources map[Kind]map[string]json.RawMessage `json:"resources"`
+}
+
+// Add records a resource in the snapshot.
+func (s *Snapshot) Add(kind Kind, name string, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	if s.Resources == nil {
+		s.Resources = make(map[Kind]map[string]json.RawMessage)
+	}
+	if s.Resources[kind] == nil {
+		s.Resources[kind] = make(map[string]json.RawMessage)
+	}
+	s.Resources[kind][name] = data
+	return nil
+}
+
+// Get decodes a recorded resource into the supplied object, returning false
+// if the resource was not recorded in the snapshot.
+func (s *Snapshot) Get(kind Kind, name string, obj interface{}) (bool, error) {
+	data, ok := s.Resources[kind][name]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, obj)
+}
+
+// Names returns the sorted names of the recorded resources of the specified
+// kind which start with the supplied prefix.
+func (s *Snapshot) Names(kind Kind, prefix string) []string {
+	var names []string
+	for name := range s.Resources[kind] {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Capture records the current state of all the applications (including their
+// scenarios and recommendations) and experiments. Either API may be nil to skip those resources.
+func Capture(ctx context.Context, appAPI applications.API, expAPI experiments.API) (*Snapshot, error) {
+	s := &Snapshot{Timestamp: time.Now().UTC()}
+
+	if appAPI != nil {
+		l := applications.Lister{API: appAPI}
+		if err := l.ForEachApplication(ctx, applications.ApplicationListQuery{}, func(item *applications.ApplicationItem) error {
+			if err := s.Add(KindApplication, item.Name.String(), item); err != nil {
+				return err
+			}
+
+			if err := l.ForEachScenario(ctx, &item.Application, applications.ScenarioListQuery{}, func(scn *applications.ScenarioItem) error {
+				return s.Add(KindScenario, item.Name.String()+"/"+scn.Name.String(), scn)
+			}); err != nil {
+				return err
+			}
+
+			return l.ForEachRecommendation(ctx, &item.Application, func(rec *applications.RecommendationItem) error {
+				return s.Add(KindRecommendation, item.Name.String()+"/"+rec.Name, rec)
+			})
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if expAPI != nil {
+		l := experiments.Lister{API: expAPI}
+		if err := l.ForEachExperiment(ctx, experiments.ExperimentListQuery{}, func(item *experiments.ExperimentItem) error {
+			return s.Add(KindExperiment, item.Name.String(), item)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// ChangeType describes how a resource changed between snapshots.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// Change describes a single resource that differs between two snapshots.
+type Change struct {
+	Kind Kind       `json:"kind"`
+	Name string     `json:"name"`
+	Type ChangeType `json:"change"`
+}
+
+// Diff returns the changes required to go from the "from" snapshot to the
+// "to" snapshot, ordered by kind and name.
+func Diff(from, to *Snapshot) []Change {
+	var changes []Change
+
+	kinds := make(map[Kind]struct{})
+	for k := range from.Resources {
+		kinds[k] = struct{}{}
+	}
+	for k := range to.Resources {
+		kinds[k] = struct{}{}
+	}
+
+	for kind := range kinds {
+		before, after := from.Resources[kind], to.Resources[kind]
+		for name, data := range after {
+			if old, ok := before[name]; !ok {
+				changes = append(changes, Change{Kind: kind, Name: name, Type: ChangeAdded})
+			} else if !bytes.Equal(compact(old), compact(data)) {
+				changes = append(changes, Change{Kind: kind, Name: name, Type: ChangeModified})
+			}
+		}
+		for name := range before {
+			if _, ok := after[name]; !ok {
+				changes = append(changes, Change{Kind: kind, Name: name, Type: ChangeRemoved})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// compact removes insignificant whitespace so stored values compare equal.
+func compact(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
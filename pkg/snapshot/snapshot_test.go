@@ -0,0 +1,184 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+func TestDiff(t *testing.T) {
+	from := &Snapshot{}
+	require.NoError(t, from.Add(KindApplication, "a", map[string]string{"title": "A"}))
+	require.NoError(t, from.Add(KindApplication, "b", map[string]string{"title": "B"}))
+	require.NoError(t, from.Add(KindExperiment, "x", map[string]string{"title": "X"}))
+
+	to := &Snapshot{}
+	require.NoError(t, to.Add(KindApplication, "a", map[string]string{"title": "A"}))
+	require.NoError(t, to.Add(KindApplication, "c", map[string]string{"title": "C"}))
+	require.NoError(t, to.Add(KindExperiment, "x", map[string]string{"title": "X2"}))
+
+	assert.Equal(t, []Change{
+		{Kind: KindApplication, Name: "b", Type: ChangeRemoved},
+		{Kind: KindApplication, Name: "c", Type: ChangeAdded},
+		{Kind: KindExperiment, Name: "x", Type: ChangeModified},
+	}, Diff(from, to))
+}
+
+func TestSnapshot_Get(t *testing.T) {
+	s := &Snapshot{}
+	require.NoError(t, s.Add(KindScenario, "a/one", map[string]string{"title": "One"}))
+	require.NoError(t, s.Add(KindScenario, "a/two", map[string]string{"title": "Two"}))
+	require.NoError(t, s.Add(KindScenario, "b/one", map[string]string{"title": "Other"}))
+
+	assert.Equal(t, []string{"a/one", "a/two"}, s.Names(KindScenario, "a/"))
+
+	var obj map[string]string
+	ok, err := s.Get(KindScenario, "a/two", &obj)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"title": "Two"}, obj)
+
+	ok, err = s.Get(KindApplication, "a", &obj)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStore_LoadAt(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+
+	t1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	require.NoError(t, s.Save(&Snapshot{Timestamp: t1}))
+	require.NoError(t, s.Save(&Snapshot{Timestamp: t2}))
+
+	snap, err := s.LoadAt(t1.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, t1, snap.Timestamp)
+
+	snap, err = s.LoadAt(time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, t2, snap.Timestamp)
+
+	_, err = s.LoadAt(t1.Add(-time.Minute))
+	assert.Error(t, err)
+}
+
+func TestHistory(t *testing.T) {
+	h := &History{Dir: t.TempDir()}
+
+	revs, err := h.Revisions("/v2/applications/a/scenarios/s/template")
+	require.NoError(t, err)
+	assert.Empty(t, revs)
+
+	rev, err := h.Record("/v2/applications/a/scenarios/s/template", map[string]string{"v": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rev.Number)
+
+	// Unchanged values do not create a new revision
+	rev, err = h.Record("/v2/applications/a/scenarios/s/template", map[string]string{"v": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rev.Number)
+
+	rev, err = h.Record("/v2/applications/a/scenarios/s/template", map[string]string{"v": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, rev.Number)
+
+	revs, err = h.Revisions("/v2/applications/a/scenarios/s/template")
+	require.NoError(t, err)
+	assert.Len(t, revs, 2)
+
+	var obj map[string]string
+	require.NoError(t, h.Get("/v2/applications/a/scenarios/s/template", 1, &obj))
+	assert.Equal(t, map[string]string{"v": "1"}, obj)
+	assert.Error(t, h.Get("/v2/applications/a/scenarios/s/template", 3, &obj))
+}
+
+// templateAPI holds a single template in memory.
+type templateAPI struct {
+	applications.API
+	template *applications.Template
+}
+
+func (a *templateAPI) GetTemplate(context.Context, string) (applications.Template, error) {
+	if a.template == nil {
+		return applications.Template{}, &api.Error{Type: "template-not-found", StatusCode: http.StatusNotFound}
+	}
+	return *a.template, nil
+}
+
+func (a *templateAPI) UpdateTemplate(_ context.Context, _ string, t applications.Template) error {
+	a.template = &t
+	return nil
+}
+
+func TestTemplateRecorder(t *testing.T) {
+	h := &History{Dir: t.TempDir()}
+	r := &TemplateRecorder{API: &templateAPI{}, History: h}
+	u := "https://api.example.com/v2/applications/a/scenarios/s/template"
+	ctx := context.Background()
+
+	// There is nothing to record the first time
+	t1 := applications.Template{Parameters: []applications.TemplateParameter{{Name: "cpu", Type: "int"}}}
+	require.NoError(t, r.UpdateTemplate(ctx, u, t1))
+
+	t2 := applications.Template{Parameters: []applications.TemplateParameter{{Name: "memory", Type: "int"}}}
+	require.NoError(t, r.UpdateTemplate(ctx, u, t2))
+
+	revs, err := h.Revisions(TemplateKey(u))
+	require.NoError(t, err)
+	if assert.Len(t, revs, 1) {
+		var actual applications.Template
+		require.NoError(t, h.Get(TemplateKey(u), revs[0].Number, &actual))
+		assert.Equal(t, t1, actual)
+	}
+}
+
+func (a *templateAPI) GetScenario(context.Context, string) (applications.Scenario, error) {
+	return applications.Scenario{Metadata: api.Metadata{"Link": {`<https://api.example.com/v2/applications/a/scenarios/s/template>; rel="https://stormforge.io/rel/template"`}}}, nil
+}
+
+func (a *templateAPI) PatchScenario(context.Context, string, applications.Scenario) error {
+	// Changing the scenario regenerates the template
+	a.template = &applications.Template{Parameters: []applications.TemplateParameter{{Name: "replicas", Type: "int"}}}
+	return nil
+}
+
+func TestTemplateRecorder_PatchScenario(t *testing.T) {
+	h := &History{Dir: t.TempDir()}
+	t1 := applications.Template{Parameters: []applications.TemplateParameter{{Name: "cpu", Type: "int"}}}
+	r := &TemplateRecorder{API: &templateAPI{template: &t1}, History: h}
+	ctx := context.Background()
+
+	require.NoError(t, r.PatchScenario(ctx, "https://api.example.com/v2/applications/a/scenarios/s", applications.Scenario{}))
+
+	key := TemplateKey("https://api.example.com/v2/applications/a/scenarios/s/template")
+	revs, err := h.Revisions(key)
+	require.NoError(t, err)
+	if assert.Len(t, revs, 1) {
+		var actual applications.Template
+		require.NoError(t, h.Get(key, revs[0].Number, &actual))
+		assert.Equal(t, t1, actual)
+	}
+}
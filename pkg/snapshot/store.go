@@ -0,0 +1,107 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timestampLayout is used for file names, it must sort lexicographically.
+const timestampLayout = "20060102T150405.000000000Z"
+
+// Store persists snapshots as JSON files in a directory.
+type Store struct {
+	// The directory containing the snapshot files.
+	Dir string
+}
+
+// Save writes the snapshot to the store.
+func (s *Store) Save(snap *Snapshot) error {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(s.Dir, snap.Timestamp.UTC().Format(timestampLayout)+".json")
+	return os.WriteFile(name, data, 0600)
+}
+
+// Timestamps returns the times of all the stored snapshots in ascending order.
+func (s *Store) Timestamps() ([]time.Time, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var result []time.Time
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		if t, err := time.Parse(timestampLayout, strings.TrimSuffix(e.Name(), ".json")); err == nil {
+			result = append(result, t)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result, nil
+}
+
+// Load reads the snapshot taken at the specified time.
+func (s *Store) Load(t time.Time) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, t.UTC().Format(timestampLayout)+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{}
+	if err := json.Unmarshal(data, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// LoadAt reads the most recent snapshot taken at or before the specified time,
+// a zero time loads the most recent snapshot.
+func (s *Store) LoadAt(t time.Time) (*Snapshot, error) {
+	ts, err := s.Timestamps()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(ts) - 1; i >= 0; i-- {
+		if t.IsZero() || !ts[i].After(t) {
+			return s.Load(ts[i])
+		}
+	}
+
+	if t.IsZero() {
+		return nil, fmt.Errorf("no snapshots found in %s", s.Dir)
+	}
+	return nil, fmt.Errorf("no snapshot found at or before %s", t.Format(time.RFC3339))
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/thestormforge/optimize-go/pkg/api"
+	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
+)
+
+// TemplateRecorder is an application API which records the current template
+// in a history before it is changed, allowing changes to be rolled back.
+// Scenario changes are also recorded since the server may regenerate the
+// template of a scenario when it changes.
+type TemplateRecorder struct {
+	applications.API
+	// The history used to record templates.
+	History *History
+}
+
+// UpdateTemplate records the current template before updating it.
+func (r *TemplateRecorder) UpdateTemplate(ctx context.Context, u string, t applications.Template) error {
+	if err := r.record(ctx, u); err != nil {
+		return err
+	}
+	return r.API.UpdateTemplate(ctx, u, t)
+}
+
+// PatchTemplate records the current template before patching it.
+func (r *TemplateRecorder) PatchTemplate(ctx context.Context, u string, t applications.Template) error {
+	if err := r.record(ctx, u); err != nil {
+		return err
+	}
+	return r.API.PatchTemplate(ctx, u, t)
+}
+
+// UpdateScenario records the current template of the scenario before updating it.
+func (r *TemplateRecorder) UpdateScenario(ctx context.Context, u string, scn applications.Scenario) (applications.Scenario, error) {
+	current, err := r.API.GetScenario(ctx, u)
+	if err := r.recordScenario(ctx, &current, err); err != nil {
+		return applications.Scenario{}, err
+	}
+	return r.API.UpdateScenario(ctx, u, scn)
+}
+
+// UpdateScenarioByName records the current template of the scenario before updating it.
+func (r *TemplateRecorder) UpdateScenarioByName(ctx context.Context, u string, n applications.ScenarioName, scn applications.Scenario) (applications.Scenario, error) {
+	current, err := r.API.GetScenarioByName(ctx, u, n)
+	if err := r.recordScenario(ctx, &current, err); err != nil {
+		return applications.Scenario{}, err
+	}
+	return r.API.UpdateScenarioByName(ctx, u, n, scn)
+}
+
+// PatchScenario records the current template of the scenario before patching it.
+func (r *TemplateRecorder) PatchScenario(ctx context.Context, u string, scn applications.Scenario) error {
+	current, err := r.API.GetScenario(ctx, u)
+	if err := r.recordScenario(ctx, &current, err); err != nil {
+		return err
+	}
+	return r.API.PatchScenario(ctx, u, scn)
+}
+
+// RecordAll adds the current template of every scenario to the history, this
+// captures changes which were made without recording the previous template.
+func (r *TemplateRecorder) RecordAll(ctx context.Context) error {
+	l := applications.Lister{API: r.API}
+	return l.ForEachApplication(ctx, applications.ApplicationListQuery{}, func(item *applications.ApplicationItem) error {
+		return l.ForEachScenario(ctx, &item.Application, applications.ScenarioListQuery{}, func(scn *applications.ScenarioItem) error {
+			return r.recordScenario(ctx, &scn.Scenario, nil)
+		})
+	})
+}
+
+// recordScenario adds the current template of a fetched scenario to the
+// history, scenarios which do not exist (yet) are ignored.
+func (r *TemplateRecorder) recordScenario(ctx context.Context, scn *applications.Scenario, err error) error {
+	if api.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if u := scn.Link(api.RelationTemplate); u != "" {
+		return r.record(ctx, u)
+	}
+	return nil
+}
+
+// record adds the current template to the history.
+func (r *TemplateRecorder) record(ctx context.Context, u string) error {
+	t, err := r.API.GetTemplate(ctx, u)
+	if api.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err = r.History.Record(TemplateKey(u), t)
+	return err
+}
+
+// TemplateKey returns the history key for the template at the supplied URL.
+func TemplateKey(u string) string {
+	if uu, err := url.Parse(u); err == nil && uu.Path != "" {
+		return uu.Path
+	}
+	return u
+}